@@ -4,11 +4,13 @@
 package amass
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -21,48 +23,107 @@ import (
 const (
 	defaultTLSConnectTimeout = 1 * time.Second
 	defaultHandshakeDeadline = 3 * time.Second
+
+	// Certificates expiring within this time are reported before they expire
+	certExpiryWarning = 30 * 24 * time.Hour
 )
 
-// pullCertificate - Attempts to pull a cert from several ports on an IP
+// PullCertificateNames - Attempts to pull a cert from several ports on an IP
 func PullCertificateNames(addr string, ports []int) []*core.AmassRequest {
 	var requests []*core.AmassRequest
 
 	// Check hosts for certificates that contain subdomain names
+	for _, c := range PullCertificates(addr, ports) {
+		// Create the new requests from names found within the cert
+		requests = append(requests, reqFromNames(namesFromCert(c.Certificate))...)
+	}
+	return requests
+}
+
+// PullCertificates - Returns the certificates presented on the ports of the IP
+func PullCertificates(addr string, ports []int) []*core.CertRequest {
+	var certs []*core.CertRequest
+
 	for _, port := range ports {
-		cfg := &tls.Config{InsecureSkipVerify: true}
-		// Set the maximum time allowed for making the connection
-		ctx, cancel := context.WithTimeout(context.Background(), defaultTLSConnectTimeout)
-		defer cancel()
-		// Obtain the connection
-		conn, err := dnssrv.DialContext(ctx, "tcp", addr+":"+strconv.Itoa(port))
+		cert, version, err := pullCertificate(addr, port, "")
 		if err != nil {
 			continue
 		}
-		defer conn.Close()
-		c := tls.Client(conn, cfg)
-		// Attempt to acquire the certificate chain
-		errChan := make(chan error, 2)
-		// This goroutine will break us out of the handshake
-		time.AfterFunc(defaultHandshakeDeadline, func() {
-			errChan <- errors.New("Handshake timeout")
+
+		certs = append(certs, &core.CertRequest{
+			Address:     addr,
+			Port:        port,
+			Certificate: cert,
+			Version:     version,
+			Tag:         core.CERT,
+			Source:      "Active Cert",
 		})
-		// Be sure we do not wait too long in this attempt
-		c.SetDeadline(time.Now().Add(defaultHandshakeDeadline))
-		// The handshake is performed in the goroutine
-		go func() {
-			errChan <- c.Handshake()
-		}()
-		// The error channel returns handshake or timeout error
-		if err = <-errChan; err != nil {
-			continue
-		}
-		// Get the correct certificate in the chain
-		certChain := c.ConnectionState().PeerCertificates
-		cert := certChain[0]
-		// Create the new requests from names found within the cert
-		requests = append(requests, reqFromNames(namesFromCert(cert))...)
 	}
-	return requests
+	return certs
+}
+
+// pullCertificate - Performs the TLS handshake and returns the certificate presented by
+// the server, and the protocol version negotiated. When serverName is not empty, it is
+// sent as the SNI value
+func pullCertificate(addr string, port int, serverName string) (*x509.Certificate, uint16, error) {
+	state, err := tlsHandshake(addr, port, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	// Get the correct certificate in the chain
+	certChain := state.PeerCertificates
+	if len(certChain) == 0 {
+		return nil, 0, errors.New("No certificates were presented")
+	}
+	return certChain[0], state.Version, nil
+}
+
+// legacyVersion - Returns the protocol version older than TLS 1.2 that the server
+// accepts, or zero when the server refuses them
+func legacyVersion(addr string, port int, serverName string) uint16 {
+	state, err := tlsHandshake(addr, port, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS10,
+		MaxVersion:         tls.VersionTLS11,
+	})
+	if err != nil {
+		return 0
+	}
+	return state.Version
+}
+
+func tlsHandshake(addr string, port int, cfg *tls.Config) (tls.ConnectionState, error) {
+	// Set the maximum time allowed for making the connection
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTLSConnectTimeout)
+	defer cancel()
+	// Obtain the connection
+	conn, err := dnssrv.DialContext(ctx, "tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	c := tls.Client(conn, cfg)
+	// Attempt to acquire the certificate chain
+	errChan := make(chan error, 2)
+	// This goroutine will break us out of the handshake
+	time.AfterFunc(defaultHandshakeDeadline, func() {
+		errChan <- errors.New("Handshake timeout")
+	})
+	// Be sure we do not wait too long in this attempt
+	c.SetDeadline(time.Now().Add(defaultHandshakeDeadline))
+	// The handshake is performed in the goroutine
+	go func() {
+		errChan <- c.Handshake()
+	}()
+	// The error channel returns handshake or timeout error
+	if err = <-errChan; err != nil {
+		return tls.ConnectionState{}, err
+	}
+	return c.ConnectionState(), nil
 }
 
 func namesFromCert(cert *x509.Certificate) []string {
@@ -118,9 +179,127 @@ func reqFromNames(subdomains []string) []*core.AmassRequest {
 		requests = append(requests, &core.AmassRequest{
 			Name:   name,
 			Domain: SubdomainToDomain(name),
-			Tag:    "cert",
+			Tag:    core.CERT,
 			Source: "Active Cert",
 		})
 	}
 	return requests
 }
+
+// expiryFinding - Returns the finding for the certificate when it has expired, or expires
+// within certExpiryWarning, and was presented for a name within the domains
+func expiryFinding(req *core.CertRequest, domains []string, now time.Time) *core.Finding {
+	name, domain := certHostName(req, domains)
+	if name == "" {
+		return nil
+	}
+
+	cert := req.Certificate
+	f := &core.Finding{
+		Name:   name,
+		Domain: domain,
+		Evidence: []string{fmt.Sprintf("%s port %d: valid from %s until %s", req.Address, req.Port,
+			cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))},
+	}
+
+	expires := cert.NotAfter.UTC().Format("2006-01-02")
+	if now.After(cert.NotAfter) {
+		f.Type = core.FindingExpiredCert
+		f.Severity = core.SeverityMedium
+		f.Description = "The certificate presented for the name expired on " + expires
+	} else if cert.NotAfter.Sub(now) < certExpiryWarning {
+		f.Type = core.FindingExpiringCert
+		f.Severity = core.SeverityLow
+		f.Description = "The certificate presented for the name expires on " + expires
+	} else {
+		return nil
+	}
+	return f
+}
+
+// tlsFindings - Returns the findings for the weak protocol version, the self-signed certificate
+// or the certificate that is not valid for the name, when presented for a name within the domains
+func tlsFindings(req *core.CertRequest, info *AmassCertInfo, domains []string) []*core.Finding {
+	var findings []*core.Finding
+
+	name, domain := certHostName(req, domains)
+	if name == "" {
+		return findings
+	}
+
+	evidence := fmt.Sprintf("%s port %d", req.Address, req.Port)
+	if weak := weakestVersion(info); weak != "" {
+		findings = append(findings, &core.Finding{
+			Type:        core.FindingWeakTLS,
+			Severity:    core.SeverityMedium,
+			Name:        name,
+			Domain:      domain,
+			Description: "The server of the name accepts the deprecated " + weak + " protocol",
+			Evidence:    []string{evidence + ": " + weak + " accepted"},
+		})
+	}
+	if info.SelfSigned {
+		findings = append(findings, &core.Finding{
+			Type:        core.FindingSelfSigned,
+			Severity:    core.SeverityLow,
+			Name:        name,
+			Domain:      domain,
+			Description: "The certificate presented for the name is self-signed",
+			Evidence:    []string{evidence + ": issued by " + req.Certificate.Issuer.String()},
+		})
+	}
+	if info.Mismatched {
+		findings = append(findings, &core.Finding{
+			Type:        core.FindingCertMismatch,
+			Severity:    core.SeverityLow,
+			Name:        name,
+			Domain:      domain,
+			Description: "The certificate presented for the name is not valid for the name",
+			Evidence:    []string{evidence + ": not valid for " + name},
+		})
+	}
+	return findings
+}
+
+// weakestVersion - Returns the name of the oldest protocol version below TLS 1.2 that the
+// server was seen to accept, or an empty string
+func weakestVersion(info *AmassCertInfo) string {
+	if info.Legacy != "" {
+		return info.Legacy
+	}
+	for _, v := range []uint16{tls.VersionTLS10, tls.VersionTLS11} {
+		if info.Version == tls.VersionName(v) {
+			return info.Version
+		}
+	}
+	return ""
+}
+
+// selfSigned - Returns true when the certificate was signed by its own key
+func selfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// certHostName - Returns the in-scope name the certificate was presented for, which is the
+// SNI value when one was sent, the name resolved to the address, or the first in-scope name
+// within the certificate
+func certHostName(req *core.CertRequest, domains []string) (string, string) {
+	names := []string{req.ServerName}
+	if req.ServerName == "" && req.Name != "" {
+		names = []string{req.Name}
+	} else if req.ServerName == "" {
+		names = namesFromCert(req.Certificate)
+	}
+
+	for _, name := range names {
+		for _, domain := range domains {
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				return name, domain
+			}
+		}
+	}
+	return "", ""
+}
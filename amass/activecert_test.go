@@ -0,0 +1,114 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestExpiryFinding(t *testing.T) {
+	now := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	domains := []string{"owasp.org"}
+
+	tests := []struct {
+		serverName string
+		dnsNames   []string
+		notAfter   time.Time
+		ftype      string
+		name       string
+	}{
+		{"", []string{"www.owasp.org"}, now.Add(-time.Hour), core.FindingExpiredCert, "www.owasp.org"},
+		{"", []string{"*.example.com", "*.owasp.org"}, now.Add(10 * 24 * time.Hour), core.FindingExpiringCert, "owasp.org"},
+		{"", []string{"www.owasp.org"}, now.Add(90 * 24 * time.Hour), "", ""},
+		{"", []string{"www.example.com"}, now.Add(-time.Hour), "", ""},
+		{"vpn.owasp.org", []string{"www.example.com"}, now.Add(-time.Hour), core.FindingExpiredCert, "vpn.owasp.org"},
+	}
+
+	for _, test := range tests {
+		req := &core.CertRequest{
+			Address:    "192.0.2.1",
+			Port:       443,
+			ServerName: test.serverName,
+			Certificate: &x509.Certificate{
+				Subject:   pkix.Name{CommonName: test.dnsNames[0]},
+				DNSNames:  test.dnsNames,
+				NotBefore: now.Add(-365 * 24 * time.Hour),
+				NotAfter:  test.notAfter,
+			},
+		}
+
+		f := expiryFinding(req, domains, now)
+		if test.ftype == "" {
+			if f != nil {
+				t.Errorf("A finding was returned for %v expiring %v: %+v", test.dnsNames, test.notAfter, f)
+			}
+			continue
+		}
+		if f == nil || f.Type != test.ftype || f.Name != test.name || f.Domain != "owasp.org" || len(f.Evidence) != 1 {
+			t.Errorf("The finding for %v expiring %v was %+v, expected %s for %s", test.dnsNames, test.notAfter, f, test.ftype, test.name)
+		}
+	}
+}
+
+func TestTLSFindings(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("The key was not generated: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.owasp.org"},
+		DNSNames:     []string{"www.owasp.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("The certificate was not created: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	if !selfSigned(cert) {
+		t.Errorf("The self-signed certificate was not detected")
+	}
+
+	req := &core.CertRequest{Address: "192.0.2.1", Port: 443, Certificate: cert, Name: "vpn.owasp.org"}
+	tests := []struct {
+		info     AmassCertInfo
+		expected []string
+	}{
+		{AmassCertInfo{Version: "TLS 1.3"}, nil},
+		{AmassCertInfo{Version: "TLS 1.3", Legacy: "TLS 1.0"}, []string{core.FindingWeakTLS}},
+		{AmassCertInfo{Version: "TLS 1.1"}, []string{core.FindingWeakTLS}},
+		{AmassCertInfo{Version: "TLS 1.2", SelfSigned: true, Mismatched: true},
+			[]string{core.FindingSelfSigned, core.FindingCertMismatch}},
+	}
+
+	for _, test := range tests {
+		var types []string
+		for _, f := range tlsFindings(req, &test.info, []string{"owasp.org"}) {
+			if f.Name != "vpn.owasp.org" || f.Domain != "owasp.org" {
+				t.Errorf("The finding was not for the name resolved to the address: %+v", f)
+			}
+			types = append(types, f.Type)
+		}
+		if !reflect.DeepEqual(types, test.expected) {
+			t.Errorf("The findings for %+v were %v, expected %v", test.info, types, test.expected)
+		}
+	}
+
+	if f := tlsFindings(req, &AmassCertInfo{SelfSigned: true}, []string{"example.com"}); len(f) != 0 {
+		t.Errorf("Findings were returned for an out of scope name: %+v", f)
+	}
+}
@@ -18,10 +18,19 @@ type AlterationService struct {
 	core.BaseAmassService
 
 	bus evbus.Bus
+
+	// Alteration rules provided by scripts
+	scripts []*script
+
+	// The naming conventions learned from the resolved names
+	patterns *patternMiner
 }
 
 func NewAlterationService(config *core.AmassConfig, bus evbus.Bus) *AlterationService {
-	as := &AlterationService{bus: bus}
+	as := &AlterationService{
+		bus:      bus,
+		patterns: newPatternMiner(),
+	}
 
 	as.BaseAmassService = *core.NewBaseAmassService("Alteration Service", config, as)
 	return as
@@ -35,6 +44,11 @@ func (as *AlterationService) OnStart() error {
 	return nil
 }
 
+// List - Returns how the altered names are resolved
+func (as *AlterationService) List() string {
+	return "Sends the altered names to the DNS Service"
+}
+
 func (as *AlterationService) OnPause() error {
 	return nil
 }
@@ -51,7 +65,9 @@ func (as *AlterationService) OnStop() error {
 }
 
 func (as *AlterationService) processRequests() {
-	t := time.NewTicker(as.Config().Frequency)
+	t := time.NewTicker(as.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
 loop:
 	for {
 		select {
@@ -59,8 +75,15 @@ loop:
 			go as.executeAlterations()
 		case <-as.PauseChan():
 			t.Stop()
+			paused = true
 		case <-as.ResumeChan():
-			t = time.NewTicker(as.Config().Frequency)
+			t = time.NewTicker(as.Config().Rates().Frequency)
+			paused = false
+		case <-as.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(as.Config().Rates().Frequency)
+			}
 		case <-as.Quit():
 			break loop
 		}
@@ -74,6 +97,7 @@ func (as *AlterationService) executeAlterations() {
 	if req == nil {
 		return
 	}
+	defer as.FinishWork()
 
 	if !as.Config().Alterations {
 		return
@@ -88,6 +112,29 @@ func (as *AlterationService) executeAlterations() {
 	}
 	as.flipNumbersInName(req)
 	as.appendNumbers(req)
+	as.followPatterns(req)
+	as.executeScripts(req)
+}
+
+// followPatterns - Sends the names that follow the naming conventions mined from the resolved names
+func (as *AlterationService) followPatterns(req *core.AmassRequest) {
+	for _, name := range as.patterns.Observe(req.Name, req.Domain) {
+		as.sendAlteredName(name, req.Domain)
+	}
+}
+
+// executeScripts - Sends the names generated by the alteration scripts
+func (as *AlterationService) executeScripts(req *core.AmassRequest) {
+	for _, s := range as.scripts {
+		names, err := s.Alter(req.Name, req.Domain)
+		if err != nil {
+			as.Config().Log.Printf("%s: %v", s.name, err)
+			continue
+		}
+		for _, name := range names {
+			as.sendAlteredName(name, req.Domain)
+		}
+	}
 }
 
 func (as *AlterationService) correctRecordTypes(req *core.AmassRequest) bool {
@@ -126,7 +173,6 @@ func (as *AlterationService) flipNumbersInName(req *core.AmassRequest) {
 func (as *AlterationService) secondNumberFlip(name, domain string, minIndex int) {
 	parts := strings.SplitN(name, ".", 2)
 
-	as.SetActive()
 	// Find the second character that is a number
 	last := strings.LastIndexFunc(parts[0], unicode.IsNumber)
 	if last < 0 || last < minIndex {
@@ -148,7 +194,6 @@ func (as *AlterationService) appendNumbers(req *core.AmassRequest) {
 	n := req.Name
 	parts := strings.SplitN(n, ".", 2)
 
-	as.SetActive()
 	for i := 0; i < 10; i++ {
 		// Send a LABEL-NUM altered name
 		nhn := parts[0] + "-" + strconv.Itoa(i) + "." + parts[1]
@@ -5,19 +5,24 @@ package amass
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"net"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/amass/core"
 	"github.com/OWASP/Amass/amass/dnssrv"
 	"github.com/OWASP/Amass/amass/handlers"
+	"github.com/OWASP/Amass/amass/sources"
 	"github.com/OWASP/Amass/amass/utils"
-	evbus "github.com/asaskevich/EventBus"
 )
 
 var Banner string = `
@@ -42,26 +47,121 @@ const (
 	Author  = "https://github.com/OWASP/Amass"
 
 	DefaultFrequency   = 10 * time.Millisecond
+	defaultOPSECJitter = 500 * time.Millisecond
+	maxDecoyRatio      = 10
 	defaultWordlistURL = "https://raw.githubusercontent.com/OWASP/Amass/master/wordlists/namelist.txt"
 )
 
+// Returned by Start while another enumeration is running in the process
+var ErrEnumerationRunning = errors.New("Another enumeration is already running in this process")
+
+var (
+	// The enumeration running in the process, since the resolver settings are package-level
+	activeEnum     *Enumeration
+	activeEnumLock sync.Mutex
+)
+
 type AmassAddressInfo struct {
 	Address     net.IP
 	Netblock    *net.IPNet
 	ASN         int
 	Description string
+	PTR         string
+	PTRMatch    bool
+
+	// The kind of reserved network holding the address, such as private or loopback
+	Reserved string
+
+	// The certificates presented on the ports of the address, when active techniques are used
+	Certificates []AmassCertInfo
+}
+
+// AmassCertInfo - The validity window of a certificate presented on a port of an address,
+// and the TLS configuration of the server presenting it
+type AmassCertInfo struct {
+	Port       int
+	ServerName string
+	Subject    string
+	NotBefore  time.Time
+	NotAfter   time.Time
+	Version    string
+	SelfSigned bool
+
+	// The protocol version below TLS 1.2 accepted by the server, and whether the certificate
+	// is not valid for the name resolved to the address. Only checked by the TLS checks
+	Legacy     string
+	Mismatched bool
+}
+
+type AmassRecordInfo struct {
+	Type     string
+	TTL      int
+	Priority int
+	Data     string
+}
+
+type AmassDomainInfo struct {
+	SOANameServer string
+	SOAContact    string
+	SOASerial     uint32
+	CAA           []string
+	DNSKEY        bool
+
+	// The software identified on the authoritative name servers, when active techniques are used
+	NameServers []AmassNameServerInfo
+}
+
+// AmassNameServerInfo - The software fingerprinted on an authoritative name server
+type AmassNameServerInfo struct {
+	Name     string
+	Address  string
+	Software string
+	Version  string
 }
 
 type AmassOutput struct {
 	Name      string
 	Domain    string
 	Addresses []AmassAddressInfo
+	Records   []AmassRecordInfo
 	Tag       string
 	Source    string
 	Type      int
+	DNSSEC    string
+	Divergent bool
+	GeoDNS    bool
+
+	// The name exists, but the resolvers returned no addresses for it
+	NoData bool
+
+	// Only provided for the root domain names
+	DomainInfo *AmassDomainInfo
+}
+
+// EnumerationStats - A snapshot of the progress made by the enumeration
+type EnumerationStats struct {
+	// The time passed since the enumeration was started
+	Elapsed time.Duration
+
+	// The number of requests waiting in the queue of each service
+	Queued map[string]int
+
+	// The number of DNS queries sent to the resolvers
+	DNSQueries uint64
+
+	// The work that was skipped due to the budgets
+	Skipped map[string]int
 }
 
+// Enumeration - The settings and results of an enumeration. Start applies the resolver
+// settings to the whole process, so only one enumeration can run in a process at a time.
+// Separate processes are used to run enumerations side by side, as the serve command does
 type Enumeration struct {
+	sync.Mutex
+
+	// The unique identifier for this enumeration
+	ID string
+
 	// The channel that will receive the results
 	Output chan *AmassOutput
 
@@ -86,6 +186,13 @@ type Enumeration struct {
 	// Will whois info be used to add additional domains?
 	Whois bool
 
+	// The names of the target organization, compared with the WHOIS and certificate
+	// organizations of the root domains discovered during intelligence collection
+	Organizations []string
+
+	// The lowest ownership score for a discovered root domain to be added (zero adds them all)
+	OwnershipScore float64
+
 	// The list of words to use when generating names
 	Wordlist []string
 
@@ -95,9 +202,19 @@ type Enumeration struct {
 	// Will recursive brute forcing be performed?
 	Recursive bool
 
-	// Minimum number of subdomain discoveries before performing recursive brute forcing
+	// Minimum number of unique names discovered beneath a subdomain before it is brute forced
 	MinForRecursive int
 
+	// The number of labels guessed at once beneath the root domains (e.g. 2 for api.internal)
+	BruteForceDepth int
+
+	// The most multi-level names guessed per root domain (zero uses the default cap)
+	MaxBruteCombinations int
+
+	// Will common service names be brute forced for SRV records? This is off by default,
+	// since it adds several queries for each domain
+	SRVBruteForcing bool
+
 	// Will discovered subdomain name alterations be generated?
 	Alterations bool
 
@@ -107,31 +224,228 @@ type Enumeration struct {
 	// Determines if active information gathering techniques will be used
 	Active bool
 
+	// Will the TLS configurations found by the active techniques be checked and reported as findings?
+	TLSChecks bool
+
+	// Will the sweeps, SNI scans and port probes include the addresses of content delivery networks?
+	ProbeCDN bool
+
+	// Will the data sources indexing analytics IDs be searched for other domains using the IDs of the in-scope sites?
+	AnalyticsPivot bool
+
+	// The pipeline stages that will be run (empty means all stages)
+	Stages []string
+
+	// Will each stage wait for the stages before it to finish their work?
+	StageBarriers bool
+
 	// A blacklist of subdomain names that will not be investigated
 	Blacklist []string
 
+	// The names of data sources that will not be queried
+	DisabledSources []string
+
+	// Will the data sources that send the root domain names to third parties be excluded?
+	MinimizeExposure bool
+
+	// Will the long-tail web search scrapers be queried? They are slow and share one rate limit
+	WebSearch bool
+
+	// The tags that names must carry to be investigated and reported (empty means all tags)
+	IncludeTags []string
+
+	// The tags of names that will not be investigated or reported
+	ExcludeTags []string
+
+	// The directory containing executables that will be used as data sources
+	PluginDir string
+
+	// The directory containing scripts for data sources, alterations and output filters
+	ScriptDir string
+
+	// The forward DNS dataset file (Rapid7 Sonar style, optionally gzipped) searched for names
+	FDNSFile string
+
+	// The directory of outputs from previous recon tools (subfinder, assetfinder, dnsrecon) searched for names
+	LocalDir string
+
+	// The seed for the pseudo-random numbers, making the choices reproducible when not zero
+	Seed int64
+
 	// Sets the maximum number of DNS queries per minute
 	Frequency time.Duration
 
+	// The most DNS queries in flight at once (zero uses the open file limit)
+	MaxDNSConcurrency int
+
+	// The most random delay inserted before each DNS query
+	Jitter time.Duration
+
+	// The time waited between starting the rate limited data source queries (zero uses the default)
+	SourceDelay time.Duration
+
+	// The most rate limited data source queries running at once (zero uses the default)
+	MaxSourceConcurrency int
+
+	// Will the guesses be shuffled and the queries spread across the resolvers and time?
+	OPSEC bool
+
+	// Unrelated domains that decoy queries are sent for (empty uses the defaults)
+	DecoyDomains []string
+
+	// The number of decoy queries sent for each name resolved (zero sends none)
+	DecoyRatio float64
+
+	// The local addresses that outbound DNS and HTTP connections are bound to, in turn
+	SourceAddrs []net.IP
+
+	// The proxies that the data sources of each category send their web requests through, where the
+	// empty category applies to the others and a nil proxy connects directly
+	SourceProxies map[string]*url.URL
+
+	// Will the query names use DNS 0x20 mixed-case encoding, discarding answers that do not echo it?
+	CaseRandomization bool
+
+	// Will names be resolved by walking the delegations from the root servers instead of using resolvers?
+	Iterative bool
+
+	// Will the brute forced names be sent straight to the authoritative servers of the root domains?
+	AuthoritativeDirect bool
+
+	// The most queries per second sent to the authoritative servers of each root domain (zero uses the default)
+	AuthoritativeRate int
+
+	// The record types queried for names that exist without addresses (empty uses MX, NS and SRV)
+	NoDataTypes []string
+
+	// The record types queried in parallel for each name (empty uses TXT, A, AAAA and CNAME)
+	QueryTypes []string
+
+	// Will only one more label of the name be revealed to each server while iterating?
+	QNAMEMinimization bool
+
+	// Will the resolvers be probed with control names and those returning filtered answers excluded?
+	// The probes are off by default, and the enum command turns them on unless -nopolicy is given
+	PolicyCheck bool
+
+	// The total number of DNS queries that can be sent (zero means no limit)
+	MaxDNSQueries uint64
+
+	// The number of API calls each data source can receive (zero means no limit)
+	MaxSourceCalls int
+
+	// The longest time the enumeration can run (zero means no limit)
+	MaxRuntime time.Duration
+
+	// The directory where service queues are written while paused or once they grow large
+	QueueDir string
+
 	// Preferred DNS resolvers identified by the user
 	Resolvers []string
 
+	// The addresses of remote workers that will resolve names for the enumeration. Each name,
+	// including the brute forcing guesses, is resolved by the next worker in turn over gRPC,
+	// and a worker that fails is left out until it can be reached again
+	Workers []string
+
+	// The addresses of remote agents used to compare answers from other vantage points
+	Agents []string
+
+	// The static token presented to the remote workers and agents
+	WorkerToken string
+
+	// The TLS configuration used to connect with the remote workers and agents
+	WorkerTLS *tls.Config
+
+	// Will DNSSEC signatures be validated on answers from signed zones?
+	DNSSEC bool
+
+	// The file providing the trust anchors for the root zone used by the DNSSEC validation
+	TrustAnchors string
+
+	// Will several resolvers be queried to detect names with divergent answers?
+	Divergence bool
+
+	// Will PTR lookups be performed to check that resolved addresses map back to the names?
+	PTRValidation bool
+
+	// Will servers in the in-scope netblocks be presented the discovered names as TLS SNI values?
+	SNIScanning bool
+
 	// The writer used to save the data operations performed
 	DataOptsWriter io.Writer
 
+	// The writer receiving the OpenTelemetry spans for the names moving through the pipeline
+	TraceWriter io.Writer
+
 	// The root domain names that the enumeration will target
 	domains []string
 
+	// The services started for the enumeration
+	config   *core.AmassConfig
+	bus      *core.EventBus
+	services []core.AmassService
+	started  time.Time
+	ended    time.Time
+
+	// The number of names sent on the output channel
+	numOutput int
+
+	// The counts by domain, data source and tag for the names sent
+	summary *summaryData
+
+	// The notable findings shown by the names sent and published by the services
+	findings *findingSet
+
+	// The service querying the data sources
+	srcs *SourcesService
+
+	// The service scanning the netblocks with TLS SNI values
+	sni *SNIService
+
+	// Scores the root domains and netblocks discovered during intelligence collection
+	verifier *OwnershipVerifier
+
+	// The result streams requested through Subscribe
+	subscriptions []*subscription
+
+	// The plugins and scripts loaded once for the enumeration, and the output filters of the scripts
+	plugins  []sources.DataSource
+	scripts  *scriptSet
+	loadLock sync.Mutex
+	filters  []*script
+
+	// Data sources provided in addition to those built into the package
+	custom []sources.DataSource
+
+	// Resolved names obtained from other tools
+	imports []*core.AmassRequest
+
+	// The imported names that were answered authoritatively and need not be resolved again
+	authoritative []string
+
+	// Names provided to the enumeration that still need to be resolved
+	seeds []*core.AmassRequest
+
 	// Pause/Resume channels for halting the enumeration
 	pause  chan struct{}
 	resume chan struct{}
 
+	// Closed when the enumeration has been asked to stop early
+	quit     chan struct{}
+	stopOnce sync.Once
+
 	// Broadcast channel that indicates no further writes to the output channel
 	done chan struct{}
+
+	// Broadcast channel that indicates the output channel has been closed
+	complete     chan struct{}
+	completeOnce sync.Once
 }
 
 func NewEnumeration() *Enumeration {
 	return &Enumeration{
+		ID:              utils.NewUUID(),
 		Output:          make(chan *AmassOutput, 100),
 		Log:             log.New(ioutil.Discard, "", 0),
 		Ports:           []int{80, 443},
@@ -141,7 +455,9 @@ func NewEnumeration() *Enumeration {
 		MinForRecursive: 1,
 		pause:           make(chan struct{}),
 		resume:          make(chan struct{}),
+		quit:            make(chan struct{}),
 		done:            make(chan struct{}),
+		complete:        make(chan struct{}),
 	}
 }
 
@@ -154,52 +470,228 @@ func (e *Enumeration) Domains() []string {
 }
 
 func (e *Enumeration) generateAmassConfig() (*core.AmassConfig, error) {
+	return e.amassConfig(true)
+}
+
+// amassConfig - Validates the settings and builds the configuration, only downloading
+// the default wordlist when requested
+func (e *Enumeration) amassConfig(fetchWordlist bool) (*core.AmassConfig, error) {
 	if e.Output == nil {
 		return nil, errors.New("The configuration did not have an output channel")
 	}
 
-	if e.Passive && e.BruteForcing {
+	if err := core.CheckStages(e.Stages); err != nil {
+		return nil, err
+	}
+	// Running the data sources alone is the same as a passive enumeration
+	passive := e.Passive || !stageSelected(e.Stages, core.StageResolve)
+
+	if e.Passive && len(e.Stages) > 0 && stageSelected(e.Stages, core.StageResolve) {
+		return nil, errors.New("The resolve stage cannot be run during a passive enumeration")
+	}
+
+	if len(e.Stages) > 0 && !stageSelected(e.Stages, core.StageResolve) &&
+		(stageSelected(e.Stages, core.StageEnrich) || stageSelected(e.Stages, core.StageActive)) {
+		return nil, errors.New("The enrich and active stages cannot be run without the resolve stage")
+	}
+
+	if (e.Active || e.SNIScanning) && !stageSelected(e.Stages, core.StageActive) {
+		return nil, errors.New("Active enumeration and SNI scanning cannot be performed without the active stage")
+	}
+
+	if passive && e.BruteForcing {
 		return nil, errors.New("Brute forcing cannot be performed without DNS resolution")
 	}
 
-	if e.Passive && e.Active {
+	if passive && e.Active {
 		return nil, errors.New("Active enumeration cannot be performed without DNS resolution")
 	}
 
+	if e.TLSChecks && !e.Active {
+		return nil, errors.New("TLS checks cannot be performed without active enumeration")
+	}
+
+	if e.AnalyticsPivot && !e.Active {
+		return nil, errors.New("The analytics pivot cannot be performed without active enumeration")
+	}
+
 	if e.Frequency < DefaultFrequency {
 		return nil, errors.New("The configuration contains a invalid frequency")
 	}
 
-	if e.Passive && e.DataOptsWriter != nil {
+	if e.MaxDNSConcurrency < 0 || e.MaxSourceConcurrency < 0 {
+		return nil, errors.New("The configuration contains a negative concurrency")
+	}
+
+	if e.Jitter < 0 || e.SourceDelay < 0 {
+		return nil, errors.New("The configuration contains a negative delay")
+	}
+
+	if e.AuthoritativeRate < 0 {
+		return nil, errors.New("The configuration contains a negative authoritative query rate")
+	}
+
+	if _, err := dnssrv.ParseQueryTypes(e.NoDataTypes); err != nil {
+		return nil, err
+	}
+
+	if _, err := dnssrv.ParseQueryTypes(e.QueryTypes); err != nil {
+		return nil, err
+	}
+
+	if e.QNAMEMinimization && !e.Iterative {
+		return nil, errors.New("QNAME minimization can only be performed during iterative resolution")
+	}
+
+	if e.DecoyRatio < 0 || e.DecoyRatio > maxDecoyRatio {
+		return nil, fmt.Errorf("The decoy ratio must be between 0 and %d", maxDecoyRatio)
+	}
+
+	if e.MinimizeExposure && e.Whois {
+		return nil, errors.New("Reverse whois lookups cannot be performed while minimizing exposure")
+	}
+
+	if passive && e.DataOptsWriter != nil {
 		return nil, errors.New("Data operations cannot be saved without DNS resolution")
 	}
 
+	if passive && (e.DNSSEC || e.Divergence || e.PTRValidation) {
+		return nil, errors.New("DNS answer checks cannot be performed without DNS resolution")
+	}
+
+	if e.TrustAnchors != "" {
+		if !e.DNSSEC {
+			return nil, errors.New("Trust anchors cannot be used without DNSSEC validation")
+		}
+		if _, err := dnssrv.ReadTrustAnchors(e.TrustAnchors); err != nil {
+			return nil, err
+		}
+	}
+
+	if passive && e.SNIScanning {
+		return nil, errors.New("SNI scanning cannot be performed without DNS resolution")
+	}
+
+	if passive && (len(e.Workers) > 0 || len(e.Agents) > 0) {
+		return nil, errors.New("Remote workers and agents cannot be used without DNS resolution")
+	}
+
+	for _, tag := range append(e.IncludeTags, e.ExcludeTags...) {
+		if !core.ValidTag(tag) {
+			return nil, fmt.Errorf("The tag %s is not one of %s", tag, strings.Join(core.Tags, ", "))
+		}
+	}
+
+	if e.FDNSFile != "" {
+		if _, err := os.Stat(e.FDNSFile); err != nil {
+			return nil, fmt.Errorf("The forward DNS dataset %s is not available", e.FDNSFile)
+		}
+	}
+
+	if e.LocalDir != "" {
+		if fi, err := os.Stat(e.LocalDir); err != nil || !fi.IsDir() {
+			return nil, fmt.Errorf("The directory of previous outputs %s is not available", e.LocalDir)
+		}
+	}
+
+	if e.QueueDir != "" {
+		if fi, err := os.Stat(e.QueueDir); err != nil || !fi.IsDir() {
+			return nil, fmt.Errorf("The queue directory %s is not available", e.QueueDir)
+		}
+	}
+
 	if len(e.Ports) == 0 {
 		e.Ports = []int{80, 443}
 	}
 
-	if e.BruteForcing && len(e.Wordlist) == 0 {
+	if e.MinForRecursive < 0 {
+		return nil, errors.New("The minimum discoveries for recursive brute forcing cannot be negative")
+	}
+	// The default requires one discovery beneath a subdomain
+	if e.MinForRecursive == 0 {
+		e.MinForRecursive = 1
+	}
+
+	if e.BruteForceDepth < 0 || e.MaxBruteCombinations < 0 {
+		return nil, errors.New("The brute forcing depth and combinations cannot be negative")
+	}
+
+	if fetchWordlist && e.BruteForcing && len(e.Wordlist) == 0 {
 		e.Wordlist, _ = getDefaultWordlist()
 	}
 
 	config := &core.AmassConfig{
-		Log:             e.Log,
-		ASNs:            e.ASNs,
-		CIDRs:           e.CIDRs,
-		IPs:             e.IPs,
-		Ports:           e.Ports,
-		Whois:           e.Whois,
-		Wordlist:        e.Wordlist,
-		BruteForcing:    e.BruteForcing,
-		Recursive:       e.Recursive,
-		MinForRecursive: e.MinForRecursive,
-		Alterations:     e.Alterations,
-		Passive:         e.Passive,
-		Active:          e.Active,
-		Blacklist:       e.Blacklist,
-		Frequency:       e.Frequency,
-		Resolvers:       e.Resolvers,
-		DataOptsWriter:  e.DataOptsWriter,
+		Log:                  e.Log,
+		ASNs:                 e.ASNs,
+		CIDRs:                e.CIDRs,
+		IPs:                  e.IPs,
+		Ports:                e.Ports,
+		Whois:                e.Whois,
+		Wordlist:             e.Wordlist,
+		BruteForcing:         e.BruteForcing,
+		Recursive:            e.Recursive,
+		MinForRecursive:      e.MinForRecursive,
+		BruteForceDepth:      e.BruteForceDepth,
+		MaxBruteCombinations: e.MaxBruteCombinations,
+		SRVBruteForcing:      e.SRVBruteForcing,
+		Alterations:          e.Alterations,
+		Passive:              passive,
+		Active:               e.Active,
+		TLSChecks:            e.TLSChecks,
+		ProbeCDN:             e.ProbeCDN,
+		AnalyticsPivot:       e.AnalyticsPivot,
+		Stages:               e.Stages,
+		StageBarriers:        e.StageBarriers,
+		Blacklist:            e.Blacklist,
+		DisabledSources:      e.DisabledSources,
+		MinimizeExposure:     e.MinimizeExposure,
+		WebSearch:            e.WebSearch,
+		IncludeTags:          e.IncludeTags,
+		ExcludeTags:          e.ExcludeTags,
+		PluginDir:            e.PluginDir,
+		ScriptDir:            e.ScriptDir,
+		FDNSFile:             e.FDNSFile,
+		LocalDir:             e.LocalDir,
+		Seed:                 e.Seed,
+		Frequency:            e.Frequency,
+		MaxDNSConcurrency:    e.MaxDNSConcurrency,
+		Jitter:               e.Jitter,
+		SourceDelay:          e.SourceDelay,
+		MaxSourceConcurrency: e.MaxSourceConcurrency,
+		OPSEC:                e.OPSEC,
+		DecoyDomains:         e.DecoyDomains,
+		DecoyRatio:           e.DecoyRatio,
+		SourceAddrs:          e.SourceAddrs,
+		SourceProxies:        e.SourceProxies,
+		CaseRandomization:    e.CaseRandomization,
+		Iterative:            e.Iterative,
+		AuthoritativeDirect:  e.AuthoritativeDirect,
+		AuthoritativeRate:    e.AuthoritativeRate,
+		NoDataTypes:          e.NoDataTypes,
+		QueryTypes:           e.QueryTypes,
+		QNAMEMinimization:    e.QNAMEMinimization,
+		PolicyCheck:          e.PolicyCheck,
+		MaxDNSQueries:        e.MaxDNSQueries,
+		MaxSourceCalls:       e.MaxSourceCalls,
+		MaxRuntime:           e.MaxRuntime,
+		QueueDir:             e.QueueDir,
+		Resolvers:            e.Resolvers,
+		Workers:              e.Workers,
+		Agents:               e.Agents,
+		WorkerToken:          e.WorkerToken,
+		WorkerTLS:            e.WorkerTLS,
+		DNSSEC:               e.DNSSEC,
+		TrustAnchors:         e.TrustAnchors,
+		Divergence:           e.Divergence,
+		PTRValidation:        e.PTRValidation,
+		SNIScanning:          e.SNIScanning,
+		DataOptsWriter:       e.DataOptsWriter,
+		TraceWriter:          e.TraceWriter,
+	}
+
+	// The queries are spread across time even when no jitter was requested
+	if config.OPSEC && config.Jitter == 0 {
+		config.Jitter = defaultOPSECJitter
 	}
 
 	for _, domain := range e.Domains() {
@@ -208,60 +700,183 @@ func (e *Enumeration) generateAmassConfig() (*core.AmassConfig, error) {
 	return config, nil
 }
 
+// Start - Executes the enumeration and blocks until it has completed or been stopped.
+// The Output channel must be drained while the enumeration is running. ErrEnumerationRunning
+// is returned while another enumeration is running in the process
 func (e *Enumeration) Start() error {
 	var services []core.AmassService
 
+	e.Lock()
+	if !e.started.IsZero() {
+		e.Unlock()
+		return errors.New("The enumeration has already been started")
+	}
+	activeEnumLock.Lock()
+	if activeEnum != nil {
+		activeEnumLock.Unlock()
+		e.Unlock()
+		return ErrEnumerationRunning
+	}
+	activeEnum = e
+	activeEnumLock.Unlock()
+	e.started = time.Now()
+	e.Unlock()
+
+	defer func() {
+		activeEnumLock.Lock()
+		activeEnum = nil
+		activeEnumLock.Unlock()
+	}()
+
+	defer e.completeOnce.Do(func() { close(e.complete) })
+	// The output is also closed when the enumeration fails before it begins
+	var running bool
+	defer func() {
+		if !running {
+			e.Lock()
+			close(e.done)
+			e.Unlock()
+			if e.Output != nil {
+				close(e.Output)
+			}
+			e.closeSubscriptions()
+		}
+	}()
+
 	config, err := e.generateAmassConfig()
 	if err != nil {
 		return err
 	}
+	if len(config.Resolvers) > 0 {
+		dnssrv.SetCustomResolvers(config.Resolvers)
+	}
+	if config.TrustAnchors != "" {
+		anchors, err := dnssrv.ReadTrustAnchors(config.TrustAnchors)
+		if err != nil {
+			return err
+		}
+		dnssrv.SetTrustAnchors(anchors)
+	}
+	if config.Seed != 0 {
+		utils.SetRandomSeed(config.Seed)
+	}
+	dnssrv.SpreadResolverQueries(config.OPSEC)
+	dnssrv.SetLocalAddresses(config.SourceAddrs)
+	dnssrv.SetCaseRandomization(config.CaseRandomization)
+	dnssrv.SetQueryLimit(config.MaxDNSQueries)
+	dnssrv.SetIterativeResolution(config.Iterative, config.QNAMEMinimization)
+	if config.PolicyCheck && !config.Passive && !utils.Replaying() {
+		for r, err := range dnssrv.FilterResolvers() {
+			config.Log.Printf("Excluding the resolver %s: %v", r, err)
+		}
+	}
 	utils.SetDialContext(dnssrv.DialContext)
 
-	bus := evbus.New()
+	scripts := e.loadedScripts()
+	defer e.closeScripts()
+	e.filters = scripts.filters
+
+	bus := core.NewEventBus(config)
 	bus.SubscribeAsync(core.OUTPUT, e.sendOutput, false)
+	bus.SubscribeAsync(core.FINDING, e.addFinding, false)
 
-	services = append(services, NewSourcesService(config, bus))
+	stages := newPipeline(config, bus)
+	srcs := NewSourcesService(config, bus)
+	for _, source := range e.addedSources() {
+		srcs.AddSource(source)
+	}
+	// The sources service also sends the names from files when the data sources are not queried
+	if config.StageEnabled(core.StagePassive) {
+		stages.add(core.StagePassive, srcs)
+	} else {
+		stages.add(core.StageResolve, srcs)
+	}
+	services = append(services, srcs)
 	var data *DataManagerService
 	if !config.Passive {
+		// The enrichment and active probes held by the data manager wait for their own stages
 		data = NewDataManagerService(config, bus)
+		ds := dnssrv.NewDNSService(config, bus)
+		ds.MarkResolved(e.authoritative...)
+		alts := NewAlterationService(config, bus)
+		alts.scripts = scripts.alterations
 
-		services = append(services,
+		resolve := []core.AmassService{
 			data,
-			dnssrv.NewDNSService(config, bus),
-			NewAlterationService(config, bus),
+			ds,
+			alts,
 			NewBruteForceService(config, bus),
-		)
+			dnssrv.NewSRVBruteService(config, bus),
+		}
+		stages.add(core.StageResolve, resolve...)
+		services = append(services, resolve...)
+	}
+	if config.SNIScanning && !config.Passive {
+		e.sni = NewSNIService(config, bus)
+		stages.add(core.StageActive, e.sni)
+		services = append(services, e.sni)
 	}
+	stages.start()
 
-	for _, service := range services {
+	for i, service := range services {
 		if err := service.Start(); err != nil {
+			for _, started := range services[:i] {
+				started.Stop()
+			}
+			bus.Unsubscribe(core.OUTPUT, e.sendOutput)
+			bus.Unsubscribe(core.FINDING, e.addFinding)
 			return err
 		}
 	}
+	running = true
+
+	e.Lock()
+	e.config = config
+	e.bus = bus
+	e.srcs = srcs
+	e.services = services
+	e.Unlock()
 
 	if data != nil {
 		e.Graph = data.Graph
 	}
+	// Send the names obtained from files through the same path as the data sources
+	srcs.InjectNames(e.seeds)
+	// Insert the names that were already resolved by other tools
+	if !config.Passive {
+		for _, req := range e.imports {
+			bus.Publish(core.RESOLVED, req)
+		}
+	}
 	// Periodically check if all the services have finished
 	t := time.NewTicker(time.Second)
 loop:
 	for {
 		select {
+		case <-e.quit:
+			break loop
 		case <-e.pause:
 			t.Stop()
+			for _, service := range services {
+				service.Pause()
+			}
 		case <-e.resume:
+			for _, service := range services {
+				service.Resume()
+			}
 			t = time.NewTicker(time.Second)
 		case <-t.C:
-			done := true
+			if config.MaxRuntime > 0 && time.Since(e.started) >= config.MaxRuntime {
+				var pending int
 
-			for _, service := range services {
-				if service.IsActive() {
-					done = false
-					break
+				for _, service := range services {
+					pending += service.NumOfRequests()
 				}
+				config.SkippedWork(core.SkippedRuntime, pending)
+				break loop
 			}
 
-			if done {
+			if stages.step() {
 				break loop
 			}
 		}
@@ -272,21 +887,131 @@ loop:
 	for _, service := range services {
 		service.Stop()
 	}
+	e.Lock()
+	e.ended = time.Now()
+	e.Unlock()
 	// Wait for output to finish being handled
 	bus.Unsubscribe(core.OUTPUT, e.sendOutput)
+	bus.Unsubscribe(core.FINDING, e.addFinding)
+	// The goroutines of the stopped services can still be publishing
+	bus.Stop()
 	bus.WaitAsync()
+	e.Lock()
 	close(e.done)
+	e.Unlock()
 	time.Sleep(2 * time.Second)
 	close(e.Output)
+	e.closeSubscriptions()
 	return nil
 }
 
+// Stats - Returns the current progress of the enumeration
+func (e *Enumeration) Stats() *EnumerationStats {
+	e.Lock()
+	defer e.Unlock()
+
+	stats := &EnumerationStats{
+		Queued:     make(map[string]int),
+		DNSQueries: dnssrv.NumOfQueries(),
+	}
+	if !e.started.IsZero() {
+		stats.Elapsed = time.Since(e.started)
+	}
+	if e.config != nil {
+		stats.Skipped = e.config.Skipped()
+	}
+	for _, service := range e.services {
+		stats.Queued[service.String()] = service.NumOfRequests()
+	}
+	return stats
+}
+
+// AddSource - Provides a data source to be queried in addition to those built into the package
+func (e *Enumeration) AddSource(source sources.DataSource) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.custom = append(e.custom, source)
+}
+
+// SourceReport - Returns the contribution of each data source to the enumeration
+func (e *Enumeration) SourceReport() []*SourceStats {
+	e.Lock()
+	srcs := e.srcs
+	e.Unlock()
+
+	if srcs == nil {
+		return nil
+	}
+	return srcs.Report()
+}
+
+// DisableSource - Stops the data source from being queried for the remainder of the running enumeration
+func (e *Enumeration) DisableSource(name string) (string, error) {
+	return e.setSourceEnabled(name, false)
+}
+
+// EnableSource - Resumes the queries sent to a data source that was disabled while running
+func (e *Enumeration) EnableSource(name string) (string, error) {
+	return e.setSourceEnabled(name, true)
+}
+
+func (e *Enumeration) setSourceEnabled(name string, enable bool) (string, error) {
+	e.Lock()
+	srcs := e.srcs
+	e.Unlock()
+
+	if srcs == nil {
+		return "", errors.New("The data sources can only be changed while the enumeration is running")
+	}
+	return srcs.SetSourceEnabled(name, enable)
+}
+
+// VirtualHosts - Returns the names that servers were found to accept during SNI scanning
+func (e *Enumeration) VirtualHosts() []VirtualHost {
+	if e.sni == nil {
+		return nil
+	}
+	return e.sni.VirtualHosts()
+}
+
+// Pause - Halts the enumeration until Resume is called. Nothing is done when the
+// enumeration has not been started or has already completed
 func (e *Enumeration) Pause() {
-	e.pause <- struct{}{}
+	e.signal(e.pause)
 }
 
+// Resume - Continues an enumeration that was paused
 func (e *Enumeration) Resume() {
-	e.resume <- struct{}{}
+	e.signal(e.resume)
+}
+
+// signal - Sends on the channel read by the loop in Start, without blocking once the
+// enumeration has been stopped or has completed
+func (e *Enumeration) signal(ch chan struct{}) {
+	e.Lock()
+	started := !e.started.IsZero()
+	e.Unlock()
+
+	if !started {
+		return
+	}
+	select {
+	case ch <- struct{}{}:
+	case <-e.quit:
+	case <-e.done:
+	}
+}
+
+// Stop - Ends the enumeration early, and the results already obtained are still delivered
+func (e *Enumeration) Stop() {
+	e.stopOnce.Do(func() { close(e.quit) })
+}
+
+// Done - Returns a channel that is closed once the enumeration has completed
+// and the Output channel has been closed
+func (e *Enumeration) Done() <-chan struct{} {
+	return e.complete
 }
 
 func (e *Enumeration) sendOutput(out *AmassOutput) {
@@ -295,8 +1020,45 @@ func (e *Enumeration) sendOutput(out *AmassOutput) {
 	case <-e.done:
 		return
 	default:
+		span := e.config.StartSpan(core.SpanOutput, &core.AmassRequest{
+			Name:   out.Name,
+			Domain: out.Domain,
+			Tag:    out.Tag,
+			Source: out.Source,
+		})
+		defer span.End()
+
+		if !e.keepOutput(out) {
+			span.SetAttribute("filtered", "true")
+			return
+		}
+
+		e.Lock()
+		e.numOutput++
+		e.tallyOutput(out)
+		e.Unlock()
 		e.Output <- out
+		e.publishToSubscribers(out)
+	}
+}
+
+// keepOutput - Checks the result against the output filter scripts
+func (e *Enumeration) keepOutput(out *AmassOutput) bool {
+	if e.config != nil && !e.config.TagInScope(out.Tag) {
+		return false
 	}
+
+	for _, f := range e.filters {
+		keep, err := f.Keep(out)
+		if err != nil {
+			e.Log.Printf("%s: %v", f.name, err)
+			continue
+		}
+		if !keep {
+			return false
+		}
+	}
+	return true
 }
 
 func (e *Enumeration) ObtainAdditionalDomains() {
@@ -308,9 +1070,7 @@ func (e *Enumeration) ObtainAdditionalDomains() {
 				continue
 			}
 
-			for _, domain := range more {
-				e.AddDomain(domain)
-			}
+			e.AddRelatedDomains(more)
 		}
 	}
 }
@@ -319,6 +1079,11 @@ func getDefaultWordlist() ([]string, error) {
 	var list []string
 	var wordlist io.Reader
 
+	// Prefer the wordlist obtained by the last update of the data files
+	if words := dataFileLines(wordlistDataFile); len(words) > 0 {
+		return words, nil
+	}
+
 	page, err := utils.GetWebPage(defaultWordlistURL, nil)
 	if err != nil {
 		return list, err
@@ -0,0 +1,166 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package amasstest provides an in-process DNS server and data sources that
+// allow the enumeration pipeline to be exercised without touching the network
+package amasstest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// DNSServer - An in-process authoritative DNS server answering from the records provided.
+// Records owned by a wildcard name, e.g. "*.example.com.", answer the names beneath it that do not exist
+type DNSServer struct {
+	sync.Mutex
+	server  *dns.Server
+	records map[string][]dns.RR
+	queries int
+}
+
+// NewDNSServer - Starts the server on a loopback UDP port with records in the zone file format
+func NewDNSServer(records ...string) (*DNSServer, error) {
+	s := &DNSServer{records: make(map[string][]dns.RR)}
+
+	for _, r := range records {
+		if err := s.AddRecord(r); err != nil {
+			return nil, err
+		}
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("DNSServer error: Failed to listen: %v", err)
+	}
+
+	started := make(chan struct{})
+	s.server = &dns.Server{
+		PacketConn:        pc,
+		Handler:           s,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go s.server.ActivateAndServe()
+	<-started
+	return s, nil
+}
+
+// Addr - Returns the address that the server is answering queries on
+func (s *DNSServer) Addr() string {
+	return s.server.PacketConn.LocalAddr().String()
+}
+
+// AddRecord - Inserts a resource record in the zone file format, e.g. "www.example.com. 300 IN A 192.0.2.1"
+func (s *DNSServer) AddRecord(record string) error {
+	rr, err := dns.NewRR(record)
+	if err != nil {
+		return fmt.Errorf("DNSServer error: Failed to parse %s: %v", record, err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	name := strings.ToLower(rr.Header().Name)
+	s.records[name] = append(s.records[name], rr)
+	return nil
+}
+
+// NumOfQueries - Returns the number of queries the server has answered
+func (s *DNSServer) NumOfQueries() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.queries
+}
+
+// Close - Shuts down the server
+func (s *DNSServer) Close() error {
+	return s.server.Shutdown()
+}
+
+// ServeDNS - Answers the query from the records, following CNAME records within the zone
+func (s *DNSServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Authoritative = true
+
+	s.Lock()
+	s.queries++
+	if len(req.Question) > 0 {
+		q := req.Question[0]
+		name := strings.ToLower(q.Name)
+
+		if s.exists(name) {
+			m.Answer = s.answers(name, q.Qtype, 0)
+		} else if wild := s.wildcard(name); wild != "" {
+			m.Answer = s.answers(wild, q.Qtype, 0)
+			for _, rr := range m.Answer {
+				if strings.ToLower(rr.Header().Name) == wild {
+					rr.Header().Name = q.Name
+				}
+			}
+		} else {
+			m.Rcode = dns.RcodeNameError
+		}
+	}
+	s.Unlock()
+
+	w.WriteMsg(m)
+}
+
+// exists - Returns true when the name owns records or has names beneath it
+func (s *DNSServer) exists(name string) bool {
+	if _, found := s.records[name]; found {
+		return true
+	}
+
+	for owner := range s.records {
+		if strings.HasSuffix(owner, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcard - Returns the wildcard owner that the closest existing ancestor of the name has, or an empty string
+func (s *DNSServer) wildcard(name string) string {
+	labels := strings.Split(name, ".")
+
+	for i := 1; i < len(labels)-1; i++ {
+		parent := strings.Join(labels[i:], ".")
+
+		if owner := "*." + parent; len(s.records[owner]) > 0 {
+			return owner
+		}
+		if s.exists(parent) {
+			break
+		}
+	}
+	return ""
+}
+
+// answers - Returns copies of the records, since packing the reply modifies the headers
+func (s *DNSServer) answers(name string, qtype uint16, depth int) []dns.RR {
+	var answers []dns.RR
+
+	for _, rr := range s.records[name] {
+		if rr.Header().Rrtype == qtype || qtype == dns.TypeANY {
+			answers = append(answers, dns.Copy(rr))
+		}
+	}
+	if len(answers) > 0 || depth > 8 {
+		return answers
+	}
+
+	for _, rr := range s.records[name] {
+		if cname, ok := rr.(*dns.CNAME); ok {
+			answers = append(answers, dns.Copy(rr))
+			answers = append(answers, s.answers(strings.ToLower(cname.Target), qtype, depth+1)...)
+		}
+	}
+	return answers
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amasstest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/amass/sources"
+)
+
+// Source - A data source returning the names provided without any network access
+type Source struct {
+	sources.BaseDataSource
+
+	sync.Mutex
+	names   []string
+	queries int
+}
+
+// NewSource - Returns a data source named org that knows the names provided
+func NewSource(org string, names ...string) *Source {
+	s := &Source{names: names}
+
+	s.BaseDataSource = *sources.NewBaseDataSource(sources.API, org)
+	return s
+}
+
+// Query - Returns the names known by the data source that belong to the domain
+func (s *Source) Query(domain, sub string) []string {
+	var results []string
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.queries++
+	for _, name := range s.names {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			results = append(results, name)
+		}
+	}
+	return results
+}
+
+// NumOfQueries - Returns the number of times the data source was queried
+func (s *Source) NumOfQueries() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.queries
+}
+
+// RevealsDomain - Returns false, since the names are never sent anywhere
+func (s *Source) RevealsDomain() bool {
+	return false
+}
+
+// BuiltinSourceNames - Returns the names of every data source that accesses the network,
+// so they can be disabled for the enumeration under test
+func BuiltinSourceNames() []string {
+	var names []string
+
+	for _, source := range sources.GetAllSources() {
+		names = append(names, source.String())
+	}
+	return names
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/sources"
+)
+
+// pivotAnalytics - Searches the data sources indexing analytics IDs for the other sites using
+// the ID found on an in-scope page, and publishes their root domains as related domain findings
+func (ss *SourcesService) pivotAnalytics(req *core.AnalyticsRequest) {
+	if ss.analyticsDup(req.ID) {
+		return
+	}
+
+	ss.StartWork()
+	go func() {
+		defer ss.FinishWork()
+
+		for _, source := range append(ss.directs, ss.throttles...) {
+			as, ok := source.(sources.AnalyticsSource)
+			if !ok || ss.sourceDisabled(source.String()) || !ss.sourceAvailable(source) ||
+				!ss.Config().AllowSourceCall(source.String()) {
+				continue
+			}
+
+			for _, domain := range relatedDomains(ss.Config(), as.AnalyticsDomains(req.ID)) {
+				ss.Config().Log.Printf("The domain %s shares the analytics ID %s with %s and can be added to the scope",
+					domain, req.ID, req.Name)
+
+				ss.bus.Publish(core.FINDING, &core.Finding{
+					Type:     core.FindingRelated,
+					Severity: core.SeverityInfo,
+					Name:     domain,
+					Domain:   req.Domain,
+					Description: fmt.Sprintf("The sites of the domain embed the analytics IDs of the "+
+						"in-scope sites, according to %s", source.String()),
+					Evidence: []string{req.ID + " " + req.URL},
+				})
+			}
+		}
+	}()
+}
+
+func (ss *SourcesService) analyticsDup(id string) bool {
+	ss.Lock()
+	defer ss.Unlock()
+
+	if _, found := ss.analyticsFilter[id]; found {
+		return true
+	}
+	ss.analyticsFilter[id] = struct{}{}
+	return false
+}
+
+// relatedDomains - Returns the root domains of the site names that are outside the scope
+func relatedDomains(config *core.AmassConfig, names []string) []string {
+	var domains []string
+
+	for _, name := range names {
+		name = strings.ToLower(removeLastDot(name))
+		if name == "" || config.IsDomainInScope(name) || config.Blacklisted(name) {
+			continue
+		}
+
+		if domain := SubdomainToDomain(name); domain != "" && !config.IsDomainInScope(domain) {
+			domains = appendUnique(domains, domain)
+		}
+	}
+	return domains
+}
+
+// ObtainAnalyticsDomains - Adds the root domains of the sites using the same analytics IDs as
+// the root domains already provided, found by the data sources indexing the IDs. The domains
+// are scored like the others discovered during intelligence collection
+func (e *Enumeration) ObtainAnalyticsDomains() {
+	ids := e.targetVerifier().TargetIDs()
+
+	config := e.sourcesConfig()
+	for _, domain := range e.domains {
+		config.AddDomain(domain)
+	}
+
+	var domains []string
+	for _, source := range e.dataSources(config) {
+		as, ok := source.(sources.AnalyticsSource)
+		if !ok || !sourceEnabled(config, source) {
+			continue
+		}
+
+		source.SetLogger(e.Log)
+		for _, id := range ids {
+			domains = append(domains, relatedDomains(config, as.AnalyticsDomains(id))...)
+		}
+	}
+	e.AddRelatedDomains(domains)
+}
@@ -4,26 +4,54 @@
 package amass
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
 	evbus "github.com/asaskevich/EventBus"
 )
 
+const (
+	// The numbers substituted for a %d found in a wordlist entry
+	defaultRangeStart = 0
+	defaultRangeEnd   = 9
+
+	// The most multi-level names guessed per root domain when no cap is configured
+	defaultBruteCombinations = 100000
+
+	// The most words a single wordlist entry can expand to, since the expansion is held in memory
+	maxWordRangeWords = 10000
+)
+
+var (
+	// Matches range syntax within wordlist entries, such as web[01-20]
+	wordRangeRegex = regexp.MustCompile(`\[([0-9]+)-([0-9]+)\]`)
+)
+
 type BruteForceService struct {
 	core.BaseAmassService
 
 	bus evbus.Bus
 
-	// Subdomains that have been worked on by brute forcing
+	// The number of unique names discovered beneath each subdomain
 	subdomains map[string]int
+
+	// The names already counted toward the discoveries beneath their subdomain
+	counted map[string]struct{}
+
+	// The words of the wordlist with the range syntax expanded
+	words []string
 }
 
 func NewBruteForceService(config *core.AmassConfig, bus evbus.Bus) *BruteForceService {
 	bfs := &BruteForceService{
 		bus:        bus,
 		subdomains: make(map[string]int),
+		counted:    make(map[string]struct{}),
 	}
 
 	bfs.BaseAmassService = *core.NewBaseAmassService("Brute Forcing Service", config, bfs)
@@ -33,12 +61,25 @@ func NewBruteForceService(config *core.AmassConfig, bus evbus.Bus) *BruteForceSe
 func (bfs *BruteForceService) OnStart() error {
 	bfs.BaseAmassService.OnStart()
 
+	var errs []error
+	bfs.words, errs = ExpandWordlist(bfs.Config().Wordlist)
+	for _, err := range errs {
+		bfs.Config().Log.Printf("%v", err)
+	}
+
 	bfs.bus.SubscribeAsync(core.RESOLVED, bfs.SendRequest, false)
+	bfs.bus.SubscribeAsync(core.NEWDOMAIN, bfs.addDomain, false)
 	go bfs.processRequests()
+	bfs.StartWork()
 	go bfs.startRootDomains()
 	return nil
 }
 
+// List - Returns how the guessed names are resolved
+func (bfs *BruteForceService) List() string {
+	return "Sends the names built from the wordlist and the root domain names to the DNS Service"
+}
+
 func (bfs *BruteForceService) OnPause() error {
 	return nil
 }
@@ -51,11 +92,14 @@ func (bfs *BruteForceService) OnStop() error {
 	bfs.BaseAmassService.OnStop()
 
 	bfs.bus.Unsubscribe(core.RESOLVED, bfs.SendRequest)
+	bfs.bus.Unsubscribe(core.NEWDOMAIN, bfs.addDomain)
 	return nil
 }
 
 func (bfs *BruteForceService) processRequests() {
-	t := time.NewTicker(bfs.Config().Frequency)
+	t := time.NewTicker(bfs.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
 loop:
 	for {
 		select {
@@ -63,8 +107,15 @@ loop:
 			go bfs.checkForNewSubdomain()
 		case <-bfs.PauseChan():
 			t.Stop()
+			paused = true
 		case <-bfs.ResumeChan():
-			t = time.NewTicker(bfs.Config().Frequency)
+			t = time.NewTicker(bfs.Config().Rates().Frequency)
+			paused = false
+		case <-bfs.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(bfs.Config().Rates().Frequency)
+			}
 		case <-bfs.Quit():
 			break loop
 		}
@@ -72,36 +123,57 @@ loop:
 	t.Stop()
 }
 
-// Returns true if the subdomain name is a duplicate entry in the filter.
-// If not, the subdomain name is added to the filter
-func (bfs *BruteForceService) subDiscoveries(sub string) int {
+// subDiscoveries - Counts the name toward the discoveries beneath the subdomain, and returns
+// the new count. Zero is returned when the name was already counted, so a name resolved
+// more than once cannot trigger the recursive brute forcing
+func (bfs *BruteForceService) subDiscoveries(name, sub string) int {
 	bfs.Lock()
 	defer bfs.Unlock()
 
-	if dis, found := bfs.subdomains[sub]; found {
-		dis += 1
-		bfs.subdomains[sub] = dis
-		return dis
+	if _, found := bfs.counted[name]; found {
+		return 0
 	}
-	bfs.subdomains[sub] = 1
-	return 1
+	bfs.counted[name] = struct{}{}
+
+	bfs.subdomains[sub]++
+	return bfs.subdomains[sub]
 }
 
 func (bfs *BruteForceService) startRootDomains() {
+	defer bfs.FinishWork()
+
 	if !bfs.Config().BruteForcing {
 		return
 	}
 	// Look at each domain provided by the config
 	for _, domain := range bfs.Config().Domains() {
-		go bfs.performBruteForcing(domain, domain)
+		bfs.startDomain(domain)
+	}
+}
+
+// addDomain - Starts brute forcing a root domain added during the enumeration
+func (bfs *BruteForceService) addDomain(domain string) {
+	if bfs.Config().BruteForcing {
+		bfs.startDomain(domain)
 	}
 }
 
+func (bfs *BruteForceService) startDomain(domain string) {
+	bfs.StartWork()
+	go func() {
+		defer bfs.FinishWork()
+
+		bfs.performBruteForcing(domain, domain)
+		bfs.performMultiLevel(domain)
+	}()
+}
+
 func (bfs *BruteForceService) checkForNewSubdomain() {
 	req := bfs.NextRequest()
 	if req == nil {
 		return
 	}
+	defer bfs.FinishWork()
 
 	if !bfs.Config().BruteForcing {
 		return
@@ -126,23 +198,223 @@ func (bfs *BruteForceService) checkForNewSubdomain() {
 		return
 	}
 	sub := strings.Join(labels[1:], ".")
-	if dis := bfs.subDiscoveries(sub); dis == bfs.Config().MinForRecursive {
+	// Only brute force beneath the subdomain once, when enough names have been found
+	if dis := bfs.subDiscoveries(req.Name, sub); dis == bfs.Config().MinForRecursive {
 		bfs.performBruteForcing(sub, req.Domain)
 	}
 }
 
 func (bfs *BruteForceService) performBruteForcing(subdomain, root string) {
-	for _, word := range bfs.Config().Wordlist {
-		bfs.SetActive()
-
-		bfs.bus.Publish(core.DNSQUERY, &core.AmassRequest{
-			Name:   word + "." + subdomain,
-			Domain: root,
-			Tag:    core.BRUTE,
-			Source: "Brute Force",
-		})
-		// Going too fast will overwhelm the dns
-		// service and overuse memory
-		time.Sleep(bfs.Config().Frequency)
+	for _, word := range bfs.guessOrder(bfs.words) {
+		bfs.sendGuess(word+"."+subdomain, root)
+	}
+}
+
+// performMultiLevel - Guesses several labels at once beneath the root domain,
+// for organizations that nest their hosts more than one label deep
+func (bfs *BruteForceService) performMultiLevel(domain string) {
+	depth := bfs.Config().BruteForceDepth
+	if depth < 2 {
+		return
+	}
+
+	limit := bfs.Config().MaxBruteCombinations
+	if limit == 0 {
+		limit = defaultBruteCombinations
+	}
+	for _, guess := range bfs.guessOrder(MultiLevelWords(bfs.words, depth, limit)) {
+		bfs.sendGuess(guess+"."+domain, domain)
+	}
+}
+
+// guessOrder - Shuffles the guesses when OPSEC is enabled, so the queries do not
+// walk through the wordlist in an order that is easy to recognize. The words are copied
+// first, since the wordlist is shared by the domains being brute forced at the same time
+func (bfs *BruteForceService) guessOrder(words []string) []string {
+	if !bfs.Config().OPSEC {
+		return words
+	}
+
+	words = append([]string(nil), words...)
+	utils.RandomShuffle(len(words), func(i, j int) {
+		words[i], words[j] = words[j], words[i]
+	})
+	return words
+}
+
+func (bfs *BruteForceService) sendGuess(name, root string) {
+	bfs.bus.Publish(core.DNSQUERY, &core.AmassRequest{
+		Name:   name,
+		Domain: root,
+		Tag:    core.BRUTE,
+		Source: "Brute Force",
+	})
+	// Going too fast will overwhelm the dns
+	// service and overuse memory
+	time.Sleep(bfs.Config().Rates().Frequency)
+}
+
+// MultiLevelWords - Returns up to limit combinations of two through depth words, such as
+// api.internal. The wordlists are ordered by how common the words are, so the combinations
+// of the earliest words are returned first, by the sum of their positions in the list
+func MultiLevelWords(words []string, depth, limit int) []string {
+	var results []string
+
+	n := len(words)
+	for d := 2; d <= depth && len(results) < limit; d++ {
+		for sum := 0; sum <= d*(n-1) && len(results) < limit; sum++ {
+			results = appendCombinations(results, words, make([]int, 0, d), d, sum, limit)
+		}
+	}
+	return results
+}
+
+// appendCombinations - Adds the combinations of the remaining labels with positions adding up to sum
+func appendCombinations(results, words []string, idx []int, depth, sum, limit int) []string {
+	if len(results) >= limit {
+		return results
+	}
+
+	if len(idx) == depth-1 {
+		if sum >= len(words) {
+			return results
+		}
+
+		labels := make([]string, 0, depth)
+		for _, i := range append(idx, sum) {
+			labels = append(labels, words[i])
+		}
+		return append(results, strings.Join(labels, "."))
+	}
+
+	for i := 0; i <= sum && i < len(words); i++ {
+		results = appendCombinations(results, words, append(idx, i), depth, sum-i, limit)
+	}
+	return results
+}
+
+// ExpandWordlist - Returns the words of the wordlist with the range syntax expanded, and the
+// errors reporting the entries skipped for expanding to more than maxWordRangeWords words
+func ExpandWordlist(entries []string) ([]string, []error) {
+	var words []string
+	var errs []error
+
+	for _, entry := range entries {
+		expanded, err := ExpandWordRanges(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		words = append(words, expanded...)
+	}
+	return words, errs
+}
+
+// ExpandWordRanges - Returns all the words described by a wordlist entry containing
+// range syntax. A bracketed range, such as web[01-20], is expanded using the width
+// of the first number for zero padding, and each %d is replaced with the numbers 0-9.
+// An entry expanding to more than maxWordRangeWords words is rejected, including entries
+// where the substituted numbers form new ranges, such as [[0-999]-999]
+func ExpandWordRanges(entry string) ([]string, error) {
+	if n := wordRangeCount(entry); n <= maxWordRangeWords {
+		if words, ok := expandWordRanges(entry, maxWordRangeWords); ok {
+			return words, nil
+		}
+	}
+	return nil, fmt.Errorf("The wordlist entry %q expands to more than %d words and was skipped",
+		entry, maxWordRangeWords)
+}
+
+// wordRangeCount - Returns the number of words the entry expands to, stopping once the
+// count exceeds maxWordRangeWords
+func wordRangeCount(entry string) int {
+	count := 1
+
+	for _, m := range wordRangeRegex.FindAllStringSubmatch(entry, -1) {
+		start, err1 := strconv.Atoi(m[1])
+		end, err2 := strconv.Atoi(m[2])
+		if err1 != nil || err2 != nil || start > end {
+			// The expansion stops at an invalid range, keeping the rest of the entry as it is
+			return count
+		}
+
+		if end-start >= maxWordRangeWords {
+			return maxWordRangeWords + 1
+		}
+		if count *= end - start + 1; count > maxWordRangeWords {
+			return maxWordRangeWords + 1
+		}
+	}
+	for i := strings.Count(entry, "%d"); i > 0; i-- {
+		if count *= defaultRangeEnd - defaultRangeStart + 1; count > maxWordRangeWords {
+			return maxWordRangeWords + 1
+		}
+	}
+	return count
+}
+
+// expandWordRanges - Returns the words described by the entry, or false once the expansion
+// grows beyond limit words
+func expandWordRanges(entry string, limit int) ([]string, bool) {
+	if limit < 1 {
+		return nil, false
+	}
+
+	if idx := wordRangeRegex.FindStringSubmatchIndex(entry); idx != nil {
+		first := entry[idx[2]:idx[3]]
+		start, err1 := strconv.Atoi(first)
+		end, err2 := strconv.Atoi(entry[idx[4]:idx[5]])
+		if err1 != nil || err2 != nil || start > end {
+			return []string{entry}, true
+		}
+
+		var width int
+		if len(first) > 1 && first[0] == '0' {
+			width = len(first)
+		}
+
+		var words []string
+		for i := start; i <= end; i++ {
+			num := strconv.Itoa(i)
+			for len(num) < width {
+				num = "0" + num
+			}
+			// Additional ranges in the entry are expanded as well
+			more, ok := expandWordRanges(entry[:idx[0]]+num+entry[idx[1]:], limit-len(words))
+			if !ok {
+				return nil, false
+			}
+			words = append(words, more...)
+		}
+		return words, true
+	}
+
+	if i := strings.Index(entry, "%d"); i != -1 {
+		var words []string
+
+		for n := defaultRangeStart; n <= defaultRangeEnd; n++ {
+			more, ok := expandWordRanges(entry[:i]+strconv.Itoa(n)+entry[i+2:], limit-len(words))
+			if !ok {
+				return nil, false
+			}
+			words = append(words, more...)
+		}
+		return words, true
+	}
+	return []string{entry}, true
+}
+
+// combinationCount - Returns the number of names MultiLevelWords will provide
+func combinationCount(words, depth, limit int) int {
+	var total int
+
+	num := words
+	for d := 2; d <= depth; d++ {
+		num *= words
+		if num >= limit || total+num >= limit {
+			return limit
+		}
+		total += num
 	}
+	return total
 }
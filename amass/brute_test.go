@@ -3,11 +3,155 @@
 
 package amass
 
-/*
 import (
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	evbus "github.com/asaskevich/EventBus"
 )
 
+func TestBruteExpandWordRanges(t *testing.T) {
+	words, _ := ExpandWordRanges("web[01-20]")
+	if num := len(words); num != 20 {
+		t.Errorf("ExpandWordRanges returned %d words instead of %d", num, 20)
+	}
+	if words[0] != "web01" || words[19] != "web20" {
+		t.Errorf("ExpandWordRanges did not zero pad the range: %s, %s", words[0], words[19])
+	}
+
+	words, _ = ExpandWordRanges("db-%d")
+	if num := len(words); num != 10 {
+		t.Errorf("ExpandWordRanges returned %d words instead of %d", num, 10)
+	}
+	if words[0] != "db-0" || words[9] != "db-9" {
+		t.Errorf("ExpandWordRanges did not substitute the numbers: %s, %s", words[0], words[9])
+	}
+
+	if words, _ = ExpandWordRanges("app[1-2]-%d"); len(words) != 20 {
+		t.Errorf("ExpandWordRanges returned %d words for multiple ranges", len(words))
+	}
+
+	if words, _ = ExpandWordRanges("mail"); len(words) != 1 || words[0] != "mail" {
+		t.Errorf("ExpandWordRanges modified a word without range syntax: %v", words)
+	}
+
+	for _, entry := range []string{"a[0-99999999]", "a[0-999]b[0-999]", "a[0-9223372036854775807]", "%d%d%d%d%d"} {
+		if words, err := ExpandWordRanges(entry); err == nil {
+			t.Errorf("ExpandWordRanges expanded %q to %d words beyond the maximum", entry, len(words))
+		}
+	}
+
+	// The ranges before an invalid range are still expanded
+	for _, entry := range []string{"a[0-9999]-%d-[9-1]", "a[1-3]-[5-2]-%d", "[2-1]%d", "a[0-99]-[1-9]"} {
+		words, _ := expandWordRanges(entry, maxWordRangeWords)
+		if num := wordRangeCount(entry); num != len(words) {
+			t.Errorf("wordRangeCount returned %d for %q, which expands to %d words", num, entry, len(words))
+		}
+	}
+	if words, err := ExpandWordRanges("a[0-99999]-[9-1]"); err == nil {
+		t.Errorf("ExpandWordRanges expanded the entry with an invalid range to %d words", len(words))
+	}
+
+	// The substituted numbers can form new ranges that were not counted in the entry
+	for _, entry := range []string{"[[0-999]-999]", "[[0-9999]-9999]", "[%d%d%d-99999]"} {
+		if words, err := ExpandWordRanges(entry); err == nil {
+			t.Errorf("ExpandWordRanges expanded the nested ranges in %q to %d words", entry, len(words))
+		}
+	}
+	if words, err := ExpandWordRanges("[[1-2]-3]"); err != nil || len(words) != 5 {
+		t.Errorf("ExpandWordRanges returned %v for the small nested ranges: %v", words, err)
+	}
+
+	words, errs := ExpandWordlist([]string{"www", "a[0-99999999]", "db[1-3]"})
+	if len(words) != 4 || len(errs) != 1 || !strings.Contains(errs[0].Error(), "a[0-99999999]") {
+		t.Errorf("ExpandWordlist returned %v and reported %v", words, errs)
+	}
+}
+
+func TestBruteMultiLevelWords(t *testing.T) {
+	words := []string{"api", "internal", "dev"}
+
+	combos := MultiLevelWords(words, 2, 100)
+	if len(combos) != 9 {
+		t.Errorf("MultiLevelWords returned %d combinations instead of 9", len(combos))
+	}
+	// The most common words are combined first
+	if combos[0] != "api.api" || combos[1] != "api.internal" || combos[2] != "internal.api" {
+		t.Errorf("MultiLevelWords did not order the combinations by position: %v", combos)
+	}
+
+	if combos = MultiLevelWords(words, 3, 12); len(combos) != 12 || combos[9] != "api.api.api" {
+		t.Errorf("MultiLevelWords did not continue with three labels within the cap: %v", combos)
+	}
+	if num := combinationCount(len(words), 3, 1000); num != 36 {
+		t.Errorf("combinationCount returned %d instead of 36", num)
+	}
+	if num := combinationCount(10000, 2, 5000); num != 5000 {
+		t.Errorf("combinationCount did not respect the cap: %d", num)
+	}
+}
+
+func TestBruteSubDiscoveries(t *testing.T) {
+	bfs := NewBruteForceService(&core.AmassConfig{}, nil)
+
+	if dis := bfs.subDiscoveries("www.dev.owasp.org", "dev.owasp.org"); dis != 1 {
+		t.Errorf("The first name was counted as %d discoveries", dis)
+	}
+	// The same name resolved again is not counted
+	if dis := bfs.subDiscoveries("www.dev.owasp.org", "dev.owasp.org"); dis != 0 {
+		t.Errorf("The repeated name was counted as %d discoveries", dis)
+	}
+	if dis := bfs.subDiscoveries("api.dev.owasp.org", "dev.owasp.org"); dis != 2 {
+		t.Errorf("The second name was counted as %d discoveries", dis)
+	}
+}
+
+func TestBruteGuessOrderConcurrent(t *testing.T) {
+	var words []string
+	for i := 0; i < 1000; i++ {
+		words = append(words, fmt.Sprintf("host%d", i))
+	}
+
+	var lock sync.Mutex
+	guesses := make(map[string]int)
+	bus := evbus.New()
+	bus.Subscribe(core.DNSQUERY, func(req *core.AmassRequest) {
+		lock.Lock()
+		guesses[req.Name]++
+		lock.Unlock()
+	})
+
+	bfs := NewBruteForceService(&core.AmassConfig{OPSEC: true}, bus)
+	bfs.words = append([]string(nil), words...)
+
+	domains := []string{"owasp.org", "example.com"}
+	for _, domain := range domains {
+		bfs.startDomain(domain)
+	}
+	for bfs.IsActive() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i, word := range bfs.words {
+		if word != words[i] {
+			t.Fatalf("The shared wordlist was reordered at %d: %s", i, word)
+		}
+	}
+	for _, domain := range domains {
+		for _, word := range words {
+			if n := guesses[word+"."+domain]; n != 1 {
+				t.Errorf("%s.%s was guessed %d times", word, domain, n)
+			}
+		}
+	}
+}
+
+/*
+
 func TestBruteForceService(t *testing.T) {
 	domains := []string{"claritysec.com", "twitter.com", "google.com", "github.com"}
 
@@ -0,0 +1,79 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"sync"
+)
+
+// Keys used when recording the work skipped due to the budgets
+const (
+	SkippedDNSQueries = "Names not resolved after the DNS query limit"
+	SkippedRuntime    = "Requests abandoned at the runtime limit"
+)
+
+// budget - Tracks the use of the limited resources and the work that was skipped
+type budget struct {
+	sync.Mutex
+	calls   map[string]int
+	skipped map[string]int
+}
+
+func (c *AmassConfig) getBudget() *budget {
+	c.budgetOnce.Do(func() {
+		c.budget = &budget{
+			calls:   make(map[string]int),
+			skipped: make(map[string]int),
+		}
+	})
+	return c.budget
+}
+
+// AllowSourceCall - Returns true when the data source has not used up its API call budget
+func (c *AmassConfig) AllowSourceCall(source string) bool {
+	b := c.getBudget()
+
+	b.Lock()
+	defer b.Unlock()
+
+	if c.MaxSourceCalls > 0 && b.calls[source] >= c.MaxSourceCalls {
+		b.skipped[source+" API calls over the limit"]++
+		return false
+	}
+	b.calls[source]++
+	return true
+}
+
+// AllowDNSQueries - Returns true when the number of DNS queries sent is within the budget
+func (c *AmassConfig) AllowDNSQueries(sent uint64) bool {
+	if c.MaxDNSQueries == 0 || sent < c.MaxDNSQueries {
+		return true
+	}
+	c.SkippedWork(SkippedDNSQueries, 1)
+	return false
+}
+
+// SkippedWork - Records work that was not performed due to the budgets
+func (c *AmassConfig) SkippedWork(what string, num int) {
+	b := c.getBudget()
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.skipped[what] += num
+}
+
+// Skipped - Returns the amount of work skipped due to the budgets
+func (c *AmassConfig) Skipped() map[string]int {
+	b := c.getBudget()
+
+	b.Lock()
+	defer b.Unlock()
+
+	skipped := make(map[string]int)
+	for k, v := range b.skipped {
+		skipped[k] = v
+	}
+	return skipped
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	evbus "github.com/asaskevich/EventBus"
+)
+
+// EventBus - Counts the asynchronous callbacks that have not returned, so the enumeration
+// can tell when no events are still on their way to the services
+type EventBus struct {
+	evbus.Bus
+
+	config  *AmassConfig
+	pending int64
+
+	// Held while an event is published, so Stop can wait out the publishes under way
+	publishing sync.RWMutex
+	stopped    bool
+
+	sync.Mutex
+	handlers map[string][]*busHandler
+}
+
+type busHandler struct {
+	callback reflect.Value
+	wrapped  interface{}
+}
+
+// NewEventBus - Returns the bus shared by the services of the enumeration
+func NewEventBus(config *AmassConfig) *EventBus {
+	return &EventBus{
+		Bus:      evbus.New(),
+		config:   config,
+		handlers: make(map[string][]*busHandler),
+	}
+}
+
+// Pending - Returns the number of asynchronous callbacks that have not returned
+func (eb *EventBus) Pending() int64 {
+	return atomic.LoadInt64(&eb.pending)
+}
+
+// SubscribeAsync - Subscribes the function to the topic, and counts each callback until it returns
+func (eb *EventBus) SubscribeAsync(topic string, fn interface{}, transactional bool) error {
+	callback := reflect.ValueOf(fn)
+	if callback.Kind() != reflect.Func {
+		return eb.Bus.SubscribeAsync(topic, fn, transactional)
+	}
+
+	wrapped := reflect.MakeFunc(callback.Type(), func(args []reflect.Value) []reflect.Value {
+		defer atomic.AddInt64(&eb.pending, -1)
+
+		return callback.Call(args)
+	}).Interface()
+
+	if err := eb.Bus.SubscribeAsync(topic, wrapped, transactional); err != nil {
+		return err
+	}
+
+	eb.Lock()
+	eb.handlers[topic] = append(eb.handlers[topic], &busHandler{
+		callback: callback,
+		wrapped:  wrapped,
+	})
+	eb.Unlock()
+	return nil
+}
+
+// Unsubscribe - Removes the function from the topic
+func (eb *EventBus) Unsubscribe(topic string, fn interface{}) error {
+	callback := reflect.ValueOf(fn)
+
+	eb.Lock()
+	for i, h := range eb.handlers[topic] {
+		if h.callback == callback {
+			eb.handlers[topic] = append(eb.handlers[topic][:i], eb.handlers[topic][i+1:]...)
+			eb.Unlock()
+			return eb.Bus.Unsubscribe(topic, h.wrapped)
+		}
+	}
+	eb.Unlock()
+	return eb.Bus.Unsubscribe(topic, fn)
+}
+
+// Publish - Executes the callbacks subscribed to the topic
+func (eb *EventBus) Publish(topic string, args ...interface{}) {
+	eb.publishing.RLock()
+	defer eb.publishing.RUnlock()
+
+	if eb.stopped {
+		return
+	}
+
+	eb.Lock()
+	num := len(eb.handlers[topic])
+	eb.Unlock()
+
+	atomic.AddInt64(&eb.pending, int64(num))
+	eb.config.NoteActivity()
+	eb.Bus.Publish(topic, args...)
+}
+
+// Stop - Drops the events published from now on, so WaitAsync is not called while the
+// services are still publishing. The callbacks of the events already published still run
+func (eb *EventBus) Stop() {
+	eb.publishing.Lock()
+	defer eb.publishing.Unlock()
+
+	eb.stopped = true
+}
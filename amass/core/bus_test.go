@@ -0,0 +1,40 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestEventBusStop(t *testing.T) {
+	bus := NewEventBus(&AmassConfig{})
+
+	var delivered int64
+	bus.SubscribeAsync(RESOLVED, func(req *AmassRequest) {
+		atomic.AddInt64(&delivered, 1)
+	}, false)
+
+	// The publishers are still running while the bus is stopped and waited on
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 1000; i++ {
+			bus.Publish(RESOLVED, &AmassRequest{Name: "www.owasp.org"})
+		}
+	}()
+	bus.Publish(RESOLVED, &AmassRequest{Name: "owasp.org"})
+	bus.Stop()
+	bus.WaitAsync()
+
+	count := atomic.LoadInt64(&delivered)
+	<-done
+	if count == 0 {
+		t.Errorf("The event published before the bus was stopped was not delivered")
+	}
+	if bus.Pending() != 0 || atomic.LoadInt64(&delivered) != count {
+		t.Errorf("Events were delivered after the bus was stopped")
+	}
+}
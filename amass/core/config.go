@@ -4,9 +4,11 @@
 package core
 
 import (
+	"crypto/tls"
 	"io"
 	"log"
 	"net"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync"
@@ -17,6 +19,12 @@ import (
 
 // AmassConfig - Passes along optional configurations
 type AmassConfig struct {
+	// The count of work queued, started or sent between the services (first for 64-bit alignment)
+	activity uint64
+
+	// The number of pipeline stages that have been opened by the coordinator
+	openStages int32
+
 	sync.Mutex
 
 	// Logger for error messages
@@ -46,9 +54,18 @@ type AmassConfig struct {
 	// Will recursive brute forcing be performed?
 	Recursive bool
 
-	// Minimum number of subdomain discoveries before performing recursive brute forcing
+	// Minimum number of unique names discovered beneath a subdomain before it is brute forced
 	MinForRecursive int
 
+	// The number of labels guessed at once beneath the root domains (e.g. 2 for api.internal)
+	BruteForceDepth int
+
+	// The most multi-level names guessed per root domain (zero uses the default cap)
+	MaxBruteCombinations int
+
+	// Will common service names be brute forced for SRV records?
+	SRVBruteForcing bool
+
 	// Will discovered subdomain name alterations be generated?
 	Alterations bool
 
@@ -58,23 +75,175 @@ type AmassConfig struct {
 	// Determines if zone transfers will be attempted
 	Active bool
 
+	// Will the TLS configurations found by the active techniques be checked and reported as findings?
+	TLSChecks bool
+
+	// Will the sweeps, SNI scans and port probes include the addresses of content delivery networks?
+	ProbeCDN bool
+
+	// Will the data sources indexing analytics IDs be searched for other domains using the IDs of the in-scope sites?
+	AnalyticsPivot bool
+
+	// The pipeline stages that will be run (empty means all stages)
+	Stages []string
+
+	// Will each stage wait for the stages before it to finish their work?
+	StageBarriers bool
+
 	// A blacklist of subdomain names that will not be investigated
 	Blacklist []string
 
+	// The names of data sources that will not be queried
+	DisabledSources []string
+
+	// Will the data sources that send the root domain names to third parties be excluded?
+	MinimizeExposure bool
+
+	// Will the long-tail web search scrapers be queried? They are slow and share one rate limit
+	WebSearch bool
+
+	// The tags that names must carry to be investigated and reported (empty means all tags)
+	IncludeTags []string
+
+	// The tags of names that will not be investigated or reported
+	ExcludeTags []string
+
+	// The directory containing executables that will be used as data sources
+	PluginDir string
+
+	// The directory containing scripts for data sources, alterations and output filters
+	ScriptDir string
+
+	// The forward DNS dataset file (Rapid7 Sonar style, optionally gzipped) searched for names
+	FDNSFile string
+
+	// The directory of outputs from previous recon tools (subfinder, assetfinder, dnsrecon) searched for names
+	LocalDir string
+
+	// The seed for the pseudo-random numbers, making the choices reproducible when not zero
+	Seed int64
+
 	// Sets the maximum number of DNS queries per minute
 	Frequency time.Duration
 
+	// The most DNS queries in flight at once (zero uses the open file limit)
+	MaxDNSConcurrency int
+
+	// The most random delay inserted before each DNS query
+	Jitter time.Duration
+
+	// The time waited between starting the rate limited data source queries (zero uses the default)
+	SourceDelay time.Duration
+
+	// The most rate limited data source queries running at once (zero uses the default)
+	MaxSourceConcurrency int
+
+	// Will the guesses be shuffled and the queries spread across the resolvers and time?
+	OPSEC bool
+
+	// Unrelated domains that decoy queries are sent for (empty uses the defaults)
+	DecoyDomains []string
+
+	// The number of decoy queries sent for each name resolved (zero sends none)
+	DecoyRatio float64
+
+	// The local addresses that outbound DNS and HTTP connections are bound to, in turn
+	SourceAddrs []net.IP
+
+	// The proxies that the data sources of each category send their web requests through, where the
+	// empty category applies to the others and a nil proxy connects directly
+	SourceProxies map[string]*url.URL
+
+	// Will the query names use DNS 0x20 mixed-case encoding, discarding answers that do not echo it?
+	CaseRandomization bool
+
+	// Will names be resolved by walking the delegations from the root servers instead of using resolvers?
+	Iterative bool
+
+	// Will the brute forced names be sent straight to the authoritative servers of the root domains?
+	AuthoritativeDirect bool
+
+	// The most queries per second sent to the authoritative servers of each root domain (zero uses the default)
+	AuthoritativeRate int
+
+	// The record types queried for names that exist without addresses (empty uses MX, NS and SRV)
+	NoDataTypes []string
+
+	// The record types queried in parallel for each name (empty uses TXT, A, AAAA and CNAME)
+	QueryTypes []string
+
+	// Will only one more label of the name be revealed to each server while iterating?
+	QNAMEMinimization bool
+
+	// Will the resolvers be probed with control names and those returning filtered answers excluded?
+	PolicyCheck bool
+
+	// The total number of DNS queries that can be sent (zero means no limit)
+	MaxDNSQueries uint64
+
+	// The number of API calls each data source can receive (zero means no limit)
+	MaxSourceCalls int
+
+	// The longest time the enumeration can run (zero means no limit)
+	MaxRuntime time.Duration
+
+	// The directory where service queues are written while paused or once they grow large
+	QueueDir string
+
 	// Preferred DNS resolvers identified by the user
 	Resolvers []string
 
+	// The addresses of remote workers that will resolve names for the enumeration. Each name,
+	// including the brute forcing guesses, is resolved by the next worker in turn over gRPC,
+	// and a worker that fails is left out until it can be reached again
+	Workers []string
+
+	// The addresses of remote agents used to compare answers from other vantage points
+	Agents []string
+
+	// The static token presented to the remote workers and agents
+	WorkerToken string
+
+	// The TLS configuration used to connect with the remote workers and agents
+	WorkerTLS *tls.Config
+
+	// Will DNSSEC signatures be validated on answers from signed zones?
+	DNSSEC bool
+
+	// The file providing the trust anchors for the root zone used by the DNSSEC validation
+	TrustAnchors string
+
+	// Will several resolvers be queried to detect names with divergent answers?
+	Divergence bool
+
+	// Will PTR lookups be performed to check that resolved addresses map back to the names?
+	PTRValidation bool
+
+	// Will servers in the in-scope netblocks be presented the discovered names as TLS SNI values?
+	SNIScanning bool
+
 	// The writer used to save the data operations performed
 	DataOptsWriter io.Writer
 
+	// The writer receiving the OpenTelemetry spans for the names moving through the pipeline
+	TraceWriter io.Writer
+
 	// The root domain names that the enumeration will target
 	domains []string
 
 	// The regular expressions for the root domains added to the enumeration
 	regexps map[string]*regexp.Regexp
+
+	// Tracks the use of the limits set above
+	budget     *budget
+	budgetOnce sync.Once
+
+	// Writes the spans when tracing is enabled
+	tracer     *tracer
+	tracerOnce sync.Once
+
+	// Closed when the configuration is changed during the enumeration
+	reloaded chan struct{}
 }
 
 func (c *AmassConfig) DomainRegex(domain string) *regexp.Regexp {
@@ -88,6 +257,9 @@ func (c *AmassConfig) DomainRegex(domain string) *regexp.Regexp {
 }
 
 func (c *AmassConfig) AddDomain(domain string) {
+	c.Lock()
+	defer c.Unlock()
+
 	c.domains = utils.UniqueAppend(c.domains, domain)
 
 	if c.regexps == nil {
@@ -117,6 +289,9 @@ func (c *AmassConfig) IsDomainInScope(name string) bool {
 }
 
 func (c *AmassConfig) Blacklisted(name string) bool {
+	c.Lock()
+	defer c.Unlock()
+
 	var resp bool
 
 	for _, bl := range c.Blacklist {
@@ -127,3 +302,43 @@ func (c *AmassConfig) Blacklisted(name string) bool {
 	}
 	return resp
 }
+
+func (c *AmassConfig) SourceDisabled(name string) bool {
+	var resp bool
+
+	for _, ds := range c.DisabledSources {
+		if strings.EqualFold(strings.TrimSpace(ds), name) {
+			resp = true
+			break
+		}
+	}
+	return resp
+}
+
+// SourceProxy - Returns the proxy for the data sources of the category, or nil
+// when their requests connect directly
+func (c *AmassConfig) SourceProxy(category string) *url.URL {
+	if proxy, found := c.SourceProxies[category]; found {
+		return proxy
+	}
+	return c.SourceProxies[""]
+}
+
+// TagInScope - Returns true when names carrying the tag are investigated and reported
+func (c *AmassConfig) TagInScope(tag string) bool {
+	for _, t := range c.ExcludeTags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return false
+		}
+	}
+
+	if len(c.IncludeTags) == 0 {
+		return true
+	}
+	for _, t := range c.IncludeTags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import "testing"
+
+func TestConfigTagInScope(t *testing.T) {
+	tests := []struct {
+		include []string
+		exclude []string
+		tag     string
+		result  bool
+	}{
+		{nil, nil, BRUTE, true},
+		{nil, []string{BRUTE, ALT}, BRUTE, false},
+		{nil, []string{BRUTE, ALT}, CERT, true},
+		{[]string{CERT, "API"}, nil, API, true},
+		{[]string{CERT, API}, nil, SCRAPE, false},
+		{[]string{CERT}, []string{CERT}, CERT, false},
+	}
+
+	for _, test := range tests {
+		config := &AmassConfig{IncludeTags: test.include, ExcludeTags: test.exclude}
+
+		if r := config.TagInScope(test.tag); r != test.result {
+			t.Errorf("Include %v and exclude %v returned %t for the %s tag", test.include, test.exclude, r, test.tag)
+		}
+	}
+
+	if ValidTag("whois") {
+		t.Errorf("The whois tag is not part of the taxonomy")
+	}
+	for _, tag := range Tags {
+		if !ValidTag(tag) {
+			t.Errorf("The %s tag was not accepted", tag)
+		}
+	}
+}
+
+func TestConfigStages(t *testing.T) {
+	config := &AmassConfig{Stages: []string{StagePassive, StageResolve}, StageBarriers: true}
+
+	if !config.StageEnabled(StageResolve) || config.StageEnabled(StageActive) {
+		t.Errorf("The selected stages were not enabled as requested")
+	}
+	if config.StageOpen(StagePassive) {
+		t.Errorf("The passive stage was open before the coordinator opened it")
+	}
+
+	config.OpenStage(StageResolve)
+	// Opening a stage opens the stages before it, and a later stage is never closed again
+	config.OpenStage(StagePassive)
+	if !config.StageOpen(StagePassive) || !config.StageOpen(StageResolve) || config.StageOpen(StageEnrich) {
+		t.Errorf("The stages up to resolve were not the only open stages")
+	}
+
+	if !(&AmassConfig{}).StageOpen(StageActive) {
+		t.Errorf("The stages were held without barriers")
+	}
+	if err := CheckStages([]string{StageEnrich, "probe"}); err == nil {
+		t.Errorf("An unknown stage was accepted")
+	}
+}
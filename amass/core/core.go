@@ -3,20 +3,39 @@
 
 package core
 
+import "strings"
+
 const (
 	// Topics used in the EventBus
-	DNSQUERY = "amass:dnsquery"
-	DNSSWEEP = "amass.dnssweep"
-	RESOLVED = "amass:resolved"
-	OUTPUT   = "amass:output"
+	DNSQUERY    = "amass:dnsquery"
+	DNSSWEEP    = "amass.dnssweep"
+	RESOLVED    = "amass:resolved"
+	OUTPUT      = "amass:output"
+	ADDRESS     = "amass:address"
+	ASNUMBER    = "amass:asnumber"
+	CERTIFICATE = "amass:certificate"
+	URL         = "amass:url"
+	HISTORY     = "amass:history"
+	FINDING     = "amass:finding"
+	NEWDOMAIN   = "amass:newdomain"
+	ANALYTICS   = "amass:analytics"
 
 	// Tags used to mark the data source with the Subdomain struct
-	ALT     = "alt"
-	ARCHIVE = "archive"
-	API     = "api"
-	BRUTE   = "brute"
-	CERT    = "cert"
-	SCRAPE  = "scrape"
+	ALT       = "alt"
+	ARCHIVE   = "archive"
+	API       = "api"
+	AXFR      = "axfr"
+	BRUTE     = "brute"
+	CERT      = "cert"
+	DNS       = "dns"
+	LOCAL     = "local"
+	SCRAPE    = "scrape"
+	WEBSEARCH = "websearch"
+
+	// DNSSEC validation results recorded with the AmassRequest
+	DNSSECSecure   = "secure"
+	DNSSECInsecure = "insecure"
+	DNSSECBogus    = "bogus"
 
 	// Node types used in the Maltego local transform
 	TypeNorm int = iota
@@ -24,3 +43,16 @@ const (
 	TypeMX
 	TypeWeb
 )
+
+// Tags - All the tags that mark how names were discovered
+var Tags = []string{ALT, API, ARCHIVE, AXFR, BRUTE, CERT, DNS, LOCAL, SCRAPE, WEBSEARCH}
+
+// ValidTag - Returns true when the tag is part of the taxonomy
+func ValidTag(tag string) bool {
+	for _, t := range Tags {
+		if strings.EqualFold(strings.TrimSpace(tag), t) {
+			return true
+		}
+	}
+	return false
+}
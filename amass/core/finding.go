@@ -0,0 +1,49 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+// The severities of the findings, from the least to the most severe
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// The types of the notable findings
+const (
+	FindingTakeover     = "takeover_candidate"
+	FindingDangling     = "dangling_record"
+	FindingZoneTransfer = "open_zone_transfer"
+	FindingInternalIP   = "internal_ip_leak"
+	FindingExpiredCert  = "expired_certificate"
+	FindingExpiringCert = "expiring_certificate"
+	FindingWeakTLS      = "weak_tls_version"
+	FindingSelfSigned   = "self_signed_certificate"
+	FindingCertMismatch = "certificate_mismatch"
+	FindingRelated      = "related_domain"
+)
+
+// Finding - A notable issue shown by the discovered names, published on the FINDING topic.
+// The Name is the asset the issue applies to, such as a subdomain or a root domain
+type Finding struct {
+	Type        string   `json:"type"`
+	Severity    string   `json:"severity"`
+	Name        string   `json:"name"`
+	Domain      string   `json:"domain"`
+	Description string   `json:"description"`
+	Evidence    []string `json:"evidence"`
+}
+
+// SeverityRank - Returns the position of the severity in the order of the severities, so
+// the findings can be sorted with the most severe first
+func SeverityRank(severity string) int {
+	for i, s := range []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical} {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
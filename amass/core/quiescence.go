@@ -0,0 +1,40 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import "sync/atomic"
+
+// NoteActivity - Records that work was queued, started or sent between the services
+func (c *AmassConfig) NoteActivity() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.activity, 1)
+}
+
+// Activity - Returns the number of times work was queued, started or sent between the services.
+// The enumeration is complete when the services are idle and this count did not change
+// while they were being checked
+func (c *AmassConfig) Activity() uint64 {
+	return atomic.LoadUint64(&c.activity)
+}
+
+// Quiescent - Returns true when no events are on their way to the services, none of the
+// services have work queued or in flight, and nothing changed while this was checked
+func Quiescent(config *AmassConfig, bus *EventBus, services []AmassService) bool {
+	before := config.Activity()
+
+	if bus.Pending() > 0 {
+		return false
+	}
+	for _, service := range services {
+		if service.IsActive() {
+			return false
+		}
+	}
+	if bus.Pending() > 0 {
+		return false
+	}
+	return config.Activity() == before
+}
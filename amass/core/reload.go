@@ -0,0 +1,97 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"net"
+	"time"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// Rates - The query rates that can be changed while the enumeration is running
+type Rates struct {
+	// The time between the requests handled by the services
+	Frequency time.Duration
+
+	// The most random delay inserted before each DNS query
+	Jitter time.Duration
+
+	// The time waited between starting the rate limited data source queries
+	SourceDelay time.Duration
+
+	// The most rate limited data source queries running at once
+	MaxSourceConcurrency int
+
+	// The most queries sent each second to the authoritative servers of a root domain
+	AuthoritativeRate int
+}
+
+// Rates - Returns the current query rates, which the services read each time they are
+// used so the changes made by SetRates are picked up during the enumeration
+func (c *AmassConfig) Rates() Rates {
+	c.Lock()
+	defer c.Unlock()
+
+	return Rates{
+		Frequency:            c.Frequency,
+		Jitter:               c.Jitter,
+		SourceDelay:          c.SourceDelay,
+		MaxSourceConcurrency: c.MaxSourceConcurrency,
+		AuthoritativeRate:    c.AuthoritativeRate,
+	}
+}
+
+// SetRates - Changes the query rates, and lets the services restart their timers
+func (c *AmassConfig) SetRates(r Rates) {
+	c.Lock()
+	c.Frequency = r.Frequency
+	c.Jitter = r.Jitter
+	c.SourceDelay = r.SourceDelay
+	c.MaxSourceConcurrency = r.MaxSourceConcurrency
+	c.AuthoritativeRate = r.AuthoritativeRate
+	c.Unlock()
+
+	c.notifyReload()
+}
+
+// AddBlacklist - Keeps the names from being investigated for the remainder of the enumeration
+func (c *AmassConfig) AddBlacklist(names ...string) {
+	c.Lock()
+	c.Blacklist = utils.UniqueAppend(c.Blacklist, names...)
+	c.Unlock()
+
+	c.notifyReload()
+}
+
+// AddCIDRs - Includes the netblocks in the targets of the enumeration
+func (c *AmassConfig) AddCIDRs(cidrs ...*net.IPNet) {
+	c.Lock()
+	c.CIDRs = append(c.CIDRs, cidrs...)
+	c.Unlock()
+
+	c.notifyReload()
+}
+
+// Reloaded - Returns a channel that is closed the next time the configuration is changed.
+// The services obtain a new channel after each reload
+func (c *AmassConfig) Reloaded() <-chan struct{} {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.reloaded == nil {
+		c.reloaded = make(chan struct{})
+	}
+	return c.reloaded
+}
+
+func (c *AmassConfig) notifyReload() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.reloaded != nil {
+		close(c.reloaded)
+	}
+	c.reloaded = make(chan struct{})
+}
@@ -3,11 +3,18 @@
 
 package core
 
+import (
+	"crypto/x509"
+	"net"
+	"time"
+)
+
 type DNSAnswer struct {
-	Name string `json:"name"`
-	Type int    `json:"type"`
-	TTL  int    `json:"TTL"`
-	Data string `json:"data"`
+	Name     string `json:"name"`
+	Type     int    `json:"type"`
+	TTL      int    `json:"TTL"`
+	Priority int    `json:"priority,omitempty"`
+	Data     string `json:"data"`
 }
 
 // AmassRequest - Contains data obtained throughout AmassService processing
@@ -17,4 +24,93 @@ type AmassRequest struct {
 	Records []DNSAnswer
 	Tag     string
 	Source  string
+
+	// The result of DNSSEC validation on the records
+	DNSSEC string
+
+	// Did the resolvers return different answers for the name?
+	Divergent bool
+
+	// Did remote agents at other vantage points observe different addresses?
+	GeoDNS bool
+
+	// Did the name exist without any addresses (NOERROR/NODATA rather than NXDOMAIN)?
+	NoData bool
+}
+
+// AddrRequest - An IP address discovered for a name, published on the ADDRESS topic
+type AddrRequest struct {
+	Address string
+	Name    string
+	Domain  string
+	Tag     string
+	Source  string
+}
+
+// NetblockRequest - A netblock containing a discovered address, published on the DNSSWEEP topic
+type NetblockRequest struct {
+	CIDR    *net.IPNet
+	Address string
+	ASN     int
+	Domain  string
+	Tag     string
+	Source  string
+}
+
+// ASNRequest - An autonomous system announcing a discovered address, published on the ASNUMBER topic
+type ASNRequest struct {
+	ASN         int
+	Prefix      string
+	Description string
+	Address     string
+	Domain      string
+	Tag         string
+	Source      string
+}
+
+// CertRequest - A certificate presented by a server, published on the CERTIFICATE topic.
+// The Version is the TLS protocol version negotiated, and the Name is the discovered name
+// resolved to the address, when the certificate was pulled for one
+type CertRequest struct {
+	Address     string
+	Port        int
+	ServerName  string
+	Certificate *x509.Certificate
+	Version     uint16
+	Name        string
+	Domain      string
+	Tag         string
+	Source      string
+}
+
+// URLRequest - A web address found for an in-scope name, published on the URL topic
+type URLRequest struct {
+	URL    string
+	Name   string
+	Domain string
+	Tag    string
+	Source string
+}
+
+// AnalyticsRequest - An analytics or advertising ID embedded in the page of an in-scope name,
+// published on the ANALYTICS topic
+type AnalyticsRequest struct {
+	ID     string
+	URL    string
+	Name   string
+	Domain string
+	Tag    string
+	Source string
+}
+
+// HistoryRequest - A DNS record value that a name held in the past, published on the HISTORY topic
+type HistoryRequest struct {
+	Name      string
+	Domain    string
+	Type      string
+	Value     string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Tag       string
+	Source    string
 }
@@ -6,7 +6,6 @@ package core
 import (
 	"errors"
 	"sync"
-	"time"
 )
 
 type AmassService interface {
@@ -31,9 +30,17 @@ type AmassService interface {
 
 	NextRequest() *AmassRequest
 	SendRequest(req *AmassRequest)
+	NumOfRequests() int
 
+	// Returns true while the service has queued or in-flight work
 	IsActive() bool
-	SetActive()
+
+	// Assigns the pipeline stage that the work of the service belongs to
+	SetStage(stage string)
+
+	// Track work performed outside of the request queue
+	StartWork()
+	FinishWork()
 
 	// Returns channels that fire during Pause/Resume operations
 	PauseChan() <-chan struct{}
@@ -52,18 +59,24 @@ type BaseAmassService struct {
 	started bool
 	stopped bool
 	queue   []*AmassRequest
-	active  time.Time
-	pause   chan struct{}
-	resume  chan struct{}
-	quit    chan struct{}
-	config  *AmassConfig
+	// The requests written to disk after the queue, when a queue directory has been provided
+	spill  *requestSpill
+	paused bool
+	// The number of requests and other work items still being handled
+	inflight int
+	pause    chan struct{}
+	resume   chan struct{}
+	quit     chan struct{}
+	config   *AmassConfig
+	// The pipeline stage that the work of the service belongs to
+	stage string
 
 	// The specific service embedding BaseAmassService
 	service AmassService
 }
 
 func NewBaseAmassService(name string, config *AmassConfig, service AmassService) *BaseAmassService {
-	return &BaseAmassService{
+	bas := &BaseAmassService{
 		name:    name,
 		queue:   make([]*AmassRequest, 0, 50),
 		pause:   make(chan struct{}),
@@ -72,6 +85,11 @@ func NewBaseAmassService(name string, config *AmassConfig, service AmassService)
 		config:  config,
 		service: service,
 	}
+
+	if config != nil && config.QueueDir != "" {
+		bas.spill = newRequestSpill(config.QueueDir)
+	}
+	return bas
 }
 
 func (bas *BaseAmassService) Start() error {
@@ -92,6 +110,18 @@ func (bas *BaseAmassService) List() string {
 }
 
 func (bas *BaseAmassService) Pause() error {
+	bas.Lock()
+	bas.paused = true
+	// The queue is held on disk until the service resumes
+	if bas.spill != nil && len(bas.queue) > 0 {
+		if err := bas.spill.Write(bas.queue...); err != nil {
+			bas.config.Log.Printf("%s: %v", bas.name, err)
+		} else {
+			bas.queue = []*AmassRequest{}
+		}
+	}
+	bas.Unlock()
+
 	return bas.service.OnPause()
 }
 
@@ -100,6 +130,10 @@ func (bas *BaseAmassService) OnPause() error {
 }
 
 func (bas *BaseAmassService) Resume() error {
+	bas.Lock()
+	bas.paused = false
+	bas.Unlock()
+
 	return bas.service.OnResume()
 }
 
@@ -113,6 +147,12 @@ func (bas *BaseAmassService) Stop() error {
 	}
 	err := bas.service.OnStop()
 	close(bas.quit)
+
+	bas.Lock()
+	if bas.spill != nil {
+		bas.spill.Close()
+	}
+	bas.Unlock()
 	return err
 }
 
@@ -124,16 +164,25 @@ func (bas *BaseAmassService) NumOfRequests() int {
 	bas.Lock()
 	defer bas.Unlock()
 
-	return len(bas.queue)
+	return len(bas.queue) + bas.spilled()
 }
 
+// NextRequest - Removes the next request from the queue, and FinishWork must be
+// called once the request has been handled
 func (bas *BaseAmassService) NextRequest() *AmassRequest {
 	bas.Lock()
 	defer bas.Unlock()
 
+	if bas.held() {
+		return nil
+	}
+	if len(bas.queue) == 0 && bas.spilled() > 0 {
+		bas.readSpill()
+	}
 	if len(bas.queue) == 0 {
 		return nil
 	}
+	bas.inflight++
 
 	var next *AmassRequest
 
@@ -153,24 +202,79 @@ func (bas *BaseAmassService) SendRequest(req *AmassRequest) {
 	bas.Lock()
 	defer bas.Unlock()
 
+	bas.config.NoteActivity()
+	// Once requests are on disk, the new ones follow them to keep the order
+	if bas.spill != nil && (bas.paused || bas.spill.Len() > 0 || len(bas.queue) >= spillThreshold) {
+		err := bas.spill.Write(req)
+		if err == nil {
+			return
+		}
+		bas.config.Log.Printf("%s: %v", bas.name, err)
+	}
 	bas.queue = append(bas.queue, req)
 }
 
-func (bas *BaseAmassService) IsActive() bool {
+// SetStage - Assigns the pipeline stage of the service, so requests are held in the
+// queue until the coordinator opens the stage
+func (bas *BaseAmassService) SetStage(stage string) {
+	bas.Lock()
+	defer bas.Unlock()
+
+	bas.stage = stage
+}
+
+// Held - Returns true while the pipeline stage of the service has not been opened
+func (bas *BaseAmassService) Held() bool {
 	bas.Lock()
 	defer bas.Unlock()
 
-	if time.Now().Sub(bas.active) > 5*time.Second {
-		return false
+	return bas.held()
+}
+
+func (bas *BaseAmassService) held() bool {
+	return bas.stage != "" && !bas.config.StageOpen(bas.stage)
+}
+
+func (bas *BaseAmassService) spilled() int {
+	if bas.spill == nil {
+		return 0
+	}
+	return bas.spill.Len()
+}
+
+// readSpill - Moves the next batch of requests from disk back into memory
+func (bas *BaseAmassService) readSpill() {
+	reqs, err := bas.spill.Read(spillBatchSize)
+	if err != nil {
+		bas.config.Log.Printf("%s: %v", bas.name, err)
 	}
-	return true
+	bas.queue = append(bas.queue, reqs...)
 }
 
-func (bas *BaseAmassService) SetActive() {
+func (bas *BaseAmassService) IsActive() bool {
 	bas.Lock()
 	defer bas.Unlock()
 
-	bas.active = time.Now()
+	return len(bas.queue) > 0 || bas.spilled() > 0 || bas.inflight > 0
+}
+
+// StartWork - Marks the beginning of work that is not tracked through the request queue
+func (bas *BaseAmassService) StartWork() {
+	bas.Lock()
+	bas.inflight++
+	bas.Unlock()
+
+	bas.config.NoteActivity()
+}
+
+// FinishWork - Marks the end of a request obtained from NextRequest or work begun with StartWork
+func (bas *BaseAmassService) FinishWork() {
+	bas.Lock()
+	defer bas.Unlock()
+
+	if bas.inflight > 0 {
+		bas.inflight--
+	}
 }
 
 func (bas *BaseAmassService) PauseChan() <-chan struct{} {
@@ -0,0 +1,113 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const (
+	// The number of requests held in memory before the rest are written to disk
+	spillThreshold = 10000
+
+	// The number of requests read back into memory at a time
+	spillBatchSize = 1000
+)
+
+// requestSpill - Holds the tail of a service queue in a file, so the queue
+// does not need to stay in memory while it cannot be worked through
+type requestSpill struct {
+	dir   string
+	path  string
+	w     *os.File
+	r     *os.File
+	enc   *json.Encoder
+	dec   *json.Decoder
+	count int
+}
+
+func newRequestSpill(dir string) *requestSpill {
+	return &requestSpill{dir: dir}
+}
+
+// Len - Returns the number of requests held on disk
+func (rs *requestSpill) Len() int {
+	return rs.count
+}
+
+// Write - Appends the requests to the file, which is created when necessary
+func (rs *requestSpill) Write(reqs ...*AmassRequest) error {
+	if rs.w == nil {
+		if err := rs.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, req := range reqs {
+		// Each request is written with a single call, so the reader only ever sees complete lines
+		if err := rs.enc.Encode(req); err != nil {
+			return fmt.Errorf("Queue error: Failed to write the request to %s: %v", rs.path, err)
+		}
+		rs.count++
+	}
+	return nil
+}
+
+// Read - Removes up to num requests from the front of the file
+func (rs *requestSpill) Read(num int) ([]*AmassRequest, error) {
+	var reqs []*AmassRequest
+
+	for i := 0; i < num && rs.count > 0; i++ {
+		req := new(AmassRequest)
+
+		if err := rs.dec.Decode(req); err != nil {
+			rs.Close()
+			return reqs, fmt.Errorf("Queue error: Failed to read the request from %s: %v", rs.path, err)
+		}
+		rs.count--
+		reqs = append(reqs, req)
+	}
+	// The file is removed once all the requests have been read back
+	if rs.count == 0 {
+		rs.Close()
+	}
+	return reqs, nil
+}
+
+// Close - Discards the file and any requests still held within it
+func (rs *requestSpill) Close() {
+	if rs.w == nil {
+		return
+	}
+
+	rs.w.Close()
+	rs.r.Close()
+	os.Remove(rs.path)
+	rs.w, rs.r, rs.enc, rs.dec = nil, nil, nil, nil
+	rs.count = 0
+}
+
+func (rs *requestSpill) open() error {
+	w, err := ioutil.TempFile(rs.dir, "amass-queue-")
+	if err != nil {
+		return fmt.Errorf("Queue error: Failed to create a file in %s: %v", rs.dir, err)
+	}
+
+	r, err := os.Open(w.Name())
+	if err != nil {
+		w.Close()
+		os.Remove(w.Name())
+		return fmt.Errorf("Queue error: Failed to open %s: %v", w.Name(), err)
+	}
+
+	rs.path = w.Name()
+	rs.w = w
+	rs.r = r
+	rs.enc = json.NewEncoder(w)
+	rs.dec = json.NewDecoder(r)
+	return nil
+}
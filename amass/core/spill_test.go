@@ -0,0 +1,62 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type spillTestService struct {
+	BaseAmassService
+}
+
+func TestQueueSpillWhilePaused(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-spill-test")
+	if err != nil {
+		t.Fatalf("Failed to create the queue directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sts := new(spillTestService)
+	sts.BaseAmassService = *NewBaseAmassService("Test", &AmassConfig{QueueDir: dir}, sts)
+	bas := &sts.BaseAmassService
+	for i := 0; i < 5; i++ {
+		bas.SendRequest(&AmassRequest{Name: strconv.Itoa(i) + ".owasp.org"})
+	}
+
+	sts.Pause()
+	for i := 5; i < 10; i++ {
+		bas.SendRequest(&AmassRequest{Name: strconv.Itoa(i) + ".owasp.org"})
+	}
+
+	if n := len(bas.queue); n != 0 {
+		t.Errorf("%d requests remained in memory while the service was paused", n)
+	}
+	if n := bas.NumOfRequests(); n != 10 {
+		t.Errorf("The service reported %d requests instead of 10", n)
+	}
+
+	sts.Resume()
+	for i := 0; i < 10; i++ {
+		req := bas.NextRequest()
+		if req == nil {
+			t.Fatalf("The request %d was lost", i)
+		}
+		if expected := strconv.Itoa(i) + ".owasp.org"; req.Name != expected {
+			t.Errorf("Returned %s instead of %s", req.Name, expected)
+		}
+		bas.FinishWork()
+	}
+
+	if req := bas.NextRequest(); req != nil {
+		t.Errorf("Returned the unexpected request %s", req.Name)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("The queue files %v were not removed", files)
+	}
+}
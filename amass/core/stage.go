@@ -0,0 +1,80 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// The stages of the enumeration pipeline
+const (
+	// Querying the data sources
+	StagePassive = "passive"
+	// DNS resolution, brute forcing, name alterations and SRV brute forcing
+	StageResolve = "resolve"
+	// The infrastructure, reverse DNS and netblock sweeps of the resolved addresses
+	StageEnrich = "enrich"
+	// Certificate grabs, web header mining and SNI scanning
+	StageActive = "active"
+)
+
+// Stages - The stages of the enumeration pipeline, in the order they are run
+var Stages = []string{StagePassive, StageResolve, StageEnrich, StageActive}
+
+func stageIndex(stage string) int {
+	for i, s := range Stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// CheckStages - Returns an error when one of the names is not a stage of the pipeline
+func CheckStages(stages []string) error {
+	for _, stage := range stages {
+		if stageIndex(stage) < 0 {
+			return fmt.Errorf("The stage %s is not one of %s", stage, strings.Join(Stages, ", "))
+		}
+	}
+	return nil
+}
+
+// StageEnabled - Returns true when the stage will be run, which is every stage
+// when none were selected
+func (c *AmassConfig) StageEnabled(stage string) bool {
+	if len(c.Stages) == 0 {
+		return true
+	}
+
+	for _, s := range c.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// StageOpen - Returns true when the services of the stage can perform their work,
+// which is always the case unless the stages are separated by barriers
+func (c *AmassConfig) StageOpen(stage string) bool {
+	if c == nil || !c.StageBarriers {
+		return true
+	}
+	return stageIndex(stage) < int(atomic.LoadInt32(&c.openStages))
+}
+
+// OpenStage - Lets the services of the stage, and the stages before it, perform their work
+func (c *AmassConfig) OpenStage(stage string) {
+	idx := int32(stageIndex(stage) + 1)
+
+	for {
+		cur := atomic.LoadInt32(&c.openStages)
+		if cur >= idx || atomic.CompareAndSwapInt32(&c.openStages, cur, idx) {
+			return
+		}
+	}
+}
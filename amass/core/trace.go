@@ -0,0 +1,188 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The stages of the pipeline that a name passes through
+const (
+	SpanEmit    = "emit"
+	SpanDedupe  = "dedupe"
+	SpanResolve = "resolve"
+	SpanEnrich  = "enrich"
+	SpanOutput  = "output"
+)
+
+// Span - The time a name spent within one stage of the pipeline. All the spans
+// for a name share the same trace, so its journey can be followed end to end
+type Span struct {
+	tracer  *tracer
+	traceID string
+	spanID  string
+	stage   string
+	start   time.Time
+	attrs   map[string]string
+}
+
+// tracer - Writes the spans to the TraceWriter using the OTLP JSON encoding,
+// which the OpenTelemetry Collector and other standard tools can ingest
+type tracer struct {
+	sync.Mutex
+	enc *json.Encoder
+}
+
+// The OTLP JSON documents written for each span
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID    string          `json:"traceId"`
+	SpanID     string          `json:"spanId"`
+	Name       string          `json:"name"`
+	Kind       int             `json:"kind"`
+	Start      string          `json:"startTimeUnixNano"`
+	End        string          `json:"endTimeUnixNano"`
+	Attributes []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// The OTLP span kind for work performed within the process
+const otlpSpanKindInternal = 1
+
+func (c *AmassConfig) getTracer() *tracer {
+	c.tracerOnce.Do(func() {
+		if c.TraceWriter != nil {
+			c.tracer = &tracer{enc: json.NewEncoder(c.TraceWriter)}
+		}
+	})
+	return c.tracer
+}
+
+// StartSpan - Begins timing the stage of the pipeline for the name. Nil is
+// returned when tracing is not enabled, and the Span methods accept it
+func (c *AmassConfig) StartSpan(stage string, req *AmassRequest) *Span {
+	if c == nil || req == nil {
+		return nil
+	}
+
+	t := c.getTracer()
+	if t == nil {
+		return nil
+	}
+
+	s := &Span{
+		tracer:  t,
+		traceID: traceID(req.Name),
+		spanID:  spanID(),
+		stage:   stage,
+		start:   time.Now(),
+		attrs: map[string]string{
+			"amass.name":   req.Name,
+			"amass.domain": req.Domain,
+		},
+	}
+	if req.Tag != "" {
+		s.attrs["amass.tag"] = req.Tag
+	}
+	if req.Source != "" {
+		s.attrs["amass.source"] = req.Source
+	}
+	return s
+}
+
+// SetAttribute - Records a detail about the work performed during the stage
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.attrs["amass."+key] = value
+}
+
+// End - Completes the span and writes it out
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	span := otlpSpan{
+		TraceID: s.traceID,
+		SpanID:  s.spanID,
+		Name:    s.stage,
+		Kind:    otlpSpanKindInternal,
+		Start:   strconv.FormatInt(s.start.UnixNano(), 10),
+		End:     strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	for k, v := range s.attrs {
+		span.Attributes = append(span.Attributes, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+	}
+
+	s.tracer.write(span)
+}
+
+func (t *tracer) write(span otlpSpan) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.enc.Encode(otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: "amass"}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/OWASP/Amass"},
+				Spans: []otlpSpan{span},
+			}},
+		}},
+	})
+}
+
+// traceID - The trace is derived from the name, so each stage joins the same trace
+// without the identifier being carried between the services
+func traceID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+func spanID() string {
+	id := make([]byte, 8)
+
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestTraceSpans(t *testing.T) {
+	// The spans are ignored when tracing is not enabled
+	var none *Span
+	none.SetAttribute("duplicate", "true")
+	none.End()
+	if s := new(AmassConfig).StartSpan(SpanEmit, &AmassRequest{Name: "www.owasp.org"}); s != nil {
+		t.Errorf("A span was started without a trace writer")
+	}
+
+	var buf bytes.Buffer
+	config := &AmassConfig{TraceWriter: &buf}
+	req := &AmassRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: CERT, Source: "Censys"}
+	for _, stage := range []string{SpanEmit, SpanDedupe, SpanResolve} {
+		span := config.StartSpan(stage, req)
+		span.SetAttribute("answers", "2")
+		span.End()
+	}
+
+	var traceID string
+	dec := json.NewDecoder(&buf)
+	for _, stage := range []string{SpanEmit, SpanDedupe, SpanResolve} {
+		var doc otlpTraces
+
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatalf("Failed to decode the %s span: %v", stage, err)
+		}
+		span := doc.ResourceSpans[0].ScopeSpans[0].Spans[0]
+		if span.Name != stage {
+			t.Errorf("The span was named %s instead of %s", span.Name, stage)
+		}
+		if traceID == "" {
+			traceID = span.TraceID
+		} else if span.TraceID != traceID {
+			t.Errorf("The %s span was not part of the name's trace", stage)
+		}
+
+		attrs := make(map[string]string)
+		for _, a := range span.Attributes {
+			attrs[a.Key] = a.Value.StringValue
+		}
+		if attrs["amass.source"] != "Censys" || attrs["amass.answers"] != "2" {
+			t.Errorf("The %s span had the attributes %v", stage, attrs)
+		}
+	}
+	if len(traceID) != 32 {
+		t.Errorf("The trace ID %q is not 16 bytes", traceID)
+	}
+}
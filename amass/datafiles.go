@@ -0,0 +1,392 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/sources"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+const (
+	// DefaultDataURL - The release endpoint providing the index of the current data files
+	DefaultDataURL = "https://raw.githubusercontent.com/OWASP/Amass/master/data/"
+
+	// The name of the index listing the data files and their checksums at the endpoint
+	dataIndexName = "index.json"
+
+	// The data file replacing the wordlist downloaded for brute forcing
+	wordlistDataFile = "namelist.txt"
+
+	// The data file adding organizations to those recognized as content delivery networks
+	cdnDataFile = "cdn_organizations.txt"
+
+	// The data file listing the address ranges published by the CDN and cloud providers
+	cloudRangesDataFile = "cloud_ranges.txt"
+
+	// The data file adding the version string patterns used to fingerprint name servers
+	fingerprintDataFile = "fingerprints.txt"
+
+	// The data file replacing the rules used by the scrapers to find the names in result pages
+	scraperRulesDataFile = "scraper_rules.json"
+)
+
+// DataFile - A file listed in the index of the release endpoint
+type DataFile struct {
+	Name string `json:"name"`
+
+	// The location of the file relative to the endpoint, when it is not published under the name
+	Path string `json:"path,omitempty"`
+
+	SHA256 string `json:"sha256"`
+}
+
+// DataUpdate - The outcome of checking one of the data files
+type DataUpdate struct {
+	Name string
+
+	// Was a new version of the file downloaded?
+	Updated bool
+
+	// The reason that the file could not be updated
+	Err error
+}
+
+// scraperRule - An entry of the scraper rules data file
+type scraperRule struct {
+	// The name of the data source using the rule
+	Source string `json:"source"`
+
+	// The regular expression selecting the parts of the pages holding the names
+	Scope string `json:"scope"`
+
+	// Either "strict" or "loose"
+	Mode string `json:"mode"`
+}
+
+// cloudRange - An address range published by a CDN or cloud provider
+type cloudRange struct {
+	cidr     *net.IPNet
+	provider string
+}
+
+var (
+	// The directory of the data files selected by the user, instead of DataDir
+	dataDirOverride string
+	dataDirLock     sync.Mutex
+
+	// The CDN organizations including those read from the data file, loaded once
+	cdnAllOrgs  []string
+	cdnDataOnce sync.Once
+
+	// The CDN and cloud ranges read from the data file, loaded once
+	cloudAllRanges []*cloudRange
+	cloudDataOnce  sync.Once
+
+	// The version patterns of the fingerprint data file are added once
+	fingerprintDataOnce sync.Once
+
+	// The scraper rules read from the data file, loaded once
+	scraperAllRules map[string]extract.Rule
+	scraperDataOnce sync.Once
+)
+
+// SetDataDir - Reads the data files from the directory, such as the one provided to the
+// update subcommand, rather than the default location. It must be called before the data
+// files are first read
+func SetDataDir(dir string) {
+	dataDirLock.Lock()
+	defer dataDirLock.Unlock()
+
+	dataDirOverride = dir
+}
+
+// DataDir - Returns the directory where the updated data files are kept
+func DataDir() (string, error) {
+	dataDirLock.Lock()
+	dir := dataDirOverride
+	dataDirLock.Unlock()
+
+	if dir != "" {
+		return dir, nil
+	}
+
+	dir, err := OutputDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "data"), nil
+}
+
+// UpdateDataFiles - Obtains the index at the URL and downloads the files that differ from
+// the copies in the directory. Each file is verified against the SHA-256 checksum in the index
+// before the local copy is replaced, so a failed download leaves the previous file in place.
+// The index is not signed and is only as trustworthy as the endpoint serving it over HTTPS
+func UpdateDataFiles(baseURL, dir string) ([]*DataUpdate, error) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	page, err := utils.GetWebPage(baseURL+dataIndexName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Update error: Failed to obtain the index of the data files: %v", err)
+	}
+
+	var index []DataFile
+	if err := json.Unmarshal([]byte(page), &index); err != nil {
+		return nil, fmt.Errorf("Update error: Failed to parse the index of the data files: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("Update error: Failed to create the data directory: %v", err)
+	}
+
+	var updates []*DataUpdate
+	for _, f := range index {
+		u := &DataUpdate{Name: f.Name}
+
+		u.Updated, u.Err = updateDataFile(baseURL, dir, f)
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+func updateDataFile(baseURL, dir string, f DataFile) (bool, error) {
+	if f.Name == "" || f.Name != filepath.Base(f.Name) || strings.ContainsAny(f.Name, `/\`) || f.Name == ".." {
+		return false, fmt.Errorf("Update error: The index listed the invalid file name %q", f.Name)
+	}
+	want := strings.ToLower(strings.TrimSpace(f.SHA256))
+	if len(want) != sha256.Size*2 {
+		return false, fmt.Errorf("Update error: The index listed no valid checksum for %s", f.Name)
+	}
+
+	path := filepath.Join(dir, f.Name)
+	if data, err := ioutil.ReadFile(path); err == nil && checksum(data) == want {
+		return false, nil
+	}
+
+	loc, err := dataFileURL(baseURL, f)
+	if err != nil {
+		return false, err
+	}
+	page, err := utils.GetWebPage(loc, nil)
+	if err != nil {
+		return false, fmt.Errorf("Update error: Failed to download %s: %v", f.Name, err)
+	}
+	data := []byte(page)
+	if got := checksum(data); got != want {
+		return false, fmt.Errorf("Update error: The checksum of %s was %s instead of %s", f.Name, got, want)
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+f.Name+"-")
+	if err != nil {
+		return false, fmt.Errorf("Update error: Failed to write %s: %v", f.Name, err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return false, fmt.Errorf("Update error: Failed to write %s: %v", f.Name, err)
+	}
+	return true, nil
+}
+
+// dataFileURL - Returns the URL of the file listed in the index, which must be on the
+// same host as the endpoint
+func dataFileURL(baseURL string, f DataFile) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("Update error: The endpoint URL %q is invalid: %v", baseURL, err)
+	}
+
+	path := f.Path
+	if path == "" {
+		path = f.Name
+	}
+	ref, err := url.Parse(path)
+	if err != nil || ref.IsAbs() || ref.Host != "" {
+		return "", fmt.Errorf("Update error: The index listed the invalid path %q for %s", f.Path, f.Name)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readDataFile - Returns the content of the updated data file, or nil when it was never downloaded
+func readDataFile(name string) []byte {
+	dir, err := DataDir()
+	if err != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// dataFileLines - Returns the lines of the updated data file, other than blank lines and comments
+func dataFileLines(name string) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(readDataFile(name)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cdnOrgs - Returns the CDN organizations built into the package and those in the data file
+func cdnOrgs() []string {
+	cdnDataOnce.Do(func() {
+		cdnAllOrgs = append(cdnAllOrgs, cdnOrganizations...)
+
+		for _, org := range dataFileLines(cdnDataFile) {
+			cdnAllOrgs = append(cdnAllOrgs, strings.ToLower(org))
+		}
+	})
+	return cdnAllOrgs
+}
+
+// cloudRangeProvider - Returns the CDN or cloud provider that published a range holding
+// the address, or an empty string when the address is not within any of the ranges
+func cloudRangeProvider(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+
+	for _, r := range cloudRanges() {
+		if r.cidr.Contains(ip) {
+			return r.provider
+		}
+	}
+	return ""
+}
+
+// cloudRanges - Returns the ranges in the data file, where each line holds a CIDR and the
+// provider, or the ranges built into the package when the data file was never downloaded
+func cloudRanges() []*cloudRange {
+	cloudDataOnce.Do(func() {
+		lines := dataFileLines(cloudRangesDataFile)
+		if len(lines) == 0 {
+			lines = cdnRanges
+		}
+
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			if _, cidr, err := net.ParseCIDR(fields[0]); err == nil {
+				cloudAllRanges = append(cloudAllRanges, &cloudRange{
+					cidr:     cidr,
+					provider: strings.ToLower(strings.Join(fields[1:], " ")),
+				})
+			}
+		}
+	})
+	return cloudAllRanges
+}
+
+// loadFingerprints - Adds the version patterns of the data file, where each line holds a
+// regular expression followed by the name of the software, to the name server fingerprinting
+func loadFingerprints() {
+	fingerprintDataOnce.Do(func() {
+		for _, line := range dataFileLines(fingerprintDataFile) {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			dnssrv.AddSoftwarePattern(fields[0], strings.Join(fields[1:], " "))
+		}
+	})
+}
+
+// scraperRules - Returns the rules of the data file by the name of the data source using them
+func scraperRules() map[string]extract.Rule {
+	scraperDataOnce.Do(func() {
+		scraperAllRules = make(map[string]extract.Rule)
+
+		data := readDataFile(scraperRulesDataFile)
+		if data == nil {
+			return
+		}
+
+		var entries []scraperRule
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if rule, err := entry.rule(); err == nil {
+				scraperAllRules[entry.Source] = rule
+			}
+		}
+	})
+	return scraperAllRules
+}
+
+func (e *scraperRule) rule() (extract.Rule, error) {
+	rule := extract.Rule{Mode: extract.Strict}
+
+	switch strings.ToLower(e.Mode) {
+	case "", "strict":
+	case "loose":
+		rule.Mode = extract.Loose
+	default:
+		return rule, fmt.Errorf("The scraper rule for %s has the unknown mode %q", e.Source, e.Mode)
+	}
+	if e.Scope != "" {
+		re, err := regexp.Compile(e.Scope)
+		if err != nil {
+			return rule, fmt.Errorf("The scraper rule for %s is invalid: %v", e.Source, err)
+		}
+		rule.Scope = re
+	}
+	return rule, nil
+}
+
+// applyScraperRules - Replaces the rules of the data sources named in the scraper rules data file
+func applyScraperRules(srcs []sources.DataSource) {
+	rules := scraperRules()
+
+	for _, source := range srcs {
+		rs, ok := source.(sources.RuleSource)
+		if !ok {
+			continue
+		}
+		if rule, found := rules[source.String()]; found {
+			rs.SetRule(rule)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateDataFiles(t *testing.T) {
+	files := map[string]string{
+		"namelist.txt":          "www\nmail\n",
+		"cdn_organizations.txt": "fastly\n",
+		"tampered.txt":          "modified in transit\n",
+		"published.txt":         "www\n",
+	}
+	index := []DataFile{
+		{Name: "namelist.txt", SHA256: checksum([]byte(files["namelist.txt"]))},
+		{Name: "cdn_organizations.txt", SHA256: checksum([]byte(files["cdn_organizations.txt"]))},
+		{Name: "tampered.txt", SHA256: checksum([]byte("the published content\n"))},
+		{Name: "wordlist.txt", Path: "../wordlists/published.txt", SHA256: checksum([]byte(files["published.txt"]))},
+		{Name: "elsewhere.txt", Path: "https://example.com/elsewhere.txt", SHA256: checksum([]byte("elsewhere"))},
+		{Name: "../escape.txt", SHA256: checksum([]byte("escape"))},
+		{Name: "nochecksum.txt"},
+	}
+	data, _ := json.Marshal(index)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(r.URL.Path)
+		switch name {
+		case dataIndexName:
+			w.Write(data)
+			return
+		case "published.txt":
+			if r.URL.Path != "/wordlists/published.txt" {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		if content, found := files[name]; found {
+			w.Write([]byte(content))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "amass-data")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The unchanged file must not be downloaded again
+	ioutil.WriteFile(filepath.Join(dir, "cdn_organizations.txt"), []byte(files["cdn_organizations.txt"]), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "tampered.txt"), []byte("the previous content\n"), 0644)
+
+	updates, err := UpdateDataFiles(srv.URL+"/data/", dir)
+	if err != nil {
+		t.Fatalf("UpdateDataFiles failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		updated bool
+		failed  bool
+	}{
+		{"namelist.txt", true, false},
+		{"cdn_organizations.txt", false, false},
+		{"tampered.txt", false, true},
+		{"wordlist.txt", true, false},
+		{"elsewhere.txt", false, true},
+		{"../escape.txt", false, true},
+		{"nochecksum.txt", false, true},
+	}
+	if len(updates) != len(tests) {
+		t.Fatalf("%d updates were returned instead of %d", len(updates), len(tests))
+	}
+	for i, test := range tests {
+		u := updates[i]
+
+		if u.Name != test.name || u.Updated != test.updated || (u.Err != nil) != test.failed {
+			t.Errorf("The update of %s returned %+v", test.name, u)
+		}
+	}
+
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "namelist.txt")); string(data) != files["namelist.txt"] {
+		t.Errorf("The downloaded namelist.txt contained %q", data)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "tampered.txt")); string(data) != "the previous content\n" {
+		t.Errorf("The file that failed verification replaced the previous copy: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.txt")); err == nil {
+		t.Errorf("The file was written outside of the data directory")
+	}
+}
+
+func TestPublishedDataFiles(t *testing.T) {
+	dir := filepath.Join("..", "data")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, dataIndexName))
+	if err != nil {
+		t.Fatalf("Failed to read the published index: %v", err)
+	}
+	var index []DataFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("Failed to parse the published index: %v", err)
+	}
+	for _, f := range index {
+		path := f.Path
+		if path == "" {
+			path = f.Name
+		}
+		if content, err := ioutil.ReadFile(filepath.Join(dir, path)); err != nil || checksum(content) != f.SHA256 {
+			t.Errorf("The published %s does not match the checksum in the index", f.Name)
+		}
+	}
+
+	var rules []scraperRule
+	content, _ := ioutil.ReadFile(filepath.Join(dir, scraperRulesDataFile))
+	if err := json.Unmarshal(content, &rules); err != nil {
+		t.Fatalf("Failed to parse the published scraper rules: %v", err)
+	}
+	for _, r := range rules {
+		if _, err := r.rule(); err != nil {
+			t.Errorf("The published scraper rule was rejected: %v", err)
+		}
+	}
+}
+
+func TestSetDataDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-data")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, cloudRangesDataFile), []byte("# comment\n192.0.2.0/24 example cdn\n"), 0644)
+
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	if lines := dataFileLines(cloudRangesDataFile); len(lines) != 1 || lines[0] != "192.0.2.0/24 example cdn" {
+		t.Errorf("The data file in the directory provided was read as %v", lines)
+	}
+}
@@ -4,14 +4,18 @@
 package amass
 
 import (
+	"crypto/tls"
 	"net"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
 	"github.com/OWASP/Amass/amass/handlers"
+	"github.com/OWASP/Amass/amass/utils"
 	evbus "github.com/asaskevich/EventBus"
 	"github.com/miekg/dns"
 )
@@ -27,12 +31,43 @@ type DataManagerService struct {
 	Graph    *handlers.Graph
 	Handlers []handlers.DataHandler
 	domains  map[string]struct{}
+
+	// Addresses that have already had the PTR record checked
+	reversed map[string]struct{}
+
+	// Names that have already had the web server response headers checked
+	probed map[string]struct{}
+
+	// The autonomous systems already announced to the other services
+	asns map[int]struct{}
+
+	// The fingerprints of the name servers for each root domain
+	nameServers     map[string][]AmassNameServerInfo
+	nameServersLock sync.Mutex
+
+	// The certificates presented on each address
+	certs     map[string][]AmassCertInfo
+	certsLock sync.Mutex
+
+	// The enrichment and active probes waiting for their pipeline stages to be opened
+	held     map[string][]func()
+	heldLock sync.Mutex
+
+	// The handler writes of other goroutines, performed on the goroutine of the requests
+	writes     []func()
+	writesLock sync.Mutex
 }
 
 func NewDataManagerService(config *core.AmassConfig, bus evbus.Bus) *DataManagerService {
 	dms := &DataManagerService{
-		bus:     bus,
-		domains: make(map[string]struct{}),
+		bus:         bus,
+		domains:     make(map[string]struct{}),
+		reversed:    make(map[string]struct{}),
+		probed:      make(map[string]struct{}),
+		asns:        make(map[int]struct{}),
+		nameServers: make(map[string][]AmassNameServerInfo),
+		certs:       make(map[string][]AmassCertInfo),
+		held:        make(map[string][]func()),
 	}
 
 	dms.BaseAmassService = *core.NewBaseAmassService("Data Manager Service", config, dms)
@@ -43,6 +78,8 @@ func (dms *DataManagerService) OnStart() error {
 	dms.BaseAmassService.OnStart()
 
 	dms.bus.SubscribeAsync(core.RESOLVED, dms.SendRequest, false)
+	dms.bus.SubscribeAsync(core.CERTIFICATE, dms.namesFromCertificate, false)
+	dms.bus.SubscribeAsync(core.HISTORY, dms.insertHistory, false)
 
 	dms.Graph = handlers.NewGraph()
 	dms.Handlers = append(dms.Handlers, dms.Graph)
@@ -54,6 +91,32 @@ func (dms *DataManagerService) OnStart() error {
 	return nil
 }
 
+// List - Returns the third parties that learn of the resolved addresses
+func (dms *DataManagerService) List() string {
+	config := dms.Config()
+
+	lines := []string{
+		"Sends the root domain names in SOA, CAA and DNSKEY queries to the resolvers",
+		"Sends the resolved addresses to origin.asn.cymru.com and the ASNs to asn.cymru.com in TXT queries to the resolvers",
+		"Sends the ASNs to the asn.shadowserver.org whois server",
+	}
+	if config.PTRValidation {
+		lines = append(lines, "Sends the resolved addresses in PTR queries to the resolvers")
+	}
+	if config.Active {
+		var ports []string
+		for _, port := range config.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+
+		lines = append(lines,
+			"Connects to the addresses of the in-scope names on ports "+strings.Join(ports, ", ")+
+				" to pull certificates and web server headers",
+			"Sends version queries to the authoritative servers of the root domains")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (dms *DataManagerService) OnPause() error {
 	return nil
 }
@@ -66,11 +129,18 @@ func (dms *DataManagerService) OnStop() error {
 	dms.BaseAmassService.OnStop()
 
 	dms.bus.Unsubscribe(core.RESOLVED, dms.SendRequest)
+	dms.bus.Unsubscribe(core.CERTIFICATE, dms.namesFromCertificate)
+	dms.bus.Unsubscribe(core.HISTORY, dms.insertHistory)
+	// Names inserted since the last check are sent before the enumeration closes its output
+	dms.StartWork()
+	dms.sendOutput(dms.discoverOutput())
 	return nil
 }
 
 func (dms *DataManagerService) processRequests() {
-	t := time.NewTicker(dms.Config().Frequency)
+	t := time.NewTicker(dms.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
 loop:
 	for {
 		select {
@@ -78,8 +148,15 @@ loop:
 			dms.manageData()
 		case <-dms.PauseChan():
 			t.Stop()
+			paused = true
 		case <-dms.ResumeChan():
-			t = time.NewTicker(dms.Config().Frequency)
+			t = time.NewTicker(dms.Config().Rates().Frequency)
+			paused = false
+		case <-dms.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(dms.Config().Rates().Frequency)
+			}
 		case <-dms.Quit():
 			break loop
 		}
@@ -93,29 +170,45 @@ loop:
 	for {
 		select {
 		case <-t.C:
-			dms.discoverOutput()
+			if output := dms.discoverOutput(); len(output) > 0 {
+				dms.StartWork()
+				go dms.sendOutput(output)
+			}
 		case <-dms.PauseChan():
 			t.Stop()
 		case <-dms.ResumeChan():
-			t = time.NewTicker(dms.Config().Frequency)
+			t = time.NewTicker(dms.Config().Rates().Frequency)
 		case <-dms.Quit():
 			break loop
 		}
 	}
 	t.Stop()
-	dms.discoverOutput()
 }
 
 func (dms *DataManagerService) manageData() {
+	if write := dms.nextWrite(); write != nil {
+		defer dms.FinishWork()
+		write()
+		return
+	}
+
 	req := dms.NextRequest()
 	if req == nil {
+		// The held work runs on this goroutine, like the requests it was taken from
+		if work := dms.nextHeld(); work != nil {
+			defer dms.FinishWork()
+			work()
+		}
 		return
 	}
+	defer dms.FinishWork()
 
-	dms.SetActive()
 	req.Name = strings.ToLower(req.Name)
 	req.Domain = strings.ToLower(req.Domain)
 
+	span := dms.Config().StartSpan(core.SpanEnrich, req)
+	defer span.End()
+
 	dms.insertDomain(req.Domain)
 	for i, r := range req.Records {
 		r.Name = strings.ToLower(r.Name)
@@ -140,6 +233,23 @@ func (dms *DataManagerService) manageData() {
 			dms.insertTXT(req, i)
 		}
 	}
+	dms.insertRecords(req)
+	// Names without addresses never appear in the output built from the graph
+	if req.NoData {
+		dms.StartWork()
+		go dms.sendOutput([]*AmassOutput{noDataOutput(req)})
+		return
+	}
+
+	if req.DNSSEC != "" {
+		dms.Graph.SetSubdomainProperty(req.Name, "dnssec", req.DNSSEC)
+	}
+	if req.Divergent {
+		dms.Graph.SetSubdomainProperty(req.Name, "divergent", "yes")
+	}
+	if req.GeoDNS {
+		dms.Graph.SetSubdomainProperty(req.Name, "geodns", "yes")
+	}
 }
 
 func (dms *DataManagerService) insertDomain(domain string) {
@@ -153,28 +263,101 @@ func (dms *DataManagerService) insertDomain(domain string) {
 	dms.domains[domain] = struct{}{}
 
 	for _, handler := range dms.Handlers {
-		handler.InsertDomain(domain, "dns", "Forward DNS")
+		handler.InsertDomain(domain, core.DNS, "Forward DNS")
 	}
 
 	dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 		Name:   domain,
 		Domain: domain,
-		Tag:    "dns",
+		Tag:    core.DNS,
 		Source: "Forward DNS",
 	})
+	dms.StartWork()
+	go dms.collectDomainInfo(domain)
+
+	dms.atStage(core.StageEnrich, func() {
+		addrs, err := LookupIPHistory(domain)
+		if err != nil {
+			dms.Config().Log.Printf("LookupIPHistory error: %v", err)
+			return
+		}
+
+		for _, addr := range addrs {
+			if asn, cidr, _, err := IPRequest(addr); err == nil {
+				dms.AttemptSweep(domain, addr, asn, cidr)
+			} else {
+				dms.Config().Log.Printf("%v", err)
+			}
+		}
+	})
+}
 
-	addrs, err := LookupIPHistory(domain)
+// collectDomainInfo - Saves the SOA, CAA and DNSKEY records published by the domain
+func (dms *DataManagerService) collectDomainInfo(domain string) {
+	var answers []core.DNSAnswer
+	defer dms.FinishWork()
+
+	for _, t := range []string{"SOA", "CAA", "DNSKEY"} {
+		if ans, err := dnssrv.Resolve(domain, t); err == nil {
+			answers = append(answers, ans...)
+		} else {
+			dms.Config().Log.Printf("DNS %s record query error: %s: %v", t, domain, err)
+		}
+	}
+
+	dms.queueWrite(func() {
+		dms.insertRecords(&core.AmassRequest{
+			Name:    domain,
+			Domain:  domain,
+			Records: answers,
+			Tag:     core.DNS,
+			Source:  "Forward DNS",
+		})
+	})
+
+	if dms.Config().Active {
+		dms.atStage(core.StageActive, func() {
+			dms.fingerprintNameServers(domain)
+		})
+	}
+}
+
+// fingerprintNameServers - Identifies the software running on the authoritative name servers
+func (dms *DataManagerService) fingerprintNameServers(domain string) {
+	var servers []AmassNameServerInfo
+	// The domain is reported once an entry is present, even when no servers were identified
+	defer func() {
+		dms.nameServersLock.Lock()
+		dms.nameServers[domain] = servers
+		dms.nameServersLock.Unlock()
+	}()
+
+	answers, err := dnssrv.Resolve(domain, "NS")
 	if err != nil {
-		dms.Config().Log.Printf("LookupIPHistory error: %v", err)
+		dms.Config().Log.Printf("DNS NS record query error: %s: %v", domain, err)
 		return
 	}
 
-	for _, addr := range addrs {
-		if _, cidr, _, err := IPRequest(addr); err == nil {
-			dms.AttemptSweep(domain, addr, cidr)
-		} else {
-			dms.Config().Log.Printf("%v", err)
+	for _, a := range answers {
+		pieces := strings.Split(a.Data, ",")
+		ns := removeLastDot(strings.TrimSpace(pieces[len(pieces)-1]))
+		if ns == "" {
+			continue
+		}
+
+		addrs, err := dnssrv.Resolve(ns, "A")
+		if err != nil || len(addrs) == 0 {
+			continue
 		}
+
+		loadFingerprints()
+		fp := dnssrv.FingerprintServer(addrs[0].Data)
+		servers = append(servers, AmassNameServerInfo{
+			Name:     ns,
+			Address:  fp.Address,
+			Software: fp.Software,
+			Version:  fp.Version,
+		})
 	}
 }
 
@@ -193,7 +376,7 @@ func (dms *DataManagerService) insertCNAME(req *core.AmassRequest, recidx int) {
 	dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 		Name:   target,
 		Domain: domain,
-		Tag:    "dns",
+		Tag:    core.DNS,
 		Source: "Forward DNS",
 	})
 }
@@ -207,18 +390,19 @@ func (dms *DataManagerService) insertA(req *core.AmassRequest, recidx int) {
 	for _, handler := range dms.Handlers {
 		handler.InsertA(req.Name, req.Domain, addr, req.Tag, req.Source)
 	}
-
-	dms.insertInfrastructure(addr)
-	// Check if active certificate access should be used on this address
-	if dms.Config().Active && dms.Config().IsDomainInScope(req.Name) {
-		dms.obtainNamesFromCertificate(addr)
+	// Internal addresses are recorded, but not probed or swept
+	if dms.insertReserved(addr) {
+		return
 	}
 
-	if _, cidr, _, err := IPRequest(addr); err == nil {
-		dms.AttemptSweep(req.Domain, addr, cidr)
-	} else {
-		dms.Config().Log.Printf("%v", err)
-	}
+	dms.bus.Publish(core.ADDRESS, &core.AddrRequest{
+		Address: addr,
+		Name:    req.Name,
+		Domain:  req.Domain,
+		Tag:     req.Tag,
+		Source:  req.Source,
+	})
+	dms.enrichAddress(addr, req.Name, req.Domain)
 }
 
 func (dms *DataManagerService) insertAAAA(req *core.AmassRequest, recidx int) {
@@ -230,24 +414,166 @@ func (dms *DataManagerService) insertAAAA(req *core.AmassRequest, recidx int) {
 	for _, handler := range dms.Handlers {
 		handler.InsertAAAA(req.Name, req.Domain, addr, req.Tag, req.Source)
 	}
+	// Internal addresses are recorded, but not probed or swept
+	if dms.insertReserved(addr) {
+		return
+	}
 
-	dms.insertInfrastructure(addr)
+	dms.bus.Publish(core.ADDRESS, &core.AddrRequest{
+		Address: addr,
+		Name:    req.Name,
+		Domain:  req.Domain,
+		Tag:     req.Tag,
+		Source:  req.Source,
+	})
+	dms.enrichAddress(addr, req.Name, req.Domain)
+}
+
+// enrichAddress - Collects the infrastructure of the resolved address, and probes it when
+// active techniques are used, as the coordinator opens the enrich and active stages
+func (dms *DataManagerService) enrichAddress(addr, name, domain string) {
+	dms.atStage(core.StageEnrich, func() {
+		dms.insertInfrastructure(addr, domain)
+		if dms.Config().PTRValidation {
+			dms.checkReverse(addr)
+		}
+
+		if asn, cidr, _, err := IPRequest(addr); err == nil {
+			dms.AttemptSweep(domain, addr, asn, cidr)
+		} else {
+			dms.Config().Log.Printf("%v", err)
+		}
+	})
 	// Check if active certificate access should be used on this address
-	if dms.Config().Active && dms.Config().IsDomainInScope(req.Name) {
-		dms.obtainNamesFromCertificate(addr)
+	if dms.Config().Active && dms.Config().IsDomainInScope(name) {
+		dms.atStage(core.StageActive, func() {
+			if cdnExcluded(dms.Config(), addr) {
+				return
+			}
+
+			dms.obtainNamesFromCertificate(addr, name)
+			dms.obtainNamesFromHeaders(name, domain)
+		})
 	}
+}
 
-	if _, cidr, _, err := IPRequest(addr); err == nil {
-		dms.AttemptSweep(req.Domain, addr, cidr)
-	} else {
+// atStage - Performs the work now, or holds it until the coordinator opens the stage.
+// The work belonging to a stage that will not be run is dropped
+func (dms *DataManagerService) atStage(stage string, work func()) {
+	config := dms.Config()
+	if !config.StageEnabled(stage) {
+		return
+	}
+	if config.StageOpen(stage) {
+		work()
+		return
+	}
+
+	dms.heldLock.Lock()
+	dms.held[stage] = append(dms.held[stage], work)
+	dms.heldLock.Unlock()
+}
+
+// nextHeld - Returns the next held work of the opened stages. StartWork has been called
+// for the work, so the caller must call FinishWork once it is done
+func (dms *DataManagerService) nextHeld() func() {
+	dms.heldLock.Lock()
+	defer dms.heldLock.Unlock()
+
+	for _, stage := range core.Stages {
+		if work := dms.held[stage]; len(work) > 0 && dms.Config().StageOpen(stage) {
+			dms.held[stage] = work[1:]
+			dms.StartWork()
+			return work[0]
+		}
+	}
+	return nil
+}
+
+// queueWrite - Holds the handler writes of another goroutine until the requests goroutine performs
+// them, since the handlers are not safe for concurrent use
+func (dms *DataManagerService) queueWrite(write func()) {
+	dms.StartWork()
+
+	dms.writesLock.Lock()
+	dms.writes = append(dms.writes, write)
+	dms.writesLock.Unlock()
+}
+
+// nextWrite - Returns the next queued handler write. The caller must call FinishWork once it is done
+func (dms *DataManagerService) nextWrite() func() {
+	dms.writesLock.Lock()
+	defer dms.writesLock.Unlock()
+
+	if len(dms.writes) == 0 {
+		return nil
+	}
+
+	write := dms.writes[0]
+	dms.writes = dms.writes[1:]
+	return write
+}
+
+// IsActive - Returns true while requests or the held work of the opened stages remain
+func (dms *DataManagerService) IsActive() bool {
+	dms.heldLock.Lock()
+	var pending bool
+	for stage, work := range dms.held {
+		if len(work) > 0 && dms.Config().StageOpen(stage) {
+			pending = true
+			break
+		}
+	}
+	dms.heldLock.Unlock()
+
+	return pending || dms.BaseAmassService.IsActive()
+}
+
+// insertReserved - Saves the reserved network containing the address, and returns
+// false when the address can be reached from the Internet
+func (dms *DataManagerService) insertReserved(addr string) bool {
+	kind, cidr := utils.ReservedAddress(addr)
+	if kind == "" {
+		return false
+	}
+
+	for _, handler := range dms.Handlers {
+		handler.InsertInfrastructure(addr, 0, cidr, "Reserved Network")
+	}
+	return true
+}
+
+// checkReverse - Saves the name provided by the PTR record for the address
+func (dms *DataManagerService) checkReverse(addr string) {
+	if _, found := dms.reversed[addr]; found {
+		return
+	}
+	dms.reversed[addr] = struct{}{}
+
+	name, err := dnssrv.Reverse(addr)
+	if err != nil {
 		dms.Config().Log.Printf("%v", err)
+		return
+	}
+	dms.Graph.SetAddressProperty(addr, "ptr", strings.ToLower(name))
+}
+
+func (dms *DataManagerService) obtainNamesFromCertificate(addr, name string) {
+	for _, cert := range PullCertificates(addr, dms.Config().Ports) {
+		cert.Name = name
+		dms.bus.Publish(core.CERTIFICATE, cert)
 	}
 }
 
-func (dms *DataManagerService) obtainNamesFromCertificate(addr string) {
-	for _, r := range PullCertificateNames(addr, dms.Config().Ports) {
+// namesFromCertificate - Sends the in-scope names found within the certificate to be resolved
+func (dms *DataManagerService) namesFromCertificate(req *core.CertRequest) {
+	dms.checkCertificate(req)
+
+	for _, r := range reqFromNames(namesFromCert(req.Certificate)) {
 		for _, domain := range dms.Config().Domains() {
 			if r.Domain == domain {
+				r.Tag = req.Tag
+				r.Source = req.Source
 				dms.bus.Publish(core.DNSQUERY, r)
 				break
 			}
@@ -255,6 +581,151 @@ func (dms *DataManagerService) obtainNamesFromCertificate(addr string) {
 	}
 }
 
+// checkCertificate - Records the validity window of the certificate and the TLS configuration
+// of the server, and publishes the findings for the certificates presented for in-scope names
+func (dms *DataManagerService) checkCertificate(req *core.CertRequest) {
+	info := AmassCertInfo{
+		Port:       req.Port,
+		ServerName: req.ServerName,
+		Subject:    req.Certificate.Subject.CommonName,
+		NotBefore:  req.Certificate.NotBefore,
+		NotAfter:   req.Certificate.NotAfter,
+		SelfSigned: selfSigned(req.Certificate),
+	}
+	if req.Version != 0 {
+		info.Version = tls.VersionName(req.Version)
+	}
+	// Servers present a default certificate to clients that do not send the SNI value,
+	// so the certificate served for the name resolved to the address is pulled again
+	named := req
+	if req.ServerName == "" && req.Name != "" {
+		named = nil
+		if cert, _, err := pullCertificate(req.Address, req.Port, req.Name); err == nil {
+			c := *req
+			c.Certificate = cert
+			c.ServerName = req.Name
+			named = &c
+		}
+	}
+	if dms.Config().TLSChecks {
+		dms.checkTLS(req, named, &info)
+	}
+
+	dms.certsLock.Lock()
+	certs := dms.certs[req.Address]
+	var found bool
+	for i, c := range certs {
+		if c.Port == info.Port && c.ServerName == info.ServerName {
+			certs[i] = info
+			found = true
+			break
+		}
+	}
+	if !found {
+		dms.certs[req.Address] = append(certs, info)
+	}
+	dms.certsLock.Unlock()
+
+	if named != nil {
+		if f := expiryFinding(named, dms.Config().Domains(), time.Now()); f != nil {
+			dms.bus.Publish(core.FINDING, f)
+		}
+	}
+	if dms.Config().TLSChecks {
+		for _, f := range tlsFindings(req, &info, dms.Config().Domains()) {
+			dms.bus.Publish(core.FINDING, f)
+		}
+	}
+}
+
+// checkTLS - Probes the server for the legacy protocol versions, and checks the certificate
+// presented when the name resolved to the address is sent as the SNI value
+func (dms *DataManagerService) checkTLS(req, named *core.CertRequest, info *AmassCertInfo) {
+	name := req.ServerName
+	if name == "" {
+		name = req.Name
+	}
+
+	if v := legacyVersion(req.Address, req.Port, name); v != 0 {
+		info.Legacy = tls.VersionName(v)
+	}
+	// The SNI scan sends names that are not expected to be served by the address
+	if req.ServerName != "" || req.Name == "" || req.Certificate.VerifyHostname(req.Name) == nil {
+		return
+	}
+	if named != nil {
+		info.Mismatched = named.Certificate.VerifyHostname(req.Name) != nil
+	}
+}
+
+// insertHistory - Stores the previous record values returned by the data sources, which
+// can reveal the origin servers of names now behind a CDN
+func (dms *DataManagerService) insertHistory(req *core.HistoryRequest) {
+	name := strings.ToLower(req.Name)
+	value := strings.ToLower(removeLastDot(req.Value))
+	if name == "" || value == "" || !dms.Config().IsDomainInScope(name) {
+		return
+	}
+
+	// Only the values within the scope are stored as subdomains
+	rrtype := strings.ToUpper(req.Type)
+	var tdomain string
+	if rrtype != "A" && rrtype != "AAAA" && dms.Config().IsDomainInScope(value) {
+		tdomain = strings.ToLower(SubdomainToDomain(value))
+	}
+
+	dms.queueWrite(func() {
+		for _, handler := range dms.Handlers {
+			handler.InsertHistory(name, strings.ToLower(req.Domain), rrtype,
+				value, tdomain, req.FirstSeen, req.LastSeen, req.Tag, req.Source)
+		}
+	})
+}
+
+// obtainNamesFromHeaders - Mines the CSP, CORS and Location headers returned by the web server,
+// and publishes the analytics IDs embedded in the pages
+func (dms *DataManagerService) obtainNamesFromHeaders(name, domain string) {
+	if _, found := dms.probed[name]; found {
+		return
+	}
+	dms.probed[name] = struct{}{}
+
+	dms.StartWork()
+	go func() {
+		defer dms.FinishWork()
+
+		names, urls, ids := ProbeWebHeaders(name, dms.Config().Ports, dms.Config().Domains())
+		for _, u := range urls {
+			dms.bus.Publish(core.URL, &core.URLRequest{
+				URL:    u,
+				Name:   name,
+				Domain: domain,
+				Tag:    core.SCRAPE,
+				Source: "Active Headers",
+			})
+
+			for _, id := range ids[u] {
+				dms.bus.Publish(core.ANALYTICS, &core.AnalyticsRequest{
+					ID:     id,
+					URL:    u,
+					Name:   name,
+					Domain: domain,
+					Tag:    core.SCRAPE,
+					Source: "Active Headers",
+				})
+			}
+		}
+		for _, n := range names {
+			dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
+				Name:   n,
+				Domain: SubdomainToDomain(n),
+				Tag:    core.SCRAPE,
+				Source: "Active Headers",
+			})
+		}
+	}()
+}
+
 func (dms *DataManagerService) insertPTR(req *core.AmassRequest, recidx int) {
 	target := strings.ToLower(removeLastDot(req.Records[recidx].Data))
 	domain := strings.ToLower(SubdomainToDomain(target))
@@ -270,7 +741,7 @@ func (dms *DataManagerService) insertPTR(req *core.AmassRequest, recidx int) {
 	dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 		Name:   target,
 		Domain: domain,
-		Tag:    "dns",
+		Tag:    core.DNS,
 		Source: "Reverse DNS",
 	})
 }
@@ -304,7 +775,7 @@ func (dms *DataManagerService) insertNS(req *core.AmassRequest, recidx int) {
 		dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 			Name:   target,
 			Domain: domain,
-			Tag:    "dns",
+			Tag:    core.DNS,
 			Source: "Forward DNS",
 		})
 	}
@@ -326,7 +797,7 @@ func (dms *DataManagerService) insertMX(req *core.AmassRequest, recidx int) {
 		dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 			Name:   target,
 			Domain: domain,
-			Tag:    "dns",
+			Tag:    core.DNS,
 			Source: "Forward DNS",
 		})
 	}
@@ -345,13 +816,32 @@ func (dms *DataManagerService) insertTXT(req *core.AmassRequest, recidx int) {
 		dms.bus.Publish(core.DNSQUERY, &core.AmassRequest{
 			Name:   name,
 			Domain: req.Domain,
-			Tag:    "dns",
+			Tag:    core.DNS,
 			Source: "Forward DNS",
 		})
 	}
 }
 
-func (dms *DataManagerService) insertInfrastructure(addr string) {
+// insertRecords - Saves the complete set of answers observed for the name
+func (dms *DataManagerService) insertRecords(req *core.AmassRequest) {
+	for _, r := range req.Records {
+		data := removeLastDot(strings.TrimSpace(r.Data))
+		if uint16(r.Type) == dns.TypeNS {
+			pieces := strings.Split(data, ",")
+			data = pieces[len(pieces)-1]
+		}
+		if data == "" {
+			continue
+		}
+
+		rrtype := dns.TypeToString[uint16(r.Type)]
+		for _, handler := range dms.Handlers {
+			handler.InsertRecord(req.Name, req.Domain, rrtype, r.TTL, r.Priority, data)
+		}
+	}
+}
+
+func (dms *DataManagerService) insertInfrastructure(addr, domain string) {
 	asn, cidr, desc, err := IPRequest(addr)
 	if err != nil {
 		dms.Config().Log.Printf("%v", err)
@@ -361,30 +851,49 @@ func (dms *DataManagerService) insertInfrastructure(addr string) {
 	for _, handler := range dms.Handlers {
 		handler.InsertInfrastructure(addr, asn, cidr, desc)
 	}
+
+	if _, found := dms.asns[asn]; found {
+		return
+	}
+	dms.asns[asn] = struct{}{}
+
+	dms.bus.Publish(core.ASNUMBER, &core.ASNRequest{
+		ASN:         asn,
+		Prefix:      cidr.String(),
+		Description: desc,
+		Address:     addr,
+		Domain:      domain,
+	})
 }
 
 // AttemptSweep - Initiates a sweep of a subset of the addresses within the CIDR
-func (dms *DataManagerService) AttemptSweep(domain, addr string, cidr *net.IPNet) {
-	if !dms.Config().IsDomainInScope(domain) {
+func (dms *DataManagerService) AttemptSweep(domain, addr string, asn int, cidr *net.IPNet) {
+	if !dms.Config().IsDomainInScope(domain) || cdnExcluded(dms.Config(), addr) {
 		return
 	}
 
-	dms.bus.Publish(core.DNSSWEEP, domain, addr, cidr)
+	dms.bus.Publish(core.DNSSWEEP, &core.NetblockRequest{
+		CIDR:    cidr,
+		Address: addr,
+		ASN:     asn,
+		Domain:  domain,
+	})
 }
 
-func (dms *DataManagerService) discoverOutput() {
+func (dms *DataManagerService) discoverOutput() []*AmassOutput {
 	dms.Graph.Lock()
 	defer dms.Graph.Unlock()
 
+	var results []*AmassOutput
 	for key, domain := range dms.Graph.Domains {
 		output := dms.findSubdomainOutput(domain)
 
 		for _, o := range output {
 			o.Domain = key
 		}
-
-		go dms.sendOutput(output)
+		results = append(results, output...)
 	}
+	return results
 }
 
 func (dms *DataManagerService) findSubdomainOutput(domain *handlers.Node) []*AmassOutput {
@@ -438,6 +947,36 @@ func (dms *DataManagerService) buildSubdomainOutput(sub *handlers.Node) *AmassOu
 		Name:   sub.Properties["name"],
 		Tag:    sub.Properties["tag"],
 		Source: sub.Properties["source"],
+		DNSSEC: sub.Properties["dnssec"],
+	}
+
+	if _, ok := sub.Properties["divergent"]; ok {
+		output.Divergent = true
+	}
+	if _, ok := sub.Properties["geodns"]; ok {
+		output.GeoDNS = true
+	}
+
+	for _, r := range sub.Records {
+		output.Records = append(output.Records, AmassRecordInfo{
+			Type:     r.Type,
+			TTL:      r.TTL,
+			Priority: r.Priority,
+			Data:     r.Data,
+		})
+	}
+
+	if sub.Labels[0] == "Domain" {
+		output.DomainInfo = buildDomainInfo(sub)
+
+		dms.nameServersLock.Lock()
+		servers, found := dms.nameServers[output.Name]
+		dms.nameServersLock.Unlock()
+		// Wait for the name servers to be fingerprinted before sending the domain
+		if dms.Config().Active && !found {
+			return nil
+		}
+		output.DomainInfo.NameServers = servers
 	}
 
 	t := core.TypeNorm
@@ -474,6 +1013,10 @@ func (dms *DataManagerService) buildSubdomainOutput(sub *handlers.Node) *AmassOu
 
 	for _, addr := range addrs {
 		if i := dms.obtainInfrastructureData(addr); i != nil {
+			// Does the reverse name match the forward name?
+			if i.PTR != "" {
+				i.PTRMatch = i.PTR == output.Name || i.PTR == cname.Properties["name"]
+			}
 			output.Addresses = append(output.Addresses, *i)
 		}
 	}
@@ -486,6 +1029,49 @@ func (dms *DataManagerService) buildSubdomainOutput(sub *handlers.Node) *AmassOu
 	return output
 }
 
+// buildDomainInfo - Summarizes the SOA, CAA and DNSKEY records saved for the domain
+func buildDomainInfo(domain *handlers.Node) *AmassDomainInfo {
+	info := new(AmassDomainInfo)
+
+	for _, r := range domain.Records {
+		switch r.Type {
+		case "SOA":
+			fields := strings.Fields(r.Data)
+			if len(fields) < 3 {
+				continue
+			}
+
+			info.SOANameServer = removeLastDot(fields[0])
+			info.SOAContact = soaContact(fields[1])
+			if serial, err := strconv.ParseUint(fields[2], 10, 32); err == nil {
+				info.SOASerial = uint32(serial)
+			}
+		case "CAA":
+			info.CAA = append(info.CAA, r.Data)
+		case "DNSKEY":
+			info.DNSKEY = true
+		}
+	}
+	return info
+}
+
+// soaContact - Returns the email address of the SOA mailbox name, where the first label not
+// ending at an escaped dot is the user
+func soaContact(mbox string) string {
+	mbox = removeLastDot(mbox)
+
+	for i := 0; i < len(mbox); i++ {
+		switch mbox[i] {
+		case '\\':
+			i++
+		case '.':
+			user := strings.Replace(mbox[:i], "\\.", ".", -1)
+			return user + "@" + mbox[i+1:]
+		}
+	}
+	return strings.Replace(mbox, "\\.", ".", -1)
+}
+
 func (dms *DataManagerService) traverseCNAME(sub *handlers.Node) *handlers.Node {
 	cname := sub
 	for {
@@ -506,7 +1092,10 @@ func (dms *DataManagerService) traverseCNAME(sub *handlers.Node) *handlers.Node
 }
 
 func (dms *DataManagerService) obtainInfrastructureData(addr *handlers.Node) *AmassAddressInfo {
-	infr := &AmassAddressInfo{Address: net.ParseIP(addr.Properties["addr"])}
+	infr := &AmassAddressInfo{
+		Address: net.ParseIP(addr.Properties["addr"]),
+		PTR:     addr.Properties["ptr"],
+	}
 
 	var nb *handlers.Node
 	for _, idx := range addr.Edges {
@@ -518,7 +1107,11 @@ func (dms *DataManagerService) obtainInfrastructureData(addr *handlers.Node) *Am
 	}
 
 	if nb == nil {
-		return nil
+		if dms.Config().StageEnabled(core.StageEnrich) {
+			return nil
+		}
+		// Only the address is known when the enrich stage is not run
+		return dms.addCertificates(infr)
 	}
 	_, infr.Netblock, _ = net.ParseCIDR(nb.Properties["cidr"])
 
@@ -537,18 +1130,61 @@ func (dms *DataManagerService) obtainInfrastructureData(addr *handlers.Node) *Am
 
 	infr.ASN, _ = strconv.Atoi(as.Properties["asn"])
 	infr.Description = as.Properties["desc"]
+	infr.Reserved, _ = utils.ReservedAddress(addr.Properties["addr"])
+	return dms.addCertificates(infr)
+}
+
+// addCertificates - Includes the certificates presented on the address so far. The
+// certificates pulled after the name was sent are only shown by the findings
+func (dms *DataManagerService) addCertificates(infr *AmassAddressInfo) *AmassAddressInfo {
+	dms.certsLock.Lock()
+	infr.Certificates = append([]AmassCertInfo(nil), dms.certs[infr.Address.String()]...)
+	dms.certsLock.Unlock()
 	return infr
 }
 
+// sendOutput - Publishes the in-scope output. The caller must have called StartWork
 func (dms *DataManagerService) sendOutput(output []*AmassOutput) {
+	defer dms.FinishWork()
+
 	for _, o := range output {
-		dms.SetActive()
 		if dms.Config().IsDomainInScope(o.Name) {
 			dms.bus.Publish(core.OUTPUT, o)
 		}
 	}
 }
 
+// noDataOutput - Builds the output for a name that exists without addresses
+func noDataOutput(req *core.AmassRequest) *AmassOutput {
+	output := &AmassOutput{
+		Name:   req.Name,
+		Domain: req.Domain,
+		Tag:    req.Tag,
+		Source: req.Source,
+		Type:   core.TypeNorm,
+		NoData: true,
+	}
+
+	for _, r := range req.Records {
+		data := removeLastDot(strings.TrimSpace(r.Data))
+		if uint16(r.Type) == dns.TypeNS {
+			pieces := strings.Split(data, ",")
+			data = pieces[len(pieces)-1]
+		}
+		if data == "" {
+			continue
+		}
+
+		output.Records = append(output.Records, AmassRecordInfo{
+			Type:     dns.TypeToString[uint16(r.Type)],
+			TTL:      r.TTL,
+			Priority: r.Priority,
+			Data:     data,
+		})
+	}
+	return output
+}
+
 func removeLastDot(name string) string {
 	sz := len(name)
 
@@ -0,0 +1,60 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+func TestNoDataOutput(t *testing.T) {
+	req := &core.AmassRequest{
+		Name:   "mail.example.com",
+		Domain: "example.com",
+		Tag:    core.BRUTE,
+		Source: "Brute Force",
+		NoData: true,
+		Records: []core.DNSAnswer{
+			{Name: "mail.example.com", Type: int(dns.TypeMX), TTL: 300, Priority: 10, Data: "mx1.example.com."},
+			{Name: "mail.example.com", Type: int(dns.TypeNS), TTL: 300, Data: "mail.example.com,ns1.example.net."},
+			{Name: "mail.example.com", Type: int(dns.TypeTXT), Data: " "},
+		},
+	}
+
+	o := noDataOutput(req)
+	if !o.NoData || o.Name != req.Name || o.Tag != req.Tag || len(o.Addresses) != 0 {
+		t.Errorf("noDataOutput returned %+v", o)
+	}
+	if len(o.Records) != 2 {
+		t.Fatalf("noDataOutput returned %d records, expected 2", len(o.Records))
+	}
+	if r := o.Records[0]; r.Type != "MX" || r.Data != "mx1.example.com" || r.Priority != 10 {
+		t.Errorf("The MX record was returned as %+v", r)
+	}
+	if r := o.Records[1]; r.Type != "NS" || r.Data != "ns1.example.net" {
+		t.Errorf("The NS record was returned as %+v", r)
+	}
+	if c := o.Confidence(); c != 75 {
+		t.Errorf("The name with records had the confidence %d, expected 75", c)
+	}
+}
+
+func TestSOAContact(t *testing.T) {
+	tests := []struct {
+		mbox     string
+		expected string
+	}{
+		{"hostmaster.example.com.", "hostmaster@example.com"},
+		{"john\\.doe.example.com.", "john.doe@example.com"},
+		{"root", "root"},
+	}
+
+	for _, test := range tests {
+		if got := soaContact(test.mbox); got != test.expected {
+			t.Errorf("%s was returned for the mailbox %s instead of %s", got, test.mbox, test.expected)
+		}
+	}
+}
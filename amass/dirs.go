@@ -0,0 +1,106 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+)
+
+// The name of the directories created for the package beneath the user directories
+const dirName = "amass"
+
+// ConfigDir - Returns the directory where the configuration of the user is kept, which is
+// $XDG_CONFIG_HOME/amass (~/.config/amass) on Linux and the other Unix systems,
+// %AppData%\amass on Windows, and ~/Library/Application Support/amass on macOS
+func ConfigDir() (string, error) {
+	return configDir(runtime.GOOS, os.Getenv)
+}
+
+// OutputDir - Returns the default directory where the output files of the user are written,
+// which is $XDG_DATA_HOME/amass (~/.local/share/amass) on Linux and the other Unix systems,
+// %LocalAppData%\amass on Windows, and ~/Library/Application Support/amass on macOS
+func OutputDir() (string, error) {
+	return outputDir(runtime.GOOS, os.Getenv)
+}
+
+// ResolvePath - Returns the path of the file within the directory, unless the path is
+// already absolute or no directory was provided
+func ResolvePath(dir, name string) string {
+	if name == "" || dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+func configDir(goos string, getenv func(string) string) (string, error) {
+	switch goos {
+	case "windows":
+		if dir := getenv("AppData"); dir != "" {
+			return filepath.Join(dir, dirName), nil
+		}
+		return "", errors.New("The %AppData% environment variable is not set")
+	case "darwin":
+		return appSupportDir(getenv)
+	}
+
+	if dir := getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, dirName), nil
+	}
+	home, err := homeDir(getenv)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", dirName), nil
+}
+
+func outputDir(goos string, getenv func(string) string) (string, error) {
+	switch goos {
+	case "windows":
+		if dir := getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, dirName), nil
+		}
+		if dir := getenv("AppData"); dir != "" {
+			return filepath.Join(dir, dirName), nil
+		}
+		return "", errors.New("The %LocalAppData% environment variable is not set")
+	case "darwin":
+		return appSupportDir(getenv)
+	}
+
+	if dir := getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, dirName), nil
+	}
+	home, err := homeDir(getenv)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", dirName), nil
+}
+
+func appSupportDir(getenv func(string) string) (string, error) {
+	home, err := homeDir(getenv)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", dirName), nil
+}
+
+// homeDir - Returns the home directory from the environment, or the user database
+func homeDir(getenv func(string) string) (string, error) {
+	for _, env := range []string{"HOME", "USERPROFILE"} {
+		if dir := getenv(env); dir != "" {
+			return dir, nil
+		}
+	}
+
+	u, err := user.Current()
+	if err != nil || u.HomeDir == "" {
+		return "", errors.New("The home directory of the user could not be determined")
+	}
+	return u.HomeDir, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUserDirs(t *testing.T) {
+	env := map[string]string{
+		"HOME":            "/home/user",
+		"XDG_CONFIG_HOME": "",
+		"XDG_DATA_HOME":   "/data",
+		"AppData":         `C:\Users\user\AppData\Roaming`,
+		"LocalAppData":    `C:\Users\user\AppData\Local`,
+	}
+	getenv := func(key string) string { return env[key] }
+
+	tests := []struct {
+		goos   string
+		config string
+		output string
+	}{
+		{"linux", filepath.Join("/home/user", ".config", "amass"), filepath.Join("/data", "amass")},
+		{"freebsd", filepath.Join("/home/user", ".config", "amass"), filepath.Join("/data", "amass")},
+		{"windows", filepath.Join(env["AppData"], "amass"), filepath.Join(env["LocalAppData"], "amass")},
+		{"darwin", filepath.Join("/home/user", "Library", "Application Support", "amass"),
+			filepath.Join("/home/user", "Library", "Application Support", "amass")},
+	}
+
+	for _, test := range tests {
+		if dir, err := configDir(test.goos, getenv); err != nil || dir != test.config {
+			t.Errorf("The %s config directory was %q (%v), expected %q", test.goos, dir, err, test.config)
+		}
+		if dir, err := outputDir(test.goos, getenv); err != nil || dir != test.output {
+			t.Errorf("The %s output directory was %q (%v), expected %q", test.goos, dir, err, test.output)
+		}
+	}
+
+	// Relative XDG directories are ignored, as the specification requires
+	env["XDG_DATA_HOME"] = "data"
+	if dir, _ := outputDir("linux", getenv); dir != filepath.Join("/home/user", ".local", "share", "amass") {
+		t.Errorf("The relative XDG_DATA_HOME was used: %q", dir)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		dir, name, want string
+	}{
+		{"", "out.txt", "out.txt"},
+		{"/results", "", ""},
+		{"/results", "out.txt", filepath.Join("/results", "out.txt")},
+		{"/results", "/tmp/out.txt", "/tmp/out.txt"},
+	}
+
+	for _, test := range tests {
+		if got := ResolvePath(test.dir, test.name); got != test.want {
+			t.Errorf("ResolvePath(%q, %q) returned %q, expected %q", test.dir, test.name, got, test.want)
+		}
+	}
+}
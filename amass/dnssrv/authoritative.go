@@ -0,0 +1,126 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+// The default number of queries per second sent to the authoritative servers of a root domain
+const defaultAuthoritativeRate = 20
+
+// resolveAuthoritative - Sends the queries for the guessed name straight to the authoritative
+// servers of the root domain, which answer faster than the recursive resolvers and keep the
+// guesses out of the public caches. Names delegated to other servers are resolved as usual
+func (ds *DNSService) resolveAuthoritative(req *core.AmassRequest) ([]core.DNSAnswer, bool) {
+	servers := ds.authoritativeServers(req.Domain)
+	if len(servers) == 0 {
+		return resolveNameTypes(req.Name, ds.queryTypes(), ds.Config().Log)
+	}
+
+	zone := dns.Fqdn(strings.ToLower(req.Domain))
+	name := dns.Fqdn(strings.ToLower(req.Name))
+
+	var exists bool
+	var answers []core.DNSAnswer
+	for _, t := range ds.queryTypes() {
+		ds.waitAuthoritative(req.Domain)
+
+		r, err := queryServers(servers, name, t)
+		if err != nil {
+			ds.Config().Log.Print(err)
+			continue
+		}
+		// The other record types do not exist either
+		if r.Rcode == dns.RcodeNameError {
+			return nil, false
+		}
+		if child, _, _ := referral(r, zone, name); child != "" {
+			return resolveNameTypes(req.Name, ds.queryTypes(), ds.Config().Log)
+		}
+		if r.Rcode == dns.RcodeSuccess {
+			exists = true
+		}
+
+		for _, a := range ExtractAnswers(r, t) {
+			a.Name = utils.CopyString(req.Name)
+			a.Data = strings.TrimSpace(a.Data)
+			answers = append(answers, a)
+		}
+	}
+	return answers, exists
+}
+
+// authLookup - The lookup of the name servers for a root domain, which is closed once it completes
+type authLookup struct {
+	done    chan struct{}
+	servers []string
+}
+
+// authoritativeServers - Returns the addresses of the name servers for the root domain,
+// looking them up the first time the domain is queried
+func (ds *DNSService) authoritativeServers(domain string) []string {
+	ds.authLock.Lock()
+	if ds.authServers == nil {
+		ds.authServers = make(map[string]*authLookup)
+	}
+	if lookup, found := ds.authServers[domain]; found {
+		ds.authLock.Unlock()
+		// Wait for the lookup started by another guess for the domain
+		<-lookup.done
+		return lookup.servers
+	}
+	lookup := &authLookup{done: make(chan struct{})}
+	ds.authServers[domain] = lookup
+	ds.authLock.Unlock()
+
+	// The names are resolved without the lock, which is also taken before each query is sent
+	var servers []string
+	if ans, err := Resolve(domain, "NS"); err == nil {
+		for _, a := range ans {
+			parts := strings.Split(a.Data, ",")
+
+			addrs, err := Resolve(parts[len(parts)-1], "A")
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				servers = utils.UniqueAppend(servers, net.JoinHostPort(addr.Data, "53"))
+			}
+		}
+	}
+	lookup.servers = servers
+	close(lookup.done)
+	return servers
+}
+
+// waitAuthoritative - Blocks until the next query can be sent to the servers of the domain
+func (ds *DNSService) waitAuthoritative(domain string) {
+	rate := ds.Config().Rates().AuthoritativeRate
+	if rate <= 0 {
+		rate = defaultAuthoritativeRate
+	}
+	interval := time.Second / time.Duration(rate)
+
+	ds.authLock.Lock()
+	if ds.authNext == nil {
+		ds.authNext = make(map[string]time.Time)
+	}
+
+	now := time.Now()
+	next := ds.authNext[domain]
+	if next.Before(now) {
+		next = now
+	}
+	ds.authNext[domain] = next.Add(interval)
+	ds.authLock.Unlock()
+
+	time.Sleep(next.Sub(now))
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestAuthoritativeRate(t *testing.T) {
+	ds := &DNSService{}
+	ds.BaseAmassService = *core.NewBaseAmassService("DNS Service",
+		&core.AmassConfig{AuthoritativeRate: 100}, ds)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		ds.waitAuthoritative("example.com")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Four queries at 100 per second were sent within %v", elapsed)
+	}
+
+	// Each root domain is paced separately
+	start = time.Now()
+	ds.waitAuthoritative("example.org")
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("The first query for another domain waited %v", elapsed)
+	}
+}
+
+func TestAuthoritativeServersCached(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	ds := &DNSService{authServers: map[string]*authLookup{
+		"example.com": {done: done, servers: []string{"192.0.2.53:53"}},
+	}}
+
+	servers := ds.authoritativeServers("example.com")
+	if len(servers) != 1 || servers[0] != "192.0.2.53:53" {
+		t.Errorf("authoritativeServers returned %v instead of the cached servers", servers)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"net"
+	"strings"
+	"sync"
+)
+
+var (
+	// The local addresses that outbound connections are bound to, in turn
+	localAddrs []net.IP
+	localNext  int
+	localLock  sync.Mutex
+)
+
+// SetLocalAddresses - Binds the outbound DNS and HTTP connections to the local addresses,
+// rotating across them. No addresses leaves the choice to the operating system
+func SetLocalAddresses(addrs []net.IP) {
+	localLock.Lock()
+	defer localLock.Unlock()
+
+	localAddrs = addrs
+	localNext = 0
+}
+
+// newDialer - Returns a dialer bound to the next local address suitable for the remote host
+func newDialer(network, remote string) *net.Dialer {
+	return &net.Dialer{LocalAddr: nextLocalAddr(network, remote)}
+}
+
+// nextLocalAddr - Selects the next local address in the rotation. When the remote host is
+// an IP address, only the local addresses of the same family are considered
+func nextLocalAddr(network, remote string) net.Addr {
+	localLock.Lock()
+	defer localLock.Unlock()
+
+	if len(localAddrs) == 0 {
+		return nil
+	}
+
+	host := remote
+	if h, _, err := net.SplitHostPort(remote); err == nil {
+		host = h
+	}
+	rip := net.ParseIP(host)
+
+	for i := 0; i < len(localAddrs); i++ {
+		ip := localAddrs[(localNext+i)%len(localAddrs)]
+
+		if rip != nil && (rip.To4() == nil) != (ip.To4() == nil) {
+			continue
+		}
+		localNext = (localNext + i + 1) % len(localAddrs)
+
+		if strings.HasPrefix(network, "udp") {
+			return &net.UDPAddr{IP: ip}
+		}
+		return &net.TCPAddr{IP: ip}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBindRotation(t *testing.T) {
+	defer SetLocalAddresses(nil)
+
+	if addr := nextLocalAddr("udp", "8.8.8.8:53"); addr != nil {
+		t.Errorf("nextLocalAddr returned %v without any local addresses", addr)
+	}
+
+	SetLocalAddresses([]net.IP{
+		net.ParseIP("192.0.2.1"),
+		net.ParseIP("2001:db8::1"),
+		net.ParseIP("192.0.2.2"),
+	})
+
+	tests := []struct {
+		network  string
+		remote   string
+		expected string
+	}{
+		{"udp", "8.8.8.8:53", "192.0.2.1:0"},
+		{"udp", "8.8.8.8:53", "192.0.2.2:0"},
+		{"tcp", "[2001:4860:4860::8888]:53", "[2001:db8::1]:0"},
+		{"tcp", "www.example.com:443", "192.0.2.2:0"},
+		{"tcp", "www.example.com:443", "192.0.2.1:0"},
+	}
+
+	for _, test := range tests {
+		addr := nextLocalAddr(test.network, test.remote)
+		if addr == nil || addr.String() != test.expected || addr.Network() != test.network {
+			t.Errorf("nextLocalAddr(%s, %s) returned %v, expected %s",
+				test.network, test.remote, addr, test.expected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"strings"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// DefaultDecoyDomains - Popular domains unrelated to any target, used when no decoys are provided
+var DefaultDecoyDomains = []string{
+	"amazon.com",
+	"apple.com",
+	"bing.com",
+	"github.com",
+	"microsoft.com",
+	"mozilla.org",
+	"netflix.com",
+	"wikipedia.org",
+	"yahoo.com",
+}
+
+// decoyLabels - Common labels placed beneath the decoy domains, so the decoys look like
+// ordinary lookups and share nothing with the names being enumerated
+var decoyLabels = []string{
+	"api",
+	"blog",
+	"cdn",
+	"dev",
+	"docs",
+	"ftp",
+	"help",
+	"img",
+	"login",
+	"m",
+	"mail",
+	"news",
+	"shop",
+	"smtp",
+	"static",
+	"support",
+	"vpn",
+	"webmail",
+	"www",
+}
+
+// sendDecoys - Interleaves queries beneath unrelated domains, so the resolvers cannot
+// isolate the domains being enumerated from the shape of the traffic. The decoys are
+// sent by the caller in the background, since their answers are never used
+func (ds *DNSService) sendDecoys(name string) {
+	n := ds.numOfDecoys()
+	if n == 0 {
+		return
+	}
+
+	domains := ds.Config().DecoyDomains
+	if len(domains) == 0 {
+		domains = DefaultDecoyDomains
+	}
+	skip := strings.SplitN(name, ".", 2)[0]
+
+	for i := 0; i < n; i++ {
+		decoy := decoyName(decoyLabel(skip), domains[utils.RandomInt()%len(domains)])
+		// The answers are discarded, since the names are not within scope
+		Resolve(decoy, "A")
+	}
+}
+
+// decoyLabel - Returns a common label to query beneath a decoy domain, other than the
+// label of the name actually being resolved
+func decoyLabel(skip string) string {
+	for {
+		if label := decoyLabels[utils.RandomInt()%len(decoyLabels)]; label != skip {
+			return label
+		}
+	}
+}
+
+// numOfDecoys - Returns the decoy queries owed for one name, carrying the fractions
+// over so ratios below one are met across several names
+func (ds *DNSService) numOfDecoys() int {
+	ratio := ds.Config().DecoyRatio
+	if ratio <= 0 {
+		return 0
+	}
+
+	ds.decoyLock.Lock()
+	defer ds.decoyLock.Unlock()
+
+	ds.decoyCredit += ratio
+	n := int(ds.decoyCredit)
+	ds.decoyCredit -= float64(n)
+	return n
+}
+
+// decoyName - Returns the label placed beneath the decoy domain
+func decoyName(label, domain string) string {
+	return label + "." + strings.Trim(strings.ToLower(domain), ".")
+}
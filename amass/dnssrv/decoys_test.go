@@ -0,0 +1,53 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestDecoysRatio(t *testing.T) {
+	tests := []struct {
+		ratio    float64
+		names    int
+		expected int
+	}{
+		{0, 10, 0},
+		{0.25, 10, 2},
+		{0.5, 10, 5},
+		{1, 10, 10},
+		{2.5, 4, 10},
+	}
+
+	for _, test := range tests {
+		ds := &DNSService{}
+		ds.BaseAmassService = *core.NewBaseAmassService("DNS Service",
+			&core.AmassConfig{DecoyRatio: test.ratio}, ds)
+
+		var total int
+		for i := 0; i < test.names; i++ {
+			total += ds.numOfDecoys()
+		}
+		if total != test.expected {
+			t.Errorf("Ratio %v over %d names sent %d decoys, expected %d",
+				test.ratio, test.names, total, test.expected)
+		}
+	}
+}
+
+func TestDecoysName(t *testing.T) {
+	if name := decoyName("vpn", "Example.ORG."); name != "vpn.example.org" {
+		t.Errorf("decoyName returned %s, expected vpn.example.org", name)
+	}
+}
+
+func TestDecoysLabel(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if label := decoyLabel("vpn"); label == "vpn" {
+			t.Errorf("decoyLabel returned the label of the real name")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The number of resolvers asked for the addresses of each name
+	divergenceSamples = 3
+)
+
+// DetectDivergence - Resolves the addresses for the name from several resolvers,
+// and returns all the answers observed and whether the answer sets differed
+func DetectDivergence(name string) ([]core.DNSAnswer, bool) {
+	var divergent bool
+	var answers []core.DNSAnswer
+
+	resolvers := pickResolvers(divergenceSamples)
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		var sets []string
+
+		for _, resolver := range resolvers {
+			ans, err := resolverExchange(resolver, name, qtype)
+			if err != nil {
+				continue
+			}
+
+			var data []string
+			for _, a := range ans {
+				data = append(data, a.Data)
+				answers = uniqueAnswerAppend(answers, a)
+			}
+			sort.Strings(data)
+			sets = append(sets, strings.Join(data, ","))
+		}
+
+		for i := 1; i < len(sets); i++ {
+			if sets[i] != sets[0] {
+				divergent = true
+				break
+			}
+		}
+	}
+	return answers, divergent
+}
+
+func pickResolvers(num int) []string {
+	current := usableResolvers()
+	resolvers := make([]string, len(current))
+	copy(resolvers, current)
+
+	utils.RandomShuffle(len(resolvers), func(i, j int) {
+		resolvers[i], resolvers[j] = resolvers[j], resolvers[i]
+	})
+
+	if len(resolvers) > num {
+		resolvers = resolvers[:num]
+	}
+	return resolvers
+}
+
+func resolverExchange(resolver, name string, qtype uint16) ([]core.DNSAnswer, error) {
+	r, err := serverExchange(resolver, QueryMessage(name, qtype))
+	if err != nil {
+		return nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNS error: Resolver returned an error %v", r)
+	}
+
+	var answers []core.DNSAnswer
+	for _, a := range ExtractAnswers(r, qtype) {
+		a.Name = utils.CopyString(name)
+		a.Data = strings.TrimSpace(a.Data)
+		answers = append(answers, a)
+	}
+	return answers, nil
+}
+
+// addressSet - Returns a comparable representation of the A and AAAA answers
+func addressSet(answers []core.DNSAnswer) string {
+	var data []string
+
+	for _, a := range answers {
+		if t := uint16(a.Type); t == dns.TypeA || t == dns.TypeAAAA {
+			data = append(data, strings.ToLower(a.Data))
+		}
+	}
+	sort.Strings(data)
+	return strings.Join(data, ",")
+}
+
+func uniqueAnswerAppend(answers []core.DNSAnswer, add core.DNSAnswer) []core.DNSAnswer {
+	for _, a := range answers {
+		if a.Type == add.Type && strings.EqualFold(a.Data, add.Data) {
+			return answers
+		}
+	}
+	return append(answers, add)
+}
@@ -0,0 +1,450 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// The DS records of the root zone key signing keys published by IANA (KSK-2017 and KSK-2024)
+var defaultTrustAnchors = []string{
+	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+var (
+	// Returned when the answers come from a zone beneath an insecure delegation, so they
+	// cannot be validated
+	ErrDNSSECInsecure = errors.New("DNSSEC error: The answers are beneath an insecure delegation")
+
+	// The DS records that the keys of the root zone are validated against
+	trustAnchorsLock sync.Mutex
+	trustAnchors     []*dns.DS
+
+	// The validated keys and delegations, filled in once each lookup completes
+	chainLock  sync.Mutex
+	chainCache map[string]*chainEntry
+
+	// Sends the queries of the validation, and is replaced during testing
+	dnssecExchange = signedExchange
+)
+
+// chainEntry - The result of validating a zone or a delegation. The done channel is closed
+// once the lookup has completed, so the callers asking at the same time wait for it
+type chainEntry struct {
+	done  chan struct{}
+	state *chainState
+}
+
+// chainState - The closest enclosing zone of a name and its validated keys, or an insecure
+// delegation above the name
+type chainState struct {
+	zone     string
+	keys     []*dns.DNSKEY
+	insecure bool
+	err      error
+
+	// The lookup failed to obtain an answer and can be tried again
+	retry bool
+}
+
+func init() {
+	chainCache = make(map[string]*chainEntry)
+
+	anchors, err := parseTrustAnchors(strings.NewReader(strings.Join(defaultTrustAnchors, "\n")), "default")
+	if err != nil {
+		panic(err)
+	}
+	trustAnchors = anchors
+}
+
+// ReadTrustAnchors - Returns the trust anchors in the zone file, which can provide DS records
+// or the DNSKEY records of the key signing keys for the root zone
+func ReadTrustAnchors(path string) ([]*dns.DS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the trust anchors: %v", err)
+	}
+	defer f.Close()
+
+	return parseTrustAnchors(f, path)
+}
+
+func parseTrustAnchors(r io.Reader, file string) ([]*dns.DS, error) {
+	var anchors []*dns.DS
+
+	for t := range dns.ParseZone(r, ".", file) {
+		if t.Error != nil {
+			return nil, fmt.Errorf("Failed to parse the trust anchors: %v", t.Error)
+		}
+
+		switch rr := t.RR.(type) {
+		case *dns.DS:
+			anchors = append(anchors, rr)
+		case *dns.DNSKEY:
+			anchors = append(anchors, rr.ToDS(dns.SHA256))
+		}
+	}
+	for _, ds := range anchors {
+		if ds.Hdr.Name != "." {
+			return nil, fmt.Errorf("The trust anchor for %s is not for the root zone", ds.Hdr.Name)
+		}
+	}
+	if len(anchors) == 0 {
+		return nil, fmt.Errorf("No trust anchors were found in %s", file)
+	}
+	return anchors, nil
+}
+
+// SetTrustAnchors - Replaces the trust anchors for the root zone, and forgets the keys
+// validated with the previous anchors
+func SetTrustAnchors(anchors []*dns.DS) {
+	trustAnchorsLock.Lock()
+	trustAnchors = anchors
+	trustAnchorsLock.Unlock()
+
+	chainLock.Lock()
+	chainCache = make(map[string]*chainEntry)
+	chainLock.Unlock()
+}
+
+// ValidateDNSSEC - Checks the RRSIG records provided with the answers for the name through the
+// chain of DS and DNSKEY records from the trust anchors. ErrDNSSECInsecure is returned when an
+// answer lies beneath an insecure delegation, such as a signed alias of an unsigned CDN name
+func ValidateDNSSEC(name string, qtype uint16) error {
+	r, err := dnssecExchange(name, qtype)
+	if err != nil {
+		return err
+	}
+
+	sets, sigs := rrsets(r.Answer)
+	// Nothing was returned that could be validated
+	if len(sets) == 0 {
+		return ErrDNSSECInsecure
+	}
+
+	var insecure bool
+	for key, rrset := range sets {
+		err := verifyChain(rrset, sigs[key])
+		if err == ErrDNSSECInsecure {
+			insecure = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if insecure {
+		return ErrDNSSECInsecure
+	}
+	return nil
+}
+
+// rrsets - Groups the records by owner and type, along with the signatures covering each group
+func rrsets(rrs []dns.RR) (map[string][]dns.RR, map[string][]*dns.RRSIG) {
+	sets := make(map[string][]dns.RR)
+	sigs := make(map[string][]*dns.RRSIG)
+
+	for _, a := range rrs {
+		owner := strings.ToLower(a.Header().Name)
+
+		if sig, ok := a.(*dns.RRSIG); ok {
+			key := owner + " " + dns.TypeToString[sig.TypeCovered]
+			sigs[key] = append(sigs[key], sig)
+			continue
+		}
+		key := owner + " " + dns.TypeToString[a.Header().Rrtype]
+		sets[key] = append(sets[key], a)
+	}
+	return sets, sigs
+}
+
+// verifyChain - Validates the RRset with the keys of its closest enclosing zone
+func verifyChain(rrset []dns.RR, sigs []*dns.RRSIG) error {
+	owner := strings.ToLower(rrset[0].Header().Name)
+
+	state := chainFor(owner)
+	if state.err != nil {
+		return state.err
+	}
+	if state.insecure {
+		return ErrDNSSECInsecure
+	}
+	if !verifyRRset(rrset, sigs, state.zone, state.keys) {
+		return fmt.Errorf("DNSSEC error: Failed to validate the %s %s answers",
+			rrset[0].Header().Name, dns.TypeToString[rrset[0].Header().Rrtype])
+	}
+	return nil
+}
+
+// verifyRRset - Returns true when one of the signatures made by the zone verifies the RRset
+func verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG, zone string, keys []*dns.DNSKEY) bool {
+	now := time.Now()
+
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(now) || !strings.EqualFold(dns.Fqdn(sig.SignerName), zone) {
+			continue
+		}
+
+		for _, k := range keys {
+			if k.Algorithm != sig.Algorithm || k.KeyTag() != sig.KeyTag {
+				continue
+			}
+			if err := sig.Verify(k, rrset); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chainFor - Walks the delegations from the root zone down to the name, and returns the closest
+// enclosing zone with its keys, or the insecure delegation found along the way
+func chainFor(name string) *chainState {
+	labels := dns.SplitDomainName(strings.ToLower(dns.Fqdn(name)))
+
+	cur := cached("keys .", rootKeys)
+	for i := len(labels) - 1; i >= 0 && cur.err == nil && !cur.insecure; i-- {
+		child := dns.Fqdn(strings.Join(labels[i:], "."))
+		parent := cur
+
+		next := cached("cut "+child, func() *chainState {
+			return zoneCut(child, parent)
+		})
+		if next.err != nil || next.insecure || next.zone != "" {
+			cur = next
+		}
+	}
+	return cur
+}
+
+// cached - Returns the result of the lookup, performed once for the key while the other callers
+// wait on it. The lookups are performed without holding the lock, and those that failed to obtain
+// an answer are forgotten so they can be tried again
+func cached(key string, lookup func() *chainState) *chainState {
+	chainLock.Lock()
+	if e, found := chainCache[key]; found {
+		chainLock.Unlock()
+		<-e.done
+		return e.state
+	}
+	e := &chainEntry{done: make(chan struct{})}
+	chainCache[key] = e
+	chainLock.Unlock()
+
+	e.state = lookup()
+	close(e.done)
+
+	if e.state.retry {
+		chainLock.Lock()
+		if chainCache[key] == e {
+			delete(chainCache, key)
+		}
+		chainLock.Unlock()
+	}
+	return e.state
+}
+
+// rootKeys - Returns the keys of the root zone that are validated by the trust anchors
+func rootKeys() *chainState {
+	trustAnchorsLock.Lock()
+	anchors := trustAnchors
+	trustAnchorsLock.Unlock()
+
+	return zoneKeys(".", anchors)
+}
+
+// zoneKeys - Obtains the DNSKEY records of the zone, and keeps them when the RRset is signed by a
+// key matching one of the DS records
+func zoneKeys(zone string, dsset []*dns.DS) *chainState {
+	r, err := dnssecExchange(zone, dns.TypeDNSKEY)
+	if err != nil {
+		return &chainState{err: err, retry: true}
+	}
+
+	sets, sigs := rrsets(r.Answer)
+	key := zone + " DNSKEY"
+
+	var keys []*dns.DNSKEY
+	for _, rr := range sets[key] {
+		if k, ok := rr.(*dns.DNSKEY); ok {
+			keys = append(keys, k)
+		}
+	}
+
+	var trusted []*dns.DNSKEY
+	for _, k := range keys {
+		for _, ds := range dsset {
+			if k.KeyTag() != ds.KeyTag || k.Algorithm != ds.Algorithm {
+				continue
+			}
+			if d := k.ToDS(ds.DigestType); d != nil && strings.EqualFold(d.Digest, ds.Digest) {
+				trusted = append(trusted, k)
+			}
+		}
+	}
+	if len(trusted) == 0 || !verifyRRset(sets[key], sigs[key], zone, trusted) {
+		return &chainState{err: fmt.Errorf("DNSSEC error: The DNSKEY records of %s do not match the DS records", zone)}
+	}
+	return &chainState{zone: zone, keys: keys}
+}
+
+// zoneCut - Checks whether the name is a zone cut beneath the validated parent zone. A signed
+// DS RRset leads to the keys of the child zone, while a signed denial of the DS records proves
+// an insecure delegation when the name is delegated. Names that are not zone cuts return an
+// empty state, so the parent zone remains the closest enclosing zone
+func zoneCut(name string, parent *chainState) *chainState {
+	r, err := dnssecExchange(name, dns.TypeDS)
+	if err != nil {
+		return &chainState{err: err, retry: true}
+	}
+
+	sets, sigs := rrsets(r.Answer)
+	if dsset := sets[name+" DS"]; len(dsset) > 0 {
+		if !verifyRRset(dsset, sigs[name+" DS"], parent.zone, parent.keys) {
+			return &chainState{err: fmt.Errorf("DNSSEC error: Failed to validate the DS records of %s", name)}
+		}
+
+		var ds []*dns.DS
+		for _, rr := range dsset {
+			if d, ok := rr.(*dns.DS); ok {
+				ds = append(ds, d)
+			}
+		}
+		return zoneKeys(name, ds)
+	}
+	// An alias cannot be delegated, so the name belongs to the parent zone
+	if cname := sets[name+" CNAME"]; len(cname) > 0 {
+		if !verifyRRset(cname, sigs[name+" CNAME"], parent.zone, parent.keys) {
+			return &chainState{err: fmt.Errorf("DNSSEC error: Failed to validate the CNAME record of %s", name)}
+		}
+		return &chainState{}
+	}
+	return deniedDS(name, r.Ns, parent)
+}
+
+// deniedDS - Examines the NSEC or NSEC3 records proving that the name has no DS records
+func deniedDS(name string, authority []dns.RR, parent *chainState) *chainState {
+	sets, sigs := rrsets(authority)
+
+	for key, rrset := range sets {
+		for _, rr := range rrset {
+			var bitmap []uint16
+
+			switch n := rr.(type) {
+			case *dns.NSEC:
+				if !strings.EqualFold(n.Hdr.Name, name) {
+					// The name does not exist, or is an empty non-terminal
+					if nsecCovers(n, name) && verifyRRset(rrset, sigs[key], parent.zone, parent.keys) {
+						return &chainState{}
+					}
+					continue
+				}
+				bitmap = n.TypeBitMap
+			case *dns.NSEC3:
+				if !n.Match(name) {
+					if n.Cover(name) && verifyRRset(rrset, sigs[key], parent.zone, parent.keys) {
+						// An unsigned delegation can be skipped by an opt-out span
+						if n.Flags&1 == 1 {
+							return &chainState{insecure: true}
+						}
+						return &chainState{}
+					}
+					continue
+				}
+				bitmap = n.TypeBitMap
+			default:
+				continue
+			}
+
+			if !verifyRRset(rrset, sigs[key], parent.zone, parent.keys) {
+				return &chainState{err: fmt.Errorf("DNSSEC error: Failed to validate the denial of DS records for %s", name)}
+			}
+			if hasType(bitmap, dns.TypeDS) {
+				return &chainState{err: fmt.Errorf("DNSSEC error: The DS records of %s were withheld", name)}
+			}
+			if hasType(bitmap, dns.TypeNS) && !hasType(bitmap, dns.TypeSOA) {
+				return &chainState{insecure: true}
+			}
+			return &chainState{}
+		}
+	}
+	return &chainState{err: fmt.Errorf("DNSSEC error: The absence of DS records for %s was not proven", name)}
+}
+
+// nsecCovers - Returns true when the name falls between the owner and the next name of the record
+func nsecCovers(n *dns.NSEC, name string) bool {
+	owner, next := strings.ToLower(n.Hdr.Name), strings.ToLower(n.NextDomain)
+	name = strings.ToLower(name)
+
+	if canonicalCompare(owner, next) >= 0 {
+		// The last record of the zone covers the names after its owner
+		return canonicalCompare(owner, name) < 0
+	}
+	return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+}
+
+// canonicalCompare - Compares the names in the canonical DNS order of RFC 4034, section 6.1
+func canonicalCompare(a, b string) int {
+	la, lb := dns.SplitDomainName(a), dns.SplitDomainName(b)
+
+	for i, j := len(la)-1, len(lb)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := strings.Compare(la[i], lb[j]); c != 0 {
+			return c
+		}
+	}
+	return len(la) - len(lb)
+}
+
+func hasType(bitmap []uint16, t uint16) bool {
+	for _, b := range bitmap {
+		if b == t {
+			return true
+		}
+	}
+	return false
+}
+
+// signedExchange - Performs the query with the DNSSEC OK bit set
+func signedExchange(name string, qtype uint16) (*dns.Msg, error) {
+	conn, err := DNSDialContext(context.Background(), "udp", "")
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to create UDP connection to resolver: %v", err)
+	}
+	defer conn.Close()
+
+	msg := QueryMessage(name, qtype)
+	// The answers will be validated here
+	msg.CheckingDisabled = true
+	for _, rr := range msg.Extra {
+		if opt, ok := rr.(*dns.OPT); ok {
+			opt.SetUDPSize(maxUDPSize)
+			opt.SetDo()
+		}
+	}
+
+	co := newConn(conn)
+	co.SetWriteDeadline(time.Now().Add(1 * time.Second))
+	if err = writeQuery(co, msg); err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to write query msg: %w", err)
+	}
+
+	co.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r, err := readMsg(co, msg)
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to read query response: %v", err)
+	}
+	r = completeExchange(conn, msg, r)
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNS error: Resolver returned an error %v", r)
+	}
+	return r, nil
+}
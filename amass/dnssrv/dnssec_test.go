@@ -0,0 +1,154 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"crypto"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testZone - A signing zone of the hierarchy answered by the test exchange
+type testZone struct {
+	name string
+	key  *dns.DNSKEY
+	priv crypto.Signer
+}
+
+func newTestZone(t *testing.T, name string) *testZone {
+	k := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: name, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := k.Generate(256)
+	if err != nil {
+		t.Fatalf("Failed to generate the key of %s: %v", name, err)
+	}
+	return &testZone{name: name, key: k, priv: priv.(crypto.Signer)}
+}
+
+// sign - Returns the RRset followed by its signature made with the key of the zone
+func (z *testZone) sign(t *testing.T, rrset ...dns.RR) []dns.RR {
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: rrset[0].Header().Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 3600},
+		Algorithm:  z.key.Algorithm,
+		KeyTag:     z.key.KeyTag(),
+		SignerName: z.name,
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+	}
+	if err := sig.Sign(z.priv, rrset); err != nil {
+		t.Fatalf("Failed to sign the %s RRset: %v", rrset[0].Header().Name, err)
+	}
+	return append(rrset, sig)
+}
+
+func testRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", s, err)
+	}
+	return rr
+}
+
+// setupSignedHierarchy - Answers the validation queries for a signed example zone beneath the test
+// root zone, which delegates cdn.example without DS records
+func setupSignedHierarchy(t *testing.T) func() {
+	root := newTestZone(t, ".")
+	example := newTestZone(t, "example.")
+	ds := example.key.ToDS(dns.SHA256)
+	ds.Hdr = dns.RR_Header{Name: "example.", Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+
+	answers := map[string]*dns.Msg{
+		". DNSKEY":         {Answer: root.sign(t, root.key)},
+		"example. DS":      {Answer: root.sign(t, ds)},
+		"example. DNSKEY":  {Answer: example.sign(t, example.key)},
+		"www.example. DS":  {Ns: example.sign(t, testRR(t, "www.example. 3600 IN NSEC zz.example. CNAME RRSIG NSEC"))},
+		"cdn.example. DS":  {Ns: example.sign(t, testRR(t, "cdn.example. 3600 IN NSEC www.example. NS RRSIG NSEC"))},
+		"mail.example. DS": {Ns: example.sign(t, testRR(t, "mail.example. 3600 IN NSEC www.example. A RRSIG NSEC"))},
+		"www.example. A": {Answer: append(example.sign(t, testRR(t, "www.example. 300 IN CNAME edge.cdn.example.")),
+			testRR(t, "edge.cdn.example. 60 IN A 192.0.2.10"))},
+		"mail.example. A":   {Answer: example.sign(t, testRR(t, "mail.example. 300 IN A 192.0.2.25"))},
+		"forged.example. A": {Answer: example.sign(t, testRR(t, "forged.example. 300 IN A 192.0.2.26"))},
+	}
+	// The forged answer keeps a signature made over another address
+	forged := answers["forged.example. A"]
+	forged.Answer[0].(*dns.A).A[3] = 99
+	answers["forged.example. DS"] = &dns.Msg{Ns: example.sign(t, testRR(t, "forged.example. 3600 IN NSEC mail.example. A RRSIG NSEC"))}
+
+	saved := dnssecExchange
+	dnssecExchange = func(name string, qtype uint16) (*dns.Msg, error) {
+		if r, found := answers[strings.ToLower(dns.Fqdn(name))+" "+dns.TypeToString[qtype]]; found {
+			return r, nil
+		}
+		return nil, errors.New("No answer")
+	}
+
+	trustAnchorsLock.Lock()
+	defaults := trustAnchors
+	trustAnchorsLock.Unlock()
+
+	anchor := root.key.ToDS(dns.SHA256)
+	anchor.Hdr = dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET}
+	SetTrustAnchors([]*dns.DS{anchor})
+
+	return func() {
+		dnssecExchange = saved
+		SetTrustAnchors(defaults)
+	}
+}
+
+func TestValidateDNSSEC(t *testing.T) {
+	defer setupSignedHierarchy(t)()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"mail.example", nil},
+		{"www.example", ErrDNSSECInsecure},
+		{"forged.example", errors.New("bogus")},
+	}
+	for _, test := range tests {
+		err := ValidateDNSSEC(test.name, dns.TypeA)
+
+		switch {
+		case test.err == nil && err != nil:
+			t.Errorf("%s was not validated: %v", test.name, err)
+		case test.err == ErrDNSSECInsecure && err != ErrDNSSECInsecure:
+			t.Errorf("%s was not reported beneath an insecure delegation: %v", test.name, err)
+		case test.err != nil && test.err != ErrDNSSECInsecure && (err == nil || err == ErrDNSSECInsecure):
+			t.Errorf("The forged answer for %s was accepted: %v", test.name, err)
+		}
+	}
+}
+
+func TestValidateDNSSECUntrustedRoot(t *testing.T) {
+	defer setupSignedHierarchy(t)()
+
+	// The root keys served no longer match the configured trust anchor
+	other := newTestZone(t, ".")
+	anchor := other.key.ToDS(dns.SHA256)
+	anchor.Hdr = dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET}
+	SetTrustAnchors([]*dns.DS{anchor})
+
+	if err := ValidateDNSSEC("mail.example", dns.TypeA); err == nil || err == ErrDNSSECInsecure {
+		t.Errorf("The answers were validated with keys outside the chain of trust: %v", err)
+	}
+}
+
+func TestParseTrustAnchors(t *testing.T) {
+	if _, err := parseTrustAnchors(strings.NewReader(strings.Join(defaultTrustAnchors, "\n")), "default"); err != nil {
+		t.Errorf("The default trust anchors were not parsed: %v", err)
+	}
+	if _, err := parseTrustAnchors(strings.NewReader("example. IN DS 1 8 2 AB"), "test"); err == nil {
+		t.Errorf("A trust anchor for a zone other than the root was accepted")
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/amass/core"
@@ -20,6 +22,9 @@ import (
 
 const (
 	defaultNumOpenFiles int64 = 10000
+
+	// ZoneXFRSource - The source of the names obtained by zone transfers from the name servers
+	ZoneXFRSource = "DNS ZoneXFR"
 )
 
 type DNSService struct {
@@ -28,13 +33,29 @@ type DNSService struct {
 	bus evbus.Bus
 
 	// Ensures we do not resolve names more than once
-	filter *cfilter.CFilter
+	filterLock sync.Mutex
+	filter     *cfilter.CFilter
 
 	// Data collected about various subdomains
 	subdomains map[string]map[int][]string
 
 	// Enforces a maximum number of DNS queries sent at any given moment
 	sem *semaphore.Weighted
+
+	// Remote workers that names are sent to for resolution
+	workers *WorkerPool
+
+	// Remote agents that provide answers from other vantage points
+	agents *WorkerPool
+
+	// The fraction of a decoy query owed from the earlier requests
+	decoyLock   sync.Mutex
+	decoyCredit float64
+
+	// The authoritative servers of the root domains and when each domain can be queried next
+	authLock    sync.Mutex
+	authServers map[string]*authLookup
+	authNext    map[string]time.Time
 }
 
 func NewDNSService(config *core.AmassConfig, bus evbus.Bus) *DNSService {
@@ -43,6 +64,10 @@ func NewDNSService(config *core.AmassConfig, bus evbus.Bus) *DNSService {
 	if weight <= 0 {
 		weight = defaultNumOpenFiles
 	}
+	// Each query holds six units of the weight while in flight
+	if config.MaxDNSConcurrency > 0 {
+		weight = int64(config.MaxDNSConcurrency) * 6
+	}
 
 	ds := &DNSService{
 		bus:        bus,
@@ -51,6 +76,13 @@ func NewDNSService(config *core.AmassConfig, bus evbus.Bus) *DNSService {
 		sem:        semaphore.NewWeighted(weight),
 	}
 
+	if len(config.Workers) > 0 {
+		ds.workers = NewWorkerPool(config.Workers, config.WorkerToken, config.WorkerTLS)
+	}
+	if len(config.Agents) > 0 {
+		ds.agents = NewWorkerPool(config.Agents, config.WorkerToken, config.WorkerTLS)
+	}
+
 	ds.BaseAmassService = *core.NewBaseAmassService("DNS Service", config, ds)
 	return ds
 }
@@ -60,10 +92,42 @@ func (ds *DNSService) OnStart() error {
 
 	ds.bus.SubscribeAsync(core.DNSQUERY, ds.SendRequest, false)
 	ds.bus.SubscribeAsync(core.DNSSWEEP, ds.ReverseDNSSweep, false)
+	ds.bus.SubscribeAsync(core.NEWDOMAIN, ds.addDomain, false)
 	go ds.processRequests()
 	return nil
 }
 
+// List - Returns the DNS servers that the names are sent to
+func (ds *DNSService) List() string {
+	config := ds.Config()
+
+	servers := "the resolvers " + strings.Join(ConfiguredResolvers(config.Resolvers), ", ")
+	if config.Iterative {
+		servers = "the root servers and the authoritative servers of each zone"
+		if config.QNAMEMinimization {
+			servers += ", revealing one more label at each delegation"
+		}
+	}
+	lines := []string{"Sends the names being resolved and the addresses in the swept netblocks to " + servers}
+
+	if len(config.Workers) > 0 {
+		lines = append(lines, "Sends the names being resolved to the remote workers "+strings.Join(config.Workers, ", "))
+	}
+	if len(config.Agents) > 0 {
+		lines = append(lines, "Sends the resolved names to the remote agents "+strings.Join(config.Agents, ", "))
+	}
+	if config.AuthoritativeDirect {
+		lines = append(lines, "Sends the brute forced names to the authoritative servers of the root domains")
+	}
+	if config.Active {
+		lines = append(lines, "Requests zone transfers from the authoritative servers of the root domains")
+	}
+	if config.DecoyRatio > 0 {
+		lines = append(lines, "Sends queries for the decoy domains to the same servers")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (ds *DNSService) OnPause() error {
 	return nil
 }
@@ -77,11 +141,14 @@ func (ds *DNSService) OnStop() error {
 
 	ds.bus.Unsubscribe(core.DNSQUERY, ds.SendRequest)
 	ds.bus.Unsubscribe(core.DNSSWEEP, ds.ReverseDNSSweep)
+	ds.bus.Unsubscribe(core.NEWDOMAIN, ds.addDomain)
 	return nil
 }
 
 func (ds *DNSService) processRequests() {
-	t := time.NewTicker(ds.Config().Frequency)
+	t := time.NewTicker(ds.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
 loop:
 	for {
 		select {
@@ -89,8 +156,15 @@ loop:
 			ds.performRequest()
 		case <-ds.PauseChan():
 			t.Stop()
+			paused = true
 		case <-ds.ResumeChan():
-			t = time.NewTicker(ds.Config().Frequency)
+			t = time.NewTicker(ds.Config().Rates().Frequency)
+			paused = false
+		case <-ds.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(ds.Config().Rates().Frequency)
+			}
 		case <-ds.Quit():
 			break loop
 		}
@@ -99,6 +173,9 @@ loop:
 }
 
 func (ds *DNSService) duplicate(name string) bool {
+	ds.filterLock.Lock()
+	defer ds.filterLock.Unlock()
+
 	if ds.filter.Lookup([]byte(name)) {
 		return true
 	}
@@ -106,19 +183,69 @@ func (ds *DNSService) duplicate(name string) bool {
 	return false
 }
 
+// MarkResolved - Keeps the names that were already answered authoritatively, such as
+// from a zone file, from being resolved again. It must be called before the service starts
+func (ds *DNSService) MarkResolved(names ...string) {
+	for _, name := range names {
+		ds.duplicate(name)
+	}
+}
+
+// addDomain - Resolves a root domain added during the enumeration, which starts the zone
+// transfers and the wildcard checks of its subdomains, along with the records collected
+// for the domain by the data manager
+func (ds *DNSService) addDomain(domain string) {
+	ds.SendRequest(&core.AmassRequest{
+		Name:   domain,
+		Domain: domain,
+		Tag:    core.DNS,
+		Source: "Forward DNS",
+	})
+}
+
+// tagInScope - The root domain names are always resolved, regardless of the tag
+func (ds *DNSService) tagInScope(req *core.AmassRequest) bool {
+	return req.Name == req.Domain || ds.Config().TagInScope(req.Tag)
+}
+
+// dropRequest - Returns the reason the request will not be resolved, or an empty string
+func (ds *DNSService) dropRequest(req *core.AmassRequest) string {
+	span := ds.Config().StartSpan(core.SpanDedupe, req)
+	defer span.End()
+
+	var reason string
+	if req.Name == "" || req.Domain == "" {
+		reason = "incomplete"
+	} else if !ds.tagInScope(req) {
+		reason = "tag"
+	} else if ds.duplicate(req.Name) {
+		reason = "duplicate"
+	} else if ds.Config().Blacklisted(req.Name) {
+		reason = "blacklisted"
+	}
+
+	if reason != "" {
+		span.SetAttribute("dropped", reason)
+	}
+	return reason
+}
+
 func (ds *DNSService) performRequest() {
 	req := ds.NextRequest()
 	// Plow through the requests that are not of interest
-	for req != nil && (req.Name == "" || req.Domain == "" ||
-		ds.duplicate(req.Name) || ds.Config().Blacklisted(req.Name)) {
+	for req != nil && ds.dropRequest(req) != "" {
+		ds.FinishWork()
 		req = ds.NextRequest()
 	}
 	if req == nil {
 		return
 	}
+	if !ds.Config().AllowDNSQueries(NumOfQueries()) {
+		ds.FinishWork()
+		return
+	}
 
 	ds.sem.Acquire(context.Background(), 6)
-	ds.SetActive()
 	go ds.completeQueries(req)
 }
 
@@ -130,35 +257,64 @@ var InitialQueryTypes = []uint16{
 }
 
 func (ds *DNSService) completeQueries(req *core.AmassRequest) {
+	defer ds.FinishWork()
 	defer ds.sem.Release(6)
 
-	var answers []core.DNSAnswer
+	// Spread the queries out so the traffic has no fixed rhythm
+	if j := ds.Config().Rates().Jitter; j > 0 {
+		time.Sleep(time.Duration(utils.RandomInt() % int(j)))
+	}
 
-	for _, t := range InitialQueryTypes {
-		tries := 3
-		if t == dns.TypeTXT {
-			tries = 10
-		}
+	span := ds.Config().StartSpan(core.SpanResolve, req)
+	defer span.End()
 
-		for i := 0; i < tries; i++ {
-			a, err, again := ds.executeQuery(req.Name, t)
-			if err == nil {
-				answers = append(answers, a...)
-				break
-			}
-			ds.Config().Log.Print(err)
-			if !again {
-				break
-			}
+	var exists bool
+	var answers []core.DNSAnswer
+	if ds.workers != nil {
+		var err error
+
+		answers, exists, err = ds.workers.Resolve(req.Name)
+		if err != nil {
+			ds.Config().Log.Printf("%s: %v", req.Name, err)
+			// Resolve the name locally when no worker is available
+			answers, exists = resolveNameTypes(req.Name, InitialQueryTypes, ds.Config().Log)
 		}
+	} else if ds.Config().AuthoritativeDirect && req.Tag == core.BRUTE {
+		answers, exists = ds.resolveAuthoritative(req)
+	} else {
+		answers, exists = resolveNameTypes(req.Name, ds.queryTypes(), ds.Config().Log)
 	}
+	// The decoys are sent outside of the semaphore, so they never delay the real queries
+	go ds.sendDecoys(req.Name)
 
 	req.Records = answers
+	span.SetAttribute("answers", strconv.Itoa(len(answers)))
+	// The name exists, but without addresses it would not be reported
+	if exists && !addressAnswers(answers) && !ds.noDataWildcard(req.Name) {
+		req.NoData = true
+		req.Records = append(req.Records, ds.probeNoDataTypes(req.Name)...)
+		span.SetAttribute("nodata", "true")
+		ds.bus.Publish(core.RESOLVED, req)
+		return
+	}
 	if len(req.Records) == 0 {
 		return
 	}
 
+	if ds.Config().DNSSEC {
+		req.DNSSEC = ds.validateAnswers(req)
+	}
+
+	if ds.Config().Divergence {
+		ds.compareResolverAnswers(req)
+	}
+
+	if ds.agents != nil {
+		ds.compareVantagePoints(req)
+	}
+
 	if req.Tag != core.CERT && DetectWildcard(req.Domain, req.Name, req.Records) {
+		span.SetAttribute("wildcard", "true")
 		return
 	}
 	// Make sure we know about any new subdomains
@@ -166,44 +322,102 @@ func (ds *DNSService) completeQueries(req *core.AmassRequest) {
 	ds.bus.Publish(core.RESOLVED, req)
 }
 
-func (ds *DNSService) executeQuery(name string, qtype uint16) ([]core.DNSAnswer, error, bool) {
+func executeQuery(name string, qtype uint16) ([]core.DNSAnswer, error, bool) {
 	var answers []core.DNSAnswer
 
+	if iterativeMode() {
+		answers, err := resolveIterative(name, qtype)
+		return answers, err, false
+	}
+
 	conn, err := DNSDialContext(context.Background(), "udp", "")
 	if err != nil {
 		return nil, fmt.Errorf("DNS error: Failed to create UDP connection to resolver: %v", err), false
 	}
 	defer conn.Close()
 
-	co := &dns.Conn{Conn: conn}
+	co := newConn(conn)
 	msg := QueryMessage(name, qtype)
 
 	co.SetWriteDeadline(time.Now().Add(1 * time.Second))
-	if err = co.WriteMsg(msg); err != nil {
-		return nil, fmt.Errorf("DNS error: Failed to write query msg: %v", err), false
+	if err = writeQuery(co, msg); err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to write query msg: %w", err), false
 	}
 
 	co.SetReadDeadline(time.Now().Add(1 * time.Second))
-	r, err := co.ReadMsg()
+	r, err := readMsg(co, msg)
 	if err != nil {
 		return nil, fmt.Errorf("DNS error: Failed to read query response: %v", err), true
 	}
+	r = completeExchange(conn, msg, r)
 	// Check that the query was successful
 	if r != nil && r.Rcode != dns.RcodeSuccess {
 		return nil, fmt.Errorf("DNS error: Resolver returned an error %v", r), false
 	}
 
-	for _, a := range ExtractRawData(r, qtype) {
-		answers = append(answers, core.DNSAnswer{
-			Name: utils.CopyString(name),
-			Type: int(qtype),
-			TTL:  0,
-			Data: strings.TrimSpace(a),
-		})
+	for _, a := range ExtractAnswers(r, qtype) {
+		a.Name = utils.CopyString(name)
+		a.Data = strings.TrimSpace(a.Data)
+		answers = append(answers, a)
 	}
 	return answers, nil, false
 }
 
+// validateAnswers - Returns the DNSSEC state for the record types answered. The name is only
+// secure when every answer is, and insecure when any answer lies beneath an insecure delegation
+func (ds *DNSService) validateAnswers(req *core.AmassRequest) string {
+	var state string
+	checked := make(map[int]struct{})
+
+	for _, a := range req.Records {
+		if _, found := checked[a.Type]; found {
+			continue
+		}
+		checked[a.Type] = struct{}{}
+
+		err := ValidateDNSSEC(req.Name, uint16(a.Type))
+		if err == ErrDNSSECInsecure {
+			state = core.DNSSECInsecure
+			continue
+		} else if err != nil {
+			ds.Config().Log.Printf("%s: %v", req.Name, err)
+			return core.DNSSECBogus
+		}
+		if state == "" {
+			state = core.DNSSECSecure
+		}
+	}
+	return state
+}
+
+// compareResolverAnswers - Adds the addresses observed by other resolvers to the request
+func (ds *DNSService) compareResolverAnswers(req *core.AmassRequest) {
+	answers, divergent := DetectDivergence(req.Name)
+
+	for _, a := range answers {
+		req.Records = uniqueAnswerAppend(req.Records, a)
+	}
+	req.Divergent = divergent
+}
+
+// compareVantagePoints - Adds the addresses observed by the remote agents to the request
+func (ds *DNSService) compareVantagePoints(req *core.AmassRequest) {
+	local := addressSet(req.Records)
+
+	for _, answers := range ds.agents.AddressesFromAll(req.Name) {
+		// The agent may have failed to resolve the name
+		if len(answers) == 0 {
+			continue
+		}
+		if addressSet(answers) != local {
+			req.GeoDNS = true
+		}
+		for _, a := range answers {
+			req.Records = uniqueAnswerAppend(req.Records, a)
+		}
+	}
+}
+
 func (ds *DNSService) checkForNewSubdomain(req *core.AmassRequest) {
 	labels := strings.Split(req.Name, ".")
 	num := len(labels)
@@ -234,7 +448,6 @@ func (ds *DNSService) checkForNewSubdomain(req *core.AmassRequest) {
 	}
 	// Otherwise, run the basic queries against this name
 	ds.basicQueries(sub, req.Domain)
-	go ds.queryServiceNames(sub, req.Domain)
 }
 
 func (ds *DNSService) dupSubdomain(sub string) bool {
@@ -284,7 +497,7 @@ func (ds *DNSService) basicQueries(subdomain, domain string) {
 		Name:    subdomain,
 		Domain:  domain,
 		Records: answers,
-		Tag:     "dns",
+		Tag:     core.DNS,
 		Source:  "Forward DNS",
 	})
 }
@@ -295,8 +508,8 @@ func (ds *DNSService) attemptZoneXFR(domain, sub, server string) {
 			ds.SendRequest(&core.AmassRequest{
 				Name:   name,
 				Domain: domain,
-				Tag:    "axfr",
-				Source: "DNS ZoneXFR",
+				Tag:    core.AXFR,
+				Source: ZoneXFRSource,
 			})
 		}
 	} else {
@@ -304,36 +517,10 @@ func (ds *DNSService) attemptZoneXFR(domain, sub, server string) {
 	}
 }
 
-func (ds *DNSService) queryServiceNames(subdomain, domain string) {
-	// Check all the popular SRV records
-	for _, name := range popularSRVRecords {
-		srvName := name + "." + subdomain
-
-		for i := 0; i < 3; i++ {
-			// Do not go too fast
-			time.Sleep(ds.Config().Frequency)
-
-			a, err, again := ds.executeQuery(srvName, dns.TypeSRV)
-			if err == nil {
-				ds.bus.Publish(core.RESOLVED, &core.AmassRequest{
-					Name:    srvName,
-					Domain:  domain,
-					Records: a,
-					Tag:     "dns",
-					Source:  "Forward DNS",
-				})
-				break
-			}
-			if !again {
-				break
-			}
-		}
-	}
-}
-
-func (ds *DNSService) ReverseDNSSweep(domain, addr string, cidr *net.IPNet) {
+func (ds *DNSService) ReverseDNSSweep(req *core.NetblockRequest) {
+	domain := req.Domain
 	// Get the subset of 200 nearby IP addresses
-	ips := utils.CIDRSubset(cidr, addr, 200)
+	ips := utils.CIDRSubset(req.CIDR, req.Address, 200)
 	// Go through the IP addresses
 	for _, ip := range ips {
 		var ptr string
@@ -349,18 +536,21 @@ func (ds *DNSService) ReverseDNSSweep(domain, addr string, cidr *net.IPNet) {
 		if ds.duplicate(ptr) {
 			continue
 		}
+		if !ds.Config().AllowDNSQueries(NumOfQueries()) {
+			return
+		}
 
 		for i := 0; i < 3; i++ {
 			// Do not go too fast
-			time.Sleep(ds.Config().Frequency)
+			time.Sleep(ds.Config().Rates().Frequency)
 
-			a, err, again := ds.executeQuery(ptr, dns.TypePTR)
+			a, err, again := executeQuery(ptr, dns.TypePTR)
 			if err == nil {
 				ds.bus.Publish(core.RESOLVED, &core.AmassRequest{
 					Name:    ptr,
 					Domain:  domain,
 					Records: a,
-					Tag:     "dns",
+					Tag:     core.DNS,
 					Source:  "Reverse DNS",
 				})
 				break
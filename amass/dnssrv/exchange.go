@@ -0,0 +1,108 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// The UDP payload size advertised in the EDNS0 option, small enough to avoid IP fragmentation
+const maxUDPSize = 1232
+
+// newConn - Wraps the connection with a read buffer large enough for the advertised payload size
+func newConn(conn net.Conn) *dns.Conn {
+	return &dns.Conn{Conn: conn, UDPSize: dns.DefaultMsgSize}
+}
+
+// readMsg - Reads the response to the query, keeping the messages that arrive truncated
+// so the answers can be requested again over TCP
+func readMsg(co *dns.Conn, msg *dns.Msg) (*dns.Msg, error) {
+	r, err := co.ReadMsg()
+	if err == dns.ErrTruncated && r != nil {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = verifyCase(msg, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// completeExchange - Follows up on responses that do not hold the full answer. Servers that
+// reject the EDNS0 option are asked again without it, and truncated answers are requested
+// again over TCP. The original response is kept when the follow up fails
+func completeExchange(conn net.Conn, msg, r *dns.Msg) *dns.Msg {
+	if r == nil {
+		return r
+	}
+
+	if r.Rcode == dns.RcodeFormatError && msg.IsEdns0() != nil {
+		plain := msg.Copy()
+		plain.Extra = removeOPT(plain.Extra)
+
+		co := newConn(conn)
+		co.SetWriteDeadline(time.Now().Add(1 * time.Second))
+		if err := writeQuery(co, plain); err != nil {
+			return r
+		}
+
+		co.SetReadDeadline(time.Now().Add(2 * time.Second))
+		resp, err := readMsg(co, plain)
+		if err != nil {
+			return r
+		}
+		msg, r = plain, resp
+	}
+
+	if r.Truncated && isUDP(conn) {
+		if resp, err := exchangeTCP(conn.RemoteAddr().String(), msg); err == nil {
+			return resp
+		}
+	}
+	return r
+}
+
+// exchangeTCP - Sends the query to the server over TCP
+func exchangeTCP(server string, msg *dns.Msg) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	conn, err := dialResolver(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	co := &dns.Conn{Conn: conn}
+	co.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if err = writeQuery(co, msg); err != nil {
+		return nil, err
+	}
+
+	co.SetReadDeadline(time.Now().Add(3 * time.Second))
+	return readMsg(co, msg)
+}
+
+func isUDP(conn net.Conn) bool {
+	_, ok := conn.RemoteAddr().(*net.UDPAddr)
+	return ok
+}
+
+func removeOPT(extra []dns.RR) []dns.RR {
+	var rrs []dns.RR
+
+	for _, rr := range extra {
+		if rr.Header().Rrtype != dns.TypeOPT {
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs
+}
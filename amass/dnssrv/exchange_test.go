@@ -0,0 +1,123 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// startTruncatingServer - Answers with the TC bit set over UDP and with the records over TCP
+func startTruncatingServer(t *testing.T) (string, func()) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		pc.Close()
+		t.Skipf("Failed to listen on TCP at the same port: %v", err)
+	}
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		if _, udp := w.RemoteAddr().(*net.UDPAddr); udp {
+			m.Truncated = true
+		} else {
+			for i := 0; i < 3; i++ {
+				rr, _ := dns.NewRR(req.Question[0].Name + " 300 IN TXT \"part of a large answer\"")
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		w.WriteMsg(m)
+	})
+
+	us := &dns.Server{PacketConn: pc, Handler: handler}
+	ts := &dns.Server{Listener: l, Handler: handler}
+	go us.ActivateAndServe()
+	go ts.ActivateAndServe()
+
+	return pc.LocalAddr().String(), func() {
+		us.Shutdown()
+		ts.Shutdown()
+	}
+}
+
+func TestExchangeTruncatedRetry(t *testing.T) {
+	addr, stop := startTruncatingServer(t)
+	defer stop()
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial the server: %v", err)
+	}
+	defer conn.Close()
+
+	msg := QueryMessage("large.example.com", dns.TypeTXT)
+	if opt := msg.IsEdns0(); opt == nil || opt.UDPSize() != maxUDPSize {
+		t.Errorf("The query did not advertise a %d byte UDP payload", maxUDPSize)
+	}
+
+	co := newConn(conn)
+	if err := co.WriteMsg(msg); err != nil {
+		t.Fatalf("Failed to write the query: %v", err)
+	}
+	r, err := readMsg(co, msg)
+	if err != nil {
+		t.Fatalf("Failed to read the response: %v", err)
+	}
+	if !r.Truncated {
+		t.Fatalf("The server did not truncate the UDP response")
+	}
+
+	r = completeExchange(conn, msg, r)
+	if r.Truncated || len(r.Answer) != 3 {
+		t.Errorf("The TCP retry returned %d answers and truncated %v", len(r.Answer), r.Truncated)
+	}
+}
+
+func TestExchangeRemoveOPT(t *testing.T) {
+	msg := QueryMessage("www.example.com", dns.TypeA)
+
+	msg.Extra = removeOPT(msg.Extra)
+	if msg.IsEdns0() != nil {
+		t.Errorf("removeOPT left the EDNS0 option in the message")
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	addr, stop := startTruncatingServer(t)
+	defer stop()
+
+	// The TCP retry of the truncated answer is one query more than the limit allows
+	SetQueryLimit(NumOfQueries() + 1)
+	defer SetQueryLimit(0)
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial the server: %v", err)
+	}
+	defer conn.Close()
+
+	msg := QueryMessage("large.example.com", dns.TypeTXT)
+	co := newConn(conn)
+	if err := writeQuery(co, msg); err != nil {
+		t.Fatalf("The query within the limit was refused: %v", err)
+	}
+	r, err := readMsg(co, msg)
+	if err != nil {
+		t.Fatalf("Failed to read the response: %v", err)
+	}
+
+	if r = completeExchange(conn, msg, r); !r.Truncated {
+		t.Errorf("The TCP retry was sent over the query limit")
+	}
+	if err := writeQuery(co, msg); err != ErrQueryLimit {
+		t.Errorf("The query over the limit returned %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ServerFingerprint - The software identified for a name server and the behavior leading to it
+type ServerFingerprint struct {
+	Address  string
+	Software string
+	Version  string
+	// The responses to the probes, such as "version.bind:NOERROR"
+	Behavior []string
+}
+
+type fingerprintProbe struct {
+	label string
+	msg   func() *dns.Msg
+}
+
+// The probes sent to each name server, in the style of fpdns
+var fingerprintProbes = []fingerprintProbe{
+	{"version.bind", func() *dns.Msg { return chaosMessage("version.bind") }},
+	{"version.server", func() *dns.Msg { return chaosMessage("version.server") }},
+	{"authors.bind", func() *dns.Msg { return chaosMessage("authors.bind") }},
+	{"hostname.bind", func() *dns.Msg { return chaosMessage("hostname.bind") }},
+	{"id.server", func() *dns.Msg { return chaosMessage("id.server") }},
+	{"iquery", func() *dns.Msg {
+		m := QueryMessage(".", dns.TypeA)
+		m.Opcode = dns.OpcodeIQuery
+		m.Extra = nil
+		return m
+	}},
+	{"status", func() *dns.Msg {
+		m := QueryMessage(".", dns.TypeNS)
+		m.Opcode = dns.OpcodeStatus
+		m.Extra = nil
+		return m
+	}},
+}
+
+type softwarePattern struct {
+	re       *regexp.Regexp
+	software string
+}
+
+// The software reported within the version strings
+var softwareFromVersion = []softwarePattern{
+	{regexp.MustCompile(`(?i)powerdns`), "PowerDNS"},
+	{regexp.MustCompile(`(?i)\bnsd\b`), "NSD"},
+	{regexp.MustCompile(`(?i)knot`), "Knot DNS"},
+	{regexp.MustCompile(`(?i)unbound`), "Unbound"},
+	{regexp.MustCompile(`(?i)dnsmasq`), "dnsmasq"},
+	{regexp.MustCompile(`(?i)microsoft`), "Microsoft DNS"},
+	{regexp.MustCompile(`(?i)yadifa`), "YADIFA"},
+	{regexp.MustCompile(`(?i)bind|^9\.\d+`), "ISC BIND"},
+}
+
+var versionNumber = regexp.MustCompile(`\d+(\.\d+)+[\w.-]*`)
+
+// The patterns added from the fingerprint database, matched before the built-in patterns
+var (
+	addedPatterns     []softwarePattern
+	addedPatternsLock sync.Mutex
+)
+
+// AddSoftwarePattern - Identifies the software when its version strings match the
+// regular expression, ahead of the patterns built into the package
+func AddSoftwarePattern(expr, software string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("Invalid version pattern for %s: %v", software, err)
+	}
+
+	addedPatternsLock.Lock()
+	defer addedPatternsLock.Unlock()
+
+	addedPatterns = append(addedPatterns, softwarePattern{re: re, software: software})
+	return nil
+}
+
+func softwarePatterns() []softwarePattern {
+	addedPatternsLock.Lock()
+	defer addedPatternsLock.Unlock()
+
+	return append(append([]softwarePattern(nil), addedPatterns...), softwareFromVersion...)
+}
+
+// FingerprintServer - Sends the CHAOS class and opcode probes to the name server
+// and identifies the software from the version string or the responses
+func FingerprintServer(addr string) *ServerFingerprint {
+	fp := &ServerFingerprint{Address: addr}
+	responses := make(map[string]*dns.Msg)
+
+	for _, probe := range fingerprintProbes {
+		r, err := serverExchange(net.JoinHostPort(addr, "53"), probe.msg())
+		if err != nil {
+			fp.Behavior = append(fp.Behavior, probe.label+":TIMEOUT")
+			continue
+		}
+
+		responses[probe.label] = r
+		fp.Behavior = append(fp.Behavior, probe.label+":"+dns.RcodeToString[r.Rcode])
+	}
+
+	var version string
+	for _, label := range []string{"version.bind", "version.server"} {
+		if v := chaosText(responses[label]); v != "" {
+			version = v
+			break
+		}
+	}
+
+	fp.Software, fp.Version = identifySoftware(version, responses)
+	return fp
+}
+
+// identifySoftware - Matches the version string, and otherwise the probe responses, to the server software
+func identifySoftware(version string, responses map[string]*dns.Msg) (string, string) {
+	if version != "" {
+		for _, s := range softwarePatterns() {
+			if s.re.MatchString(version) {
+				return s.software, versionNumber.FindString(version)
+			}
+		}
+	}
+
+	answered := func(label string) bool {
+		return chaosText(responses[label]) != ""
+	}
+	rcode := func(label string) int {
+		if r, found := responses[label]; found {
+			return r.Rcode
+		}
+		return -1
+	}
+
+	switch {
+	case answered("authors.bind"):
+		// Only BIND populates the list of authors
+		return "ISC BIND", ""
+	case answered("id.server") && rcode("version.bind") == dns.RcodeRefused:
+		return "NSD", ""
+	case rcode("iquery") == dns.RcodeNotImplemented && rcode("version.bind") == dns.RcodeServerFailure:
+		return "Microsoft DNS", ""
+	case len(responses) == 0:
+		return "", ""
+	}
+	return "unknown", ""
+}
+
+func chaosMessage(name string) *dns.Msg {
+	m := QueryMessage(name, dns.TypeTXT)
+
+	m.RecursionDesired = false
+	m.Question[0].Qclass = dns.ClassCHAOS
+	m.Extra = nil
+	return m
+}
+
+// chaosText - Returns the TXT data from the answer to a CHAOS class probe
+func chaosText(r *dns.Msg) string {
+	if r == nil || r.Rcode != dns.RcodeSuccess {
+		return ""
+	}
+
+	for _, a := range r.Answer {
+		if t, ok := a.(*dns.TXT); ok {
+			return strings.TrimSpace(strings.Join(t.Txt, " "))
+		}
+	}
+	return ""
+}
+
+// serverExchange - Sends the message to the server and returns the response
+func serverExchange(server string, msg *dns.Msg) (*dns.Msg, error) {
+	conn, err := dialResolver(context.Background(), "udp", server)
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to create UDP connection to %s: %v", server, err)
+	}
+	defer conn.Close()
+
+	co := newConn(conn)
+	co.SetWriteDeadline(time.Now().Add(1 * time.Second))
+	if err = writeQuery(co, msg); err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to write query msg: %w", err)
+	}
+
+	co.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r, err := readMsg(co, msg)
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to read query response: %v", err)
+	}
+	return completeExchange(conn, msg, r), nil
+}
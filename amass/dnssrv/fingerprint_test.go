@@ -0,0 +1,68 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestFingerprintIdentifySoftware(t *testing.T) {
+	txt := func(data string) *dns.Msg {
+		m := new(dns.Msg)
+		m.Answer = append(m.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: "version.bind.", Rrtype: dns.TypeTXT, Class: dns.ClassCHAOS},
+			Txt: []string{data},
+		})
+		return m
+	}
+	rcode := func(code int) *dns.Msg {
+		m := new(dns.Msg)
+		m.Rcode = code
+		return m
+	}
+
+	tests := []struct {
+		version   string
+		responses map[string]*dns.Msg
+		software  string
+		release   string
+	}{
+		{"9.11.4-P2-RedHat-9.11.4-9.P2.el7", nil, "ISC BIND", "9.11.4-P2-RedHat-9.11.4-9.P2.el7"},
+		{"PowerDNS Authoritative Server 4.1.8", nil, "PowerDNS", "4.1.8"},
+		{"NSD 4.1.26", nil, "NSD", "4.1.26"},
+		{"Knot DNS 2.7.6", nil, "Knot DNS", "2.7.6"},
+		{"", map[string]*dns.Msg{"authors.bind": txt("Mark Andrews")}, "ISC BIND", ""},
+		{"", map[string]*dns.Msg{
+			"id.server":    txt("ns1"),
+			"version.bind": rcode(dns.RcodeRefused),
+		}, "NSD", ""},
+		{"", map[string]*dns.Msg{"version.bind": rcode(dns.RcodeRefused)}, "unknown", ""},
+		{"", nil, "", ""},
+	}
+
+	for _, test := range tests {
+		software, release := identifySoftware(test.version, test.responses)
+
+		if software != test.software || release != test.release {
+			t.Errorf("Identified %q as %s %s instead of %s %s",
+				test.version, software, release, test.software, test.release)
+		}
+	}
+}
+
+func TestAddSoftwarePattern(t *testing.T) {
+	defer func() { addedPatterns = nil }()
+
+	if err := AddSoftwarePattern(`(?i)gdnsd`, "gdnsd"); err != nil {
+		t.Fatalf("The pattern was not added: %v", err)
+	}
+	if err := AddSoftwarePattern(`(`, "invalid"); err == nil {
+		t.Errorf("The invalid pattern was accepted")
+	}
+	if software, release := identifySoftware("gdnsd 3.2.1", nil); software != "gdnsd" || release != "3.2.1" {
+		t.Errorf("Identified the version as %s %s", software, release)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The most referrals and CNAMEs followed while resolving one name
+	maxIterations = 24
+
+	// The shortest time that a delegation is kept in the cache
+	minDelegationTTL = 5 * time.Minute
+)
+
+// RootServers - The addresses of the root name servers where the iterative resolution starts
+var RootServers = []string{
+	"198.41.0.4:53",     // a.root-servers.net
+	"199.9.14.201:53",   // b.root-servers.net
+	"192.33.4.12:53",    // c.root-servers.net
+	"199.7.91.13:53",    // d.root-servers.net
+	"192.203.230.10:53", // e.root-servers.net
+	"192.5.5.241:53",    // f.root-servers.net
+	"192.112.36.4:53",   // g.root-servers.net
+	"198.97.190.53:53",  // h.root-servers.net
+	"192.36.148.17:53",  // i.root-servers.net
+	"192.58.128.30:53",  // j.root-servers.net
+	"193.0.14.129:53",   // k.root-servers.net
+	"199.7.83.42:53",    // l.root-servers.net
+	"202.12.27.33:53",   // m.root-servers.net
+}
+
+// ErrIterationLimit - Returned when the delegations or aliases of a name do not end
+var ErrIterationLimit = errors.New("DNS error: Too many referrals were followed for the name")
+
+// delegation - The name servers that a zone was delegated to
+type delegation struct {
+	servers []string
+	expires time.Time
+}
+
+var (
+	// Set to one while names are resolved by walking the delegations from the roots
+	iterative int32
+
+	// Set to one while only one more label is revealed to each server in the path
+	minimizing int32
+
+	// The delegations learned from the referrals, keyed by zone
+	delegations     = make(map[string]*delegation)
+	delegationsLock sync.Mutex
+)
+
+// SetIterativeResolution - Resolves the names by walking the delegations from the root servers
+// instead of sending the queries to recursive resolvers. The QNAME minimization option limits
+// each server in the path to learning one more label of the name
+func SetIterativeResolution(enable, qnameMinimization bool) {
+	var v, m int32
+	if enable {
+		v = 1
+		if qnameMinimization {
+			m = 1
+		}
+	}
+	atomic.StoreInt32(&iterative, v)
+	atomic.StoreInt32(&minimizing, m)
+}
+
+func iterativeMode() bool {
+	return atomic.LoadInt32(&iterative) == 1
+}
+
+// resolveIterative - Obtains the answers of the type from the authoritative servers for the name
+func resolveIterative(name string, qtype uint16) ([]core.DNSAnswer, error) {
+	r, err := iterate(name, qtype, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNS error: Server returned an error %v", r)
+	}
+
+	var answers []core.DNSAnswer
+	for _, a := range ExtractAnswers(r, qtype) {
+		a.Name = utils.CopyString(name)
+		a.Data = strings.TrimSpace(a.Data)
+		answers = append(answers, a)
+	}
+	return answers, nil
+}
+
+// iterate - Follows the referrals from the closest known zone until a server answers
+// authoritatively, and continues with the target of any alias that was returned
+func iterate(name string, qtype uint16, depth int) (*dns.Msg, error) {
+	name = strings.ToLower(dns.Fqdn(name))
+	zone, servers := closestDelegation(name)
+
+	for ; depth < maxIterations; depth++ {
+		qname, qt := name, qtype
+		if atomic.LoadInt32(&minimizing) == 1 {
+			if next := nextMinimizedName(name, zone); next != name {
+				qname, qt = next, dns.TypeNS
+			}
+		}
+
+		r, err := queryServers(servers, qname, qt)
+		if err != nil {
+			return nil, err
+		}
+
+		if child, ns, ttl := referral(r, zone, qname); child != "" {
+			addrs := delegationAddrs(ns, r, depth)
+			if len(addrs) == 0 {
+				return nil, fmt.Errorf("DNS error: No addresses were found for the servers of %s", child)
+			}
+			cacheDelegation(child, addrs, ttl)
+			zone, servers = child, addrs
+			continue
+		}
+
+		// The label revealed was not delegated, so the next one is revealed to the same servers
+		if qname != name {
+			if r.Rcode == dns.RcodeNameError {
+				// Nothing exists beneath a name that does not exist
+				return r, nil
+			}
+			zone = qname
+			continue
+		}
+
+		if target := aliasTarget(r, name, qtype); target != "" {
+			cr, err := iterate(target, qtype, depth+1)
+			if err == nil {
+				r.Answer = append(r.Answer, cr.Answer...)
+			}
+		}
+		return r, nil
+	}
+	return nil, ErrIterationLimit
+}
+
+// nextMinimizedName - Returns the name with one more label than the zone
+func nextMinimizedName(name, zone string) string {
+	names := MinimizedNames(name, zone)
+	if len(names) == 0 {
+		return name
+	}
+	return dns.Fqdn(names[0])
+}
+
+// queryServers - Sends the query without recursion to the servers, trying the next
+// server when one does not respond
+func queryServers(servers []string, name string, qtype uint16) (*dns.Msg, error) {
+	var err error
+
+	order := make([]string, len(servers))
+	copy(order, servers)
+	utils.RandomShuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	for _, server := range order {
+		var r *dns.Msg
+
+		r, err = serverQuery(server, name, qtype)
+		if err == nil && r.Rcode != dns.RcodeServerFailure && r.Rcode != dns.RcodeRefused {
+			return r, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("DNS error: %s returned %s", server, dns.RcodeToString[r.Rcode])
+		}
+	}
+	return nil, err
+}
+
+func serverQuery(server, name string, qtype uint16) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, err := dialResolver(ctx, "udp", server)
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to create UDP connection to %s: %v", server, err)
+	}
+	defer conn.Close()
+
+	msg := QueryMessage(strings.TrimSuffix(name, "."), qtype)
+	msg.RecursionDesired = false
+
+	co := newConn(conn)
+	co.SetWriteDeadline(time.Now().Add(1 * time.Second))
+	if err = writeQuery(co, msg); err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to write query msg: %w", err)
+	}
+
+	co.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r, err := readMsg(co, msg)
+	if err != nil {
+		return nil, fmt.Errorf("DNS error: Failed to read query response: %v", err)
+	}
+	return completeExchange(conn, msg, r), nil
+}
+
+// referral - Returns the child zone, its name servers and the TTL when the response
+// delegates the name to servers closer to it than the current zone
+func referral(r *dns.Msg, zone, name string) (string, []string, uint32) {
+	if r.Rcode != dns.RcodeSuccess || len(r.Answer) > 0 {
+		return "", nil, 0
+	}
+
+	var child string
+	var ns []string
+	var ttl uint32
+	for _, rr := range r.Ns {
+		n, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+
+		owner := strings.ToLower(n.Hdr.Name)
+		if !inZone(name, owner) || len(owner) <= len(zone) || !inZone(owner, zone) {
+			continue
+		}
+		if child != "" && owner != child {
+			continue
+		}
+
+		child = owner
+		ns = append(ns, strings.ToLower(n.Ns))
+		ttl = n.Hdr.Ttl
+	}
+	return child, ns, ttl
+}
+
+// delegationAddrs - Returns the addresses of the name servers, using the glue records
+// and resolving the names of the servers that were provided without them
+func delegationAddrs(ns []string, r *dns.Msg, depth int) []string {
+	glue := make(map[string][]string)
+
+	for _, rr := range r.Extra {
+		if a, ok := rr.(*dns.A); ok {
+			owner := strings.ToLower(a.Hdr.Name)
+			glue[owner] = append(glue[owner], net.JoinHostPort(a.A.String(), "53"))
+		}
+	}
+
+	var addrs []string
+	for _, n := range ns {
+		if a, found := glue[n]; found {
+			addrs = append(addrs, a...)
+		}
+	}
+	if len(addrs) > 0 {
+		return addrs
+	}
+
+	for _, n := range ns {
+		r, err := iterate(n, dns.TypeA, depth+1)
+		if err != nil {
+			continue
+		}
+
+		for _, rr := range r.Answer {
+			if a, ok := rr.(*dns.A); ok {
+				addrs = append(addrs, net.JoinHostPort(a.A.String(), "53"))
+			}
+		}
+		if len(addrs) > 0 {
+			break
+		}
+	}
+	return addrs
+}
+
+// aliasTarget - Returns the target of the CNAME answering the name, when the server
+// did not also provide the records of the type requested
+func aliasTarget(r *dns.Msg, name string, qtype uint16) string {
+	if qtype == dns.TypeCNAME {
+		return ""
+	}
+
+	var target string
+	for _, rr := range r.Answer {
+		if rr.Header().Rrtype == qtype {
+			return ""
+		}
+		if c, ok := rr.(*dns.CNAME); ok && strings.EqualFold(c.Hdr.Name, name) {
+			target = strings.ToLower(c.Target)
+		}
+	}
+	return target
+}
+
+// closestDelegation - Returns the deepest cached zone containing the name and its servers
+func closestDelegation(name string) (string, []string) {
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		zone := dns.Fqdn(strings.Join(labels[i:], "."))
+
+		if d, found := delegations[zone]; found {
+			if time.Now().Before(d.expires) {
+				return zone, d.servers
+			}
+			delete(delegations, zone)
+		}
+	}
+	return ".", RootServers
+}
+
+func cacheDelegation(zone string, servers []string, ttl uint32) {
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	d := time.Duration(ttl) * time.Second
+	if d < minDelegationTTL {
+		d = minDelegationTTL
+	}
+	delegations[zone] = &delegation{
+		servers: servers,
+		expires: time.Now().Add(d),
+	}
+}
+
+// inZone - Reports whether the fully qualified name is the zone or beneath it
+func inZone(name, zone string) bool {
+	return zone == "." || name == zone || strings.HasSuffix(name, "."+zone)
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestIterativeReferral(t *testing.T) {
+	r := new(dns.Msg)
+	r.Ns = []dns.RR{
+		testRR(t, "Example.COM. 172800 IN NS a.iana-servers.net."),
+		testRR(t, "example.com. 172800 IN NS b.iana-servers.net."),
+	}
+
+	child, ns, ttl := referral(r, "com.", "www.example.com.")
+	if child != "example.com." || len(ns) != 2 || ttl != 172800 {
+		t.Errorf("referral returned %s %v %d", child, ns, ttl)
+	}
+
+	// A delegation back up to a parent zone must not be followed
+	if child, _, _ := referral(r, "example.com.", "www.example.com."); child != "" {
+		t.Errorf("referral followed the delegation of %s from within the zone", child)
+	}
+
+	// Delegations for other names are not referrals
+	if child, _, _ := referral(r, "com.", "www.example.org."); child != "" {
+		t.Errorf("referral returned %s for a name outside of the zone", child)
+	}
+
+	r.Answer = []dns.RR{testRR(t, "www.example.com. 300 IN A 192.0.2.1")}
+	if child, _, _ := referral(r, "com.", "www.example.com."); child != "" {
+		t.Errorf("referral treated an answer as a delegation to %s", child)
+	}
+}
+
+func TestIterativeDelegationAddrs(t *testing.T) {
+	r := new(dns.Msg)
+	r.Extra = []dns.RR{
+		testRR(t, "ns1.example.com. 300 IN A 192.0.2.53"),
+		testRR(t, "ns1.example.com. 300 IN AAAA 2001:db8::53"),
+		testRR(t, "unrelated.example.net. 300 IN A 192.0.2.99"),
+	}
+
+	addrs := delegationAddrs([]string{"ns1.example.com."}, r, 0)
+	if len(addrs) != 1 || addrs[0] != "192.0.2.53:53" {
+		t.Errorf("delegationAddrs returned %v, expected the glue address", addrs)
+	}
+}
+
+func TestIterativeAliasTarget(t *testing.T) {
+	r := new(dns.Msg)
+	r.Answer = []dns.RR{testRR(t, "www.example.com. 300 IN CNAME web.example.net.")}
+
+	if target := aliasTarget(r, "www.example.com.", dns.TypeA); target != "web.example.net." {
+		t.Errorf("aliasTarget returned %q, expected web.example.net.", target)
+	}
+	if target := aliasTarget(r, "www.example.com.", dns.TypeCNAME); target != "" {
+		t.Errorf("aliasTarget followed the alias when the CNAME itself was requested")
+	}
+
+	r.Answer = append(r.Answer, testRR(t, "web.example.net. 300 IN A 192.0.2.1"))
+	if target := aliasTarget(r, "www.example.com.", dns.TypeA); target != "" {
+		t.Errorf("aliasTarget followed the alias when the records were already provided")
+	}
+}
+
+func TestIterativeDelegationCache(t *testing.T) {
+	defer func() {
+		delegationsLock.Lock()
+		delegations = make(map[string]*delegation)
+		delegationsLock.Unlock()
+	}()
+
+	if zone, servers := closestDelegation("www.example.com."); zone != "." || len(servers) != len(RootServers) {
+		t.Errorf("closestDelegation did not start from the root servers")
+	}
+
+	cacheDelegation("com.", []string{"192.0.2.1:53"}, 0)
+	cacheDelegation("example.com.", []string{"192.0.2.2:53"}, 3600)
+
+	if zone, servers := closestDelegation("www.example.com."); zone != "example.com." || servers[0] != "192.0.2.2:53" {
+		t.Errorf("closestDelegation returned %s %v, expected example.com.", zone, servers)
+	}
+	if zone, _ := closestDelegation("www.example2.com."); zone != "com." {
+		t.Errorf("closestDelegation returned %s, expected com.", zone)
+	}
+}
+
+func TestIterativeMinimizedName(t *testing.T) {
+	if next := nextMinimizedName("a.b.example.com.", "."); next != "com." {
+		t.Errorf("nextMinimizedName returned %s from the root, expected com.", next)
+	}
+	if next := nextMinimizedName("a.b.example.com.", "example.com."); next != "b.example.com." {
+		t.Errorf("nextMinimizedName returned %s, expected b.example.com.", next)
+	}
+}
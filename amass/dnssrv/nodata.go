@@ -0,0 +1,51 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+// DefaultNoDataTypes - The record types queried for names that exist without addresses
+var DefaultNoDataTypes = []string{"MX", "NS", "SRV"}
+
+// probeNoDataTypes - Queries the other record types for a name that exists without
+// addresses, so infrastructure names such as mail domains are not discarded
+func (ds *DNSService) probeNoDataTypes(name string) []core.DNSAnswer {
+	names := ds.Config().NoDataTypes
+	if len(names) == 0 {
+		names = DefaultNoDataTypes
+	}
+	types, err := ParseQueryTypes(names)
+	if err != nil {
+		ds.Config().Log.Print(err)
+		return nil
+	}
+
+	queried := ds.queryTypes()
+
+	var answers []core.DNSAnswer
+	for _, t := range types {
+		if containsType(queried, t) {
+			continue
+		}
+
+		if a, err, _ := executeQuery(name, t); err == nil {
+			answers = append(answers, a...)
+		}
+	}
+	return answers
+}
+
+// addressAnswers - Reports whether the answers lead to addresses for the name
+func addressAnswers(answers []core.DNSAnswer) bool {
+	for _, a := range answers {
+		switch uint16(a.Type) {
+		case dns.TypeA, dns.TypeAAAA, dns.TypeCNAME:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+// startNoDataServer - Answers NOERROR without records for the queries of empty.example.com,
+// which only owns an MX record, and NXDOMAIN for the names that do not exist
+func startNoDataServer(t *testing.T) (string, func()) {
+	srv, err := amasstest.NewDNSServer("empty.example.com. 300 IN MX 10 mail.example.net.")
+	if err != nil {
+		t.Fatalf("Failed to start the DNS server: %v", err)
+	}
+	return srv.Addr(), func() { srv.Close() }
+}
+
+func TestNoDataExists(t *testing.T) {
+	addr, stop := startNoDataServer(t)
+	defer stop()
+
+	saved := CustomResolvers
+	CustomResolvers = []string{addr}
+	defer func() { CustomResolvers = saved }()
+
+	l := log.New(ioutil.Discard, "", 0)
+	if answers, exists := resolveNameTypes("empty.example.com", InitialQueryTypes, l); len(answers) != 0 || !exists {
+		t.Errorf("The NODATA name returned %d answers and exists %v", len(answers), exists)
+	}
+	if _, exists := resolveNameTypes("missing.example.com", InitialQueryTypes, l); exists {
+		t.Errorf("The NXDOMAIN name was reported to exist")
+	}
+
+	ds := &DNSService{}
+	if ds.noDataWildcard("empty.example.com") {
+		t.Errorf("The zone was reported to answer NOERROR for names that cannot exist")
+	}
+	done := make(chan struct{})
+	close(done)
+	noDataZonesLock.Lock()
+	noDataZones["wild.example.com"] = &noDataCheck{done: done, wildcard: true}
+	noDataZonesLock.Unlock()
+	if !ds.noDataWildcard("empty.wild.example.com") {
+		t.Errorf("The cached NOERROR wildcard was not reported")
+	}
+}
+
+func TestNoDataAddressAnswers(t *testing.T) {
+	tests := []struct {
+		types    []uint16
+		expected bool
+	}{
+		{nil, false},
+		{[]uint16{dns.TypeTXT, dns.TypeMX}, false},
+		{[]uint16{dns.TypeTXT, dns.TypeA}, true},
+		{[]uint16{dns.TypeCNAME}, true},
+	}
+
+	for _, test := range tests {
+		var answers []core.DNSAnswer
+		for _, t := range test.types {
+			answers = append(answers, core.DNSAnswer{Type: int(t)})
+		}
+
+		if got := addressAnswers(answers); got != test.expected {
+			t.Errorf("addressAnswers(%v) returned %v, expected %v", test.types, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+var (
+	// ControlNames - Names that an unfiltered resolver answers with public addresses
+	ControlNames = []string{"www.google.com", "www.wikipedia.org"}
+
+	// ControlZone - The zone beneath which the unlikely names used as probes should not exist
+	ControlZone = "example.com"
+
+	// The resolvers found returning filtered answers, and those left to send queries to
+	filtered     = make(map[string]error)
+	usable       []string
+	filteredLock sync.Mutex
+)
+
+// CheckResolverPolicy - Sends the control name probes to the resolver and returns an error
+// describing the filtering when the answers were rewritten by a response policy. Nil is
+// returned when the resolver could not be reached, since the health checks report that
+func CheckResolverPolicy(resolver string) error {
+	for _, name := range ControlNames {
+		answers, err := resolverExchange(resolver, name, dns.TypeA)
+		if err != nil {
+			continue
+		}
+
+		for _, a := range answers {
+			if uint16(a.Type) != dns.TypeA {
+				continue
+			}
+			if sinkholeAddress(a.Data) {
+				return fmt.Errorf("DNS error: %s answered the control name %s with the blocked address %s",
+					resolver, name, a.Data)
+			}
+		}
+	}
+
+	name := unlikelyName(ControlZone)
+	if name == "" {
+		return nil
+	}
+	answers, err := resolverExchange(resolver, name, dns.TypeA)
+	if err != nil {
+		return nil
+	}
+	for _, a := range answers {
+		if uint16(a.Type) == dns.TypeA {
+			return fmt.Errorf("DNS error: %s answered the nonexistent name %s with the address %s",
+				resolver, name, a.Data)
+		}
+	}
+	return nil
+}
+
+// FilterResolvers - Probes the current resolvers in parallel and stops sending queries to
+// those returning filtered answers. The excluded resolvers are returned with the reasons.
+// When every resolver is filtered, the queries continue to be sent to all of them
+func FilterResolvers() map[string]error {
+	resolvers := CurrentResolvers()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	results := make(map[string]error)
+	for _, r := range resolvers {
+		wg.Add(1)
+
+		go func(resolver string) {
+			defer wg.Done()
+
+			if err := CheckResolverPolicy(resolver); err != nil {
+				lock.Lock()
+				results[resolver] = err
+				lock.Unlock()
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	var clean []string
+	for _, r := range resolvers {
+		if _, found := results[r]; !found {
+			clean = append(clean, r)
+		}
+	}
+
+	filteredLock.Lock()
+	defer filteredLock.Unlock()
+
+	filtered = results
+	usable = clean
+	if len(usable) == 0 {
+		usable = nil
+	}
+	return results
+}
+
+// ResolverFiltered - Returns the reason that the resolver was excluded, or nil
+func ResolverFiltered(resolver string) error {
+	filteredLock.Lock()
+	defer filteredLock.Unlock()
+
+	return filtered[resolver]
+}
+
+// usableResolvers - Returns the current resolvers that were not excluded by the probes
+func usableResolvers() []string {
+	filteredLock.Lock()
+	defer filteredLock.Unlock()
+
+	if len(usable) > 0 {
+		return usable
+	}
+	return CurrentResolvers()
+}
+
+// sinkholeAddress - Reports whether the address is one that blocked names are commonly
+// answered with, such as 0.0.0.0, a loopback address or a private network address
+func sinkholeAddress(addr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return false
+	}
+	if ip.IsUnspecified() || ip.IsLoopback() {
+		return true
+	}
+
+	kind, _ := utils.ReservedAddress(ip.String())
+	return kind != ""
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+	"github.com/miekg/dns"
+)
+
+// startPolicyServer - Answers the control names with the address provided, and the other
+// names beneath the control zone with the walled garden address when it is not empty
+func startPolicyServer(t *testing.T, control, garden string) (string, func()) {
+	var records []string
+	for _, name := range ControlNames {
+		records = append(records, dns.Fqdn(name)+" 300 IN A "+control)
+	}
+	if garden != "" {
+		records = append(records, "*."+dns.Fqdn(ControlZone)+" 300 IN A "+garden)
+	}
+
+	srv, err := amasstest.NewDNSServer(records...)
+	if err != nil {
+		t.Fatalf("Failed to start the DNS server: %v", err)
+	}
+	return srv.Addr(), func() { srv.Close() }
+}
+
+func resetResolverFilter() {
+	filteredLock.Lock()
+	defer filteredLock.Unlock()
+
+	filtered = make(map[string]error)
+	usable = nil
+}
+
+func TestFilterResolvers(t *testing.T) {
+	clean, stopClean := startPolicyServer(t, "93.184.216.34", "")
+	defer stopClean()
+	sinkhole, stopSinkhole := startPolicyServer(t, "0.0.0.0", "")
+	defer stopSinkhole()
+	garden, stopGarden := startPolicyServer(t, "93.184.216.34", "93.184.216.99")
+	defer stopGarden()
+
+	saved := CustomResolvers
+	CustomResolvers = []string{clean, sinkhole, garden}
+	defer func() {
+		CustomResolvers = saved
+		resetResolverFilter()
+	}()
+
+	excluded := FilterResolvers()
+	if len(excluded) != 2 {
+		t.Errorf("%d resolvers were excluded instead of two: %v", len(excluded), excluded)
+	}
+	for _, r := range []string{sinkhole, garden} {
+		if ResolverFiltered(r) == nil {
+			t.Errorf("The filtering resolver %s was not excluded", r)
+		}
+	}
+	if err := ResolverFiltered(clean); err != nil {
+		t.Errorf("The clean resolver was excluded: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if r := NextResolverAddress(); r != clean {
+			t.Errorf("The query was sent to the excluded resolver %s", r)
+		}
+	}
+}
+
+func TestFilterResolversAllFiltered(t *testing.T) {
+	sinkhole, stop := startPolicyServer(t, "127.0.0.1", "")
+	defer stop()
+
+	saved := CustomResolvers
+	CustomResolvers = []string{sinkhole}
+	defer func() {
+		CustomResolvers = saved
+		resetResolverFilter()
+	}()
+
+	if excluded := FilterResolvers(); len(excluded) != 1 {
+		t.Errorf("The sinkhole resolver was not reported")
+	}
+	// The queries are still sent when no other resolver is available
+	if r := NextResolverAddress(); r != sinkhole {
+		t.Errorf("The query was sent to %s instead of the only resolver", r)
+	}
+}
+
+func TestSinkholeAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"0.0.0.0", true},
+		{"127.0.0.1", true},
+		{"::", true},
+		{"10.10.34.34", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+		{"not an address", false},
+	}
+
+	for _, test := range tests {
+		if got := sinkholeAddress(test.addr); got != test.want {
+			t.Errorf("sinkholeAddress(%q) returned %t, expected %t", test.addr, got, test.want)
+		}
+	}
+}
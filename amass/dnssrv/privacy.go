@@ -0,0 +1,95 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+// ErrCaseMismatch - Returned for responses that did not echo the 0x20 encoded query name,
+// which are likely to have been spoofed by an off-path attacker
+var ErrCaseMismatch = errors.New("DNS error: The response did not echo the case of the query name")
+
+// Set to one while the query names are sent with DNS 0x20 mixed-case encoding
+var caseRandomization int32
+
+// SetCaseRandomization - Sends the query names with the case of each letter randomized,
+// and discards the responses that do not echo the name exactly
+func SetCaseRandomization(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&caseRandomization, v)
+}
+
+func caseRandomized() bool {
+	return atomic.LoadInt32(&caseRandomization) == 1
+}
+
+// encodeName - Applies the 0x20 encoding to the name when it has been enabled
+func encodeName(name string) string {
+	if !caseRandomized() {
+		return name
+	}
+
+	b := []byte(strings.ToLower(name))
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' && utils.RandomInt()&1 == 1 {
+			b[i] = c - ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// verifyCase - Checks that the response echoed the query name exactly, and then folds the
+// owner names to lower case for the code examining the records
+func verifyCase(msg, r *dns.Msg) error {
+	if !caseRandomized() || len(msg.Question) == 0 {
+		return nil
+	}
+
+	if len(r.Question) == 0 || r.Question[0].Name != msg.Question[0].Name {
+		return ErrCaseMismatch
+	}
+
+	r.Question[0].Name = strings.ToLower(r.Question[0].Name)
+	for _, section := range [][]dns.RR{r.Answer, r.Ns, r.Extra} {
+		for _, rr := range section {
+			rr.Header().Name = strings.ToLower(rr.Header().Name)
+		}
+	}
+	return nil
+}
+
+// MinimizedNames - Returns the names queried while iterating down from the zone with QNAME
+// minimization, revealing one more label of the name to each server in the delegation path
+func MinimizedNames(name, zone string) []string {
+	name = strings.Trim(strings.ToLower(name), ".")
+	zone = strings.Trim(strings.ToLower(zone), ".")
+
+	if zone != "" && name != zone && !strings.HasSuffix(name, "."+zone) {
+		return []string{name}
+	}
+
+	labels := strings.Split(name, ".")
+	var skip int
+	if zone != "" {
+		skip = len(strings.Split(zone, "."))
+	}
+
+	var names []string
+	for i := len(labels) - skip - 1; i >= 0; i-- {
+		names = append(names, strings.Join(labels[i:], "."))
+	}
+	if len(names) == 0 {
+		names = []string{name}
+	}
+	return names
+}
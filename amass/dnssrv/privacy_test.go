@@ -0,0 +1,63 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestPrivacyCaseRandomization(t *testing.T) {
+	SetCaseRandomization(true)
+	defer SetCaseRandomization(false)
+
+	name := "www.example.com"
+	msg := QueryMessage(strings.Repeat("abcdefgh", 4)+"."+name, dns.TypeA)
+	qname := msg.Question[0].Name
+
+	if qname == strings.ToLower(qname) {
+		t.Errorf("The query name %s was not mixed-case encoded", qname)
+	}
+
+	spoofed := new(dns.Msg)
+	spoofed.SetReply(msg)
+	spoofed.Question[0].Name = strings.ToLower(qname)
+	if err := verifyCase(msg, spoofed); err != ErrCaseMismatch {
+		t.Errorf("verifyCase accepted a response that did not echo the case")
+	}
+
+	r := new(dns.Msg)
+	r.SetReply(msg)
+	rr, _ := dns.NewRR(qname + " 300 IN A 192.0.2.1")
+	r.Answer = append(r.Answer, rr)
+	if err := verifyCase(msg, r); err != nil {
+		t.Errorf("verifyCase rejected a response that echoed the case: %v", err)
+	}
+	if owner := r.Answer[0].Header().Name; owner != strings.ToLower(qname) {
+		t.Errorf("verifyCase did not fold the owner name %s to lower case", owner)
+	}
+}
+
+func TestPrivacyMinimizedNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		expected []string
+	}{
+		{"a.b.example.com", "com", []string{"example.com", "b.example.com", "a.b.example.com"}},
+		{"a.b.example.com.", "example.com", []string{"b.example.com", "a.b.example.com"}},
+		{"www.example.com", "", []string{"com", "example.com", "www.example.com"}},
+		{"example.com", "example.com", []string{"example.com"}},
+		{"www.example.org", "example.com", []string{"www.example.org"}},
+	}
+
+	for _, test := range tests {
+		names := MinimizedNames(test.name, test.zone)
+		if strings.Join(names, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("MinimizedNames(%s, %s) returned %v, expected %v", test.name, test.zone, names, test.expected)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+// ParseQueryTypes - Returns the record types for the names, such as MX or SRV
+func ParseQueryTypes(names []string) ([]uint16, error) {
+	var types []uint16
+
+	for _, n := range names {
+		t, err := textToTypeNum(strings.ToUpper(strings.TrimSpace(n)))
+		if err != nil {
+			return nil, err
+		}
+		if !containsType(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// ConfiguredQueryTypes - Returns the record types queried for each name by the configuration
+func ConfiguredQueryTypes(config *core.AmassConfig) []uint16 {
+	if len(config.QueryTypes) == 0 {
+		return InitialQueryTypes
+	}
+
+	types, err := ParseQueryTypes(config.QueryTypes)
+	if err != nil || len(types) == 0 {
+		return InitialQueryTypes
+	}
+	return types
+}
+
+func (ds *DNSService) queryTypes() []uint16 {
+	return ConfiguredQueryTypes(ds.Config())
+}
+
+// resolveNameTypes - Queries for each of the record types in parallel, since ANY queries
+// are refused or answered partially by most servers, and merges the answers. It also reports
+// whether any of the responses showed that the name exists, even without records
+func resolveNameTypes(name string, types []uint16, log *log.Logger) ([]core.DNSAnswer, bool) {
+	var wg sync.WaitGroup
+
+	results := make([][]core.DNSAnswer, len(types))
+	found := make([]bool, len(types))
+	for i, t := range types {
+		wg.Add(1)
+
+		go func(i int, qtype uint16) {
+			defer wg.Done()
+
+			results[i], found[i] = resolveType(name, qtype, log)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var exists bool
+	for _, f := range found {
+		if f {
+			exists = true
+			break
+		}
+	}
+	return mergeAnswers(results...), exists
+}
+
+// resolveType - Queries for the record type, trying again when the resolver did not respond
+func resolveType(name string, qtype uint16, log *log.Logger) ([]core.DNSAnswer, bool) {
+	tries := 3
+	if qtype == dns.TypeTXT {
+		tries = 10
+	}
+
+	for i := 0; i < tries; i++ {
+		a, err, again := executeQuery(name, qtype)
+		if err == nil {
+			return a, true
+		}
+		log.Print(err)
+		if !again {
+			break
+		}
+	}
+	return nil, false
+}
+
+// mergeAnswers - Combines the answers in order, dropping records seen in an earlier answer,
+// such as the CNAME that is returned with the answers for several types
+func mergeAnswers(sets ...[]core.DNSAnswer) []core.DNSAnswer {
+	var merged []core.DNSAnswer
+	seen := make(map[string]struct{})
+
+	for _, set := range sets {
+		for _, a := range set {
+			key := strings.Join([]string{strings.ToLower(a.Name),
+				dns.TypeToString[uint16(a.Type)], strings.ToLower(a.Data)}, " ")
+			if _, dup := seen[key]; dup {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			merged = append(merged, a)
+		}
+	}
+	return merged
+}
+
+func containsType(types []uint16, qtype uint16) bool {
+	for _, t := range types {
+		if t == qtype {
+			return true
+		}
+	}
+	return false
+}
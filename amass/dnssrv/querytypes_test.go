@@ -0,0 +1,50 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+func TestQueryTypesParse(t *testing.T) {
+	types, err := ParseQueryTypes([]string{"mx", " SRV ", "NS", "MX"})
+	if err != nil || len(types) != 3 || types[0] != dns.TypeMX || types[1] != dns.TypeSRV {
+		t.Errorf("ParseQueryTypes returned %v, %v", types, err)
+	}
+
+	if _, err := ParseQueryTypes([]string{"BOGUS"}); err == nil {
+		t.Errorf("ParseQueryTypes accepted an unsupported record type")
+	}
+}
+
+func TestQueryTypesConfigured(t *testing.T) {
+	if types := ConfiguredQueryTypes(&core.AmassConfig{}); len(types) != len(InitialQueryTypes) {
+		t.Errorf("The default configuration queried %v", types)
+	}
+
+	types := ConfiguredQueryTypes(&core.AmassConfig{QueryTypes: []string{"A", "MX"}})
+	if len(types) != 2 || types[0] != dns.TypeA || types[1] != dns.TypeMX {
+		t.Errorf("The configured types were returned as %v", types)
+	}
+}
+
+func TestQueryTypesMerge(t *testing.T) {
+	cname := core.DNSAnswer{Name: "www.example.com", Type: int(dns.TypeCNAME), Data: "web.example.com."}
+
+	merged := mergeAnswers(
+		[]core.DNSAnswer{cname, {Name: "www.example.com", Type: int(dns.TypeA), Data: "192.0.2.1"}},
+		[]core.DNSAnswer{cname, {Name: "www.example.com", Type: int(dns.TypeAAAA), Data: "2001:db8::1"}},
+		nil,
+		[]core.DNSAnswer{{Name: "WWW.example.com", Type: int(dns.TypeCNAME), Data: "Web.Example.com."}},
+	)
+	if len(merged) != 3 {
+		t.Errorf("mergeAnswers returned %d answers, expected 3: %v", len(merged), merged)
+	}
+	if merged[0].Type != int(dns.TypeCNAME) || merged[2].Type != int(dns.TypeAAAA) {
+		t.Errorf("mergeAnswers did not keep the order of the answers: %v", merged)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
+)
+
+// ErrReplaying - Returned for connections that cannot be answered from a recording
+var ErrReplaying = errors.New("Network access is disabled while replaying a recorded run")
+
+// interactionKey - Identifies the DNS query within a recording
+func interactionKey(msg *dns.Msg) string {
+	if len(msg.Question) == 0 {
+		return ""
+	}
+
+	q := msg.Question[0]
+	return strings.ToLower(q.Name) + " " + dns.TypeToString[q.Qtype]
+}
+
+// recordingConn - Saves the DNS messages exchanged over a UDP connection
+type recordingConn struct {
+	net.Conn
+	sync.Mutex
+	key string
+}
+
+func (rc *recordingConn) Write(b []byte) (int, error) {
+	msg := new(dns.Msg)
+	if err := msg.Unpack(b); err == nil {
+		rc.Lock()
+		rc.key = interactionKey(msg)
+		rc.Unlock()
+	}
+	return rc.Conn.Write(b)
+}
+
+func (rc *recordingConn) Read(b []byte) (int, error) {
+	n, err := rc.Conn.Read(b)
+	if err != nil {
+		return n, err
+	}
+
+	rc.Lock()
+	key := rc.key
+	rc.Unlock()
+
+	if key != "" {
+		body := make([]byte, n)
+		copy(body, b[:n])
+
+		utils.RecordInteraction(&utils.Interaction{
+			Kind: utils.InteractionDNS,
+			Key:  key,
+			Body: body,
+		})
+	}
+	return n, err
+}
+
+// replayConn - Answers the DNS queries written to it from the recording
+type replayConn struct {
+	sync.Mutex
+	responses [][]byte
+}
+
+func (rc *replayConn) Write(b []byte) (int, error) {
+	msg := new(dns.Msg)
+	if err := msg.Unpack(b); err != nil {
+		return 0, err
+	}
+
+	i, found := utils.ReplayInteraction(utils.InteractionDNS, interactionKey(msg))
+	if !found {
+		return len(b), nil
+	}
+
+	r := new(dns.Msg)
+	if err := r.Unpack(i.Body); err != nil {
+		return len(b), nil
+	}
+	// The response must match the ID and the name case of the new query
+	r.Id = msg.Id
+	r.Question = msg.Question
+	if resp, err := r.Pack(); err == nil {
+		rc.Lock()
+		rc.responses = append(rc.responses, resp)
+		rc.Unlock()
+	}
+	return len(b), nil
+}
+
+func (rc *replayConn) Read(b []byte) (int, error) {
+	rc.Lock()
+	defer rc.Unlock()
+
+	if len(rc.responses) == 0 {
+		return 0, utils.ErrNotRecorded
+	}
+
+	n := copy(b, rc.responses[0])
+	rc.responses = rc.responses[1:]
+	return n, nil
+}
+
+func (rc *replayConn) Close() error                       { return nil }
+func (rc *replayConn) LocalAddr() net.Addr                { return &net.UDPAddr{} }
+func (rc *replayConn) RemoteAddr() net.Addr               { return &net.UDPAddr{} }
+func (rc *replayConn) SetDeadline(t time.Time) error      { return nil }
+func (rc *replayConn) SetReadDeadline(t time.Time) error  { return nil }
+func (rc *replayConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// recordableConn - Returns the connection prepared for recording or replaying the DNS queries
+func recordableConn(conn net.Conn) net.Conn {
+	if utils.Recording() {
+		return &recordingConn{Conn: conn}
+	}
+	return conn
+}
@@ -5,11 +5,12 @@ package dnssrv
 
 import (
 	"context"
-	"math/rand"
 	"net"
 	"strings"
+	"sync"
 
 	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
 )
 
 var (
@@ -51,21 +52,77 @@ var (
 	}
 
 	CustomResolvers = []string{}
+
+	// The shuffled order of the resolvers left in the current round
+	rotation     []string
+	rotating     bool
+	rotationLock sync.Mutex
 )
 
+// CurrentResolvers - Returns the resolvers that queries are being sent to
+func CurrentResolvers() []string {
+	if len(CustomResolvers) > 0 {
+		return CustomResolvers
+	}
+	return PublicResolvers
+}
+
+// SpreadResolverQueries - Sends one query to every resolver, in a new shuffled order each
+// round, instead of picking the resolvers at random. Each resolver then sees an even share
+// of the queries and none of them receives a long run of consecutive names
+func SpreadResolverQueries(enable bool) {
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	rotating = enable
+	rotation = nil
+}
+
 // NextResolverAddress - Requests the next server
 func NextResolverAddress() string {
-	resolvers := PublicResolvers
-	if len(CustomResolvers) > 0 {
-		resolvers = CustomResolvers
+	resolvers := usableResolvers()
+
+	rotationLock.Lock()
+	if rotating {
+		if len(rotation) == 0 {
+			rotation = make([]string, len(resolvers))
+			copy(rotation, resolvers)
+			utils.RandomShuffle(len(rotation), func(i, j int) {
+				rotation[i], rotation[j] = rotation[j], rotation[i]
+			})
+		}
+
+		next := rotation[0]
+		rotation = rotation[1:]
+		rotationLock.Unlock()
+		return next
 	}
+	rotationLock.Unlock()
 
-	rnd := rand.Int()
+	rnd := utils.RandomInt()
 	idx := rnd % len(resolvers)
 	return resolvers[idx]
 }
 
 func SetCustomResolvers(resolvers []string) {
+	CustomResolvers = utils.UniqueAppend(CustomResolvers, resolverAddresses(resolvers)...)
+}
+
+// ConfiguredResolvers - Returns the resolvers that queries would be sent to once the resolvers
+// provided are set, without changing the resolvers in use
+func ConfiguredResolvers(resolvers []string) []string {
+	custom := utils.UniqueAppend(append([]string(nil), CustomResolvers...), resolverAddresses(resolvers)...)
+
+	if len(custom) > 0 {
+		return custom
+	}
+	return PublicResolvers
+}
+
+// resolverAddresses - Adds the DNS port to the resolvers provided without one
+func resolverAddresses(resolvers []string) []string {
+	var addrs []string
+
 	for _, r := range resolvers {
 		addr := r
 
@@ -73,23 +130,64 @@ func SetCustomResolvers(resolvers []string) {
 		if len(parts) == 1 && parts[0] == addr {
 			addr += ":53"
 		}
-
-		CustomResolvers = utils.UniqueAppend(CustomResolvers, addr)
+		addrs = append(addrs, addr)
 	}
+	return addrs
+}
+
+// CheckResolver - Sends a query for the root zone name servers to the resolver
+func CheckResolver(resolver string) error {
+	_, err := resolverExchange(resolver, ".", dns.TypeNS)
+	return err
 }
 
 func DNSDialContext(ctx context.Context, network, address string) (net.Conn, error) {
-	d := &net.Dialer{}
+	return dialResolver(ctx, network, NextResolverAddress())
+}
+
+// dialResolver - Connects to the resolver, unless the queries are answered from a recording
+func dialResolver(ctx context.Context, network, resolver string) (net.Conn, error) {
+	if utils.Replaying() {
+		return &replayConn{}, nil
+	}
 
-	return d.DialContext(ctx, network, NextResolverAddress())
+	d := newDialer(network, resolver)
+	conn, err := d.DialContext(ctx, network, resolver)
+	if err != nil {
+		return nil, err
+	}
+	return recordableConn(conn), nil
 }
 
 func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
-	d := &net.Dialer{
-		Resolver: &net.Resolver{
-			PreferGo: true,
-			Dial:     DNSDialContext,
+	if utils.Replaying() {
+		return nil, ErrReplaying
+	}
+
+	d := newDialer(network, address)
+	d.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			resolver := NextResolverAddress()
+
+			conn, err := newDialer(network, resolver).DialContext(ctx, network, resolver)
+			if err != nil {
+				return nil, err
+			}
+			return &queryConn{Conn: conn}, nil
 		},
 	}
 	return d.DialContext(ctx, network, address)
 }
+
+// queryConn - Counts each message the Go resolver writes against the DNS query limit
+type queryConn struct {
+	net.Conn
+}
+
+func (c *queryConn) Write(b []byte) (int, error) {
+	if !reserveQuery() {
+		return 0, ErrQueryLimit
+	}
+	return c.Conn.Write(b)
+}
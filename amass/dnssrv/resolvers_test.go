@@ -20,3 +20,24 @@ func TestResolversPublicResolvers(t *testing.T) {
 	}
 	CustomResolvers = []string{}
 }
+
+func TestResolversSpreadQueries(t *testing.T) {
+	saved := CustomResolvers
+	defer func() {
+		CustomResolvers = saved
+		SpreadResolverQueries(false)
+	}()
+
+	CustomResolvers = []string{"10.0.0.1:53", "10.0.0.2:53", "10.0.0.3:53"}
+	SpreadResolverQueries(true)
+
+	counts := make(map[string]int)
+	for i := 0; i < 3*len(CustomResolvers); i++ {
+		counts[NextResolverAddress()]++
+	}
+	for _, r := range CustomResolvers {
+		if counts[r] != 3 {
+			t.Errorf("%s received %d queries, expected an even share of 3", r, counts[r])
+		}
+	}
+}
@@ -107,4 +107,44 @@ var popularSRVRecords = []string{
 	"_nicname._tcp",
 	"_nicname._udp",
 	"_collab-edge._tls",
+	"_sipfederationtls._tcp",
+	"_sipinternal._tcp",
+	"_sipinternaltls._tcp",
+	"_h323cs._tcp",
+	"_h323ls._udp",
+	"_gc._tcp",
+	"_gc._msdcs",
+	"_ldap._tcp.dc._msdcs",
+	"_ldap._tcp.gc._msdcs",
+	"_ldap._tcp.pdc._msdcs",
+	"_kerberos._tcp.dc._msdcs",
+	"_vlmcs._tcp",
+	"_mongodb._tcp",
+	"_ntp._udp",
+	"_snmp._udp",
+	"_telnet._tcp",
+	"_ssh._tcp",
+	"_ftp._tcp",
+	"_nntp._tcp",
+	"_rtsp._tcp",
+	"_rdp._tcp",
+	"_vnc._tcp",
+	"_mysql._tcp",
+	"_postgresql._tcp",
+	"_elasticsearch._tcp",
+	"_etcd-client._tcp",
+	"_etcd-server._tcp",
+	"_etcd-server-ssl._tcp",
+	"_kafka._tcp",
+	"_amqp._tcp",
+	"_mqtt._tcp",
+	"_docker._tcp",
+	"_jenkins._tcp",
+	"_git._tcp",
+	"_svn._tcp",
+	"_turn._udp",
+	"_turn._tcp",
+	"_turns._tcp",
+	"_carddav._tcp",
+	"_carddavs._tcp",
 }
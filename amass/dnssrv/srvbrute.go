@@ -0,0 +1,190 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	evbus "github.com/asaskevich/EventBus"
+)
+
+type SRVBruteService struct {
+	core.BaseAmassService
+
+	bus evbus.Bus
+
+	// Subdomains that have already been checked for service names
+	subdomains map[string]struct{}
+}
+
+func NewSRVBruteService(config *core.AmassConfig, bus evbus.Bus) *SRVBruteService {
+	sbs := &SRVBruteService{
+		bus:        bus,
+		subdomains: make(map[string]struct{}),
+	}
+
+	sbs.BaseAmassService = *core.NewBaseAmassService("SRV Brute Forcing Service", config, sbs)
+	return sbs
+}
+
+func (sbs *SRVBruteService) OnStart() error {
+	sbs.BaseAmassService.OnStart()
+
+	sbs.bus.SubscribeAsync(core.RESOLVED, sbs.SendRequest, false)
+	sbs.bus.SubscribeAsync(core.NEWDOMAIN, sbs.addDomain, false)
+	go sbs.processRequests()
+	sbs.StartWork()
+	go sbs.startRootDomains()
+	return nil
+}
+
+// List - Returns how the service names are resolved
+func (sbs *SRVBruteService) List() string {
+	return "Sends the well-known SRV service names under the root domains and the discovered subdomains to the resolvers"
+}
+
+func (sbs *SRVBruteService) OnPause() error {
+	return nil
+}
+
+func (sbs *SRVBruteService) OnResume() error {
+	return nil
+}
+
+func (sbs *SRVBruteService) OnStop() error {
+	sbs.BaseAmassService.OnStop()
+
+	sbs.bus.Unsubscribe(core.RESOLVED, sbs.SendRequest)
+	sbs.bus.Unsubscribe(core.NEWDOMAIN, sbs.addDomain)
+	return nil
+}
+
+func (sbs *SRVBruteService) processRequests() {
+	t := time.NewTicker(sbs.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
+loop:
+	for {
+		select {
+		case <-t.C:
+			sbs.checkForNewSubdomain()
+		case <-sbs.PauseChan():
+			t.Stop()
+			paused = true
+		case <-sbs.ResumeChan():
+			t = time.NewTicker(sbs.Config().Rates().Frequency)
+			paused = false
+		case <-sbs.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(sbs.Config().Rates().Frequency)
+			}
+		case <-sbs.Quit():
+			break loop
+		}
+	}
+	t.Stop()
+}
+
+func (sbs *SRVBruteService) startRootDomains() {
+	defer sbs.FinishWork()
+
+	if !sbs.Config().SRVBruteForcing {
+		return
+	}
+	// Look at each domain provided by the config
+	for _, domain := range sbs.Config().Domains() {
+		sbs.startDomain(domain)
+	}
+}
+
+// addDomain - Queries the service names under a root domain added during the enumeration
+func (sbs *SRVBruteService) addDomain(domain string) {
+	if sbs.Config().SRVBruteForcing {
+		sbs.startDomain(domain)
+	}
+}
+
+func (sbs *SRVBruteService) startDomain(domain string) {
+	if !sbs.dupSubdomain(domain) {
+		sbs.StartWork()
+		go sbs.queryServiceNames(domain, domain)
+	}
+}
+
+func (sbs *SRVBruteService) checkForNewSubdomain() {
+	req := sbs.NextRequest()
+	if req == nil {
+		return
+	}
+	defer sbs.FinishWork()
+
+	if !sbs.Config().SRVBruteForcing {
+		return
+	}
+
+	labels := strings.Split(req.Name, ".")
+	num := len(labels)
+	// Is this large enough to consider further?
+	if num < 2 {
+		return
+	}
+	// Do not further evaluate service subdomains
+	if strings.HasPrefix(labels[0], "_") || strings.HasPrefix(labels[1], "_") {
+		return
+	}
+	// It cannot have fewer labels than the root domain name
+	if num-1 < len(strings.Split(req.Domain, ".")) {
+		return
+	}
+
+	sub := strings.Join(labels[1:], ".")
+	if !sbs.Config().IsDomainInScope(sub) || sbs.dupSubdomain(sub) {
+		return
+	}
+	sbs.StartWork()
+	go sbs.queryServiceNames(sub, req.Domain)
+}
+
+func (sbs *SRVBruteService) dupSubdomain(sub string) bool {
+	sbs.Lock()
+	defer sbs.Unlock()
+
+	if _, found := sbs.subdomains[sub]; found {
+		return true
+	}
+	sbs.subdomains[sub] = struct{}{}
+	return false
+}
+
+// NumOfServiceNames - Returns the number of SRV names checked for each subdomain
+func NumOfServiceNames() int {
+	return len(popularSRVRecords)
+}
+
+func (sbs *SRVBruteService) queryServiceNames(subdomain, domain string) {
+	defer sbs.FinishWork()
+
+	// Check all the popular SRV records
+	for _, name := range popularSRVRecords {
+		srvName := name + "." + subdomain
+		if !sbs.Config().AllowDNSQueries(NumOfQueries()) {
+			return
+		}
+
+		if a, err := Resolve(srvName, "SRV"); err == nil {
+			sbs.bus.Publish(core.RESOLVED, &core.AmassRequest{
+				Name:    srvName,
+				Domain:  domain,
+				Records: a,
+				Tag:     core.BRUTE,
+				Source:  "SRV Brute Force",
+			})
+		}
+		// Do not go too fast
+		time.Sleep(sbs.Config().Rates().Frequency)
+	}
+}
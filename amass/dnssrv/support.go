@@ -5,9 +5,12 @@ package dnssrv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/OWASP/Amass/amass/core"
@@ -15,12 +18,67 @@ import (
 	"github.com/miekg/dns"
 )
 
+// The number of DNS queries sent to the resolvers and servers
+var numQueries uint64
+
+// The most DNS queries sent before the others are refused (zero for no limit)
+var queryLimit uint64
+
+// ErrQueryLimit - Returned for the DNS queries that would exceed the query limit
+var ErrQueryLimit = errors.New("DNS error: The DNS query limit was reached")
+
+// NumOfQueries - Returns the number of DNS queries sent since the program started
+func NumOfQueries() uint64 {
+	return atomic.LoadUint64(&numQueries)
+}
+
+// SetQueryLimit - Sets the most DNS queries sent since the program started, counting every
+// query, such as the fingerprinting, DNSSEC, decoy, health check and iterative queries
+func SetQueryLimit(max uint64) {
+	atomic.StoreUint64(&queryLimit, max)
+}
+
+// reserveQuery - Counts a query about to be sent, and returns false when the limit was reached
+func reserveQuery() bool {
+	for {
+		n := atomic.LoadUint64(&numQueries)
+		if max := atomic.LoadUint64(&queryLimit); max > 0 && n >= max {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&numQueries, n, n+1) {
+			return true
+		}
+	}
+}
+
+// writeQuery - Sends the query on the connection once it is counted against the query limit
+func writeQuery(co *dns.Conn, msg *dns.Msg) error {
+	if !reserveQuery() {
+		return ErrQueryLimit
+	}
+
+	if err := co.WriteMsg(msg); err != nil {
+		// The query was never sent
+		atomic.AddUint64(&numQueries, ^uint64(0))
+		return err
+	}
+	return nil
+}
+
 func Resolve(name, qtype string) ([]core.DNSAnswer, error) {
 	qt, err := textToTypeNum(qtype)
 	if err != nil {
 		return nil, err
 	}
 
+	if iterativeMode() {
+		ans, err := resolveIterative(name, qt)
+		if err == nil && len(ans) == 0 {
+			err = fmt.Errorf("DNS query for %s, type %d returned 0 records", name, qt)
+		}
+		return ans, err
+	}
+
 	conn, err := DNSDialContext(context.Background(), "udp", "")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to obtain UDP connection to the DNS resolver: %v", err)
@@ -75,7 +133,7 @@ func QueryMessage(name string, qtype uint16) *dns.Msg {
 		Question: make([]dns.Question, 1),
 	}
 	m.Question[0] = dns.Question{
-		Name:   dns.Fqdn(name),
+		Name:   dns.Fqdn(encodeName(name)),
 		Qtype:  qtype,
 		Qclass: uint16(dns.ClassINET),
 	}
@@ -89,8 +147,8 @@ func ExchangeConn(conn net.Conn, name string, qtype uint16) ([]core.DNSAnswer, e
 	var m, r *dns.Msg
 
 	tries := 3
-	if qtype == dns.TypeNS || qtype == dns.TypeMX ||
-		qtype == dns.TypeSOA || qtype == dns.TypeSPF {
+	if qtype == dns.TypeNS || qtype == dns.TypeMX || qtype == dns.TypeSOA ||
+		qtype == dns.TypeSPF || qtype == dns.TypeCAA || qtype == dns.TypeDNSKEY {
 		tries = 7
 	} else if qtype == dns.TypeTXT {
 		tries = 10
@@ -100,14 +158,15 @@ func ExchangeConn(conn net.Conn, name string, qtype uint16) ([]core.DNSAnswer, e
 		m = QueryMessage(name, qtype)
 
 		// Perform the DNS query
-		co := &dns.Conn{Conn: conn}
-		if err = co.WriteMsg(m); err != nil {
-			return nil, fmt.Errorf("DNS error: Failed to write msg to the resolver: %v", err)
+		co := newConn(conn)
+		if err = writeQuery(co, m); err != nil {
+			return nil, fmt.Errorf("DNS error: Failed to write msg to the resolver: %w", err)
 		}
 		// Set the maximum time for receiving the answer
 		co.SetReadDeadline(time.Now().Add(2 * time.Second))
-		r, err = co.ReadMsg()
+		r, err = readMsg(co, m)
 		if err == nil {
+			r = completeExchange(conn, m, r)
 			break
 		}
 	}
@@ -120,13 +179,10 @@ func ExchangeConn(conn net.Conn, name string, qtype uint16) ([]core.DNSAnswer, e
 	}
 
 	var answers []core.DNSAnswer
-	for _, a := range ExtractRawData(r, qtype) {
-		answers = append(answers, core.DNSAnswer{
-			Name: name,
-			Type: int(qtype),
-			TTL:  0,
-			Data: strings.TrimSpace(a),
-		})
+	for _, a := range ExtractAnswers(r, qtype) {
+		a.Name = name
+		a.Data = strings.TrimSpace(a.Data)
+		answers = append(answers, a)
 	}
 
 	if len(answers) == 0 {
@@ -242,6 +298,10 @@ func textToTypeNum(text string) (uint16, error) {
 		qtype = dns.TypeSPF
 	case "SRV":
 		qtype = dns.TypeSRV
+	case "CAA":
+		qtype = dns.TypeCAA
+	case "DNSKEY":
+		qtype = dns.TypeDNSKEY
 	}
 
 	if qtype == 0 {
@@ -253,63 +313,73 @@ func textToTypeNum(text string) (uint16, error) {
 func ExtractRawData(msg *dns.Msg, qtype uint16) []string {
 	var data []string
 
+	for _, a := range ExtractAnswers(msg, qtype) {
+		data = append(data, a.Data)
+	}
+	return data
+}
+
+// ExtractAnswers - Returns the answers of the requested type, including the TTL
+// and the priority provided by MX and SRV records
+func ExtractAnswers(msg *dns.Msg, qtype uint16) []core.DNSAnswer {
+	var answers []core.DNSAnswer
+
 	for _, a := range msg.Answer {
-		if a.Header().Rrtype == qtype {
-			switch qtype {
-			case dns.TypeA:
-				if t, ok := a.(*dns.A); ok {
-					data = append(data, utils.CopyString(t.A.String()))
-				}
-			case dns.TypeAAAA:
-				if t, ok := a.(*dns.AAAA); ok {
-					data = append(data, utils.CopyString(t.AAAA.String()))
-				}
-			case dns.TypeCNAME:
-				if t, ok := a.(*dns.CNAME); ok {
-					data = append(data, utils.CopyString(t.Target))
-				}
-			case dns.TypePTR:
-				if t, ok := a.(*dns.PTR); ok {
-					data = append(data, utils.CopyString(t.Ptr))
-				}
-			case dns.TypeNS:
-				if t, ok := a.(*dns.NS); ok {
-					data = append(data, realName(t.Hdr)+","+removeLastDot(t.Ns))
-				}
-			case dns.TypeMX:
-				if t, ok := a.(*dns.MX); ok {
-					data = append(data, utils.CopyString(t.Mx))
-				}
-			case dns.TypeTXT:
-				if t, ok := a.(*dns.TXT); ok {
-					var all string
-
-					for _, piece := range t.Txt {
-						all += piece + " "
-					}
-					data = append(data, all)
-				}
-			case dns.TypeSOA:
-				if t, ok := a.(*dns.SOA); ok {
-					data = append(data, t.Ns+" "+t.Mbox)
-				}
-			case dns.TypeSPF:
-				if t, ok := a.(*dns.SPF); ok {
-					var all string
-
-					for _, piece := range t.Txt {
-						all += piece + " "
-					}
-					data = append(data, all)
-				}
-			case dns.TypeSRV:
-				if t, ok := a.(*dns.SRV); ok {
-					data = append(data, utils.CopyString(t.Target))
-				}
+		if a.Header().Rrtype != qtype {
+			continue
+		}
+
+		var ok bool
+		var priority int
+		var value string
+		switch t := a.(type) {
+		case *dns.A:
+			ok, value = true, utils.CopyString(t.A.String())
+		case *dns.AAAA:
+			ok, value = true, utils.CopyString(t.AAAA.String())
+		case *dns.CNAME:
+			ok, value = true, utils.CopyString(t.Target)
+		case *dns.PTR:
+			ok, value = true, utils.CopyString(t.Ptr)
+		case *dns.NS:
+			ok, value = true, realName(t.Hdr)+","+removeLastDot(t.Ns)
+		case *dns.MX:
+			ok, value = true, utils.CopyString(t.Mx)
+			priority = int(t.Preference)
+		case *dns.TXT:
+			ok = true
+			for _, piece := range t.Txt {
+				value += piece + " "
+			}
+		case *dns.SOA:
+			ok, value = true, t.Ns+" "+t.Mbox+" "+strconv.FormatUint(uint64(t.Serial), 10)
+		case *dns.SPF:
+			ok = true
+			for _, piece := range t.Txt {
+				value += piece + " "
 			}
+		case *dns.SRV:
+			ok, value = true, utils.CopyString(t.Target)
+			priority = int(t.Priority)
+		case *dns.CAA:
+			ok, value = true, strconv.Itoa(int(t.Flag))+" "+t.Tag+" "+t.Value
+		case *dns.DNSKEY:
+			ok = true
+			value = strconv.Itoa(int(t.Flags)) + " " + strconv.Itoa(int(t.Algorithm)) +
+				" " + strconv.Itoa(int(t.KeyTag()))
+		}
+
+		if ok {
+			answers = append(answers, core.DNSAnswer{
+				Name:     realName(*a.Header()),
+				Type:     int(qtype),
+				TTL:      int(a.Header().Ttl),
+				Priority: priority,
+				Data:     value,
+			})
 		}
 	}
-	return data
+	return answers
 }
 
 func realName(hdr dns.RR_Header) string {
@@ -337,11 +407,14 @@ func setupOptions() *dns.OPT {
 		Address:       net.ParseIP("0.0.0.0").To4(),
 	}
 
-	return &dns.OPT{
+	opt := &dns.OPT{
 		Hdr: dns.RR_Header{
 			Name:   ".",
 			Rrtype: dns.TypeOPT,
 		},
 		Option: []dns.EDNS0{e},
 	}
+	// Without the size, resolvers limit the answers to 512 bytes and truncate the rest
+	opt.SetUDPSize(maxUDPSize)
+	return opt
 }
@@ -4,10 +4,12 @@
 package dnssrv
 
 import (
-	"math/rand"
 	"strings"
+	"sync"
 
 	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+	"github.com/miekg/dns"
 )
 
 const (
@@ -44,6 +46,48 @@ func DetectWildcard(domain, subdomain string, records []core.DNSAnswer) bool {
 	return answer
 }
 
+// noDataCheck - The check of a subdomain for NOERROR answers, which is closed once it completes
+type noDataCheck struct {
+	done     chan struct{}
+	wildcard bool
+}
+
+var (
+	// The subdomains checked, or being checked, for NOERROR answers to names that cannot exist
+	noDataZones     = make(map[string]*noDataCheck)
+	noDataZonesLock sync.Mutex
+)
+
+// noDataWildcard - Checks whether the parent of the name answers NOERROR for a name that
+// cannot exist, which would make every guess beneath it appear to exist without records
+func (ds *DNSService) noDataWildcard(name string) bool {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return false
+	}
+	parent := parts[1]
+
+	noDataZonesLock.Lock()
+	if check, found := noDataZones[parent]; found {
+		noDataZonesLock.Unlock()
+		// Wait for the check started by another name beneath the subdomain
+		<-check.done
+		return check.wildcard
+	}
+	check := &noDataCheck{done: make(chan struct{})}
+	noDataZones[parent] = check
+	noDataZonesLock.Unlock()
+
+	// The query is sent without the lock, so the checks of other subdomains are not held up
+	if n := unlikelyName(parent); n != "" {
+		if _, err, _ := executeQuery(n, dns.TypeA); err == nil {
+			check.wildcard = true
+		}
+	}
+	close(check.done)
+	return check.wildcard
+}
+
 func compareAnswers(ans1, ans2 []core.DNSAnswer) bool {
 	var match bool
 loop:
@@ -97,13 +141,13 @@ func unlikelyName(sub string) string {
 		return ""
 	}
 	// Shuffle our LDH characters
-	rand.Shuffle(ldhLen, func(i, j int) {
+	utils.RandomShuffle(ldhLen, func(i, j int) {
 		ldh[i], ldh[j] = ldh[j], ldh[i]
 	})
 
-	l = (rand.Int() % l) + 1
+	l = (utils.RandomInt() % l) + 1
 	for i := 0; i < l; i++ {
-		sel := rand.Int() % ldhLen
+		sel := utils.RandomInt() % ldhLen
 
 		// The first nor last char may be a hyphen
 		if (i == 0 || i == l-1) && ldh[sel] == '-' {
@@ -0,0 +1,416 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// How long a worker that failed is left out of the pool
+	workerRetryDelay = 30 * time.Second
+
+	// The longest time waited for a remote worker to resolve a name
+	workerTimeout = 30 * time.Second
+
+	// The gRPC service provided by the workers
+	workerService = "amass.Worker"
+
+	// The content subtype of the gRPC messages sent between the workers and coordinators
+	workerCodec = "json"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// Returned when none of the remote workers could be reached
+var ErrNoWorkers = errors.New("Worker error: No remote workers are available")
+
+// Returned when the request did not present the token required by the worker
+var ErrUnauthorized = errors.New("Worker error: The request was not authorized")
+
+// WorkerRequest - The names sent to a remote worker for resolution
+type WorkerRequest struct {
+	Token string
+	Names []string
+}
+
+// WorkerResponse - The answers obtained by a remote worker for each name
+type WorkerResponse struct {
+	Answers map[string][]core.DNSAnswer
+
+	// The names that received a NOERROR answer, including those without records
+	Exists map[string]bool
+}
+
+// Worker - Resolves names on behalf of a coordinating enumeration over gRPC.
+// Without a token and TLS the interface should only be reachable from trusted hosts
+type Worker struct {
+	log   *log.Logger
+	token string
+}
+
+// authorized - Checks the token in the request when the worker requires one
+func (w *Worker) authorized(req *WorkerRequest) error {
+	if w.token == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(w.token)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Resolve - Performs the initial queries for each name in the request
+func (w *Worker) Resolve(req *WorkerRequest, resp *WorkerResponse) error {
+	if err := w.authorized(req); err != nil {
+		return err
+	}
+
+	resp.Answers = make(map[string][]core.DNSAnswer)
+	resp.Exists = make(map[string]bool)
+
+	for _, name := range req.Names {
+		a, exists := resolveNameTypes(name, InitialQueryTypes, w.log)
+		if len(a) > 0 {
+			resp.Answers[name] = a
+		}
+		if exists {
+			resp.Exists[name] = true
+		}
+	}
+	return nil
+}
+
+// Addresses - Performs only the A and AAAA queries for each name in the request,
+// which allows the worker to act as an agent at another vantage point
+func (w *Worker) Addresses(req *WorkerRequest, resp *WorkerResponse) error {
+	if err := w.authorized(req); err != nil {
+		return err
+	}
+
+	resp.Answers = make(map[string][]core.DNSAnswer)
+
+	for _, name := range req.Names {
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			if a, err, _ := executeQuery(name, qtype); err == nil {
+				resp.Answers[name] = append(resp.Answers[name], a...)
+			}
+		}
+	}
+	return nil
+}
+
+// workerMethods - The methods of the Worker provided through the gRPC service
+type workerMethods interface {
+	Resolve(req *WorkerRequest, resp *WorkerResponse) error
+	Addresses(req *WorkerRequest, resp *WorkerResponse) error
+}
+
+// The gRPC service is described by hand, since the messages are encoded with jsonCodec
+// rather than with code generated from protocol buffers
+var workerServiceDesc = grpc.ServiceDesc{
+	ServiceName: workerService,
+	HandlerType: (*workerMethods)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Resolve", Handler: workerHandler(workerMethods.Resolve)},
+		{MethodName: "Addresses", Handler: workerHandler(workerMethods.Addresses)},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "workers.go",
+}
+
+// The gRPC handler of a unary method
+type workerMethodHandler = func(srv interface{}, ctx context.Context,
+	dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error)
+
+// workerHandler - Returns the gRPC handler that decodes the request and calls the method
+func workerHandler(method func(workerMethods, *WorkerRequest, *WorkerResponse) error) workerMethodHandler {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := new(WorkerRequest)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+
+		resp := new(WorkerResponse)
+		if err := method(srv.(workerMethods), req, resp); err != nil {
+			if errors.Is(err, ErrUnauthorized) {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// jsonCodec - Encodes the worker messages as JSON within the gRPC frames
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return workerCodec
+}
+
+// ServeWorker - Accepts resolution requests from coordinators on the address until an error occurs.
+// Requests must present the token when one is provided, and the connections use TLS when
+// tlsConfig is provided. Client certificates are required when tlsConfig has ClientCAs set.
+// An address without a host binds to the loopback interface, and other interfaces are only
+// served when the token or mutual TLS is required, so the worker is not an open resolver
+func ServeWorker(addr, token string, tlsConfig *tls.Config, l *log.Logger) error {
+	if l == nil {
+		l = log.New(ioutil.Discard, "", 0)
+	}
+
+	addr = workerAddress(addr)
+	mutual := tlsConfig != nil && tlsConfig.ClientCAs != nil &&
+		tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert
+	if token == "" && !mutual && !loopbackAddress(addr) {
+		return fmt.Errorf("Worker error: A token or mutual TLS is required to listen on %s", addr)
+	}
+
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&workerServiceDesc, &Worker{log: l, token: token})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Worker error: Failed to listen on %s: %v", addr, err)
+	}
+	defer ln.Close()
+
+	if err := server.Serve(ln); err != nil {
+		return fmt.Errorf("Worker error: Stopped serving on %s: %v", addr, err)
+	}
+	return nil
+}
+
+// workerAddress - Returns the address with the loopback interface when no host is given
+func workerAddress(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return addr
+}
+
+// loopbackAddress - Returns true when the address can only be reached from the local host
+func loopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// WorkerPool - Distributes names across remote workers, leaving out workers that fail.
+// Each name resolved by the enumeration, including the guesses of brute forcing, is sent
+// to the next worker in turn, so the workload is spread one name at a time rather than in
+// shards planned ahead. The answers return to the coordinator, which alone keeps the graph.
+// The workers are reached over gRPC, with the messages encoded as JSON
+type WorkerPool struct {
+	sync.Mutex
+	addrs   []string
+	token   string
+	tls     *tls.Config
+	next    int
+	clients map[string]*grpc.ClientConn
+	failed  map[string]time.Time
+}
+
+// NewWorkerPool - The token is presented with each request, and the connections
+// use TLS when tlsConfig is provided
+func NewWorkerPool(addrs []string, token string, tlsConfig *tls.Config) *WorkerPool {
+	return &WorkerPool{
+		addrs:   addrs,
+		token:   token,
+		tls:     tlsConfig,
+		clients: make(map[string]*grpc.ClientConn),
+		failed:  make(map[string]time.Time),
+	}
+}
+
+// Resolve - Has the next available worker resolve the name, trying the others upon failure.
+// The name exists when a NOERROR answer was received, even without records
+func (wp *WorkerPool) Resolve(name string) ([]core.DNSAnswer, bool, error) {
+	for i := 0; i < len(wp.addrs); i++ {
+		addr, client := wp.nextWorker()
+		if client == nil {
+			continue
+		}
+
+		resp := new(WorkerResponse)
+		err := wp.call(client, "Resolve", name, resp)
+		if err == nil {
+			return resp.Answers[name], resp.Exists[name], nil
+		}
+		// Trying the other workers will not help when the token is rejected
+		if errors.Is(err, ErrUnauthorized) {
+			return nil, false, err
+		}
+		wp.markFailed(addr)
+	}
+	return nil, false, ErrNoWorkers
+}
+
+// AddressesFromAll - Returns the addresses for the name observed by each available agent
+func (wp *WorkerPool) AddressesFromAll(name string) map[string][]core.DNSAnswer {
+	results := make(map[string][]core.DNSAnswer)
+
+	for range wp.addrs {
+		addr, client := wp.nextWorker()
+		if client == nil {
+			continue
+		}
+
+		resp := new(WorkerResponse)
+		if err := wp.call(client, "Addresses", name, resp); err != nil {
+			wp.markFailed(addr)
+			continue
+		}
+		results[addr] = resp.Answers[name]
+	}
+	return results
+}
+
+func (wp *WorkerPool) call(conn *grpc.ClientConn, method, name string, resp *WorkerResponse) error {
+	ctx, cancel := context.WithTimeout(context.Background(), workerTimeout)
+	defer cancel()
+
+	req := &WorkerRequest{Token: wp.token, Names: []string{name}}
+	err := conn.Invoke(ctx, "/"+workerService+"/"+method, req, resp, grpc.CallContentSubtype(workerCodec))
+	return remoteError(err)
+}
+
+// remoteError - Returns the sentinel error for the status returned by the worker
+func remoteError(err error) error {
+	if err != nil && status.Code(err) == codes.Unauthenticated {
+		return ErrUnauthorized
+	}
+	return err
+}
+
+// nextWorker - Returns the next worker in turn, and the client connected to it. The worker
+// is dialed without holding the lock, so a slow worker does not hold up the others
+func (wp *WorkerPool) nextWorker() (string, *grpc.ClientConn) {
+	wp.Lock()
+	addr := wp.addrs[wp.next%len(wp.addrs)]
+	wp.next++
+
+	if t, found := wp.failed[addr]; found {
+		if time.Since(t) < workerRetryDelay {
+			wp.Unlock()
+			return addr, nil
+		}
+		delete(wp.failed, addr)
+	}
+
+	if client, found := wp.clients[addr]; found {
+		wp.Unlock()
+		return addr, client
+	}
+	wp.Unlock()
+
+	client, err := wp.dial(addr)
+
+	wp.Lock()
+	defer wp.Unlock()
+
+	if err != nil {
+		wp.failed[addr] = time.Now()
+		return addr, nil
+	}
+	// Another request could have connected to the worker in the meantime
+	if existing, found := wp.clients[addr]; found {
+		client.Close()
+		return addr, existing
+	}
+	wp.clients[addr] = client
+	return addr, client
+}
+
+// dial - Connects to the worker in the background, so a worker that cannot be
+// reached fails the first call made to it
+func (wp *WorkerPool) dial(addr string) (*grpc.ClientConn, error) {
+	creds := grpc.WithInsecure()
+	if wp.tls != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(wp.tls))
+	}
+	return grpc.Dial(addr, creds)
+}
+
+func (wp *WorkerPool) markFailed(addr string) {
+	wp.Lock()
+	defer wp.Unlock()
+
+	if client, found := wp.clients[addr]; found {
+		client.Close()
+		delete(wp.clients, addr)
+	}
+	wp.failed[addr] = time.Now()
+}
+
+// LoadWorkerTLS - Builds the TLS configuration used by both the workers and the coordinators.
+// The certificate and key are presented to the other side, and when the CA file is provided
+// the other side must present a certificate signed by it, which gives mutual TLS
+func LoadWorkerTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Worker error: Failed to load the certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		data, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Worker error: Failed to read %s: %v", caFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("Worker error: No certificates were found in %s", caFile)
+		}
+		config.RootCAs = pool
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
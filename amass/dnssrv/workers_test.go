@@ -0,0 +1,189 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dnssrv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWorkerAuthentication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-worker-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cert, key, ca := writeTestCertificate(t, dir)
+	config, err := LoadWorkerTLS(cert, key, ca)
+	if err != nil {
+		t.Fatalf("Failed to load the TLS configuration: %v", err)
+	}
+	addr := startTestWorker(t, "secret", config)
+
+	tests := []struct {
+		token string
+		err   error
+	}{
+		{"secret", nil},
+		{"guess", ErrUnauthorized},
+	}
+	for _, test := range tests {
+		wp := NewWorkerPool([]string{addr}, test.token, config)
+
+		if err := waitForWorker(wp); !errors.Is(err, test.err) {
+			t.Errorf("The token %q returned %v instead of %v", test.token, err, test.err)
+		}
+	}
+
+	// Without a client certificate the mutual TLS handshake fails
+	wp := NewWorkerPool([]string{addr}, "secret", &tls.Config{RootCAs: config.RootCAs})
+	if _, c := wp.nextWorker(); c != nil {
+		if err := emptyRequest(wp, c); err == nil {
+			t.Errorf("The worker accepted a connection without a client certificate")
+		}
+	}
+}
+
+func TestWorkerOpenAddress(t *testing.T) {
+	if addr := workerAddress(":4000"); addr != "127.0.0.1:4000" {
+		t.Errorf("The address without a host was bound to %s", addr)
+	}
+
+	tests := []struct {
+		addr     string
+		loopback bool
+	}{
+		{"127.0.0.1:4000", true},
+		{"[::1]:4000", true},
+		{"localhost:4000", true},
+		{"0.0.0.0:4000", false},
+		{"192.0.2.1:4000", false},
+		{"worker.example.com:4000", false},
+	}
+	for _, test := range tests {
+		if loopback := loopbackAddress(test.addr); loopback != test.loopback {
+			t.Errorf("%s was reported as loopback %v", test.addr, loopback)
+		}
+	}
+
+	// Without the token or mutual TLS the other interfaces are not served
+	if err := ServeWorker("0.0.0.0:0", "", nil, nil); err == nil {
+		t.Errorf("The worker served all interfaces without authentication")
+	}
+	if err := ServeWorker("0.0.0.0:0", "", &tls.Config{}, nil); err == nil {
+		t.Errorf("The worker served all interfaces with TLS but without client certificates")
+	}
+}
+
+func TestWorkerNoData(t *testing.T) {
+	dnsAddr, stop := startNoDataServer(t)
+	defer stop()
+
+	saved := CustomResolvers
+	CustomResolvers = []string{dnsAddr}
+	defer func() { CustomResolvers = saved }()
+
+	wp := NewWorkerPool([]string{startTestWorker(t, "", nil)}, "", nil)
+	if err := waitForWorker(wp); err != nil {
+		t.Fatalf("The worker did not accept the request: %v", err)
+	}
+
+	answers, exists, err := wp.Resolve("empty.example.com")
+	if err != nil {
+		t.Fatalf("The worker failed to resolve the name: %v", err)
+	}
+	if len(answers) != 0 || !exists {
+		t.Errorf("The NODATA name returned %d answers and exists %v", len(answers), exists)
+	}
+	if _, exists, err := wp.Resolve("missing.example.com"); err != nil || exists {
+		t.Errorf("The NXDOMAIN name was reported to exist: %v", err)
+	}
+}
+
+// startTestWorker - Serves a worker on an available loopback port, and returns its address
+func startTestWorker(t *testing.T, token string, config *tls.Config) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find an available port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	go ServeWorker(addr, token, config, nil)
+	return addr
+}
+
+// waitForWorker - Sends requests without names until the worker accepts connections,
+// and returns the error of the last request
+func waitForWorker(wp *WorkerPool) error {
+	var err error
+
+	for i := 0; i < 50; i++ {
+		_, c := wp.nextWorker()
+		if err = emptyRequest(wp, c); status.Code(err) != codes.Unavailable {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return err
+}
+
+// emptyRequest - Sends a request without names, so the worker does not send queries
+func emptyRequest(wp *WorkerPool, conn *grpc.ClientConn) error {
+	return remoteError(conn.Invoke(context.Background(), "/"+workerService+"/Resolve",
+		&WorkerRequest{Token: wp.token}, new(WorkerResponse), grpc.CallContentSubtype(workerCodec)))
+}
+
+// writeTestCertificate - Creates a self-signed certificate for 127.0.0.1 that also acts as the CA
+func writeTestCertificate(t *testing.T, dir string) (string, string, string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "amass-worker"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+	keyder, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to marshal the key: %v", err)
+	}
+
+	cert := filepath.Join(dir, "worker.crt")
+	key := filepath.Join(dir, "worker.key")
+	ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyder}), 0600)
+	return cert, key, cert
+}
@@ -0,0 +1,161 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+)
+
+var testZone = []string{
+	"www.example.com. 300 IN A 192.0.2.10",
+	"mail.example.com. 300 IN A 192.0.2.20",
+	"ftp.example.com. 300 IN CNAME www.example.com.",
+}
+
+// The infrastructure data for the zone addresses, since the online lookups cannot succeed
+func init() {
+	netDataLock.Lock()
+	defer netDataLock.Unlock()
+
+	netDataCache[64496] = &ASRecord{
+		ASN:         64496,
+		CC:          "US",
+		Registry:    "arin",
+		Description: "Documentation ASN",
+		Netblocks:   []string{"192.0.2.0/24"},
+	}
+}
+
+// runTestEnumeration - Executes the full pipeline against the mock DNS server and returns the output
+func runTestEnumeration(t testing.TB, srv *amasstest.DNSServer, src *amasstest.Source) map[string]*AmassOutput {
+	results := make(map[string]*AmassOutput)
+
+	enum := NewEnumeration()
+	enum.AddDomain("example.com")
+	enum.AddSource(src)
+	enum.DisabledSources = amasstest.BuiltinSourceNames()
+	enum.Resolvers = []string{srv.Addr()}
+	enum.SRVBruteForcing = false
+	enum.Alterations = false
+	enum.Seed = 1
+
+	output := make(chan *AmassOutput, 50)
+	enum.Output = output
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- enum.Start()
+	}()
+
+	for out := range output {
+		results[out.Name] = out
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("The enumeration failed to start: %v", err)
+	}
+	return results
+}
+
+func TestEnumerationPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("The enumeration pipeline test waits for the services to become idle")
+	}
+
+	srv, err := amasstest.NewDNSServer(testZone...)
+	if err != nil {
+		t.Fatalf("Failed to start the mock DNS server: %v", err)
+	}
+	defer srv.Close()
+
+	src := amasstest.NewSource("Mock Source", "www.example.com", "mail.example.com",
+		"WWW.example.com", "ftp.example.com", "missing.example.com", "www.other.com")
+	results := runTestEnumeration(t, srv, src)
+
+	if src.NumOfQueries() == 0 {
+		t.Errorf("The mock data source was never queried")
+	}
+	for _, name := range []string{"www.example.com", "mail.example.com", "ftp.example.com"} {
+		out, found := results[name]
+		if !found {
+			t.Errorf("The enumeration did not output %s", name)
+			continue
+		}
+		if len(out.Addresses) == 0 {
+			t.Errorf("The enumeration did not resolve the addresses for %s", name)
+		}
+	}
+	for _, name := range []string{"missing.example.com", "www.other.com"} {
+		if _, found := results[name]; found {
+			t.Errorf("The enumeration output %s, which should not have been", name)
+		}
+	}
+}
+
+func BenchmarkEnumerationPipeline(b *testing.B) {
+	srv, err := amasstest.NewDNSServer(testZone...)
+	if err != nil {
+		b.Fatalf("Failed to start the mock DNS server: %v", err)
+	}
+	defer srv.Close()
+
+	for i := 0; i < b.N; i++ {
+		src := amasstest.NewSource("Mock Source", "www.example.com", "mail.example.com", "ftp.example.com")
+		runTestEnumeration(b, srv, src)
+	}
+}
+
+func TestEnumerationStartFailure(t *testing.T) {
+	enum := NewEnumeration()
+	enum.AddDomain("example.com")
+	enum.Passive = true
+	enum.BruteForcing = true
+
+	// The enumeration is not running, so neither call waits for it
+	enum.Pause()
+	enum.Resume()
+
+	if err := enum.Start(); err == nil {
+		t.Fatalf("The enumeration started with brute forcing during a passive enumeration")
+	}
+	if _, open := <-enum.Output; open {
+		t.Errorf("The output channel was not closed when the enumeration failed to start")
+	}
+	select {
+	case <-enum.Done():
+	default:
+		t.Errorf("Done did not report the failed enumeration")
+	}
+	if err := enum.Start(); err == nil {
+		t.Errorf("The enumeration was started a second time")
+	}
+
+	enum.Pause()
+	enum.Resume()
+}
+
+func TestEnumerationStartConcurrent(t *testing.T) {
+	activeEnumLock.Lock()
+	activeEnum = NewEnumeration()
+	activeEnumLock.Unlock()
+
+	enum := NewEnumeration()
+	enum.AddDomain("example.com")
+	enum.Passive = true
+	enum.BruteForcing = true
+
+	if err := enum.Start(); !errors.Is(err, ErrEnumerationRunning) {
+		t.Errorf("The enumeration started while another was running: %v", err)
+	}
+
+	activeEnumLock.Lock()
+	activeEnum = nil
+	activeEnumLock.Unlock()
+	// The refused enumeration was not marked as started, so it fails on its settings instead
+	if err := enum.Start(); err == nil || errors.Is(err, ErrEnumerationRunning) {
+		t.Errorf("The enumeration was not started once the other had finished: %v", err)
+	}
+}
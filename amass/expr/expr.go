@@ -0,0 +1,462 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package expr evaluates small boolean filter expressions, such as
+// `resolved && !cdn && confidence > 50`, against a set of named values.
+//
+// Supported are the && || and ! operators, parentheses, comparisons using
+// == != < <= > >=, regular expression matches using =~, numbers, double
+// quoted strings, true and false. A bare value is true when it is a true
+// boolean, a non-zero number or a non-empty string.
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression - A parsed filter expression
+type Expression struct {
+	text string
+	root node
+}
+
+// Parse - Compiles the filter expression, rejecting the identifiers other than the fields
+func Parse(text string, fields []string) (*Expression, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, fields: make(map[string]struct{})}
+	for _, f := range fields {
+		p.fields[f] = struct{}{}
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("Expression error: Unexpected %q at position %d", t.text, t.pos)
+	}
+	return &Expression{text: text, root: root}, nil
+}
+
+// Eval - Returns the result of the expression using the values provided in env
+func (e *Expression) Eval(env map[string]interface{}) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+func (e *Expression) String() string {
+	return e.text
+}
+
+//-------------------------------------------------------------------------------------------------
+// Lexer
+//-------------------------------------------------------------------------------------------------
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!"}
+
+func lex(text string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			var b strings.Builder
+
+			j := i + 1
+			for ; j < len(text) && text[j] != '"'; j++ {
+				if text[j] == '\\' && j+1 < len(text) {
+					j++
+				}
+				b.WriteByte(text[j])
+			}
+			if j >= len(text) {
+				return nil, fmt.Errorf("Expression error: Unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: b.String(), pos: i})
+			i = j + 1
+		case unicode.IsDigit(c) || c == '-' || c == '.':
+			j := i + 1
+			for j < len(text) && (unicode.IsDigit(rune(text[j])) || text[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text[i:j], pos: i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(text) && (unicode.IsLetter(rune(text[j])) ||
+				unicode.IsDigit(rune(text[j])) || text[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: text[i:j], pos: i})
+			i = j
+		default:
+			var found bool
+
+			for _, op := range operators {
+				if strings.HasPrefix(text[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("Expression error: Unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(text)}), nil
+}
+
+//-------------------------------------------------------------------------------------------------
+// Parser
+//-------------------------------------------------------------------------------------------------
+
+type parser struct {
+	tokens []token
+	pos    int
+	fields map[string]struct{}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokOp && t.text == "||"; t = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokOp && t.text == "&&"; t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokOp && t.text == "!" {
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{n: n}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokOp {
+		return left, nil
+	}
+
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+		p.next()
+	default:
+		return left, nil
+	}
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &compareNode{op: t.text, left: left, right: right}
+	// Compile the regular expression once when it is a literal
+	if lit, ok := right.(*literalNode); ok && t.text == "=~" {
+		s, ok := lit.v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expression error: The =~ operator requires a string pattern")
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("Expression error: %v", err)
+		}
+		cmp.re = re
+	}
+	return cmp, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokRParen {
+			return nil, fmt.Errorf("Expression error: Missing ')' at position %d", r.pos)
+		}
+		return n, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Expression error: Invalid number %q at position %d", t.text, t.pos)
+		}
+		return &literalNode{v: f}, nil
+	case tokString:
+		return &literalNode{v: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{v: true}, nil
+		case "false":
+			return &literalNode{v: false}, nil
+		}
+		if _, found := p.fields[t.text]; !found {
+			return nil, fmt.Errorf("Expression error: Unknown field %q at position %d", t.text, t.pos)
+		}
+		return &identNode{name: t.text}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("Expression error: Unexpected end of the expression")
+	}
+	return nil, fmt.Errorf("Expression error: Unexpected %q at position %d", t.text, t.pos)
+}
+
+//-------------------------------------------------------------------------------------------------
+// Evaluation
+//-------------------------------------------------------------------------------------------------
+
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	v interface{}
+}
+
+func (n *literalNode) eval(env map[string]interface{}) (interface{}, error) {
+	return n.v, nil
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, found := env[n.name]
+	if !found {
+		return nil, fmt.Errorf("Expression error: Unknown field %q", n.name)
+	}
+	return normalize(v), nil
+}
+
+type notNode struct {
+	n node
+}
+
+func (n *notNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, err := n.n.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(env map[string]interface{}) (interface{}, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit the evaluation
+	if n.op == "&&" && !truthy(l) {
+		return false, nil
+	} else if n.op == "||" && truthy(l) {
+		return true, nil
+	}
+
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp
+}
+
+func (n *compareNode) eval(env map[string]interface{}) (interface{}, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "=~" {
+		re := n.re
+		if re == nil {
+			pattern, ok := r.(string)
+			if !ok {
+				return nil, fmt.Errorf("Expression error: The =~ operator requires a string pattern")
+			}
+			if re, err = regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("Expression error: %v", err)
+			}
+		}
+		return re.MatchString(fmt.Sprint(l)), nil
+	}
+
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return nil, fmt.Errorf("Expression error: Cannot compare a number with %v", r)
+		}
+		return compareOrdered(n.op, lv < rv, lv == rv), nil
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expression error: Cannot compare a string with %v", r)
+		}
+		return compareOrdered(n.op, lv < rv, lv == rv), nil
+	case bool:
+		rv, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Expression error: Cannot compare a boolean with %v", r)
+		}
+		switch n.op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		}
+		return nil, fmt.Errorf("Expression error: Booleans cannot be compared with %s", n.op)
+	}
+	return nil, fmt.Errorf("Expression error: Unsupported value %v", l)
+}
+
+func compareOrdered(op string, less, equal bool) bool {
+	switch op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// normalize - Converts the numeric types to float64
+func normalize(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
+
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		return t != ""
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package expr
+
+import (
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	env := map[string]interface{}{
+		"name":       "dev.example.com",
+		"resolved":   true,
+		"cdn":        false,
+		"confidence": 75,
+		"tag":        "cert",
+	}
+
+	tests := []struct {
+		text   string
+		result bool
+	}{
+		{"resolved", true},
+		{"!resolved", false},
+		{"resolved && !cdn && confidence > 50", true},
+		{"resolved && cdn", false},
+		{"cdn || confidence >= 75", true},
+		{"confidence < 75", false},
+		{"confidence <= 75 && confidence != 10", true},
+		{`tag == "cert"`, true},
+		{`tag != "cert" || name =~ "^dev\\."`, true},
+		{`name =~ "^www"`, false},
+		{"!(resolved && cdn)", true},
+		{"(cdn || resolved) && confidence == 75", true},
+	}
+
+	var fields []string
+	for name := range env {
+		fields = append(fields, name)
+	}
+
+	for _, test := range tests {
+		e, err := Parse(test.text, fields)
+		if err != nil {
+			t.Errorf("Parse(%q) returned an error: %v", test.text, err)
+			continue
+		}
+
+		result, err := e.Eval(env)
+		if err != nil {
+			t.Errorf("Eval(%q) returned an error: %v", test.text, err)
+		} else if result != test.result {
+			t.Errorf("Eval(%q) returned %v, expected %v", test.text, result, test.result)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	fields := []string{"resolved", "name", "confidence"}
+	for _, text := range []string{"", "resolved &&", "(resolved", `name == "dev`, "confidence > > 5", "a # b", "unknown && true"} {
+		if _, err := Parse(text, fields); err == nil {
+			t.Errorf("Parse(%q) did not return an error", text)
+		}
+	}
+
+	e, _ := Parse("resolved && true", fields)
+	if _, err := e.Eval(map[string]interface{}{}); err == nil {
+		t.Errorf("Eval did not return an error for the missing field")
+	}
+
+	e, _ = Parse(`confidence > "high"`, fields)
+	if _, err := e.Eval(map[string]interface{}{"confidence": 50}); err == nil {
+		t.Errorf("Eval did not return an error when comparing a number with a string")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// Mode - Selects how strictly the names found in text are checked
+type Mode int
+
+// The modes of extraction
+const (
+	// Strict - Only names that are not part of a longer token are returned. This is
+	// the mode used for web pages, API responses and JavaScript
+	Strict Mode = iota
+	// Loose - Names are returned wherever they are found, even when they run into the
+	// neighbouring text, as within the snippets of search engine results
+	Loose
+)
+
+// Matches the runs of mixed case base64 data, such as the images embedded in pages
+var blobRegex = regexp.MustCompile(`[A-Za-z0-9+/]{40,}={0,2}`)
+
+// Matches the joints of string literals concatenated together, such as "api" + ".example.com"
+var concatRegex = regexp.MustCompile(`["'\x60]\s*\+\s*["'\x60]`)
+
+// Replaces the escaped forms of the dots and slashes used by JavaScript, JSON, URLs and
+// HTML after the text has been converted to lowercase
+var unescaper = strings.NewReplacer(
+	`\\.`, ".",
+	`\.`, ".",
+	`\x2e`, ".",
+	`\u002e`, ".",
+	`%2e`, ".",
+	`&#46;`, ".",
+	`&#x2e;`, ".",
+	`\/`, "/",
+	`\u002f`, "/",
+	`%2f`, "/",
+)
+
+// Extractor - Finds the names within a domain in text
+type Extractor struct {
+	mode Mode
+	re   *regexp.Regexp
+}
+
+// New - Returns an Extractor for the names within the domain
+func New(domain string, mode Mode) *Extractor {
+	return &Extractor{
+		mode: mode,
+		re:   utils.SubdomainRegex(strings.ToLower(domain)),
+	}
+}
+
+// Names - Returns the unique names within the domain found in the text, in lowercase
+func Names(domain, text string) []string {
+	return New(domain, Strict).Names(text)
+}
+
+// Names - Returns the unique names within the domain found in the text, in lowercase
+func (e *Extractor) Names(text string) []string {
+	var names []string
+
+	if e.mode == Strict {
+		text = blobRegex.ReplaceAllStringFunc(text, blankBlob)
+	}
+	text = unescaper.Replace(strings.ToLower(text))
+
+	for _, idx := range e.re.FindAllStringIndex(text, -1) {
+		if e.mode == Strict && !bounded(text, idx[0], idx[1]) {
+			continue
+		}
+		names = utils.UniqueAppend(names, text[idx[0]:idx[1]])
+	}
+	return names
+}
+
+// ScriptNames - Returns the names found in the JavaScript source, including names built
+// by concatenating string literals
+func (e *Extractor) ScriptNames(script string) []string {
+	names := e.Names(script)
+
+	return utils.UniqueAppend(names, e.Names(concatRegex.ReplaceAllString(script, ""))...)
+}
+
+// Match - Returns true when the entire value is a name within the domain
+func (e *Extractor) Match(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	return name != "" && e.re.FindString(name) == name
+}
+
+// bounded - Returns true when the match is not a piece of a longer label or name
+func bounded(text string, start, end int) bool {
+	if start > 0 && labelChar(text[start-1]) {
+		return false
+	}
+	if end < len(text) && labelChar(text[end]) {
+		return false
+	}
+	// A following dot and label show that the match belongs to another domain
+	if end+1 < len(text) && text[end] == '.' && labelChar(text[end+1]) {
+		return false
+	}
+	return true
+}
+
+func labelChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_'
+}
+
+// blankBlob - Removes the run when it holds the upper and lowercase letters and digits
+// of base64 data, since a long path or word is unlikely to contain all three
+func blankBlob(run string) string {
+	var upper, lower, digit bool
+
+	for i := 0; i < len(run); i++ {
+		switch c := run[i]; {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	if upper && lower && digit {
+		return strings.Repeat(" ", len(run))
+	}
+	return run
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	blob := "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk" +
+		"+M9QDwADhgGAWjR9aw.example.com"
+
+	tests := []struct {
+		text   string
+		strict []string
+		loose  []string
+	}{
+		{`<a href="https://WWW.Example.com/">`, []string{"www.example.com"}, []string{"www.example.com"}},
+		{"mail.example.com, ftp.example.com", []string{"mail.example.com", "ftp.example.com"},
+			[]string{"mail.example.com", "ftp.example.com"}},
+		{"www.example.community", nil, []string{"www.example.com"}},
+		{"www.example.com.evil.net", nil, []string{"www.example.com"}},
+		{"Visit www.example.com.", []string{"www.example.com"}, []string{"www.example.com"}},
+		{strings.Repeat("a", 70) + ".example.com", nil, []string{strings.Repeat("a", 63) + ".example.com"}},
+		{blob, nil, []string{"m9qdwadhggawjr9aw.example.com"}},
+		{`var re = /api\.example\.com/;`, []string{"api.example.com"}, []string{"api.example.com"}},
+		{`{"host": "cdn.example.com"}`, []string{"cdn.example.com"}, []string{"cdn.example.com"}},
+		{`https:\/\/auth.example.com\/login`, []string{"auth.example.com"}, []string{"auth.example.com"}},
+		{"https%3A%2F%2Fshop.example.com", []string{"shop.example.com"}, []string{"shop.example.com"}},
+		{"www.other.com", nil, nil},
+	}
+
+	for _, test := range tests {
+		if names := New("example.com", Strict).Names(test.text); !equal(names, test.strict) {
+			t.Errorf("Strict extraction from %q returned %v, expected %v", test.text, names, test.strict)
+		}
+		if names := New("example.com", Loose).Names(test.text); !equal(names, test.loose) {
+			t.Errorf("Loose extraction from %q returned %v, expected %v", test.text, names, test.loose)
+		}
+	}
+}
+
+func TestScriptNames(t *testing.T) {
+	script := `var b="https://" + "staging" + ".example.com/graphql"; fetch("//api.example.com");`
+	expected := []string{"api.example.com", "staging.example.com"}
+
+	if names := New("example.com", Strict).ScriptNames(script); !equal(names, expected) {
+		t.Errorf("ScriptNames returned %v, expected %v", names, expected)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		match bool
+	}{
+		{"www.example.com", true},
+		{" WWW.Example.com ", true},
+		{"example.com", false},
+		{"www.example.com.evil.net", false},
+		{"a b.example.com", false},
+		{"", false},
+	}
+
+	ex := New("example.com", Strict)
+	for _, test := range tests {
+		if m := ex.Match(test.name); m != test.match {
+			t.Errorf("Match(%q) returned %t, expected %t", test.name, m, test.match)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
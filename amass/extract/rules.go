@@ -0,0 +1,40 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"regexp"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// Rule - Declares the parts of the responses from a data source that hold the names,
+// so the data source does not need its own parsing code
+type Rule struct {
+	// Limits the extraction to the first submatch of each match, such as the links of
+	// the search results, or to the entire match when the expression has no groups
+	Scope *regexp.Regexp
+
+	// The mode used to extract the names from the text within the scope
+	Mode Mode
+}
+
+// Names - Returns the unique names within the domain found in the parts of the text
+// selected by the rule
+func (r *Rule) Names(domain, text string) []string {
+	ex := New(domain, r.Mode)
+	if r.Scope == nil {
+		return ex.Names(text)
+	}
+
+	var names []string
+	for _, m := range r.Scope.FindAllStringSubmatch(text, -1) {
+		part := m[0]
+		if len(m) > 1 {
+			part = m[1]
+		}
+		names = utils.UniqueAppend(names, ex.Names(part)...)
+	}
+	return names
+}
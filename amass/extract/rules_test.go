@@ -0,0 +1,30 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRuleNames(t *testing.T) {
+	page := `<a class="result__url" href="https://www.example.com/">www.example.com</a>
+		<div class="ad">Visit shop.example.com today</div>
+		<a class="result__url" href="https://dev.example.com/docs">dev.example.com/docs</a>`
+
+	tests := []struct {
+		rule     Rule
+		expected []string
+	}{
+		{Rule{}, []string{"www.example.com", "shop.example.com", "dev.example.com"}},
+		{Rule{Scope: regexp.MustCompile(`class="result__url" href="([^"]+)"`)}, []string{"www.example.com", "dev.example.com"}},
+		{Rule{Scope: regexp.MustCompile(`dev\.example\.com/docs`)}, []string{"dev.example.com"}},
+	}
+
+	for _, test := range tests {
+		if names := test.rule.Names("example.com", page); !equal(names, test.expected) {
+			t.Errorf("The rule %v returned %v, expected %v", test.rule.Scope, names, test.expected)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+// The most evidence kept for each finding, such as the names obtained by a zone transfer
+const maxEvidence = 25
+
+// findingSet - Collects the findings once for each type and asset, merging the evidence
+type findingSet struct {
+	findings map[string]*core.Finding
+}
+
+func newFindingSet() *findingSet {
+	return &findingSet{findings: make(map[string]*core.Finding)}
+}
+
+func (fs *findingSet) add(f *core.Finding) {
+	key := f.Type + "|" + f.Name
+
+	cur, found := fs.findings[key]
+	if !found {
+		c := *f
+		c.Evidence = append([]string(nil), f.Evidence...)
+		fs.findings[key] = &c
+		return
+	}
+
+	for _, e := range f.Evidence {
+		if len(cur.Evidence) >= maxEvidence {
+			break
+		}
+		cur.Evidence = appendUnique(cur.Evidence, e)
+	}
+}
+
+// sorted - Returns copies of the findings with the most severe first
+func (fs *findingSet) sorted() []*core.Finding {
+	var findings []*core.Finding
+
+	for _, f := range fs.findings {
+		c := *f
+		c.Evidence = append([]string(nil), f.Evidence...)
+		findings = append(findings, &c)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+
+		if ra, rb := core.SeverityRank(a.Severity), core.SeverityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return findings
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// outputFindings - Returns the findings shown by the records of the name
+func outputFindings(out *AmassOutput) []*core.Finding {
+	var findings []*core.Finding
+
+	if service := out.TakeoverService(); service != "" {
+		findings = append(findings, &core.Finding{
+			Type:     core.FindingTakeover,
+			Severity: core.SeverityHigh,
+			Name:     out.Name,
+			Domain:   out.Domain,
+			Description: fmt.Sprintf("The name is an alias of %s, which did not resolve, so the "+
+				"resource could be claimed by a third party", service),
+			Evidence: []string{"CNAME " + out.CNAMETarget()},
+		})
+	} else if out.Dangling() {
+		findings = append(findings, &core.Finding{
+			Type:        core.FindingDangling,
+			Severity:    core.SeverityLow,
+			Name:        out.Name,
+			Domain:      out.Domain,
+			Description: "The name is an alias of a name that did not resolve",
+			Evidence:    []string{"CNAME " + out.CNAMETarget()},
+		})
+	}
+
+	if out.Private() {
+		f := &core.Finding{
+			Type:        core.FindingInternalIP,
+			Severity:    core.SeverityMedium,
+			Name:        out.Name,
+			Domain:      out.Domain,
+			Description: "The public DNS records of the name disclose internal addresses",
+		}
+		for _, addr := range out.Addresses {
+			if addr.Reserved != "" {
+				f.Evidence = append(f.Evidence, fmt.Sprintf("%s (%s)", addr.Address, addr.Reserved))
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	// Names loaded from zone files carry the same tag, but were not transferred by amass
+	if out.Tag == core.AXFR && out.Source == dnssrv.ZoneXFRSource {
+		findings = append(findings, &core.Finding{
+			Type:        core.FindingZoneTransfer,
+			Severity:    core.SeverityHigh,
+			Name:        out.Domain,
+			Domain:      out.Domain,
+			Description: "A name server of the domain allowed the zone to be transferred",
+			Evidence:    []string{out.Name},
+		})
+	}
+	return findings
+}
+
+// CollectFindings - Returns the findings shown by the names, such as those read from the
+// JSON output of a run, with the most severe first
+func CollectFindings(results []*AmassOutput) []*core.Finding {
+	fs := newFindingSet()
+
+	for _, out := range results {
+		for _, f := range outputFindings(out) {
+			fs.add(f)
+		}
+	}
+	return fs.sorted()
+}
+
+// addFinding - Records the finding published by a service
+func (e *Enumeration) addFinding(f *core.Finding) {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.findings == nil {
+		e.findings = newFindingSet()
+	}
+	e.findings.add(f)
+}
+
+// Findings - Returns the notable findings of the enumeration, with the most severe first
+func (e *Enumeration) Findings() []*core.Finding {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.findings == nil {
+		return nil
+	}
+	return e.findings.sorted()
+}
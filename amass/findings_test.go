@@ -0,0 +1,79 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+func TestCollectFindings(t *testing.T) {
+	results := []*AmassOutput{
+		{Name: "docs.owasp.org", Domain: "owasp.org", NoData: true, Records: []AmassRecordInfo{
+			{Type: "CNAME", Data: "owasp.github.io."},
+		}},
+		{Name: "vpn.owasp.org", Domain: "owasp.org", Addresses: []AmassAddressInfo{
+			{Address: net.ParseIP("10.0.0.1"), Reserved: "10.0.0.0/8"},
+			{Address: net.ParseIP("192.0.2.1")},
+		}},
+		{Name: "a.owasp.org", Domain: "owasp.org", Tag: core.AXFR, Source: dnssrv.ZoneXFRSource},
+		{Name: "b.owasp.org", Domain: "owasp.org", Tag: core.AXFR, Source: dnssrv.ZoneXFRSource},
+		{Name: "c.owasp.org", Domain: "owasp.org", Tag: core.AXFR, Source: "Zone File"},
+	}
+
+	findings := CollectFindings(results)
+	if len(findings) != 3 {
+		t.Fatalf("%d findings were returned instead of 3: %+v", len(findings), findings)
+	}
+
+	expected := []struct {
+		ftype    string
+		severity string
+		name     string
+		evidence []string
+	}{
+		{core.FindingZoneTransfer, core.SeverityHigh, "owasp.org", []string{"a.owasp.org", "b.owasp.org"}},
+		{core.FindingTakeover, core.SeverityHigh, "docs.owasp.org", []string{"CNAME owasp.github.io"}},
+		{core.FindingInternalIP, core.SeverityMedium, "vpn.owasp.org", []string{"10.0.0.1 (10.0.0.0/8)"}},
+	}
+	for i, e := range expected {
+		f := findings[i]
+
+		if f.Type != e.ftype || f.Severity != e.severity || f.Name != e.name || !reflect.DeepEqual(f.Evidence, e.evidence) {
+			t.Errorf("Finding %d was %+v, expected %s %s for %s with %v", i, f, e.severity, e.ftype, e.name, e.evidence)
+		}
+	}
+}
+
+func TestEnumerationFindings(t *testing.T) {
+	e := NewEnumeration()
+
+	for i := 0; i < maxEvidence+5; i++ {
+		e.addFinding(&core.Finding{
+			Type:     core.FindingZoneTransfer,
+			Severity: core.SeverityHigh,
+			Name:     "owasp.org",
+			Evidence: []string{fmt.Sprintf("host%d.owasp.org", i)},
+		})
+	}
+	e.addFinding(&core.Finding{Type: core.FindingDangling, Severity: core.SeverityLow, Name: "old.owasp.org"})
+
+	findings := e.Findings()
+	if len(findings) != 2 || findings[0].Type != core.FindingZoneTransfer {
+		t.Fatalf("The findings were not merged and sorted: %+v", findings)
+	}
+	if n := len(findings[0].Evidence); n != maxEvidence {
+		t.Errorf("The finding kept %d pieces of evidence instead of %d", n, maxEvidence)
+	}
+
+	findings[0].Evidence[0] = "changed"
+	if e.Findings()[0].Evidence[0] == "changed" {
+		t.Errorf("The findings returned were not copies")
+	}
+}
@@ -5,13 +5,17 @@ require (
 	github.com/PuerkitoBio/goquery v1.4.1
 	github.com/andybalholm/cascadia v1.0.0 // indirect
 	github.com/asaskevich/EventBus v0.0.0-20180315140547-d46933a94f05
+	github.com/golang/protobuf v1.2.0 // indirect
 	github.com/johnnadratowski/golang-neo4j-bolt-driver v0.0.0-20180720234410-c68f22031e42
 	github.com/miekg/dns v1.0.8
 	github.com/temoto/robotstxt v0.0.0-20170603013557-9e4646fa7053 // indirect
 	github.com/temoto/robotstxt-go v0.0.0-20170603013557-9e4646fa7053 // indirect
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/crypto v0.0.0-20180723164146-c126467f60eb // indirect
-	golang.org/x/net v0.0.0-20180724234803-3673e40ba225 // indirect
-	golang.org/x/sys v0.0.0-20180724212812-e072cadbbdc8 // indirect
+	golang.org/x/net v0.0.0-20180826012351-8a410e7b638d // indirect
+	golang.org/x/sys v0.0.0-20190204203706-41f3e6584952 // indirect
 	golang.org/x/text v0.3.0 // indirect
-	golang.org/x/tools v0.0.0-20180725152638-4d8a0ac9f66c // indirect
+	golang.org/x/tools v0.0.0-20180828015842-6cd1fcedba52 // indirect
+	google.golang.org/genproto v0.0.0-20180817151627-c66870c02cf8 // indirect
+	google.golang.org/grpc v1.15.0
 )
@@ -0,0 +1,170 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// The format name and version written to the header of the graph archives
+const (
+	ArchiveFormat  = "amass-graph"
+	ArchiveVersion = 1
+)
+
+// ArchiveHeader - The first JSON document of a graph archive, followed by one document
+// for each quad of the graph. The Origin names the machine or tester that exported the
+// graph, and is recorded for the quads that do not already carry their origins
+type ArchiveHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Origin  string    `json:"origin,omitempty"`
+	Created time.Time `json:"created"`
+	Quads   int       `json:"quads"`
+}
+
+// ExportArchive - Writes all the quads in the store to w as a gzip compressed archive,
+// so the entire graph can be moved to another machine with the timestamps of the runs
+func (qs *QuadStore) ExportArchive(w io.Writer, origin string) error {
+	qs.Lock()
+	var quads []*Quad
+	for _, q := range qs.quads {
+		c := *q
+		quads = append(quads, &c)
+	}
+	qs.Unlock()
+	// Sorting keeps the archives of the same graph identical
+	sort.Slice(quads, func(i, j int) bool {
+		a, b := quads[i], quads[j]
+
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		if a.Predicate != b.Predicate {
+			return a.Predicate < b.Predicate
+		}
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+		return a.Label < b.Label
+	})
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	if err := enc.Encode(&ArchiveHeader{
+		Format:  ArchiveFormat,
+		Version: ArchiveVersion,
+		Origin:  origin,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Quads:   len(quads),
+	}); err != nil {
+		return fmt.Errorf("Archive error: Failed to write the header: %v", err)
+	}
+
+	for _, q := range quads {
+		if err := enc.Encode(q); err != nil {
+			return fmt.Errorf("Archive error: Failed to write the quad: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("Archive error: %v", err)
+	}
+	return nil
+}
+
+// ReadArchive - Returns the header of the graph archive read from r, and calls fn with
+// the header and each of the quads that follow it
+func ReadArchive(r io.Reader, fn func(*ArchiveHeader, *Quad) error) (*ArchiveHeader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Archive error: The file is not a graph archive: %v", err)
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(zr)
+	header := new(ArchiveHeader)
+	if err := dec.Decode(header); err != nil || header.Format != ArchiveFormat {
+		return nil, fmt.Errorf("Archive error: The file is not a graph archive")
+	}
+	if header.Version > ArchiveVersion {
+		return nil, fmt.Errorf("Archive error: Version %d is newer than the supported version %d",
+			header.Version, ArchiveVersion)
+	}
+
+	for num := 1; ; num++ {
+		q := new(Quad)
+
+		if err := dec.Decode(q); err == io.EOF {
+			break
+		} else if err != nil {
+			return header, fmt.Errorf("Archive error: Failed to read quad %d: %v", num, err)
+		}
+		if err := fn(header, q); err != nil {
+			return header, err
+		}
+	}
+	return header, nil
+}
+
+// ImportArchive - Adds the quads of the graph archive read from r to the store, keeping
+// the timestamps of the runs and the origins that observed them. It returns the number
+// of quads that were not already covered by the store
+func (qs *QuadStore) ImportArchive(r io.Reader) (int, error) {
+	var added int
+
+	_, err := ReadArchive(r, func(header *ArchiveHeader, q *Quad) error {
+		changed, err := qs.importQuad(q, header.Origin)
+		if changed {
+			added++
+		}
+		return err
+	})
+	return added, err
+}
+
+// MergeStore - Adds the quads of the other store, such as the local graph of another
+// tester, recording the origin for the quads that do not already carry their origins.
+// It returns the number of quads that were not already covered by the store
+func (qs *QuadStore) MergeStore(other *QuadStore, origin string) (int, error) {
+	other.Lock()
+	var quads []*Quad
+	for _, q := range other.quads {
+		c := *q
+		quads = append(quads, &c)
+	}
+	other.Unlock()
+
+	var added int
+	for _, q := range quads {
+		changed, err := qs.importQuad(q, origin)
+		if err != nil {
+			return added, err
+		}
+		if changed {
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (qs *QuadStore) importQuad(q *Quad, origin string) (bool, error) {
+	if q.Subject == "" || q.Predicate == "" || q.Object == "" {
+		return false, nil
+	}
+	q.FirstSeen = q.FirstSeen.UTC()
+	q.LastSeen = q.LastSeen.UTC()
+	if len(q.Origins) == 0 && origin != "" {
+		q.Origins = []string{origin}
+	}
+
+	qs.Lock()
+	defer qs.Unlock()
+
+	return qs.mergeQuad(q)
+}
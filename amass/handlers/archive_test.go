@@ -0,0 +1,141 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuadStoreArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-archive-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := NewQuadStore(filepath.Join(dir, "src.json"))
+	if err != nil {
+		t.Fatalf("Failed to create the quad store: %v", err)
+	}
+	defer src.Close()
+
+	first := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	_, cidr, _ := net.ParseCIDR("192.0.2.0/24")
+	src.SetObservationTime(first)
+	src.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "dns", "Forward DNS")
+	src.InsertInfrastructure("192.0.2.10", 64496, cidr, "TEST-NET")
+	src.SetObservationTime(first.Add(24 * time.Hour))
+	src.InsertCNAME("docs.owasp.org", "owasp.org", "www.owasp.org", "owasp.org", "dns", "Forward DNS")
+	src.InsertHistory("www.owasp.org", "owasp.org", "A", "203.0.113.5", "", first.AddDate(-1, 0, 0), first, "api", "SecurityTrails")
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive, ""); err != nil {
+		t.Fatalf("The archive was not exported: %v", err)
+	}
+	data := archive.Bytes()
+
+	dst, err := NewQuadStore(filepath.Join(dir, "dst.json"))
+	if err != nil {
+		t.Fatalf("Failed to create the quad store: %v", err)
+	}
+	defer dst.Close()
+
+	added, err := dst.ImportArchive(bytes.NewReader(data))
+	if err != nil || added != src.Len() || dst.Len() != src.Len() {
+		t.Fatalf("Imported %d of %d quads: %v", added, src.Len(), err)
+	}
+	if !reflect.DeepEqual(dst.Runs(), src.Runs()) {
+		t.Errorf("The runs %v were imported as %v", src.Runs(), dst.Runs())
+	}
+	if !reflect.DeepEqual(dst.Inventory(), src.Inventory()) {
+		t.Errorf("The assets were not imported with their timestamps")
+	}
+
+	if added, err := dst.ImportArchive(bytes.NewReader(data)); err != nil || added != 0 {
+		t.Errorf("Importing the archive again added %d quads: %v", added, err)
+	}
+	if _, err := dst.ImportArchive(strings.NewReader(`{"subject":"www.owasp.org"}`)); err == nil {
+		t.Errorf("A file that is not a graph archive was accepted")
+	}
+}
+
+func TestQuadStoreMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-merge-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	open := func(name string) *QuadStore {
+		qs, err := NewQuadStore(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to create the quad store: %v", err)
+		}
+		return qs
+	}
+
+	alice := open("alice.json")
+	defer alice.Close()
+	alice.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "dns", "Forward DNS")
+
+	bob := open("bob.json")
+	defer bob.Close()
+	bob.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "dns", "Forward DNS")
+	bob.InsertA("vpn.owasp.org", "owasp.org", "192.0.2.20", "brute", "Brute Forcing")
+
+	var archive bytes.Buffer
+	if err := alice.ExportArchive(&archive, "alice"); err != nil {
+		t.Fatalf("The archive was not exported: %v", err)
+	}
+
+	merged := open("merged.json")
+	defer merged.Close()
+	if _, err := merged.ImportArchive(&archive); err != nil {
+		t.Fatalf("The archive was not imported: %v", err)
+	}
+	if _, err := merged.MergeStore(bob, "bob"); err != nil {
+		t.Fatalf("The store was not merged: %v", err)
+	}
+	// Merging the same graph again does not change the store
+	if added, err := merged.MergeStore(bob, "bob"); err != nil || added != 0 {
+		t.Errorf("Merging the store again added %d quads: %v", added, err)
+	}
+
+	origins := func(subject, predicate, object string) []string {
+		q := merged.quads[quadKey{subject, predicate, object, ""}]
+		if q == nil {
+			return nil
+		}
+		return q.Origins
+	}
+	if o := origins("www.owasp.org", "a_to", "192.0.2.10"); !reflect.DeepEqual(o, []string{"alice", "bob"}) {
+		t.Errorf("The statement observed by both testers has the origins %v", o)
+	}
+	if o := origins("vpn.owasp.org", "a_to", "192.0.2.20"); !reflect.DeepEqual(o, []string{"bob"}) {
+		t.Errorf("The statement observed by one tester has the origins %v", o)
+	}
+
+	// The origins are kept when the merged graph is archived again
+	var out bytes.Buffer
+	if err := merged.ExportArchive(&out, "lead"); err != nil {
+		t.Fatalf("The merged archive was not exported: %v", err)
+	}
+	final := open("final.json")
+	defer final.Close()
+	if _, err := final.ImportArchive(&out); err != nil {
+		t.Fatalf("The merged archive was not imported: %v", err)
+	}
+	if q := final.quads[quadKey{"www.owasp.org", "a_to", "192.0.2.10", ""}]; q == nil ||
+		!reflect.DeepEqual(q.Origins, []string{"alice", "bob"}) {
+		t.Errorf("The origins were not kept by the archive: %+v", q)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// The number of batches kept while the remote service cannot be reached, before the
+// oldest documents are dropped
+const maxPendingBatches = 100
+
+// batchWriter - Collects the data operations written by the DataOptsHandler, one JSON
+// document for each write, and sends them in batches to a remote service. The batches are
+// sent in the background, so a slow service does not hold up the writes, and a batch that
+// could not be sent is kept and sent again with the next one
+type batchWriter struct {
+	sync.Mutex
+	docs [][]byte
+
+	// The reason the last batch could not be sent, and the documents dropped since
+	err     error
+	dropped int
+
+	// The batch is sent when it holds size documents, or when the interval has passed
+	size     int
+	interval time.Duration
+	send     func(docs [][]byte) error
+
+	// Only one batch is sent at a time
+	sending sync.Mutex
+	ready   chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newBatchWriter(size int, interval time.Duration, send func(docs [][]byte) error) *batchWriter {
+	b := &batchWriter{
+		size:     size,
+		interval: interval,
+		send:     send,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go b.sendBatches()
+	return b
+}
+
+func (b *batchWriter) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	doc := make([]byte, len(p))
+	copy(doc, p)
+	b.docs = append(b.docs, doc)
+	b.limitPending()
+
+	if len(b.docs) >= b.size {
+		select {
+		case b.ready <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Flush - Sends the documents that have not been sent yet, and reports the documents
+// that could not be sent
+func (b *batchWriter) Flush() error {
+	if err := b.sendPending(); err != nil {
+		return err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if b.dropped > 0 {
+		return fmt.Errorf("%d documents were dropped while the service could not be reached: %v", b.dropped, b.err)
+	}
+	return nil
+}
+
+// Close - Stops sending the batches in the background, and sends the documents that remain
+func (b *batchWriter) Close() error {
+	b.once.Do(func() { close(b.done) })
+
+	return b.Flush()
+}
+
+func (b *batchWriter) sendBatches() {
+	t := time.NewTicker(b.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.ready:
+			b.sendPending()
+		case <-t.C:
+			b.sendPending()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// sendPending - Sends the documents in batches of at most size documents. The batch that
+// could not be sent is put back ahead of the documents written since
+func (b *batchWriter) sendPending() error {
+	b.sending.Lock()
+	defer b.sending.Unlock()
+
+	for {
+		b.Lock()
+		if len(b.docs) == 0 {
+			b.Unlock()
+			return nil
+		}
+
+		n := len(b.docs)
+		if n > b.size {
+			n = b.size
+		}
+		docs := b.docs[:n:n]
+		b.docs = b.docs[n:]
+		b.Unlock()
+
+		err := b.send(docs)
+
+		b.Lock()
+		b.err = err
+		if err != nil {
+			b.docs = append(docs, b.docs...)
+			b.limitPending()
+			b.Unlock()
+			return err
+		}
+		b.Unlock()
+	}
+}
+
+// limitPending - Drops the oldest documents beyond the pending batches kept. The lock
+// must be held by the caller
+func (b *batchWriter) limitPending() {
+	if extra := len(b.docs) - b.size*maxPendingBatches; extra > 0 {
+		b.docs = b.docs[extra:]
+		b.dropped += extra
+	}
+}
+
+// multiWriter - Writes to every writer even when some of them fail
+type multiWriter struct {
+	writers []io.Writer
+}
+
+// MultiWriter - Returns a writer duplicating the writes to each of the writers. Unlike
+// io.MultiWriter, a writer that fails does not keep the others from receiving the write,
+// and the first of the errors is returned
+func MultiWriter(writers ...io.Writer) io.Writer {
+	return &multiWriter{writers: writers}
+}
+
+func (m *multiWriter) Write(p []byte) (int, error) {
+	var first error
+
+	for _, w := range m.writers {
+		if _, err := w.Write(p); err != nil && first == nil {
+			first = err
+		}
+	}
+	if first != nil {
+		return 0, first
+	}
+	return len(p), nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchWriterRetry(t *testing.T) {
+	var lock sync.Mutex
+	var sent []string
+	fail := true
+
+	b := newBatchWriter(2, time.Hour, func(docs [][]byte) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if fail {
+			return errors.New("The service is unavailable")
+		}
+		for _, doc := range docs {
+			sent = append(sent, string(doc))
+		}
+		return nil
+	})
+
+	for _, doc := range []string{"a", "b", "c"} {
+		// The writes are not held up or failed by the service
+		if _, err := b.Write([]byte(doc)); err != nil {
+			t.Fatalf("The write failed: %v", err)
+		}
+	}
+	if err := b.Flush(); err == nil {
+		t.Errorf("The failure of the service was not reported")
+	}
+
+	lock.Lock()
+	fail = false
+	lock.Unlock()
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+	if len(sent) != 3 || sent[0] != "a" || sent[2] != "c" {
+		t.Errorf("The documents sent after the failure were %v", sent)
+	}
+}
+
+func TestBatchWriterDropped(t *testing.T) {
+	b := newBatchWriter(1, time.Hour, func(docs [][]byte) error {
+		return errors.New("The service is unavailable")
+	})
+	defer b.Close()
+
+	for i := 0; i < maxPendingBatches+5; i++ {
+		b.Write([]byte("doc"))
+	}
+	b.Flush()
+
+	b.Lock()
+	defer b.Unlock()
+	if len(b.docs) != maxPendingBatches || b.dropped != 5 {
+		t.Errorf("%d documents were kept and %d dropped", len(b.docs), b.dropped)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("The sink failed")
+}
+
+func TestMultiWriter(t *testing.T) {
+	var first, second bytes.Buffer
+
+	w := MultiWriter(&first, failingWriter{}, &second)
+	if _, err := w.Write([]byte("doc")); err == nil {
+		t.Errorf("The failure of the sink was not returned")
+	}
+	if first.String() != "doc" || second.String() != "doc" {
+		t.Errorf("The failing sink stopped the others: %q, %q", first.String(), second.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"io"
 	"net"
+	"time"
 )
 
 type DataOptsHandler struct {
@@ -45,6 +46,17 @@ func DataOptsDriver(data []JSONFileFormat, handler DataHandler) error {
 			if _, ipnet, err = net.ParseCIDR(opt.CIDR); err == nil {
 				err = handler.InsertInfrastructure(opt.Address, opt.ASN, ipnet, opt.Description)
 			}
+		case OptRecord:
+			err = handler.InsertRecord(opt.Name, opt.Domain, opt.RecordType, opt.TTL, opt.Priority, opt.Data)
+		case OptHistory:
+			var first, last time.Time
+			if first, err = time.Parse(time.RFC3339, opt.FirstSeen); err != nil {
+				break
+			}
+			if last, err = time.Parse(time.RFC3339, opt.LastSeen); err == nil {
+				err = handler.InsertHistory(opt.Name, opt.Domain, opt.RecordType, opt.Data,
+					opt.TargetDomain, first, last, opt.Tag, opt.Source)
+			}
 		}
 		if err != nil {
 			break
@@ -170,3 +182,30 @@ func (d *DataOptsHandler) InsertInfrastructure(addr string, asn int, cidr *net.I
 		Description: desc,
 	})
 }
+
+func (d *DataOptsHandler) InsertRecord(name, domain, rrtype string, ttl, priority int, data string) error {
+	return d.Enc.Encode(&JSONFileFormat{
+		Type:       OptRecord,
+		Name:       name,
+		Domain:     domain,
+		RecordType: rrtype,
+		TTL:        ttl,
+		Priority:   priority,
+		Data:       data,
+	})
+}
+
+func (d *DataOptsHandler) InsertHistory(name, domain, rrtype, value, tdomain string, first, last time.Time, tag, source string) error {
+	return d.Enc.Encode(&JSONFileFormat{
+		Type:         OptHistory,
+		Name:         name,
+		Domain:       domain,
+		TargetDomain: tdomain,
+		RecordType:   rrtype,
+		Data:         value,
+		FirstSeen:    first.UTC().Format(time.RFC3339),
+		LastSeen:     last.UTC().Format(time.RFC3339),
+		Tag:          tag,
+		Source:       source,
+	})
+}
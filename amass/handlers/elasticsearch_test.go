@@ -0,0 +1,91 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestElasticsearch(t *testing.T) {
+	var template, user string
+	var docs []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		user, _, _ = r.BasicAuth()
+
+		switch r.URL.Path {
+		case "/_index_template/recon":
+			template = string(body)
+		case "/_bulk":
+			scanner := bufio.NewScanner(bytes.NewReader(body))
+			for scanner.Scan() {
+				var doc map[string]interface{}
+				json.Unmarshal(scanner.Bytes(), &doc)
+				if _, found := doc["index"]; !found {
+					docs = append(docs, doc)
+				}
+			}
+			fmt.Fprint(w, `{"errors":false,"items":[]}`)
+		}
+	}))
+	defer srv.Close()
+
+	es, err := NewElasticsearch(strings.Replace(srv.URL, "http://", "http://elastic:secret@", 1) + "/recon")
+	if err != nil {
+		t.Fatalf("NewElasticsearch returned an error: %v", err)
+	}
+	if !strings.Contains(template, `"index_patterns": ["recon*"]`) {
+		t.Errorf("The index template was not installed: %s", template)
+	}
+	if user != "elastic" {
+		t.Errorf("The credentials were not sent")
+	}
+
+	h := NewDataOptsHandler(es)
+	h.InsertDomain("owasp.org", "dns", "Forward DNS")
+	h.InsertA("www.owasp.org", "owasp.org", "192.0.2.1", "cert", "Crtsh")
+	if len(docs) != 0 {
+		t.Errorf("The documents were indexed before the batch was full")
+	}
+	if err := es.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("%d documents were indexed, expected 2", len(docs))
+	}
+	if docs[1]["name"] != "www.owasp.org" || docs[1]["addr"] != "192.0.2.1" || docs[1]["@timestamp"] == nil {
+		t.Errorf("The document was not indexed correctly: %v", docs[1])
+	}
+}
+
+func TestElasticsearchRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_bulk" {
+			fmt.Fprint(w, `{"errors":true,"items":[{"index":{"error":{"type":"mapper_parsing_exception"}}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	es, err := NewElasticsearch(srv.URL)
+	if err != nil {
+		t.Fatalf("NewElasticsearch returned an error: %v", err)
+	}
+	if es.index != "amass" {
+		t.Errorf("The default index was %q", es.index)
+	}
+
+	NewDataOptsHandler(es).InsertDomain("owasp.org", "dns", "Forward DNS")
+	if err := es.Close(); err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("The rejected document was not reported: %v", err)
+	}
+}
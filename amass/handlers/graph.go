@@ -6,21 +6,32 @@ package handlers
 import (
 	"net"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/amass/utils/viz"
 )
 
 type Edge struct {
-	From, To int
-	Label    string
-	idx      int
+	From, To   int
+	Label      string
+	Properties map[string]string
+	idx        int
+}
+
+type Record struct {
+	Type     string
+	TTL      int
+	Priority int
+	Data     string
 }
 
 type Node struct {
 	Edges      []int
 	Labels     []string
 	Properties map[string]string
+	Records    []Record
 	idx        int
 }
 
@@ -32,6 +43,8 @@ type Graph struct {
 	PTRs       map[string]*Node
 	Netblocks  map[string]*Node
 	ASNs       map[int]*Node
+	// The name and mail servers outside of the scope that the subdomains used in the past
+	Hosts      map[string]*Node
 	Nodes      []*Node
 	curNodeIdx int
 	Edges      []*Edge
@@ -46,6 +59,7 @@ func NewGraph() *Graph {
 		PTRs:       make(map[string]*Node),
 		Netblocks:  make(map[string]*Node),
 		ASNs:       make(map[int]*Node),
+		Hosts:      make(map[string]*Node),
 	}
 }
 
@@ -87,6 +101,26 @@ func (g *Graph) NewEdge(from, to int, label string) *Edge {
 	return e
 }
 
+// SetSubdomainProperty - Records additional information on the node for the subdomain name
+func (g *Graph) SetSubdomainProperty(name, key, value string) {
+	g.Lock()
+	defer g.Unlock()
+
+	if sub, found := g.Subdomains[name]; found {
+		sub.Properties[key] = value
+	}
+}
+
+// SetAddressProperty - Records additional information on the node for the IP address
+func (g *Graph) SetAddressProperty(addr, key, value string) {
+	g.Lock()
+	defer g.Unlock()
+
+	if a, found := g.Addresses[addr]; found {
+		a.Properties[key] = value
+	}
+}
+
 func (g *Graph) VizData() ([]viz.Node, []viz.Edge) {
 	g.Lock()
 	defer g.Unlock()
@@ -430,3 +464,119 @@ func (g *Graph) InsertInfrastructure(addr string, asn int, cidr *net.IPNet, desc
 	g.NewEdge(as, n, "HAS_PREFIX")
 	return nil
 }
+
+func (g *Graph) InsertRecord(name, domain, rrtype string, ttl, priority int, data string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	sub, found := g.Subdomains[name]
+	if !found {
+		return nil
+	}
+
+	for i, r := range sub.Records {
+		if r.Type == rrtype && r.Data == data {
+			sub.Records[i].TTL = ttl
+			sub.Records[i].Priority = priority
+			return nil
+		}
+	}
+
+	sub.Records = append(sub.Records, Record{
+		Type:     rrtype,
+		TTL:      ttl,
+		Priority: priority,
+		Data:     data,
+	})
+	return nil
+}
+
+// InsertHistory - Adds an edge from the name to the previous value of the record, which holds
+// the dates that the value was first and last observed
+func (g *Graph) InsertHistory(name, domain, rrtype, value, tdomain string, first, last time.Time, tag, source string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	g.historicalSubdomain(name, domain, tag, source)
+
+	var target *Node
+	switch rrtype = strings.ToUpper(rrtype); rrtype {
+	case "A", "AAAA":
+		if _, found := g.Addresses[value]; !found {
+			a := g.NewNode("IPAddress")
+			a.Properties["addr"] = value
+			a.Properties["type"] = "IPv4"
+			if rrtype == "AAAA" {
+				a.Properties["type"] = "IPv6"
+			}
+			g.Addresses[value] = a
+		}
+		target = g.Addresses[value]
+	case "NS", "MX":
+		if tdomain != "" {
+			target = g.historicalSubdomain(value, tdomain, tag, source)
+			break
+		}
+
+		// The servers outside of the scope are not stored as subdomains
+		if _, found := g.Hosts[value]; !found {
+			h := g.NewNode(rrtype)
+			h.Properties["name"] = value
+			h.Properties["tag"] = tag
+			h.Properties["source"] = source
+			g.Hosts[value] = h
+		}
+		target = g.Hosts[value]
+	default:
+		// The other names are only stored when within the scope
+		if tdomain == "" {
+			return nil
+		}
+		target = g.historicalSubdomain(value, tdomain, tag, source)
+	}
+
+	from := g.Subdomains[name].idx
+	label := "HISTORICAL_" + rrtype + "_TO"
+	e := g.NewEdge(from, target.idx, label)
+	if e == nil {
+		// The edge already exists, so the observed dates are widened
+		for _, idx := range g.Nodes[from].Edges {
+			if edge := g.Edges[idx]; edge.Label == label && edge.From == from && edge.To == target.idx {
+				e = edge
+				break
+			}
+		}
+	}
+	if e.Properties == nil {
+		e.Properties = make(map[string]string)
+	}
+
+	f, l := first.UTC().Format(time.RFC3339), last.UTC().Format(time.RFC3339)
+	if cur, found := e.Properties["first_seen"]; !found || f < cur {
+		e.Properties["first_seen"] = f
+	}
+	if cur, found := e.Properties["last_seen"]; !found || l > cur {
+		e.Properties["last_seen"] = l
+	}
+	e.Properties["source"] = source
+	return nil
+}
+
+// historicalSubdomain - Returns the node of the subdomain, which is inserted beneath its root
+// domain when not already known. The caller must hold the lock
+func (g *Graph) historicalSubdomain(name, domain, tag, source string) *Node {
+	if sub, found := g.Subdomains[name]; found {
+		return sub
+	}
+
+	sub := g.NewNode("Subdomain")
+	sub.Properties["name"] = name
+	sub.Properties["tag"] = tag
+	sub.Properties["source"] = source
+	g.Subdomains[name] = sub
+
+	if d, found := g.Domains[domain]; found && name != domain {
+		g.NewEdge(d.idx, sub.idx, "ROOT_OF")
+	}
+	return sub
+}
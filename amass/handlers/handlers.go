@@ -5,6 +5,7 @@ package handlers
 
 import (
 	"net"
+	"time"
 )
 
 const (
@@ -17,6 +18,8 @@ const (
 	OptNS             = "ns"
 	OptMX             = "mx"
 	OptInfrastructure = "infrastructure"
+	OptRecord         = "record"
+	OptHistory        = "history"
 )
 
 type DataHandler interface {
@@ -37,6 +40,11 @@ type DataHandler interface {
 	InsertMX(name, domain, target, tdomain, tag, source string) error
 
 	InsertInfrastructure(addr string, asn int, cidr *net.IPNet, desc string) error
+
+	InsertRecord(name, domain, rrtype string, ttl, priority int, data string) error
+
+	// The tdomain is the root domain of a value within the scope, and is empty otherwise
+	InsertHistory(name, domain, rrtype, value, tdomain string, first, last time.Time, tag, source string) error
 }
 
 type JSONFileFormat struct {
@@ -52,4 +60,10 @@ type JSONFileFormat struct {
 	Description  string `json:"desc"`
 	Tag          string `json:"tag"`
 	Source       string `json:"source"`
+	RecordType   string `json:"rrtype,omitempty"`
+	TTL          int    `json:"ttl,omitempty"`
+	Priority     int    `json:"priority,omitempty"`
+	Data         string `json:"data,omitempty"`
+	FirstSeen    string `json:"first_seen,omitempty"`
+	LastSeen     string `json:"last_seen,omitempty"`
 }
@@ -0,0 +1,149 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"sort"
+	"time"
+)
+
+// The node types that represent names in the quad store
+var nameTypes = map[string]struct{}{
+	"domain":    {},
+	"subdomain": {},
+	"ns":        {},
+	"mx":        {},
+}
+
+// Asset - A name and the runs that first and last observed it
+type Asset struct {
+	Name      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// AddressChange - The addresses a name gained or lost within a date range
+type AddressChange struct {
+	Name    string
+	Added   []string
+	Removed []string
+}
+
+// LastRun - Returns the most recent time that statements were observed
+func (qs *QuadStore) LastRun() time.Time {
+	qs.Lock()
+	defer qs.Unlock()
+
+	return qs.lastRun
+}
+
+// Assets - Returns the names held in the store along with when they were observed
+func (qs *QuadStore) Assets() []*Asset {
+	qs.Lock()
+	defer qs.Unlock()
+
+	var assets []*Asset
+	for subject, quads := range qs.out {
+		if !qs.isName(subject) {
+			continue
+		}
+
+		a := &Asset{Name: subject}
+		for _, q := range quads {
+			if historyPredicate(q.Predicate) {
+				continue
+			}
+			if a.FirstSeen.IsZero() || q.FirstSeen.Before(a.FirstSeen) {
+				a.FirstSeen = q.FirstSeen
+			}
+			if q.LastSeen.After(a.LastSeen) {
+				a.LastSeen = q.LastSeen
+			}
+		}
+		assets = append(assets, a)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].Name < assets[j].Name
+	})
+	return assets
+}
+
+// Disappeared - Returns the names last observed within the date range that
+// were not observed by the most recent run
+func (qs *QuadStore) Disappeared(from, to time.Time) []*Asset {
+	last := qs.LastRun()
+
+	var gone []*Asset
+	for _, a := range qs.Assets() {
+		if a.LastSeen.Before(last) && inRange(a.LastSeen, from, to) {
+			gone = append(gone, a)
+		}
+	}
+	return gone
+}
+
+// AddressChanges - Returns the names that gained addresses, or stopped resolving to
+// addresses while still being observed, within the date range
+func (qs *QuadStore) AddressChanges(from, to time.Time) []*AddressChange {
+	assets := make(map[string]*Asset)
+	for _, a := range qs.Assets() {
+		assets[a.Name] = a
+	}
+
+	qs.Lock()
+	defer qs.Unlock()
+
+	var changes []*AddressChange
+	for name, a := range assets {
+		c := &AddressChange{Name: name}
+
+		for _, q := range qs.out[name] {
+			if q.Predicate != "a_to" && q.Predicate != "aaaa_to" {
+				continue
+			}
+			// Addresses observed along with the name the first time are not changes
+			if q.FirstSeen.After(a.FirstSeen) && inRange(q.FirstSeen, from, to) {
+				c.Added = append(c.Added, q.Object)
+			}
+			if q.LastSeen.Before(a.LastSeen) && inRange(q.LastSeen, from, to) {
+				c.Removed = append(c.Removed, q.Object)
+			}
+		}
+
+		if len(c.Added) > 0 || len(c.Removed) > 0 {
+			sort.Strings(c.Added)
+			sort.Strings(c.Removed)
+			changes = append(changes, c)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func (qs *QuadStore) isName(subject string) bool {
+	for _, q := range qs.out[subject] {
+		if q.Predicate != "type" {
+			continue
+		}
+		if _, found := nameTypes[q.Object]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange - Zero times leave that end of the range open
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQuadStoreHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-history-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "graph.json")
+	first := time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	run := func(when time.Time, insert func(qs *QuadStore)) {
+		qs, err := NewQuadStore(path)
+		if err != nil {
+			t.Fatalf("Failed to open the quad store: %v", err)
+		}
+		defer qs.Close()
+
+		qs.SetObservationTime(when)
+		insert(qs)
+	}
+
+	run(first, func(qs *QuadStore) {
+		qs.InsertDomain("owasp.org", "dns", "Forward DNS")
+		qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "cert", "Censys")
+		qs.InsertA("old.owasp.org", "owasp.org", "192.0.2.20", "scrape", "Google")
+	})
+	run(second, func(qs *QuadStore) {
+		qs.InsertDomain("owasp.org", "dns", "Forward DNS")
+		qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.30", "cert", "Censys")
+	})
+
+	qs, err := NewQuadStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open the quad store: %v", err)
+	}
+	defer qs.Close()
+
+	if last := qs.LastRun(); !last.Equal(second) {
+		t.Errorf("The last run was %s instead of %s", last, second)
+	}
+
+	gone := qs.Disappeared(time.Time{}, time.Time{})
+	if len(gone) != 1 || gone[0].Name != "old.owasp.org" || !gone[0].LastSeen.Equal(first) {
+		t.Errorf("Disappeared returned %v instead of old.owasp.org", gone)
+	}
+	if gone := qs.Disappeared(second, time.Time{}); len(gone) != 0 {
+		t.Errorf("Disappeared returned %v outside of the date range", gone)
+	}
+
+	changes := qs.AddressChanges(time.Time{}, time.Time{})
+	expected := []*AddressChange{{
+		Name:    "www.owasp.org",
+		Added:   []string{"192.0.2.30"},
+		Removed: []string{"192.0.2.10"},
+	}}
+	if !reflect.DeepEqual(changes, expected) {
+		for _, c := range changes {
+			t.Errorf("AddressChanges returned the unexpected change %+v", c)
+		}
+	}
+}
+
+func TestInsertDNSHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-history-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+	old := time.Date(2015, time.January, 10, 0, 0, 0, 0, time.UTC)
+	moved := time.Date(2016, time.June, 20, 0, 0, 0, 0, time.UTC)
+
+	qs, err := NewQuadStore(filepath.Join(dir, "graph.json"))
+	if err != nil {
+		t.Fatalf("Failed to open the quad store: %v", err)
+	}
+	defer qs.Close()
+	qs.SetObservationTime(run)
+
+	qs.InsertDomain("owasp.org", "dns", "Forward DNS")
+	qs.InsertHistory("owasp.org", "owasp.org", "A", "198.51.100.7", "", old, moved, "api", "SecurityTrails")
+	// The dates already covered by the store are not written again
+	num := qs.Len()
+	qs.InsertHistory("owasp.org", "owasp.org", "A", "198.51.100.7", "", old.AddDate(0, 1, 0), moved, "api", "SecurityTrails")
+	if qs.Len() != num {
+		t.Errorf("The statement was stored again for dates within the previous range")
+	}
+
+	if nodes, _ := qs.Query("owasp.org out:a_history"); !reflect.DeepEqual(nodes, []string{"198.51.100.7"}) {
+		t.Errorf("The history query returned %v", nodes)
+	}
+	// The dates reported by the data source are not mistaken for the runs
+	if last := qs.LastRun(); !last.Equal(run) {
+		t.Errorf("The last run was %s instead of %s", last, run)
+	}
+	for _, a := range qs.Assets() {
+		if a.Name == "owasp.org" && !a.FirstSeen.Equal(run) {
+			t.Errorf("The domain was first seen at %s instead of %s", a.FirstSeen, run)
+		}
+	}
+
+	g := NewGraph()
+	g.InsertDomain("owasp.org", "dns", "Forward DNS")
+	g.InsertHistory("owasp.org", "owasp.org", "A", "198.51.100.7", "", moved, moved, "api", "WhoisXML")
+	g.InsertHistory("owasp.org", "owasp.org", "A", "198.51.100.7", "", old, old, "api", "SecurityTrails")
+	if len(g.Edges) != 1 {
+		t.Fatalf("The graph held %d edges instead of one", len(g.Edges))
+	}
+	e := g.Edges[0]
+	if e.Label != "HISTORICAL_A_TO" || e.Properties["first_seen"] != "2015-01-10T00:00:00Z" ||
+		e.Properties["last_seen"] != "2016-06-20T00:00:00Z" {
+		t.Errorf("The graph recorded the edge %s %v", e.Label, e.Properties)
+	}
+}
+
+func TestGraphHistoryScope(t *testing.T) {
+	seen := time.Date(2016, time.June, 20, 0, 0, 0, 0, time.UTC)
+
+	g := NewGraph()
+	g.InsertDomain("owasp.org", "dns", "Forward DNS")
+	g.InsertHistory("www.owasp.org", "owasp.org", "NS", "ns1.oldhost.net", "", seen, seen, "api", "SecurityTrails")
+	g.InsertHistory("www.owasp.org", "owasp.org", "CNAME", "web.owasp.org", "owasp.org", seen, seen, "api", "SecurityTrails")
+	g.InsertHistory("www.owasp.org", "owasp.org", "CNAME", "cdn.example.net", "", seen, seen, "api", "SecurityTrails")
+
+	if sub, found := g.Subdomains["www.owasp.org"]; !found || sub.Properties["tag"] != "api" {
+		t.Errorf("The name was not inserted as a subdomain with the tag of the request")
+	}
+	if _, found := g.Subdomains["ns1.oldhost.net"]; found {
+		t.Errorf("The name server outside of the scope was inserted as a subdomain")
+	}
+	if ns, found := g.Hosts["ns1.oldhost.net"]; !found || ns.Labels[0] != "NS" {
+		t.Errorf("The name server outside of the scope was not inserted")
+	}
+	if _, found := g.Subdomains["cdn.example.net"]; found || len(g.Edges) != 4 {
+		t.Errorf("The alias outside of the scope was inserted")
+	}
+	if _, found := g.Subdomains["web.owasp.org"]; !found {
+		t.Errorf("The target within the scope was not inserted as a subdomain")
+	}
+}
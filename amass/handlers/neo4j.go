@@ -4,7 +4,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	bolt "github.com/johnnadratowski/golang-neo4j-bolt-driver"
 	//"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
@@ -354,3 +357,60 @@ func (n *Neo4j) InsertInfrastructure(addr string, asn int, cidr *net.IPNet, desc
 		"MERGE (as)-[:HAS_PREFIX]->(netblock)", params)
 	return err
 }
+
+func (n *Neo4j) InsertRecord(name, domain, rrtype string, ttl, priority int, data string) error {
+	params := map[string]interface{}{
+		"name":     name,
+		"type":     rrtype,
+		"ttl":      ttl,
+		"priority": priority,
+		"data":     data,
+	}
+
+	_, err := n.conn.ExecNeo("MATCH (sub:Subdomain {name: {name}}) "+
+		"MERGE (r:DNSRecord {name: {name}, type: {type}, data: {data}}) "+
+		"SET r.ttl = {ttl}, r.priority = {priority} "+
+		"MERGE (sub)-[:HAS_RECORD]->(r)", params)
+	return err
+}
+
+func (n *Neo4j) InsertHistory(name, domain, rrtype, value, tdomain string, first, last time.Time, tag, source string) error {
+	rrtype = strings.ToUpper(rrtype)
+	switch rrtype {
+	case "A", "AAAA", "CNAME", "NS", "MX":
+	default:
+		return fmt.Errorf("Neo4j error: The %s records cannot be stored as history", rrtype)
+	}
+
+	params := map[string]interface{}{
+		"name":   name,
+		"value":  value,
+		"first":  first.UTC().Format(time.RFC3339),
+		"last":   last.UTC().Format(time.RFC3339),
+		"tag":    tag,
+		"source": source,
+	}
+
+	target := "(t:Subdomain {name: {value}}) ON CREATE SET t.tag = {tag}, t.source = {source}"
+	switch {
+	case rrtype == "A" || rrtype == "AAAA":
+		target = "(t:IPAddress {addr: {value}})"
+	case tdomain != "":
+	case rrtype == "NS" || rrtype == "MX":
+		// The servers outside of the scope are not stored as subdomains
+		target = "(t:" + rrtype + " {name: {value}})"
+	default:
+		// The other names are only stored when within the scope
+		return nil
+	}
+
+	// The relationship type cannot be a parameter, so it is limited to the types checked above
+	_, err := n.conn.ExecNeo("MERGE (sub:Subdomain {name: {name}}) "+
+		"ON CREATE SET sub.tag = {tag}, sub.source = {source} "+
+		"MERGE "+target+" "+
+		"MERGE (sub)-[r:HISTORICAL_"+rrtype+"_TO]->(t) "+
+		"SET r.first_seen = CASE WHEN r.first_seen IS NULL OR {first} < r.first_seen THEN {first} ELSE r.first_seen END, "+
+		"r.last_seen = CASE WHEN r.last_seen IS NULL OR {last} > r.last_seen THEN {last} ELSE r.last_seen END, "+
+		"r.source = {source}", params)
+	return err
+}
@@ -0,0 +1,467 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quad - A single subject, predicate and object statement in the graph, along with
+// the first and last runs that observed it, and the machines or testers that observed
+// it when the graph was merged from several archives
+type Quad struct {
+	Subject   string    `json:"subject"`
+	Predicate string    `json:"predicate"`
+	Object    string    `json:"object"`
+	Label     string    `json:"label,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Origins   []string  `json:"origins,omitempty"`
+}
+
+type quadKey struct {
+	subject, predicate, object, label string
+}
+
+func (q *Quad) key() quadKey {
+	return quadKey{q.Subject, q.Predicate, q.Object, q.Label}
+}
+
+// QuadStore - An embedded graph of quads kept in a local file, which can be queried
+// with Cayley style path traversals without running a database server
+type QuadStore struct {
+	sync.Mutex
+	file  *os.File
+	enc   *json.Encoder
+	quads map[quadKey]*Quad
+	out   map[string][]*Quad
+	in    map[string][]*Quad
+
+	// The time recorded for the statements observed while the store is open
+	now time.Time
+	// The most recent time that any statement was observed
+	lastRun time.Time
+}
+
+// NewQuadStore - Loads the quads saved in the file at path, and appends the new quads
+// to it. Each time the store is opened counts as a separate run for the timestamps
+func NewQuadStore(path string) (*QuadStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Quad store error: Failed to open %s: %v", path, err)
+	}
+
+	qs := &QuadStore{
+		file:  f,
+		quads: make(map[quadKey]*Quad),
+		out:   make(map[string][]*Quad),
+		in:    make(map[string][]*Quad),
+		now:   time.Now().UTC().Truncate(time.Second),
+	}
+
+	dec := json.NewDecoder(f)
+	for {
+		q := new(Quad)
+
+		if err := dec.Decode(q); err == io.EOF {
+			break
+		} else if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("Quad store error: Failed to read %s: %v", path, err)
+		}
+		qs.index(q)
+	}
+
+	qs.enc = json.NewEncoder(f)
+	return qs, nil
+}
+
+// SetObservationTime - Changes the time recorded for the statements observed from now on
+func (qs *QuadStore) SetObservationTime(t time.Time) {
+	qs.Lock()
+	defer qs.Unlock()
+
+	qs.now = t.UTC()
+}
+
+// Close - Closes the file holding the quads
+func (qs *QuadStore) Close() error {
+	return qs.file.Close()
+}
+
+// Len - Returns the number of quads in the store
+func (qs *QuadStore) Len() int {
+	qs.Lock()
+	defer qs.Unlock()
+
+	return len(qs.quads)
+}
+
+// AddQuad - Saves the statement, unless it is already present in the store
+func (qs *QuadStore) AddQuad(subject, predicate, object, label string) error {
+	qs.Lock()
+	defer qs.Unlock()
+
+	q := &Quad{
+		Subject:   subject,
+		Predicate: predicate,
+		Object:    object,
+		Label:     label,
+		FirstSeen: qs.now,
+		LastSeen:  qs.now,
+	}
+	// Statements already observed during this run are not written again
+	if cur, found := qs.quads[q.key()]; found {
+		if !cur.LastSeen.Before(qs.now) {
+			return nil
+		}
+		q.FirstSeen = cur.FirstSeen
+	}
+
+	if err := qs.enc.Encode(q); err != nil {
+		return fmt.Errorf("Quad store error: Failed to write the quad: %v", err)
+	}
+	qs.index(q)
+	return nil
+}
+
+// addHistoryQuad - Saves the statement with the dates reported by a data source, rather
+// than the time of the run, unless the dates are already covered by the store
+func (qs *QuadStore) addHistoryQuad(subject, predicate, object, label string, first, last time.Time) error {
+	qs.Lock()
+	defer qs.Unlock()
+
+	_, err := qs.mergeQuad(&Quad{
+		Subject:   subject,
+		Predicate: predicate,
+		Object:    object,
+		Label:     label,
+		FirstSeen: first.UTC(),
+		LastSeen:  last.UTC(),
+	})
+	return err
+}
+
+// mergeQuad - Saves the quad with its own timestamps, unless they are already covered by
+// the store, and returns true when the store was changed. The caller must hold the lock
+func (qs *QuadStore) mergeQuad(q *Quad) (bool, error) {
+	if cur, found := qs.quads[q.key()]; found && !q.FirstSeen.Before(cur.FirstSeen) &&
+		!q.LastSeen.After(cur.LastSeen) && len(mergeOrigins(cur.Origins, q.Origins)) == len(cur.Origins) {
+		return false, nil
+	}
+
+	if err := qs.enc.Encode(q); err != nil {
+		return false, fmt.Errorf("Quad store error: Failed to write the quad: %v", err)
+	}
+	qs.index(q)
+	return true, nil
+}
+
+// historyPredicate - Returns true when the quad holds a previous record value, so the
+// dates were reported by a data source instead of recorded by a run
+func historyPredicate(predicate string) bool {
+	return strings.HasSuffix(predicate, "_history")
+}
+
+// index - Adds the quad, or merges the timestamps when the statement is already present
+func (qs *QuadStore) index(q *Quad) {
+	if !historyPredicate(q.Predicate) && q.LastSeen.After(qs.lastRun) {
+		qs.lastRun = q.LastSeen
+	}
+
+	if cur, found := qs.quads[q.key()]; found {
+		if q.FirstSeen.Before(cur.FirstSeen) {
+			cur.FirstSeen = q.FirstSeen
+		}
+		if q.LastSeen.After(cur.LastSeen) {
+			cur.LastSeen = q.LastSeen
+		}
+		cur.Origins = mergeOrigins(cur.Origins, q.Origins)
+		return
+	}
+
+	q.Origins = mergeOrigins(nil, q.Origins)
+	qs.quads[q.key()] = q
+	qs.out[q.Subject] = append(qs.out[q.Subject], q)
+	qs.in[q.Object] = append(qs.in[q.Object], q)
+}
+
+// mergeOrigins - Returns the origins in either list, sorted
+func mergeOrigins(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+
+	set := make(map[string]struct{})
+	for _, o := range append(append([]string(nil), a...), b...) {
+		set[o] = struct{}{}
+	}
+
+	var origins []string
+	for o := range set {
+		origins = append(origins, o)
+	}
+	sort.Strings(origins)
+	return origins
+}
+
+func (qs *QuadStore) addQuads(quads ...[3]string) error {
+	for _, q := range quads {
+		if q[0] == "" || q[2] == "" {
+			continue
+		}
+		if err := qs.AddQuad(q[0], q[1], q[2], ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (qs *QuadStore) addName(name, domain, ntype, tag, source string) error {
+	return qs.addQuads(
+		[3]string{name, "type", ntype},
+		[3]string{name, "root", domain},
+		[3]string{name, "tag", tag},
+		[3]string{name, "source", source},
+	)
+}
+
+func (qs *QuadStore) InsertDomain(domain, tag, source string) error {
+	return qs.addName(domain, domain, "domain", tag, source)
+}
+
+func (qs *QuadStore) InsertCNAME(name, domain, target, tdomain, tag, source string) error {
+	if err := qs.addName(name, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	if err := qs.addName(target, tdomain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	return qs.AddQuad(name, "cname_to", target, "")
+}
+
+func (qs *QuadStore) InsertA(name, domain, addr, tag, source string) error {
+	if err := qs.addName(name, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	return qs.addQuads(
+		[3]string{addr, "type", "ipv4"},
+		[3]string{name, "a_to", addr},
+	)
+}
+
+func (qs *QuadStore) InsertAAAA(name, domain, addr, tag, source string) error {
+	if err := qs.addName(name, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	return qs.addQuads(
+		[3]string{addr, "type", "ipv6"},
+		[3]string{name, "aaaa_to", addr},
+	)
+}
+
+func (qs *QuadStore) InsertPTR(name, domain, target, tag, source string) error {
+	if err := qs.addName(target, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	return qs.addQuads(
+		[3]string{name, "type", "ptr"},
+		[3]string{name, "ptr_to", target},
+	)
+}
+
+func (qs *QuadStore) InsertSRV(name, domain, service, target, tag, source string) error {
+	for _, n := range []string{name, service, target} {
+		if err := qs.addName(n, domain, "subdomain", tag, source); err != nil {
+			return err
+		}
+	}
+	return qs.addQuads(
+		[3]string{name, "service", service},
+		[3]string{service, "srv_to", target},
+	)
+}
+
+func (qs *QuadStore) InsertNS(name, domain, target, tdomain, tag, source string) error {
+	if err := qs.addName(name, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	if err := qs.addName(target, tdomain, "ns", tag, source); err != nil {
+		return err
+	}
+	return qs.AddQuad(name, "ns_to", target, "")
+}
+
+func (qs *QuadStore) InsertMX(name, domain, target, tdomain, tag, source string) error {
+	if err := qs.addName(name, domain, "subdomain", tag, source); err != nil {
+		return err
+	}
+	if err := qs.addName(target, tdomain, "mx", tag, source); err != nil {
+		return err
+	}
+	return qs.AddQuad(name, "mx_to", target, "")
+}
+
+func (qs *QuadStore) InsertInfrastructure(addr string, asn int, cidr *net.IPNet, desc string) error {
+	as := "AS" + strconv.Itoa(asn)
+
+	return qs.addQuads(
+		[3]string{cidr.String(), "type", "netblock"},
+		[3]string{cidr.String(), "contains", addr},
+		[3]string{as, "type", "as"},
+		[3]string{as, "description", desc},
+		[3]string{as, "has_prefix", cidr.String()},
+	)
+}
+
+func (qs *QuadStore) InsertRecord(name, domain, rrtype string, ttl, priority int, data string) error {
+	return qs.AddQuad(name, strings.ToLower(rrtype)+"_record", data, "")
+}
+
+func (qs *QuadStore) InsertHistory(name, domain, rrtype, value, tdomain string, first, last time.Time, tag, source string) error {
+	return qs.addHistoryQuad(name, strings.ToLower(rrtype)+"_history", value, source, first, last)
+}
+
+// QuadPath - A traversal through the quad store, following the Cayley path style
+type QuadPath struct {
+	store *QuadStore
+	nodes map[string]struct{}
+}
+
+// Vertex - Starts a path from the nodes, or from every subject when none are provided
+func (qs *QuadStore) Vertex(nodes ...string) *QuadPath {
+	qs.Lock()
+	defer qs.Unlock()
+
+	p := &QuadPath{store: qs, nodes: make(map[string]struct{})}
+	if len(nodes) == 0 {
+		for n := range qs.out {
+			p.nodes[n] = struct{}{}
+		}
+	}
+	for _, n := range nodes {
+		p.nodes[n] = struct{}{}
+	}
+	return p
+}
+
+// Out - Follows the predicates from the subjects to the objects
+func (p *QuadPath) Out(predicates ...string) *QuadPath {
+	return p.follow(p.store.out, predicates, func(q *Quad) string { return q.Object })
+}
+
+// In - Follows the predicates from the objects back to the subjects
+func (p *QuadPath) In(predicates ...string) *QuadPath {
+	return p.follow(p.store.in, predicates, func(q *Quad) string { return q.Subject })
+}
+
+// Has - Keeps the nodes that have the predicate pointing to the object
+func (p *QuadPath) Has(predicate, object string) *QuadPath {
+	p.store.Lock()
+	defer p.store.Unlock()
+
+	next := &QuadPath{store: p.store, nodes: make(map[string]struct{})}
+	for n := range p.nodes {
+		for _, q := range p.store.out[n] {
+			if q.Predicate == predicate && q.Object == object {
+				next.nodes[n] = struct{}{}
+				break
+			}
+		}
+	}
+	return next
+}
+
+// All - Returns the nodes reached by the path
+func (p *QuadPath) All() []string {
+	var nodes []string
+
+	for n := range p.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func (p *QuadPath) follow(edges map[string][]*Quad, predicates []string, end func(*Quad) string) *QuadPath {
+	p.store.Lock()
+	defer p.store.Unlock()
+
+	next := &QuadPath{store: p.store, nodes: make(map[string]struct{})}
+	for n := range p.nodes {
+		for _, q := range edges[n] {
+			if matchPredicate(q.Predicate, predicates) {
+				next.nodes[end(q)] = struct{}{}
+			}
+		}
+	}
+	return next
+}
+
+func matchPredicate(predicate string, predicates []string) bool {
+	if len(predicates) == 0 {
+		return true
+	}
+
+	for _, p := range predicates {
+		if p == predicate {
+			return true
+		}
+	}
+	return false
+}
+
+// Query - Evaluates a path such as "owasp.org in:root out:a_to in:contains", where the
+// first element is the starting node (or * for all nodes) and the rest are the steps
+// out:predicate, in:predicate and has:predicate=object
+func (qs *QuadStore) Query(path string) ([]string, error) {
+	fields := strings.Fields(path)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Quad store error: The query path is empty")
+	}
+
+	var p *QuadPath
+	if fields[0] == "*" {
+		p = qs.Vertex()
+	} else {
+		p = qs.Vertex(strings.Split(fields[0], ",")...)
+	}
+
+	for _, step := range fields[1:] {
+		parts := strings.SplitN(step, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Quad store error: The step %q is missing the predicate", step)
+		}
+
+		var predicates []string
+		if parts[1] != "" && parts[1] != "*" {
+			predicates = strings.Split(parts[1], ",")
+		}
+
+		switch parts[0] {
+		case "out":
+			p = p.Out(predicates...)
+		case "in":
+			p = p.In(predicates...)
+		case "has":
+			kv := strings.SplitN(parts[1], "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("Quad store error: The step %q must be has:predicate=object", step)
+			}
+			p = p.Has(kv[0], kv[1])
+		default:
+			return nil, fmt.Errorf("Quad store error: Unknown step %q", step)
+		}
+	}
+	return p.All(), nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestQuadStoreQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-quads-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "graph.json")
+	qs, err := NewQuadStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create the quad store: %v", err)
+	}
+
+	_, cidr, _ := net.ParseCIDR("192.0.2.0/24")
+	qs.InsertDomain("owasp.org", "dns", "Forward DNS")
+	qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "cert", "Censys")
+	qs.InsertA("dev.owasp.org", "owasp.org", "192.0.2.20", "scrape", "Google")
+	qs.InsertCNAME("docs.owasp.org", "owasp.org", "www.owasp.org", "owasp.org", "api", "VirusTotal")
+	qs.InsertInfrastructure("192.0.2.10", 64496, cidr, "TEST-NET")
+	qs.InsertInfrastructure("192.0.2.20", 64496, cidr, "TEST-NET")
+	// The same statement is only stored once
+	qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "cert", "Censys")
+	num := qs.Len()
+	qs.Close()
+
+	// The quads are loaded back from the file
+	qs, err = NewQuadStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen the quad store: %v", err)
+	}
+	defer qs.Close()
+	if qs.Len() != num {
+		t.Errorf("Loaded %d quads from the file instead of %d", qs.Len(), num)
+	}
+
+	tests := []struct {
+		path  string
+		nodes []string
+	}{
+		{"owasp.org in:root has:type=subdomain out:a_to", []string{"192.0.2.10", "192.0.2.20"}},
+		{"docs.owasp.org out:cname_to out:a_to in:contains in:has_prefix", []string{"AS64496"}},
+		{"* has:tag=cert", []string{"www.owasp.org"}},
+		{"192.0.2.20 in:a_to", []string{"dev.owasp.org"}},
+	}
+
+	for _, test := range tests {
+		nodes, err := qs.Query(test.path)
+		if err != nil {
+			t.Errorf("The query %q failed: %v", test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(nodes, test.nodes) {
+			t.Errorf("The query %q returned %v instead of %v", test.path, nodes, test.nodes)
+		}
+	}
+
+	if _, err := qs.Query("owasp.org sideways:root"); err == nil {
+		t.Errorf("The unknown step did not return an error")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StoredAsset - A node of the quad store, such as a name, address or netblock, along with
+// the first and last runs that observed it
+type StoredAsset struct {
+	Node      string    `json:"node"`
+	Type      string    `json:"type"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// AssetFilter - Selects the assets returned by the quad store
+type AssetFilter func(*StoredAsset) bool
+
+// Runs - Returns the times of the runs that observed statements in the store, oldest first
+func (qs *QuadStore) Runs() []time.Time {
+	qs.Lock()
+	defer qs.Unlock()
+
+	set := make(map[time.Time]struct{})
+	for _, q := range qs.quads {
+		if historyPredicate(q.Predicate) {
+			continue
+		}
+		set[q.FirstSeen] = struct{}{}
+		set[q.LastSeen] = struct{}{}
+	}
+
+	var runs []time.Time
+	for t := range set {
+		runs = append(runs, t)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Before(runs[j]) })
+	return runs
+}
+
+// Inventory - Returns the nodes with a type that are selected by all the filters, where the
+// type statements tell when each node was observed
+func (qs *QuadStore) Inventory(filters ...AssetFilter) []*StoredAsset {
+	qs.Lock()
+	defer qs.Unlock()
+
+	var assets []*StoredAsset
+loop:
+	for _, q := range qs.quads {
+		if q.Predicate != "type" {
+			continue
+		}
+
+		a := &StoredAsset{
+			Node:      q.Subject,
+			Type:      q.Object,
+			FirstSeen: q.FirstSeen,
+			LastSeen:  q.LastSeen,
+		}
+		for _, keep := range filters {
+			if !keep(a) {
+				continue loop
+			}
+		}
+		assets = append(assets, a)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		if assets[i].Type != assets[j].Type {
+			return assets[i].Type < assets[j].Type
+		}
+		return assets[i].Node < assets[j].Node
+	})
+	return assets
+}
+
+// FirstSeenAfter - Keeps the assets that were first observed after the time
+func FirstSeenAfter(t time.Time) AssetFilter {
+	return func(a *StoredAsset) bool {
+		return a.FirstSeen.After(t)
+	}
+}
+
+// PresentIn - Keeps the assets observed by the run. Since only the first and last
+// observations are stored, the assets are considered present in the runs between them
+func PresentIn(run time.Time) AssetFilter {
+	return func(a *StoredAsset) bool {
+		return !run.Before(a.FirstSeen) && !run.After(a.LastSeen)
+	}
+}
+
+// RunDiff - Keeps the assets present in run a, but not in run b
+func RunDiff(a, b time.Time) AssetFilter {
+	inA, inB := PresentIn(a), PresentIn(b)
+
+	return func(asset *StoredAsset) bool {
+		return inA(asset) && !inB(asset)
+	}
+}
+
+// WithinASN - Keeps the autonomous system, its netblocks and addresses, and the names
+// resolving to the addresses directly or through an alias
+func (qs *QuadStore) WithinASN(asn int) AssetFilter {
+	as := "AS" + strconv.Itoa(asn)
+
+	nodes := make(map[string]struct{})
+	for _, n := range []*QuadPath{
+		qs.Vertex(as),
+		qs.Vertex(as).Out("has_prefix"),
+		qs.Vertex(as).Out("has_prefix").Out("contains"),
+		qs.Vertex(as).Out("has_prefix").Out("contains").In("a_to", "aaaa_to"),
+		qs.Vertex(as).Out("has_prefix").Out("contains").In("a_to", "aaaa_to").In("cname_to"),
+	} {
+		for _, node := range n.All() {
+			nodes[node] = struct{}{}
+		}
+	}
+
+	return func(a *StoredAsset) bool {
+		_, found := nodes[a.Node]
+		return found
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQuadStoreRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-runs-test")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	qs, err := NewQuadStore(filepath.Join(dir, "graph.json"))
+	if err != nil {
+		t.Fatalf("Failed to create the quad store: %v", err)
+	}
+	defer qs.Close()
+
+	first := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(7 * 24 * time.Hour)
+	_, cidr, _ := net.ParseCIDR("192.0.2.0/24")
+
+	qs.SetObservationTime(first)
+	qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "dns", "Forward DNS")
+	qs.InsertA("old.owasp.org", "owasp.org", "198.51.100.1", "dns", "Forward DNS")
+	qs.InsertInfrastructure("192.0.2.10", 64496, cidr, "TEST-NET")
+
+	qs.SetObservationTime(second)
+	qs.InsertA("www.owasp.org", "owasp.org", "192.0.2.10", "dns", "Forward DNS")
+	qs.InsertCNAME("docs.owasp.org", "owasp.org", "www.owasp.org", "owasp.org", "dns", "Forward DNS")
+	qs.InsertInfrastructure("192.0.2.10", 64496, cidr, "TEST-NET")
+	// The dates reported by the data sources are not runs
+	qs.InsertHistory("www.owasp.org", "owasp.org", "A", "203.0.113.5", "", first.AddDate(-1, 0, 0), first, "api", "SecurityTrails")
+
+	if runs := qs.Runs(); !reflect.DeepEqual(runs, []time.Time{first, second}) {
+		t.Errorf("The runs were %v", runs)
+	}
+
+	nodes := func(assets []*StoredAsset) []string {
+		var names []string
+		for _, a := range assets {
+			names = append(names, a.Node)
+		}
+		return names
+	}
+
+	tests := []struct {
+		desc    string
+		filters []AssetFilter
+		nodes   []string
+	}{
+		{"first seen after the first run", []AssetFilter{FirstSeenAfter(first)}, []string{"docs.owasp.org"}},
+		{"present in the first run only", []AssetFilter{RunDiff(first, second)}, []string{"198.51.100.1", "old.owasp.org"}},
+		{"present in the second run only", []AssetFilter{RunDiff(second, first)}, []string{"docs.owasp.org"}},
+		{"within the autonomous system", []AssetFilter{qs.WithinASN(64496)},
+			[]string{"AS64496", "192.0.2.10", "192.0.2.0/24", "docs.owasp.org", "www.owasp.org"}},
+		{"new within the autonomous system", []AssetFilter{qs.WithinASN(64496), FirstSeenAfter(first)},
+			[]string{"docs.owasp.org"}},
+	}
+
+	for _, test := range tests {
+		if got := nodes(qs.Inventory(test.filters...)); !reflect.DeepEqual(got, test.nodes) {
+			t.Errorf("The assets %s were %v instead of %v", test.desc, got, test.nodes)
+		}
+	}
+}
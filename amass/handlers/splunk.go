@@ -0,0 +1,114 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The sourcetype of the events sent to the HTTP Event Collector
+const splunkSourceType = "amass:dataopts"
+
+// Splunk - Sends each data operation as an event to the Splunk HTTP Event Collector
+type Splunk struct {
+	*batchWriter
+	client *http.Client
+	url    string
+	token  string
+	index  string
+}
+
+type splunkEvent struct {
+	Time       int64           `json:"time"`
+	Source     string          `json:"source"`
+	SourceType string          `json:"sourcetype"`
+	Index      string          `json:"index,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+type splunkResponse struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+}
+
+// NewSplunk - Returns the writer for the collector at the URL, such as https://localhost:8088.
+// The index query parameter selects an index other than the default of the token
+func NewSplunk(rawurl, token string) (*Splunk, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("Splunk error: The URL %s is not valid", rawurl)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("Splunk error: The HTTP Event Collector token was not provided")
+	}
+
+	s := &Splunk{
+		client: &http.Client{Timeout: 30 * time.Second},
+		token:  token,
+		index:  u.Query().Get("index"),
+	}
+	u.RawQuery = ""
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/services/collector/event"
+	}
+	s.url = u.String()
+	s.batchWriter = newBatchWriter(100, 10*time.Second, s.send)
+	return s, nil
+}
+
+// Close - Sends the events that have not been sent yet
+func (s *Splunk) Close() error {
+	return s.batchWriter.Close()
+}
+
+func (s *Splunk) send(docs [][]byte) error {
+	var body bytes.Buffer
+
+	now := time.Now().Unix()
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		doc = bytes.TrimSpace(doc)
+		if !json.Valid(doc) {
+			continue
+		}
+
+		enc.Encode(&splunkEvent{
+			Time:       now,
+			Source:     "amass",
+			SourceType: splunkSourceType,
+			Index:      s.index,
+			Event:      doc,
+		})
+	}
+
+	req, err := http.NewRequest("POST", s.url, &body)
+	if err != nil {
+		return fmt.Errorf("Splunk error: %v", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Splunk error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	in, _ := ioutil.ReadAll(resp.Body)
+	// The collector explains the failures, such as an invalid token, in the response
+	var result splunkResponse
+	if err := json.Unmarshal(in, &result); err == nil && result.Code != 0 {
+		return fmt.Errorf("Splunk error: The collector returned code %d: %s", result.Code, result.Text)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Splunk error: The collector returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplunkBatches(t *testing.T) {
+	var batches int
+	var auth, path string
+	var events []splunkEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		batches++
+
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var ev splunkEvent
+			if err := dec.Decode(&ev); err != nil {
+				break
+			}
+			events = append(events, ev)
+		}
+		fmt.Fprint(w, `{"text":"Success","code":0}`)
+	}))
+	defer srv.Close()
+
+	s, err := NewSplunk(srv.URL+"?index=recon", "0000-1111")
+	if err != nil {
+		t.Fatalf("NewSplunk returned an error: %v", err)
+	}
+	s.size = 2
+
+	h := NewDataOptsHandler(s)
+	h.InsertDomain("owasp.org", "dns", "Forward DNS")
+	h.InsertA("www.owasp.org", "owasp.org", "192.0.2.1", "cert", "Crtsh")
+	h.InsertA("dev.owasp.org", "owasp.org", "192.0.2.2", "cert", "Crtsh")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+
+	if batches != 2 || len(events) != 3 {
+		t.Errorf("%d events were sent in %d batches, expected 3 in 2", len(events), batches)
+	}
+	if auth != "Splunk 0000-1111" || path != "/services/collector/event" {
+		t.Errorf("The events were sent to %s with the authorization %q", path, auth)
+	}
+	if len(events) > 0 {
+		if ev := events[0]; ev.Index != "recon" || ev.SourceType != splunkSourceType || !strings.Contains(string(ev.Event), `"owasp.org"`) {
+			t.Errorf("The event was not sent correctly: %+v", ev)
+		}
+	}
+}
+
+func TestSplunkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"text":"Invalid token","code":4}`)
+	}))
+	defer srv.Close()
+
+	if _, err := NewSplunk(srv.URL, ""); err == nil {
+		t.Errorf("The missing token was accepted")
+	}
+
+	s, _ := NewSplunk(srv.URL, "bad")
+	NewDataOptsHandler(s).InsertDomain("owasp.org", "dns", "Forward DNS")
+	if err := s.Close(); err == nil || !strings.Contains(err.Error(), "Invalid token") {
+		t.Errorf("The rejected token was not reported: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The parts of an HTTP Archive (HAR) file that can reveal hostnames
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string      `json:"url"`
+				Headers []harHeader `json:"headers"`
+			} `json:"request"`
+			Response struct {
+				RedirectURL string      `json:"redirectURL"`
+				Headers     []harHeader `json:"headers"`
+				Content     struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ParseHAR - Returns the names within the root domains found in the requests,
+// headers and response bodies of the HAR file
+func ParseHAR(r io.Reader, domains []string) ([]string, error) {
+	var har harFile
+	var names []string
+
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("HAR error: Failed to decode the file: %v", err)
+	}
+
+	var text []string
+	for _, entry := range har.Log.Entries {
+		text = append(text, entry.Request.URL, entry.Response.RedirectURL, entry.Response.Content.Text)
+
+		for _, h := range append(entry.Request.Headers, entry.Response.Headers...) {
+			text = append(text, h.Value)
+		}
+	}
+
+	for _, domain := range domains {
+		ex := extract.New(domain, extract.Strict)
+
+		for _, t := range text {
+			names = utils.UniqueAppend(names, ex.Names(t)...)
+		}
+	}
+	return names, nil
+}
+
+// ImportHAR - Reads names from the HAR file to seed the enumeration when it starts
+func (e *Enumeration) ImportHAR(r io.Reader) error {
+	names, err := ParseHAR(r, e.Domains())
+	if err != nil {
+		return err
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	for _, name := range names {
+		e.seeds = append(e.seeds, &core.AmassRequest{
+			Name:   name,
+			Domain: e.rootDomain(name),
+			Tag:    core.SCRAPE,
+			Source: "HAR File",
+		})
+	}
+	return nil
+}
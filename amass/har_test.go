@@ -0,0 +1,51 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHARParse(t *testing.T) {
+	input := `{"log": {"entries": [
+	{
+		"request": {
+			"url": "https://app.example.com/login",
+			"headers": [{"name": "Origin", "value": "https://portal.example.com"}]
+		},
+		"response": {
+			"redirectURL": "https://sso.example.com/auth",
+			"headers": [{"name": "Location", "value": "https://sso.example.com/auth"}],
+			"content": {"text": "fetch('https://API.example.com/v1'); img.src='https://cdn.other.com/x.png'"}
+		}
+	}]}}`
+
+	names, err := ParseHAR(strings.NewReader(input), []string{"example.com"})
+	if err != nil {
+		t.Fatalf("ParseHAR returned an error: %v", err)
+	}
+
+	expected := []string{"app.example.com", "sso.example.com", "api.example.com", "portal.example.com"}
+	if len(names) != len(expected) {
+		t.Fatalf("ParseHAR returned %v, expected %v", names, expected)
+	}
+	for _, e := range expected {
+		var found bool
+
+		for _, n := range names {
+			if n == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("ParseHAR did not return %s", e)
+		}
+	}
+
+	if _, err := ParseHAR(strings.NewReader("not json"), []string{"example.com"}); err == nil {
+		t.Errorf("ParseHAR did not return an error for a malformed file")
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+// How long the results of the resolver checks are reused by the readiness endpoint
+const resolverCheckInterval = 30 * time.Second
+
+// HealthReport - The state returned by the /healthz and /readyz endpoints
+type HealthReport struct {
+	Ready     bool              `json:"ready"`
+	Role      string            `json:"role"`
+	State     string            `json:"state,omitempty"`
+	Queued    int               `json:"queued_requests"`
+	Resolvers map[string]string `json:"resolvers"`
+	Storage   string            `json:"storage,omitempty"`
+}
+
+// healthChecker - Builds the reports, caching the resolver checks so
+// orchestrators polling the endpoints do not flood the resolvers
+type healthChecker struct {
+	sync.Mutex
+	role      string
+	state     func() (string, int)
+	queueDir  string
+	checked   time.Time
+	resolvers map[string]string
+}
+
+// HealthHandler - Returns the /healthz and /readyz endpoints reporting the state of the
+// enumeration, the health of the resolver pool and access to the queue directory
+func (e *Enumeration) HealthHandler() http.Handler {
+	return newHealthHandler(e.healthChecker())
+}
+
+// APIHandler - Returns the /config endpoint, which applies the ConfigUpdate sent in a POST
+// request to the running enumeration, and the /domains endpoint, which lists the root domains
+// or adds those sent in a POST request. When the WorkerToken is set, the requests must present
+// it as a bearer token
+func (e *Enumeration) APIHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/config", e.serveConfig)
+	mux.HandleFunc("/domains", e.serveDomains)
+	if e.WorkerToken == "" {
+		return mux
+	}
+
+	expected := []byte("Bearer " + e.WorkerToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "The request did not present the token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (e *Enumeration) healthChecker() *healthChecker {
+	return &healthChecker{
+		role:     "coordinator",
+		state:    e.healthState,
+		queueDir: e.QueueDir,
+	}
+}
+
+// WorkerHealthHandler - Returns the /healthz and /readyz endpoints for a resolution worker
+func WorkerHealthHandler() http.Handler {
+	return newHealthHandler(&healthChecker{role: "worker"})
+}
+
+// ServeHealth - Serves the health endpoints on the address until an error occurs
+func ServeHealth(addr string, handler http.Handler) error {
+	return http.ListenAndServe(addr, handler)
+}
+
+// ServeAPI - Serves the API endpoints on the address until an error occurs. The endpoints
+// change the running enumeration, so an address without a host binds to the loopback interface
+func ServeAPI(addr string, handler http.Handler) error {
+	return http.ListenAndServe(apiAddress(addr), handler)
+}
+
+func apiAddress(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return addr
+}
+
+func newHealthHandler(hc *healthChecker) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// The process is alive as long as it answers
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, http.StatusOK, hc.report(false))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := hc.report(true)
+
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeHealthReport(w, status, report)
+	})
+	return mux
+}
+
+func writeHealthReport(w http.ResponseWriter, status int, report *HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// report - Performs the resolver and storage checks only when readiness is requested
+func (hc *healthChecker) report(checks bool) *HealthReport {
+	report := &HealthReport{
+		Ready: true,
+		Role:  hc.role,
+	}
+
+	if hc.state != nil {
+		report.State, report.Queued = hc.state()
+		if report.State != "running" {
+			report.Ready = false
+		}
+	}
+	if !checks {
+		return report
+	}
+
+	report.Resolvers = hc.checkResolvers()
+	var usable bool
+	for _, status := range report.Resolvers {
+		if status == "ok" {
+			usable = true
+			break
+		}
+	}
+	if !usable {
+		report.Ready = false
+	}
+
+	if hc.queueDir != "" {
+		report.Storage = "ok"
+		if err := checkQueueDir(hc.queueDir); err != nil {
+			report.Storage = err.Error()
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+func (hc *healthChecker) checkResolvers() map[string]string {
+	hc.Lock()
+	defer hc.Unlock()
+
+	if hc.resolvers != nil && time.Since(hc.checked) < resolverCheckInterval {
+		return copyStatus(hc.resolvers)
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	results := make(map[string]string)
+	for _, r := range dnssrv.CurrentResolvers() {
+		wg.Add(1)
+
+		go func(resolver string) {
+			defer wg.Done()
+
+			status := "ok"
+			if err := dnssrv.CheckResolver(resolver); err != nil {
+				status = err.Error()
+			} else if err := dnssrv.ResolverFiltered(resolver); err != nil {
+				status = err.Error()
+			}
+
+			lock.Lock()
+			results[resolver] = status
+			lock.Unlock()
+		}(r)
+	}
+	wg.Wait()
+
+	hc.resolvers = results
+	hc.checked = time.Now()
+	return copyStatus(results)
+}
+
+func copyStatus(status map[string]string) map[string]string {
+	c := make(map[string]string, len(status))
+
+	for k, v := range status {
+		c[k] = v
+	}
+	return c
+}
+
+// checkQueueDir - Confirms that the queues can still be written to the directory
+func checkQueueDir(dir string) error {
+	f, err := ioutil.TempFile(dir, "amass-health-*")
+	if err != nil {
+		return err
+	}
+
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// healthState - Returns the stage of the enumeration and the requests waiting in the services
+func (e *Enumeration) healthState() (string, int) {
+	e.Lock()
+	defer e.Unlock()
+
+	state := "starting"
+	if !e.ended.IsZero() {
+		state = "finished"
+	} else if !e.started.IsZero() {
+		state = "running"
+	}
+
+	var queued int
+	for _, service := range e.services {
+		queued += service.NumOfRequests()
+	}
+	return state, queued
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	state := "starting"
+	hc := &healthChecker{
+		role:  "coordinator",
+		state: func() (string, int) { return state, 5 },
+		// Avoid sending queries to the resolvers during the test
+		checked:   time.Now(),
+		resolvers: map[string]string{"192.0.2.1:53": "ok", "192.0.2.2:53": "i/o timeout"},
+	}
+	server := httptest.NewServer(newHealthHandler(hc))
+	defer server.Close()
+
+	get := func(path string) (int, *HealthReport) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to request %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		report := new(HealthReport)
+		if err := json.NewDecoder(resp.Body).Decode(report); err != nil {
+			t.Fatalf("Failed to decode the %s report: %v", path, err)
+		}
+		return resp.StatusCode, report
+	}
+
+	if status, _ := get("/healthz"); status != http.StatusOK {
+		t.Errorf("The liveness endpoint returned %d", status)
+	}
+	if status, _ := get("/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("The enumeration was ready before it started running")
+	}
+
+	state = "running"
+	status, report := get("/readyz")
+	if status != http.StatusOK || !report.Ready || report.Queued != 5 {
+		t.Errorf("The readiness endpoint returned %d with %+v", status, report)
+	}
+
+	hc.resolvers = map[string]string{"192.0.2.1:53": "i/o timeout"}
+	if status, _ := get("/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("The coordinator was ready without a usable resolver")
+	}
+}
+
+func TestAPIToken(t *testing.T) {
+	e := NewEnumeration()
+	e.WorkerToken = "secret"
+	handler := e.APIHandler()
+
+	tests := []struct {
+		auth   string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/domains", nil)
+		if test.auth != "" {
+			req.Header.Set("Authorization", test.auth)
+		}
+
+		handler.ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("The request with %q returned the status %d instead of %d", test.auth, w.Code, test.status)
+		}
+	}
+
+	if addr := apiAddress(":8080"); addr != "127.0.0.1:8080" {
+		t.Errorf("The API endpoints were bound to %s", addr)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// EnumerationManifest - Records how an enumeration was performed, so the results
+// can be reproduced and attributed to the run that produced them
+type EnumerationManifest struct {
+	ID      string            `json:"id"`
+	Version string            `json:"version"`
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Domains []string          `json:"domains"`
+	Config  *ManifestConfig   `json:"config"`
+	Sources []*ManifestSource `json:"sources"`
+	Counts  *ManifestCounts   `json:"counts"`
+}
+
+// ManifestConfig - The enumeration options in effect during the run
+type ManifestConfig struct {
+	ASNs            []int    `json:"asns,omitempty"`
+	CIDRs           []string `json:"cidrs,omitempty"`
+	IPs             []string `json:"ips,omitempty"`
+	Ports           []int    `json:"ports,omitempty"`
+	Whois           bool     `json:"whois"`
+	Wordlist        int      `json:"wordlist_size"`
+	BruteForcing    bool     `json:"brute_forcing"`
+	Recursive       bool     `json:"recursive"`
+	MinForRecursive int      `json:"min_for_recursive"`
+	BruteForceDepth int      `json:"brute_force_depth,omitempty"`
+	MaxBruteCombos  int      `json:"max_brute_combinations,omitempty"`
+	SRVBruteForcing bool     `json:"srv_brute_forcing"`
+	Alterations     bool     `json:"alterations"`
+	Passive         bool     `json:"passive"`
+	Active          bool     `json:"active"`
+	TLSChecks       bool     `json:"tls_checks"`
+	ProbeCDN        bool     `json:"probe_cdn"`
+	AnalyticsPivot  bool     `json:"analytics_pivot"`
+	Stages          []string `json:"stages,omitempty"`
+	StageBarriers   bool     `json:"stage_barriers"`
+	Blacklist       []string `json:"blacklist,omitempty"`
+	DisabledSources []string `json:"disabled_sources,omitempty"`
+	MinExposure     bool     `json:"minimize_exposure"`
+	WebSearch       bool     `json:"web_search"`
+	IncludeTags     []string `json:"include_tags,omitempty"`
+	ExcludeTags     []string `json:"exclude_tags,omitempty"`
+	PluginDir       string   `json:"plugin_dir,omitempty"`
+	ScriptDir       string   `json:"script_dir,omitempty"`
+	FDNSFile        string   `json:"fdns_file,omitempty"`
+	LocalDir        string   `json:"local_dir,omitempty"`
+	Seed            int64    `json:"seed,omitempty"`
+	Frequency       string   `json:"frequency"`
+	MaxDNSConc      int      `json:"max_dns_concurrency,omitempty"`
+	Jitter          string   `json:"jitter,omitempty"`
+	SourceDelay     string   `json:"source_delay,omitempty"`
+	MaxSourceConc   int      `json:"max_source_concurrency,omitempty"`
+	OPSEC           bool     `json:"opsec"`
+	DecoyDomains    []string `json:"decoy_domains,omitempty"`
+	DecoyRatio      float64  `json:"decoy_ratio,omitempty"`
+	SourceAddrs     []string `json:"source_addrs,omitempty"`
+	Proxies         []string `json:"source_proxies,omitempty"`
+	CaseRandom      bool     `json:"case_randomization"`
+	Iterative       bool     `json:"iterative"`
+	AuthDirect      bool     `json:"authoritative_direct"`
+	AuthRate        int      `json:"authoritative_rate,omitempty"`
+	NoDataTypes     []string `json:"nodata_types,omitempty"`
+	QueryTypes      []string `json:"query_types,omitempty"`
+	QNAMEMin        bool     `json:"qname_minimization"`
+	PolicyCheck     bool     `json:"policy_check"`
+	MaxDNSQueries   uint64   `json:"max_dns_queries,omitempty"`
+	MaxSourceCalls  int      `json:"max_source_calls,omitempty"`
+	MaxRuntime      string   `json:"max_runtime,omitempty"`
+	QueueDir        string   `json:"queue_dir,omitempty"`
+	Resolvers       []string `json:"resolvers,omitempty"`
+	Workers         []string `json:"workers,omitempty"`
+	Agents          []string `json:"agents,omitempty"`
+	DNSSEC          bool     `json:"dnssec"`
+	TrustAnchors    string   `json:"trust_anchors,omitempty"`
+	Divergence      bool     `json:"divergence"`
+	PTRValidation   bool     `json:"ptr_validation"`
+	SNIScanning     bool     `json:"sni_scanning"`
+}
+
+// ManifestSource - A data source and whether it was queried during the run
+type ManifestSource struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Version  string `json:"version"`
+	Enabled  bool   `json:"enabled"`
+	Queries  int    `json:"queries,omitempty"`
+	Names    int    `json:"names,omitempty"`
+	Unique   int    `json:"unique_names,omitempty"`
+	Errors   int    `json:"errors,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// ManifestCounts - Totals for the work performed during the run
+type ManifestCounts struct {
+	Names      int            `json:"names"`
+	DNSQueries uint64         `json:"dns_queries"`
+	Skipped    map[string]int `json:"skipped,omitempty"`
+}
+
+// Manifest - Returns the manifest describing the enumeration
+func (e *Enumeration) Manifest() *EnumerationManifest {
+	stats := e.Stats()
+	report := make(map[string]*SourceStats)
+	for _, s := range e.SourceReport() {
+		report[s.Name] = s
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	m := &EnumerationManifest{
+		ID:      e.ID,
+		Version: Version,
+		Start:   e.started,
+		End:     e.ended,
+		Domains: e.domains,
+		Config: &ManifestConfig{
+			ASNs:            e.ASNs,
+			Ports:           e.Ports,
+			Whois:           e.Whois,
+			Wordlist:        len(e.Wordlist),
+			BruteForcing:    e.BruteForcing,
+			Recursive:       e.Recursive,
+			MinForRecursive: e.MinForRecursive,
+			BruteForceDepth: e.BruteForceDepth,
+			MaxBruteCombos:  e.MaxBruteCombinations,
+			SRVBruteForcing: e.SRVBruteForcing,
+			Alterations:     e.Alterations,
+			Passive:         e.Passive,
+			Active:          e.Active,
+			TLSChecks:       e.TLSChecks,
+			ProbeCDN:        e.ProbeCDN,
+			AnalyticsPivot:  e.AnalyticsPivot,
+			Stages:          e.Stages,
+			StageBarriers:   e.StageBarriers,
+			Blacklist:       e.Blacklist,
+			DisabledSources: e.DisabledSources,
+			MinExposure:     e.MinimizeExposure,
+			WebSearch:       e.WebSearch,
+			IncludeTags:     e.IncludeTags,
+			ExcludeTags:     e.ExcludeTags,
+			PluginDir:       e.PluginDir,
+			ScriptDir:       e.ScriptDir,
+			FDNSFile:        e.FDNSFile,
+			LocalDir:        e.LocalDir,
+			Seed:            e.Seed,
+			Frequency:       e.Frequency.String(),
+			MaxDNSConc:      e.MaxDNSConcurrency,
+			MaxSourceConc:   e.MaxSourceConcurrency,
+			OPSEC:           e.OPSEC,
+			DecoyDomains:    e.DecoyDomains,
+			DecoyRatio:      e.DecoyRatio,
+			CaseRandom:      e.CaseRandomization,
+			Iterative:       e.Iterative,
+			AuthDirect:      e.AuthoritativeDirect,
+			AuthRate:        e.AuthoritativeRate,
+			NoDataTypes:     e.NoDataTypes,
+			QueryTypes:      e.QueryTypes,
+			QNAMEMin:        e.QNAMEMinimization,
+			PolicyCheck:     e.PolicyCheck,
+			MaxDNSQueries:   e.MaxDNSQueries,
+			MaxSourceCalls:  e.MaxSourceCalls,
+			QueueDir:        e.QueueDir,
+			Resolvers:       e.Resolvers,
+			Workers:         e.Workers,
+			Agents:          e.Agents,
+			DNSSEC:          e.DNSSEC,
+			TrustAnchors:    e.TrustAnchors,
+			Divergence:      e.Divergence,
+			PTRValidation:   e.PTRValidation,
+			SNIScanning:     e.SNIScanning,
+		},
+		Counts: &ManifestCounts{
+			Names:      e.numOutput,
+			DNSQueries: stats.DNSQueries,
+			Skipped:    stats.Skipped,
+		},
+	}
+
+	if e.Jitter > 0 {
+		m.Config.Jitter = e.Jitter.String()
+	}
+	if e.SourceDelay > 0 {
+		m.Config.SourceDelay = e.SourceDelay.String()
+	}
+	if e.MaxRuntime > 0 {
+		m.Config.MaxRuntime = e.MaxRuntime.String()
+	}
+	for _, cidr := range e.CIDRs {
+		m.Config.CIDRs = append(m.Config.CIDRs, cidr.String())
+	}
+	for _, ip := range e.IPs {
+		m.Config.IPs = append(m.Config.IPs, ip.String())
+	}
+	for _, ip := range e.SourceAddrs {
+		m.Config.SourceAddrs = append(m.Config.SourceAddrs, ip.String())
+	}
+	// The credentials of the proxies are not written to the manifest
+	for category, proxy := range e.SourceProxies {
+		entry := utils.RedactProxy(proxy)
+		if category != "" {
+			entry = category + "=" + entry
+		}
+		m.Config.Proxies = append(m.Config.Proxies, entry)
+	}
+	sort.Strings(m.Config.Proxies)
+	// The data sources are built into the binary, so they share its version
+	for _, src := range e.Sources() {
+		ms := &ManifestSource{
+			Name:     src.Name,
+			Category: src.Category,
+			Version:  Version,
+			Enabled:  src.Enabled,
+		}
+		if s, found := report[src.Name]; found && s.Queries > 0 {
+			ms.Queries = s.Queries
+			ms.Names = s.Names
+			ms.Unique = s.Unique
+			ms.Errors = s.Errors
+			ms.Duration = s.Duration.String()
+		}
+		m.Sources = append(m.Sources, ms)
+	}
+	return m
+}
+
+// WriteManifest - Encodes the manifest for the enumeration as JSON
+func (e *Enumeration) WriteManifest(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	enc.SetIndent("", "  ")
+	return enc.Encode(e.Manifest())
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+// The answer section of a massdns ndjson (-o J) result
+type massdnsResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Data   struct {
+		Answers []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			TTL  int    `json:"ttl"`
+			Data string `json:"data"`
+		} `json:"answers"`
+	} `json:"data"`
+}
+
+// WriteMassDNSNames - Writes the names in the massdns input format, one per line
+func WriteMassDNSNames(w io.Writer, names []string) error {
+	bw := bufio.NewWriter(w)
+
+	for _, name := range names {
+		if _, err := bw.WriteString(strings.TrimSuffix(name, ".") + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ParseMassDNS - Reads massdns results in the simple (-o S) or ndjson (-o J)
+// format, and returns the answers for each name
+func ParseMassDNS(r io.Reader) (map[string][]core.DNSAnswer, error) {
+	results := make(map[string][]core.DNSAnswer)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			var res massdnsResult
+
+			if err := json.Unmarshal([]byte(line), &res); err != nil {
+				return results, fmt.Errorf("massdns error: Line %d: %v", num, err)
+			}
+			if res.Status != "" && res.Status != "NOERROR" {
+				continue
+			}
+			for _, a := range res.Data.Answers {
+				addMassDNSRecord(results, a.Name, a.TTL, a.Type, a.Data)
+			}
+			continue
+		}
+
+		// The simple format is the owner name, record type and data
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return results, fmt.Errorf("massdns error: Line %d: Malformed record %q", num, line)
+		}
+		addMassDNSRecord(results, fields[0], 0, fields[1], strings.Join(fields[2:], " "))
+	}
+	return results, scanner.Err()
+}
+
+func addMassDNSRecord(results map[string][]core.DNSAnswer, name string, ttl int, rrtype, data string) {
+	text := dns.Fqdn(name) + " " + strconv.Itoa(ttl) + " IN " + strings.ToUpper(rrtype) + " " + data
+	// Records that cannot be parsed or are not used by amass are ignored
+	rr, err := dns.NewRR(text)
+	if err != nil || rr == nil {
+		return
+	}
+	addRecordAnswers(results, rr)
+}
+
+// ImportMassDNS - Reads massdns results that will be inserted into the enumeration
+// when it starts. Names that are not within the root domains are ignored
+func (e *Enumeration) ImportMassDNS(r io.Reader) error {
+	results, err := ParseMassDNS(r)
+
+	e.Lock()
+	defer e.Unlock()
+
+	for name, answers := range results {
+		domain := e.rootDomain(name)
+		if domain == "" {
+			continue
+		}
+
+		e.imports = append(e.imports, &core.AmassRequest{
+			Name:    name,
+			Domain:  domain,
+			Records: answers,
+			Tag:     core.DNS,
+			Source:  "massdns",
+		})
+	}
+	return err
+}
+
+// rootDomain - Returns the root domain name that contains the name
+func (e *Enumeration) rootDomain(name string) string {
+	for _, d := range e.domains {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return d
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestMassDNSParse(t *testing.T) {
+	input := `www.example.com. CNAME web.example.com.
+web.example.com. A 192.0.2.10
+mail.example.com. MX 10 mx.example.com.
+{"name":"api.example.com.","type":"A","class":"IN","status":"NOERROR","data":{"answers":[{"ttl":300,"type":"A","class":"IN","name":"api.example.com.","data":"192.0.2.20"}]}}
+{"name":"gone.example.com.","type":"A","class":"IN","status":"NXDOMAIN","data":{}}
+`
+
+	results, err := ParseMassDNS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseMassDNS returned an error: %v", err)
+	}
+
+	if a := results["www.example.com"]; len(a) != 1 || a[0].Type != int(dns.TypeCNAME) {
+		t.Errorf("The CNAME record was not parsed: %v", a)
+	}
+	if a := results["web.example.com"]; len(a) != 1 || a[0].Data != "192.0.2.10" {
+		t.Errorf("The A record was not parsed: %v", a)
+	}
+	if a := results["mail.example.com"]; len(a) != 1 || a[0].Priority != 10 {
+		t.Errorf("The MX record was not parsed: %v", a)
+	}
+	if a := results["api.example.com"]; len(a) != 1 || a[0].TTL != 300 {
+		t.Errorf("The ndjson result was not parsed: %v", a)
+	}
+	if _, found := results["gone.example.com"]; found {
+		t.Errorf("The NXDOMAIN result should have been ignored")
+	}
+
+	if _, err := ParseMassDNS(strings.NewReader("malformed\n")); err == nil {
+		t.Errorf("ParseMassDNS did not return an error for a malformed line")
+	}
+}
+
+func TestMassDNSWriteNames(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteMassDNSNames(&buf, []string{"www.example.com", "api.example.com."}); err != nil {
+		t.Fatalf("WriteMassDNSNames returned an error: %v", err)
+	}
+	if buf.String() != "www.example.com\napi.example.com\n" {
+		t.Errorf("WriteMassDNSNames wrote %q", buf.String())
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// RejectScope - The reason given for imported names that are not within the root domains
+const RejectScope = "outside the root domains"
+
+// ImportReport - The outcome of normalizing a list of names obtained from elsewhere
+type ImportReport struct {
+	Accepted   int             `json:"accepted"`
+	Duplicates int             `json:"duplicates"`
+	Rejected   []*RejectedName `json:"rejected,omitempty"`
+}
+
+// RejectedName - A line of the list that was not imported and the reason why
+type RejectedName struct {
+	Line   int    `json:"line"`
+	Input  string `json:"input"`
+	Reason string `json:"reason"`
+}
+
+// Reasons - Returns the number of lines rejected for each reason
+func (ir *ImportReport) Reasons() map[string]int {
+	reasons := make(map[string]int)
+
+	for _, r := range ir.Rejected {
+		reasons[r.Reason]++
+	}
+	return reasons
+}
+
+// String - Summarizes the rejected lines, such as "2 invalid label syntax, 1 empty"
+func (ir *ImportReport) String() string {
+	var parts []string
+
+	for reason, num := range ir.Reasons() {
+		parts = append(parts, fmt.Sprintf("%d %s", num, reason))
+	}
+	sort.Strings(parts)
+	return fmt.Sprintf("%d accepted, %d duplicates, %d rejected (%s)",
+		ir.Accepted, ir.Duplicates, len(ir.Rejected), strings.Join(parts, ", "))
+}
+
+func (ir *ImportReport) reject(line int, input, reason string) {
+	ir.Rejected = append(ir.Rejected, &RejectedName{
+		Line:   line,
+		Input:  input,
+		Reason: reason,
+	})
+}
+
+// listName - A name from the list along with the line it was found on
+type listName struct {
+	line int
+	name string
+}
+
+// ParseNameList - Reads a list with one name per line, normalizing each name and
+// recording the lines that were rejected instead of failing. Blank lines and
+// lines starting with # are skipped
+func ParseNameList(r io.Reader) ([]string, *ImportReport, error) {
+	list, report, err := parseNameList(r)
+
+	var names []string
+	for _, n := range list {
+		names = append(names, n.name)
+	}
+	return names, report, err
+}
+
+func parseNameList(r io.Reader) ([]*listName, *ImportReport, error) {
+	var names []*listName
+	report := new(ImportReport)
+	filter := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, reason := utils.SanitizeName(line)
+		if reason != "" {
+			report.reject(num, line, reason)
+			continue
+		}
+		if _, found := filter[name]; found {
+			report.Duplicates++
+			continue
+		}
+		filter[name] = struct{}{}
+		names = append(names, &listName{line: num, name: name})
+	}
+	report.Accepted = len(names)
+	return names, report, scanner.Err()
+}
+
+// ImportNames - Reads a list of names obtained elsewhere that will seed the enumeration
+// when it starts. The names outside of the root domains are rejected in the report
+func (e *Enumeration) ImportNames(r io.Reader, source string) (*ImportReport, error) {
+	list, report, err := parseNameList(r)
+
+	e.Lock()
+	defer e.Unlock()
+
+	report.Accepted = 0
+	for _, n := range list {
+		domain := e.rootDomain(n.name)
+		if domain == "" {
+			report.reject(n.line, n.name, RejectScope)
+			continue
+		}
+
+		e.seeds = append(e.seeds, &core.AmassRequest{
+			Name:   n.name,
+			Domain: domain,
+			Tag:    core.API,
+			Source: source,
+		})
+		report.Accepted++
+	}
+	return report, err
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+func TestImportNames(t *testing.T) {
+	input := `# Names exported from the asset inventory
+https://www.owasp.org/index.php
+*.dev.owasp.org
+www.owasp.org:443
+
+192.0.2.10
+bad_name-.owasp.org
+www.example.com
+`
+
+	e := NewEnumeration()
+	e.AddDomain("owasp.org")
+	report, err := e.ImportNames(strings.NewReader(input), "Names File")
+	if err != nil {
+		t.Fatalf("ImportNames returned an error: %v", err)
+	}
+
+	if report.Accepted != 2 || report.Duplicates != 1 || len(e.seeds) != 2 {
+		t.Errorf("The report was %s with %d seeds", report, len(e.seeds))
+	}
+	for _, req := range e.seeds {
+		if req.Domain != "owasp.org" || req.Source != "Names File" {
+			t.Errorf("The name %s was imported as %+v", req.Name, req)
+		}
+	}
+
+	reasons := report.Reasons()
+	if reasons[utils.RejectAddress] != 1 || reasons[utils.RejectSyntax] != 1 || reasons[RejectScope] != 1 {
+		t.Errorf("The rejected lines were counted as %v", reasons)
+	}
+	for _, rej := range report.Rejected {
+		if rej.Reason == RejectScope && rej.Line != 8 {
+			t.Errorf("The out of scope name was reported on line %d instead of 8", rej.Line)
+		}
+	}
+}
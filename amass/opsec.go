@@ -0,0 +1,105 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/OWASP/Amass/amass/sources"
+)
+
+// OPSECDisclosure - The third parties contacted by one part of the enumeration
+// and the data that is sent to them
+type OPSECDisclosure struct {
+	Service string
+
+	// One line for each third party or kind of data
+	Details []string
+}
+
+// Disclosure - Returns the OPSEC disclosure of every service that would be started with the current
+// configuration. No traffic is sent while the disclosure is collected
+func (e *Enumeration) Disclosure() ([]*OPSECDisclosure, error) {
+	config, err := e.amassConfig(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if config.Whois {
+		lines = append(lines, "Sends the root domain names to viewdns.info for reverse whois lookups")
+	}
+	if config.AnalyticsPivot {
+		var names []string
+		for _, source := range e.dataSources(config) {
+			if _, ok := source.(sources.AnalyticsSource); ok && sourceEnabled(config, source) {
+				names = append(names, source.String())
+			}
+		}
+		if len(names) > 0 {
+			lines = append(lines, "Sends the analytics IDs found on the in-scope sites to "+strings.Join(names, ", "))
+		}
+	}
+	if e.OwnershipScore > 0 && (config.Whois || config.AnalyticsPivot) {
+		var ports []string
+		for _, port := range e.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+
+		lines = append(lines,
+			"Sends the discovered root domains and netblocks to the WHOIS servers on port 43, starting at "+whoisRootServer,
+			"Connects to the discovered root domains and up to "+strconv.Itoa(maxOwnershipHosts)+
+				" hosts of each discovered netblock on ports "+strings.Join(ports, ", ")+
+				" to pull certificates, and requests the home pages of the domains over HTTP and HTTPS")
+	}
+	if config.BruteForcing && len(config.Wordlist) == 0 && len(dataFileLines(wordlistDataFile)) == 0 {
+		lines = append(lines, "Downloads the default wordlist from "+defaultWordlistURL)
+	}
+
+	var disclosures []*OPSECDisclosure
+	if len(lines) > 0 {
+		disclosures = append(disclosures, &OPSECDisclosure{Service: "Enumeration", Details: lines})
+	}
+
+	bus := core.NewEventBus(config)
+	srcs := NewSourcesService(config, bus)
+	for _, source := range e.addedSources() {
+		srcs.AddSource(source)
+	}
+	services := []core.AmassService{srcs}
+	if !config.Passive {
+		services = append(services,
+			NewDataManagerService(config, bus),
+			dnssrv.NewDNSService(config, bus),
+		)
+		if config.Alterations {
+			services = append(services, NewAlterationService(config, bus))
+		}
+		if config.BruteForcing {
+			services = append(services, NewBruteForceService(config, bus))
+		}
+		if config.SRVBruteForcing {
+			services = append(services, dnssrv.NewSRVBruteService(config, bus))
+		}
+	}
+	if config.SNIScanning && !config.Passive {
+		services = append(services, NewSNIService(config, bus))
+	}
+
+	for _, service := range services {
+		list := service.List()
+		if list == "" || list == "N/A" {
+			continue
+		}
+
+		disclosures = append(disclosures, &OPSECDisclosure{
+			Service: service.String(),
+			Details: strings.Split(list, "\n"),
+		})
+	}
+	return disclosures, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+func disclosureServices(disclosures []*OPSECDisclosure) map[string][]string {
+	services := make(map[string][]string)
+
+	for _, d := range disclosures {
+		services[d.Service] = d.Details
+	}
+	return services
+}
+
+func TestDisclosurePassive(t *testing.T) {
+	e := NewEnumeration()
+	e.Passive = true
+	e.Whois = true
+	e.AddDomain("example.com")
+
+	disclosures, err := e.Disclosure()
+	if err != nil {
+		t.Fatalf("Disclosure returned an error: %v", err)
+	}
+	services := disclosureServices(disclosures)
+
+	if len(services) != 2 {
+		t.Errorf("The passive enumeration disclosed %d services instead of two: %v", len(services), services)
+	}
+	if lines := services["Enumeration"]; len(lines) != 1 || !strings.Contains(lines[0], "viewdns.info") {
+		t.Errorf("The reverse whois lookups were not disclosed: %v", lines)
+	}
+
+	e.OwnershipScore = DefaultOwnershipScore
+	disclosures, _ = e.Disclosure()
+	if lines := disclosureServices(disclosures)["Enumeration"]; len(lines) != 3 || !strings.Contains(lines[1], "port 43") {
+		t.Errorf("The ownership verification was not disclosed: %v", lines)
+	}
+
+	var crtsh bool
+	for _, line := range services["Sources Service"] {
+		if strings.HasPrefix(line, "crt.sh (cert): Sends the root domain names to crt.sh") {
+			crtsh = true
+		}
+		if strings.HasSuffix(line, "N/A") {
+			t.Errorf("A data source did not describe the data it sends: %s", line)
+		}
+	}
+	if !crtsh {
+		t.Errorf("The crt.sh data source was not disclosed")
+	}
+}
+
+func TestDisclosureActive(t *testing.T) {
+	e := NewEnumeration()
+	e.Active = true
+	e.BruteForcing = true
+	e.Wordlist = []string{"www"}
+	e.DisabledSources = []string{"crt.sh"}
+	e.Resolvers = []string{"192.0.2.53"}
+	e.AddDomain("example.com")
+
+	disclosures, err := e.Disclosure()
+	if err != nil {
+		t.Fatalf("Disclosure returned an error: %v", err)
+	}
+	services := disclosureServices(disclosures)
+
+	if lines := services["DNS Service"]; len(lines) == 0 || !strings.Contains(lines[0], "192.0.2.53:53") {
+		t.Errorf("The configured resolver was not disclosed: %v", lines)
+	}
+	for _, r := range dnssrv.CustomResolvers {
+		if r == "192.0.2.53:53" {
+			t.Errorf("The disclosure changed the resolvers in use")
+		}
+	}
+
+	for _, name := range []string{"Sources Service", "Data Manager Service", "DNS Service", "Brute Forcing Service"} {
+		if _, found := services[name]; !found {
+			t.Errorf("The %s was not disclosed", name)
+		}
+	}
+	for _, name := range []string{"Enumeration", "SNI Service"} {
+		if _, found := services[name]; found {
+			t.Errorf("The %s was disclosed without being used", name)
+		}
+	}
+	for _, line := range services["Sources Service"] {
+		if strings.HasPrefix(line, "crt.sh ") {
+			t.Errorf("The disabled data source was disclosed: %s", line)
+		}
+	}
+
+	var certs bool
+	for _, line := range services["Data Manager Service"] {
+		if strings.Contains(line, "ports 80, 443") {
+			certs = true
+		}
+	}
+	if !certs {
+		t.Errorf("The active certificate pulls were not disclosed: %v", services["Data Manager Service"])
+	}
+}
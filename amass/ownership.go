@@ -0,0 +1,533 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// DefaultOwnershipScore - The suggested lowest score for a discovered root domain to be added
+// during intelligence collection, when the ownership verification is requested. A certificate
+// issued to the organization or a shared analytics ID is enough on its own, while a matching
+// WHOIS organization also needs the other evidence
+const DefaultOwnershipScore = 0.5
+
+// The amount each kind of evidence adds to the ownership score
+const (
+	whoisOrgWeight    = 0.4
+	certOrgWeight     = 0.5
+	analyticsIDWeight = 0.6
+)
+
+const (
+	// The WHOIS server asked first, which refers the query to the authoritative server
+	whoisRootServer = "whois.iana.org"
+
+	// The most referrals followed for one WHOIS query
+	maxWhoisReferrals = 3
+
+	// The longest time allowed for each WHOIS server to answer
+	whoisTimeout = 10 * time.Second
+
+	// The largest WHOIS response read from a server
+	maxWhoisResponse = 1 << 20
+
+	// The most hosts of a netblock that certificates are pulled from
+	maxOwnershipHosts = 8
+)
+
+// The WHOIS fields naming the registrant of a domain or the holder of a netblock
+var whoisOrgFields = []string{
+	"registrant organization",
+	"registrant organisation",
+	"registrant",
+	"orgname",
+	"org-name",
+	"organization",
+	"organisation",
+	"owner",
+}
+
+// The WHOIS fields naming the server the query is referred to
+var whoisReferralFields = []string{
+	"refer",
+	"whois",
+	"registrar whois server",
+	"referralserver",
+}
+
+// The organization names used by privacy services and redacted records, which say nothing
+// about the owner of the asset
+var whoisPrivacyMarkers = []string{
+	"privacy",
+	"redacted",
+	"proxy",
+	"whoisguard",
+	"withheld",
+	"not disclosed",
+	"data protected",
+	"statutory masking",
+}
+
+// The words ignored when organization names are compared
+var orgNameSuffixes = map[string]struct{}{
+	"the": {}, "inc": {}, "incorporated": {}, "llc": {}, "llp": {}, "ltd": {}, "limited": {},
+	"corp": {}, "corporation": {}, "co": {}, "company": {}, "plc": {}, "gmbh": {}, "ag": {},
+	"sa": {}, "sas": {}, "srl": {}, "bv": {}, "nv": {}, "ab": {}, "oy": {}, "pty": {}, "kk": {},
+}
+
+// The analytics, tag manager and advertising IDs embedded in web pages
+var analyticsIDRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`\bUA-[0-9]{4,10}-[0-9]{1,4}\b`),
+	regexp.MustCompile(`\bG-[A-Z0-9]{8,12}\b`),
+	regexp.MustCompile(`\bGTM-[A-Z0-9]{4,9}\b`),
+	regexp.MustCompile(`\bca-pub-[0-9]{10,20}\b`),
+}
+
+// OwnershipScore - The score given to a discovered root domain or netblock, along with the
+// evidence tying it to the target organization
+type OwnershipScore struct {
+	Asset    string
+	Score    float64
+	Evidence []string
+}
+
+// OwnershipVerifier - Scores whether the discovered root domains and netblocks belong to the
+// target organization, by comparing their WHOIS and certificate organizations and the analytics
+// IDs of their web pages with those of the target
+type OwnershipVerifier struct {
+	sync.Mutex
+
+	// The ports checked for certificates
+	Ports []int
+
+	// The normalized organization names and the analytics IDs of the target
+	orgs []string
+	ids  []string
+
+	// Obtain the data used as evidence, and are replaced during testing
+	whois    func(query string) (string, error)
+	certOrgs func(host string, ports []int) []string
+	webPage  func(url string) (string, error)
+}
+
+// NewOwnershipVerifier - Returns a verifier knowing the target by the organization names provided
+func NewOwnershipVerifier(orgs []string, ports []int) *OwnershipVerifier {
+	v := &OwnershipVerifier{
+		Ports:    ports,
+		whois:    whoisQuery,
+		certOrgs: certificateOrgs,
+		webPage: func(url string) (string, error) {
+			return utils.GetWebPage(url, nil)
+		},
+	}
+
+	for _, org := range orgs {
+		if n := normalizeOrgName(org); n != "" {
+			v.orgs = utils.UniqueAppend(v.orgs, n)
+		}
+	}
+	return v
+}
+
+// AddTarget - Learns the WHOIS and certificate organizations and the analytics IDs of a root
+// domain known to belong to the target
+func (v *OwnershipVerifier) AddTarget(domain string) {
+	orgs, ids := v.domainEvidence(domain)
+
+	v.Lock()
+	defer v.Unlock()
+
+	v.orgs = utils.UniqueAppend(v.orgs, orgs...)
+	for _, id := range ids {
+		if firstSharedID(v.ids, []string{id}) == "" {
+			v.ids = append(v.ids, id)
+		}
+	}
+}
+
+// HasEvidence - Returns true when something is known about the target to compare with
+func (v *OwnershipVerifier) HasEvidence() bool {
+	v.Lock()
+	defer v.Unlock()
+
+	return len(v.orgs) > 0 || len(v.ids) > 0
+}
+
+// TargetIDs - Returns the analytics IDs found on the pages of the target
+func (v *OwnershipVerifier) TargetIDs() []string {
+	v.Lock()
+	defer v.Unlock()
+
+	return append([]string(nil), v.ids...)
+}
+
+// ScoreDomain - Returns the ownership score of the discovered root domain
+func (v *OwnershipVerifier) ScoreDomain(domain string) *OwnershipScore {
+	score := &OwnershipScore{Asset: domain}
+
+	whoisOrgs, certOrgs, ids := v.domainSignals(domain)
+	v.score(score, whoisOrgs, certOrgs, ids)
+	return score
+}
+
+// ScoreNetblock - Returns the ownership score of the discovered netblock, using the WHOIS
+// record of the netblock and the certificates served by a sample of its hosts
+func (v *OwnershipVerifier) ScoreNetblock(cidr *net.IPNet) *OwnershipScore {
+	score := &OwnershipScore{Asset: cidr.String()}
+
+	hosts := utils.NetHostsSample(cidr, maxOwnershipHosts)
+	if len(hosts) == 0 {
+		return score
+	}
+
+	var whoisOrgs, certOrgs []string
+	if text, err := v.whois(cidr.IP.String()); err == nil {
+		whoisOrgs = whoisOrganizations(text)
+	}
+	for _, host := range hosts {
+		certOrgs = utils.UniqueAppend(certOrgs, normalizeOrgNames(v.certOrgs(host.String(), v.Ports))...)
+	}
+
+	v.score(score, whoisOrgs, certOrgs, nil)
+	return score
+}
+
+func (v *OwnershipVerifier) score(score *OwnershipScore, whoisOrgs, certOrgs, ids []string) {
+	v.Lock()
+	defer v.Unlock()
+
+	if org := firstShared(v.orgs, whoisOrgs); org != "" {
+		score.Score += whoisOrgWeight
+		score.Evidence = append(score.Evidence, fmt.Sprintf("The WHOIS organization %q matches the target", org))
+	}
+	if org := firstShared(v.orgs, certOrgs); org != "" {
+		score.Score += certOrgWeight
+		score.Evidence = append(score.Evidence, fmt.Sprintf("The certificate organization %q matches the target", org))
+	}
+	if id := firstSharedID(v.ids, ids); id != "" {
+		score.Score += analyticsIDWeight
+		score.Evidence = append(score.Evidence, fmt.Sprintf("The web page shares the analytics ID %s with the target", id))
+	}
+	if score.Score > 1 {
+		score.Score = 1
+	}
+}
+
+// domainEvidence - Returns all the normalized organization names and the analytics IDs of the domain
+func (v *OwnershipVerifier) domainEvidence(domain string) ([]string, []string) {
+	whoisOrgs, certOrgs, ids := v.domainSignals(domain)
+
+	return utils.UniqueAppend(whoisOrgs, certOrgs...), ids
+}
+
+// domainSignals - Returns the WHOIS and certificate organizations and the analytics IDs of the domain
+func (v *OwnershipVerifier) domainSignals(domain string) ([]string, []string, []string) {
+	var whoisOrgs, ids []string
+
+	if text, err := v.whois(domain); err == nil {
+		whoisOrgs = whoisOrganizations(text)
+	}
+	certOrgs := normalizeOrgNames(v.certOrgs(domain, v.Ports))
+
+	for _, scheme := range []string{"https", "http"} {
+		if page, err := v.webPage(scheme + "://" + domain + "/"); err == nil {
+			ids = AnalyticsIDs(page)
+			break
+		}
+	}
+	return whoisOrgs, certOrgs, ids
+}
+
+// AnalyticsIDs - Returns the Google Analytics, Tag Manager and AdSense IDs found in the page
+func AnalyticsIDs(page string) []string {
+	var ids []string
+
+	// The IDs are case sensitive, so they are not added with UniqueAppend
+	seen := make(map[string]struct{})
+	for _, re := range analyticsIDRegexes {
+		for _, id := range re.FindAllString(page, -1) {
+			if _, found := seen[id]; !found {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// analyticsAccount - Returns the part of the ID shared by every site of the account. The
+// properties of a Universal Analytics account only differ by the final number
+func analyticsAccount(id string) string {
+	if strings.HasPrefix(id, "UA-") {
+		if i := strings.LastIndex(id, "-"); i > 2 {
+			return id[:i]
+		}
+	}
+	return id
+}
+
+func firstSharedID(target, ids []string) string {
+	for _, id := range ids {
+		for _, t := range target {
+			if analyticsAccount(id) == analyticsAccount(t) {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+func firstShared(target, orgs []string) string {
+	for _, org := range orgs {
+		for _, t := range target {
+			if org == t {
+				return org
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeOrgName - Returns the organization name in lowercase without punctuation and legal
+// suffixes, or an empty string when the name belongs to a privacy service
+func normalizeOrgName(org string) string {
+	lower := strings.ToLower(org)
+	for _, marker := range whoisPrivacyMarkers {
+		if strings.Contains(lower, marker) {
+			return ""
+		}
+	}
+
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r < 0x80
+	})
+
+	var kept []string
+	for _, w := range words {
+		if _, found := orgNameSuffixes[w]; !found {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+func normalizeOrgNames(orgs []string) []string {
+	var names []string
+
+	for _, org := range orgs {
+		if n := normalizeOrgName(org); n != "" {
+			names = utils.UniqueAppend(names, n)
+		}
+	}
+	return names
+}
+
+// whoisOrganizations - Returns the normalized organization names found in the WHOIS response
+func whoisOrganizations(text string) []string {
+	var orgs []string
+
+	for _, field := range whoisOrgFields {
+		orgs = append(orgs, whoisValues(text, field)...)
+	}
+	return normalizeOrgNames(orgs)
+}
+
+// whoisValues - Returns the values of the field within the WHOIS response
+func whoisValues(text, field string) []string {
+	var values []string
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.ToLower(strings.TrimSpace(parts[0])) != field {
+			continue
+		}
+		if value := strings.TrimSpace(parts[1]); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// whoisReferral - Returns the WHOIS server that the response refers the query to
+func whoisReferral(text string) string {
+	for _, field := range whoisReferralFields {
+		for _, value := range whoisValues(text, field) {
+			server := strings.TrimPrefix(strings.TrimPrefix(value, "rwhois://"), "whois://")
+			if host, _, err := net.SplitHostPort(server); err == nil {
+				server = host
+			}
+			if server = strings.ToLower(strings.TrimSpace(server)); server != "" {
+				return server
+			}
+		}
+	}
+	return ""
+}
+
+// whoisQuery - Returns the WHOIS response of the most specific server for the domain or address,
+// following the referrals that start from the IANA server
+func whoisQuery(query string) (string, error) {
+	var resp string
+
+	server := whoisRootServer
+	for i := 0; i <= maxWhoisReferrals && server != ""; i++ {
+		text, err := whoisLookup(server, query)
+		if err != nil {
+			if resp != "" {
+				break
+			}
+			return "", err
+		}
+		resp = text
+
+		next := whoisReferral(text)
+		if next == server {
+			break
+		}
+		server = next
+	}
+	return resp, nil
+}
+
+func whoisLookup(server, query string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), whoisTimeout)
+	defer cancel()
+
+	conn, err := dnssrv.DialContext(ctx, "tcp", net.JoinHostPort(server, "43"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(whoisTimeout))
+	if _, err := io.WriteString(conn, query+"\r\n"); err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(conn, maxWhoisResponse))
+	if err != nil && len(data) == 0 {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// certificateOrgs - Returns the subject organizations of the certificates served for the host
+func certificateOrgs(host string, ports []int) []string {
+	var orgs []string
+
+	serverName := host
+	if net.ParseIP(host) != nil {
+		serverName = ""
+	}
+	for _, port := range ports {
+		if cert, _, err := pullCertificate(host, port, serverName); err == nil {
+			orgs = utils.UniqueAppend(orgs, cert.Subject.Organization...)
+		}
+	}
+	return orgs
+}
+
+// AddRelatedDomains - Adds the root domains discovered during intelligence collection. When the
+// OwnershipScore is set, the domains scored below it are kept out as belonging to another organization.
+// The verification contacts the WHOIS servers and the discovered domains, so it is only done on request
+func (e *Enumeration) AddRelatedDomains(domains []string) {
+	v := e.ownershipVerifier()
+
+	for _, domain := range domains {
+		if e.hasDomain(domain) {
+			continue
+		}
+		if v == nil {
+			e.AddDomain(domain)
+			continue
+		}
+
+		score := v.ScoreDomain(domain)
+		if score.Score < e.OwnershipScore {
+			e.Log.Printf("The domain %s was not added with the ownership score %.2f", domain, score.Score)
+			continue
+		}
+		e.Log.Printf("The domain %s was added with the ownership score %.2f: %s",
+			domain, score.Score, strings.Join(score.Evidence, "; "))
+		e.AddDomain(domain)
+	}
+}
+
+// RelatedNetblock - Returns true when the netblock discovered during intelligence collection
+// is scored at or above the OwnershipScore, or when the netblocks are not being verified
+func (e *Enumeration) RelatedNetblock(cidr *net.IPNet) bool {
+	v := e.ownershipVerifier()
+	if v == nil {
+		return true
+	}
+
+	score := v.ScoreNetblock(cidr)
+	if score.Score < e.OwnershipScore {
+		e.Log.Printf("The netblock %s was not added with the ownership score %.2f", cidr, score.Score)
+		return false
+	}
+	return true
+}
+
+// ownershipVerifier - Returns the verifier of the target, or nil when the ownership is not
+// verified or nothing is known of the target
+func (e *Enumeration) ownershipVerifier() *OwnershipVerifier {
+	if e.OwnershipScore <= 0 {
+		return nil
+	}
+
+	if v := e.targetVerifier(); v.HasEvidence() {
+		return v
+	}
+	return nil
+}
+
+// targetVerifier - Returns the verifier that learned about the target from the organizations
+// and the root domains provided before it was first used
+func (e *Enumeration) targetVerifier() *OwnershipVerifier {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.verifier == nil {
+		e.verifier = NewOwnershipVerifier(e.Organizations, e.Ports)
+		for _, domain := range e.domains {
+			e.verifier.AddTarget(domain)
+		}
+		if e.OwnershipScore > 0 && !e.verifier.HasEvidence() {
+			e.Log.Printf("Nothing is known of the target organization, so the ownership is not verified")
+		}
+	}
+	return e.verifier
+}
+
+func (e *Enumeration) hasDomain(domain string) bool {
+	e.Lock()
+	defer e.Unlock()
+
+	for _, d := range e.domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
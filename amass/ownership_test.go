@@ -0,0 +1,146 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeOrgName(t *testing.T) {
+	tests := []struct {
+		org      string
+		expected string
+	}{
+		{"Example, Inc.", "example"},
+		{"The Example Company LLC", "example"},
+		{"EXAMPLE CORP", "example"},
+		{"Example Holdings GmbH", "example holdings"},
+		{"Domains By Proxy, LLC", ""},
+		{"REDACTED FOR PRIVACY", ""},
+	}
+
+	for _, test := range tests {
+		if got := normalizeOrgName(test.org); got != test.expected {
+			t.Errorf("%q was normalized to %q instead of %q", test.org, got, test.expected)
+		}
+	}
+}
+
+func TestWhoisResponse(t *testing.T) {
+	text := `% IANA WHOIS server
+refer:        whois.verisign-grs.com
+
+Domain Name: EXAMPLE.COM
+Registrar WHOIS Server: whois.registrar.example
+Registrant Organization: Example, Inc.
+Admin Organization: Example Hosting
+`
+
+	if orgs := whoisOrganizations(text); len(orgs) != 1 || orgs[0] != "example" {
+		t.Errorf("The WHOIS organizations were %v", orgs)
+	}
+	if server := whoisReferral(text); server != "whois.verisign-grs.com" {
+		t.Errorf("The WHOIS query was referred to %s", server)
+	}
+	if server := whoisReferral("ReferralServer:  rwhois://rwhois.example.net:4321"); server != "rwhois.example.net" {
+		t.Errorf("The WHOIS query was referred to %s", server)
+	}
+}
+
+func TestAnalyticsIDs(t *testing.T) {
+	page := `<script async src="https://www.googletagmanager.com/gtag/js?id=G-ABCD1234EF"></script>
+<script>gtag('config', 'UA-1234567-3'); (function(w,d,s,l,i){})(window,document,'script','dataLayer','GTM-K9X2PQ');</script>
+<ins class="adsbygoogle" data-ad-client="ca-pub-1234567890123456"></ins>`
+
+	ids := AnalyticsIDs(page)
+	if got := strings.Join(ids, ","); got != "UA-1234567-3,G-ABCD1234EF,GTM-K9X2PQ,ca-pub-1234567890123456" {
+		t.Errorf("The analytics IDs were %s", got)
+	}
+	if firstSharedID([]string{"UA-1234567-1"}, ids) != "UA-1234567-3" {
+		t.Errorf("The properties of the Universal Analytics account were not matched")
+	}
+}
+
+func TestOwnershipScore(t *testing.T) {
+	whois := map[string]string{
+		"example.com":    "Registrant Organization: Example, Inc.",
+		"example.net":    "Registrant Organization: EXAMPLE LLC",
+		"example-cdn.io": "Registrant Organization: Example Inc",
+		"unrelated.org":  "Registrant Organization: Domains By Proxy, LLC",
+		"192.0.2.0":      "OrgName: Example, Inc.",
+	}
+	certs := map[string][]string{
+		"example.com":    {"Example, Inc."},
+		"example-cdn.io": {"Example Incorporated"},
+		"192.0.2.22":     {"Example Inc"},
+	}
+	pages := map[string]string{
+		"https://example.com/":   "gtag('config', 'G-ABCD1234EF');",
+		"http://example.net/":    "gtag('config', 'G-ABCD1234EF');",
+		"https://unrelated.org/": "gtag('config', 'G-ZZZZ9999ZZ');",
+	}
+
+	v := NewOwnershipVerifier(nil, []int{443})
+	v.whois = func(query string) (string, error) {
+		if text, found := whois[query]; found {
+			return text, nil
+		}
+		return "", errors.New("No WHOIS record")
+	}
+	v.certOrgs = func(host string, ports []int) []string {
+		return certs[host]
+	}
+	v.webPage = func(url string) (string, error) {
+		if page, found := pages[url]; found {
+			return page, nil
+		}
+		return "", errors.New("Not found")
+	}
+
+	if v.HasEvidence() {
+		t.Errorf("The verifier had evidence before learning about the target")
+	}
+	v.AddTarget("example.com")
+
+	tests := []struct {
+		domain string
+		score  float64
+	}{
+		{"example.net", 1},
+		{"example-cdn.io", 0.9},
+		{"unrelated.org", 0},
+		{"missing.org", 0},
+	}
+	for _, test := range tests {
+		if s := v.ScoreDomain(test.domain); s.Score != test.score {
+			t.Errorf("%s was scored %.2f instead of %.2f: %v", test.domain, s.Score, test.score, s.Evidence)
+		}
+	}
+
+	_, cidr, _ := net.ParseCIDR("192.0.2.0/26")
+	if s := v.ScoreNetblock(cidr); s.Score != 0.9 || len(s.Evidence) != 2 {
+		t.Errorf("The netblock was scored %.2f: %v", s.Score, s.Evidence)
+	}
+
+	e := NewEnumeration()
+	e.Log = log.New(ioutil.Discard, "", 0)
+	e.AddDomain("example.com")
+	e.OwnershipScore = DefaultOwnershipScore
+	e.verifier = v
+	e.AddRelatedDomains([]string{"example.net", "unrelated.org", "example-cdn.io"})
+	if got := strings.Join(e.Domains(), ","); got != "example.com,example.net,example-cdn.io" {
+		t.Errorf("The domains added were %s", got)
+	}
+
+	e.OwnershipScore = 0
+	e.AddRelatedDomains([]string{"unrelated.org"})
+	if len(e.Domains()) != 4 {
+		t.Errorf("The domain was not added without the ownership verification")
+	}
+}
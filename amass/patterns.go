@@ -0,0 +1,237 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// The most names kept per root domain as templates for the mined tokens
+	maxPatternTemplates = 1000
+
+	// How far past the highest number observed the numeric suffixes are guessed
+	numericLookahead = 3
+
+	// Ranges wider than this only have the numbers past the highest observed guessed
+	maxNumericGap = 50
+
+	envPlaceholder    = "{env}"
+	regionPlaceholder = "{region}"
+)
+
+// Tokens that commonly identify the environment that a host belongs to
+var environmentTokens = map[string]struct{}{
+	"dev": {}, "devel": {}, "development": {}, "test": {}, "tst": {}, "qa": {},
+	"uat": {}, "stage": {}, "staging": {}, "stg": {}, "preprod": {}, "prod": {},
+	"production": {}, "prd": {}, "sandbox": {}, "demo": {}, "int": {},
+}
+
+var (
+	// Cloud region codes, such as us-east-1 or eu-west-2
+	regionRE = regexp.MustCompile("(us|eu|ap|sa|ca|me|af)-(east|west|north|south|central|northeast|southeast|northwest|southwest)-[0-9]")
+
+	// The words and separators within a label
+	tokenRE = regexp.MustCompile("[a-z0-9]+|[-_]")
+
+	// A label ending with a number, such as web01
+	numericRE = regexp.MustCompile("^(.*?)([0-9]+)$")
+)
+
+// patternMiner - Learns the naming conventions of each root domain from the resolved
+// names, and guesses the names that follow the same conventions
+type patternMiner struct {
+	sync.Mutex
+	domains map[string]*domainPatterns
+}
+
+// domainPatterns - The tokens and templates mined from the names within a root domain
+type domainPatterns struct {
+	envs         map[string]struct{}
+	regions      map[string]struct{}
+	envTemplates map[string]struct{}
+	regTemplates map[string]struct{}
+	numbers      map[string]*numericRange
+}
+
+// numericRange - The numbers observed at the end of labels that share a prefix
+type numericRange struct {
+	min, max int
+	width    int
+	guessed  map[int]struct{}
+}
+
+func newPatternMiner() *patternMiner {
+	return &patternMiner{domains: make(map[string]*domainPatterns)}
+}
+
+// Observe - Mines the structure of the resolved name and returns the guesses that
+// follow the patterns learned so far for the root domain
+func (pm *patternMiner) Observe(name, domain string) []string {
+	if name == domain || !strings.HasSuffix(name, "."+domain) {
+		return nil
+	}
+	sub := strings.TrimSuffix(name, "."+domain)
+
+	pm.Lock()
+	defer pm.Unlock()
+
+	dp, found := pm.domains[domain]
+	if !found {
+		dp = &domainPatterns{
+			envs:         make(map[string]struct{}),
+			regions:      make(map[string]struct{}),
+			envTemplates: make(map[string]struct{}),
+			regTemplates: make(map[string]struct{}),
+			numbers:      make(map[string]*numericRange),
+		}
+		pm.domains[domain] = dp
+	}
+
+	guesses := make(map[string]struct{})
+	for _, g := range dp.mineEnvironments(sub) {
+		guesses[g] = struct{}{}
+	}
+	for _, g := range dp.mineRegions(sub) {
+		guesses[g] = struct{}{}
+	}
+	for _, g := range dp.mineNumbers(sub) {
+		guesses[g] = struct{}{}
+	}
+	delete(guesses, sub)
+
+	var names []string
+	for g := range guesses {
+		names = append(names, g+"."+domain)
+	}
+	return names
+}
+
+// mineEnvironments - Finds the environment tokens in the labels, such as api-dev
+func (dp *domainPatterns) mineEnvironments(sub string) []string {
+	var learned []string
+	var templates []string
+
+	labels := strings.Split(sub, ".")
+	for i, label := range labels {
+		tokens := tokenRE.FindAllString(label, -1)
+
+		for j, tok := range tokens {
+			if _, env := environmentTokens[tok]; !env {
+				continue
+			}
+			if _, known := dp.envs[tok]; !known {
+				dp.envs[tok] = struct{}{}
+				learned = append(learned, tok)
+			}
+
+			t := make([]string, len(tokens))
+			copy(t, tokens)
+			t[j] = envPlaceholder
+			l := make([]string, len(labels))
+			copy(l, labels)
+			l[i] = strings.Join(t, "")
+			templates = append(templates, strings.Join(l, "."))
+		}
+	}
+	return fillTemplates(dp.envTemplates, templates, dp.envs, learned, envPlaceholder)
+}
+
+// mineRegions - Finds the cloud region codes in the name, such as api.us-east-1
+func (dp *domainPatterns) mineRegions(sub string) []string {
+	var learned []string
+	var templates []string
+
+	for _, loc := range regionRE.FindAllStringIndex(sub, -1) {
+		region := sub[loc[0]:loc[1]]
+
+		if _, known := dp.regions[region]; !known {
+			dp.regions[region] = struct{}{}
+			learned = append(learned, region)
+		}
+		templates = append(templates, sub[:loc[0]]+regionPlaceholder+sub[loc[1]:])
+	}
+	return fillTemplates(dp.regTemplates, templates, dp.regions, learned, regionPlaceholder)
+}
+
+// fillTemplates - Applies all the tokens to the new templates, and the newly learned
+// tokens to the templates saved from the earlier names
+func fillTemplates(saved map[string]struct{}, templates []string,
+	tokens map[string]struct{}, learned []string, placeholder string) []string {
+	var guesses []string
+
+	for _, tok := range learned {
+		for t := range saved {
+			guesses = append(guesses, strings.Replace(t, placeholder, tok, 1))
+		}
+	}
+
+	for _, t := range templates {
+		if _, found := saved[t]; !found && len(saved) < maxPatternTemplates {
+			saved[t] = struct{}{}
+		}
+		for tok := range tokens {
+			guesses = append(guesses, strings.Replace(t, placeholder, tok, 1))
+		}
+	}
+	return guesses
+}
+
+// mineNumbers - Tracks the range of numbers ending the first label, such as web01
+// through web04, and guesses the missing numbers and those just past the range
+func (dp *domainPatterns) mineNumbers(sub string) []string {
+	parts := strings.SplitN(sub, ".", 2)
+
+	m := numericRE.FindStringSubmatch(parts[0])
+	if m == nil {
+		return nil
+	}
+	num, err := strconv.Atoi(m[2])
+	if err != nil {
+		return nil
+	}
+
+	var width int
+	if len(m[2]) > 1 && m[2][0] == '0' {
+		width = len(m[2])
+	}
+	var rest string
+	if len(parts) > 1 {
+		rest = "." + parts[1]
+	}
+
+	key := m[1] + "#" + strconv.Itoa(width) + rest
+	r, found := dp.numbers[key]
+	if !found {
+		r = &numericRange{min: num, max: num, width: width, guessed: make(map[int]struct{})}
+		dp.numbers[key] = r
+	}
+	r.guessed[num] = struct{}{}
+	if num < r.min {
+		r.min = num
+	}
+	if num > r.max {
+		r.max = num
+	}
+
+	start := r.min
+	if r.max-r.min > maxNumericGap {
+		start = r.max
+	}
+
+	var guesses []string
+	for i := start; i <= r.max+numericLookahead; i++ {
+		if _, done := r.guessed[i]; done {
+			continue
+		}
+
+		r.guessed[i] = struct{}{}
+		guesses = append(guesses, m[1]+fmt.Sprintf("%0*d", r.width, i)+rest)
+	}
+	return guesses
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import "testing"
+
+func TestPatternMining(t *testing.T) {
+	pm := newPatternMiner()
+
+	observe := func(name string) map[string]struct{} {
+		guesses := make(map[string]struct{})
+
+		for _, g := range pm.Observe(name, "owasp.org") {
+			guesses[g] = struct{}{}
+		}
+		return guesses
+	}
+
+	observe("api-dev.owasp.org")
+	// The new environment token is applied to the earlier names
+	guesses := observe("web-stage.owasp.org")
+	for _, name := range []string{"api-stage.owasp.org", "web-dev.owasp.org"} {
+		if _, found := guesses[name]; !found {
+			t.Errorf("The environment guess %s was not provided: %v", name, guesses)
+		}
+	}
+
+	observe("app.us-east-1.owasp.org")
+	guesses = observe("db.eu-west-2.owasp.org")
+	for _, name := range []string{"app.eu-west-2.owasp.org", "db.us-east-1.owasp.org"} {
+		if _, found := guesses[name]; !found {
+			t.Errorf("The region guess %s was not provided: %v", name, guesses)
+		}
+	}
+
+	guesses = observe("node01.owasp.org")
+	if _, found := guesses["node02.owasp.org"]; !found {
+		t.Errorf("The name following node01 was not guessed: %v", guesses)
+	}
+	// Only the numbers past the earlier guesses are provided as the range grows
+	guesses = observe("node04.owasp.org")
+	for _, name := range []string{"node05.owasp.org", "node06.owasp.org", "node07.owasp.org"} {
+		if _, found := guesses[name]; !found {
+			t.Errorf("The numeric guess %s was not provided: %v", name, guesses)
+		}
+	}
+	if _, found := guesses["node02.owasp.org"]; found {
+		t.Errorf("The numbers already guessed were provided again")
+	}
+	// The guesses are not repeated for names that were already generated
+	if guesses = observe("node03.owasp.org"); len(guesses) != 0 {
+		t.Errorf("The numbers were guessed again: %v", guesses)
+	}
+
+	if names := pm.Observe("owasp.org", "owasp.org"); len(names) != 0 {
+		t.Errorf("Guesses were provided for the root domain: %v", names)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"github.com/OWASP/Amass/amass/core"
+)
+
+// pipeline - Coordinates the stages of the enumeration. Without barriers every stage is
+// opened at the start, otherwise each stage is only opened once the services of the
+// stages before it have finished their work
+type pipeline struct {
+	config *core.AmassConfig
+	bus    *core.EventBus
+
+	// The enabled stages, in the order they are run
+	stages   []string
+	services map[string][]core.AmassService
+	// The number of stages that have been opened
+	open int
+}
+
+func newPipeline(config *core.AmassConfig, bus *core.EventBus) *pipeline {
+	p := &pipeline{
+		config:   config,
+		bus:      bus,
+		services: make(map[string][]core.AmassService),
+	}
+
+	for _, stage := range core.Stages {
+		if config.StageEnabled(stage) {
+			p.stages = append(p.stages, stage)
+		}
+	}
+	return p
+}
+
+// add - Assigns the services to the stage, which is held until the stage is opened
+func (p *pipeline) add(stage string, services ...core.AmassService) {
+	for _, service := range services {
+		service.SetStage(stage)
+	}
+	p.services[stage] = append(p.services[stage], services...)
+}
+
+// start - Opens the first stage, or all of them when the stages are not separated by barriers
+func (p *pipeline) start() {
+	if len(p.stages) == 0 {
+		return
+	}
+
+	p.open = 1
+	if !p.config.StageBarriers {
+		p.open = len(p.stages)
+	}
+	p.config.OpenStage(p.stages[p.open-1])
+}
+
+// running - Returns the services of the stages that have been opened
+func (p *pipeline) running() []core.AmassService {
+	var services []core.AmassService
+
+	for _, stage := range p.stages[:p.open] {
+		services = append(services, p.services[stage]...)
+	}
+	return services
+}
+
+// step - Checks if the opened stages have finished their work, and opens the next stage.
+// It returns true once the last requested stage has finished
+func (p *pipeline) step() bool {
+	if !core.Quiescent(p.config, p.bus, p.running()) {
+		return false
+	}
+	if p.open >= len(p.stages) {
+		return true
+	}
+
+	stage := p.stages[p.open]
+	p.open++
+	p.config.Log.Printf("Starting the %s stage", stage)
+	p.config.OpenStage(stage)
+	return false
+}
+
+// stageSelected - Returns true when the stage is one of those selected, which is every
+// stage when none were selected
+func stageSelected(stages []string, stage string) bool {
+	return (&core.AmassConfig{Stages: stages}).StageEnabled(stage)
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestStageSelection(t *testing.T) {
+	tests := []struct {
+		stages  []string
+		passive bool
+		active  bool
+		valid   bool
+		result  bool
+	}{
+		{nil, false, true, true, false},
+		{[]string{core.StagePassive}, false, false, true, true},
+		{[]string{core.StagePassive, core.StageResolve}, false, false, true, false},
+		{[]string{core.StagePassive, core.StageResolve}, false, true, false, false},
+		{[]string{core.StageResolve, core.StageActive}, false, true, true, false},
+		{[]string{core.StagePassive, core.StageEnrich}, false, false, false, false},
+		{[]string{core.StageResolve}, true, false, false, false},
+		{[]string{"probe"}, false, false, false, false},
+	}
+
+	for _, test := range tests {
+		e := NewEnumeration()
+		e.AddDomain("example.com")
+		e.Stages = test.stages
+		e.Passive = test.passive
+		e.Active = test.active
+
+		config, err := e.amassConfig(false)
+		if (err == nil) != test.valid {
+			t.Errorf("The stages %v were accepted %t: %v", test.stages, err == nil, err)
+			continue
+		}
+		if err == nil && config.Passive != test.result {
+			t.Errorf("The stages %v set passive mode %t", test.stages, config.Passive)
+		}
+	}
+}
+
+type stageService struct {
+	core.BaseAmassService
+}
+
+func newStageService(config *core.AmassConfig) *stageService {
+	s := new(stageService)
+	s.BaseAmassService = *core.NewBaseAmassService("Stage Service", config, s)
+	return s
+}
+
+func TestPipelineBarriers(t *testing.T) {
+	config := &core.AmassConfig{
+		Log:           log.New(ioutil.Discard, "", 0),
+		Stages:        []string{core.StagePassive, core.StageResolve},
+		StageBarriers: true,
+	}
+	bus := core.NewEventBus(config)
+
+	srcs, dns := newStageService(config), newStageService(config)
+	p := newPipeline(config, bus)
+	p.add(core.StagePassive, srcs)
+	p.add(core.StageResolve, dns)
+	p.start()
+
+	srcs.SendRequest(&core.AmassRequest{Name: "www.example.com", Domain: "example.com"})
+	dns.SendRequest(&core.AmassRequest{Name: "www.example.com", Domain: "example.com"})
+	if req := dns.NextRequest(); req != nil {
+		t.Errorf("The resolve stage was started before the passive stage finished")
+	}
+	if p.step() {
+		t.Errorf("The pipeline finished while the passive stage had work")
+	}
+
+	if req := srcs.NextRequest(); req == nil {
+		t.Fatalf("The passive stage was not started")
+	}
+	srcs.FinishWork()
+	if p.step() || !config.StageOpen(core.StageResolve) {
+		t.Fatalf("The resolve stage was not started once the passive stage finished")
+	}
+	if config.StageOpen(core.StageEnrich) {
+		t.Errorf("The enrich stage was started without being requested")
+	}
+
+	if req := dns.NextRequest(); req == nil {
+		t.Fatalf("The resolve stage did not receive the held request")
+	}
+	dns.FinishWork()
+	if !p.step() {
+		t.Errorf("The pipeline did not stop after the requested stages")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"time"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/OWASP/Amass/amass/sources"
+)
+
+// EnumerationPlan - Describes the work an enumeration would perform with the current configuration
+type EnumerationPlan struct {
+	// The root domain names that would be targeted
+	Domains []string
+
+	// The services that would be started
+	Services []string
+
+	// The resolvers that answered a test query, and those that did not
+	Resolvers            []string
+	UnreachableResolvers []string
+
+	// The status of every registered data source
+	Sources []*SourceInfo
+
+	// Data sources with API keys that were rejected
+	KeyErrors map[string]error
+
+	// Community run data sources that could not be reached
+	Unavailable map[string]error
+
+	// The number of names generated from the wordlist for the root domains
+	BruteNames int
+
+	// The wordlist entries that expand to too many words and would be skipped
+	WordlistErrors []error
+
+	// The number of SRV names checked for the root domains
+	SRVNames int
+
+	// The number of DNS queries sent for the generated names
+	EstimatedQueries int
+
+	// The time needed to send the generated names at the configured frequency
+	EstimatedDuration time.Duration
+}
+
+// Plan - Validates the configuration and describes the enumeration without
+// sending any traffic toward the target domains
+func (e *Enumeration) Plan() (*EnumerationPlan, error) {
+	config, err := e.generateAmassConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &EnumerationPlan{
+		Domains:     config.Domains(),
+		Services:    []string{"Sources Service"},
+		Sources:     e.Sources(),
+		KeyErrors:   make(map[string]error),
+		Unavailable: make(map[string]error),
+	}
+
+	if !config.Passive {
+		plan.Services = append(plan.Services, "Data Manager Service", "DNS Service", "Alteration Service")
+		if config.BruteForcing {
+			plan.Services = append(plan.Services, "Brute Forcing Service")
+		}
+		if config.SRVBruteForcing {
+			plan.Services = append(plan.Services, "SRV Brute Forcing Service")
+		}
+		if config.SNIScanning {
+			plan.Services = append(plan.Services, "SNI Service")
+		}
+
+		// The resolvers of the configuration are checked without setting them
+		for _, r := range dnssrv.ConfiguredResolvers(config.Resolvers) {
+			if err := dnssrv.CheckResolver(r); err != nil {
+				plan.UnreachableResolvers = append(plan.UnreachableResolvers, r)
+				continue
+			}
+			plan.Resolvers = append(plan.Resolvers, r)
+		}
+	}
+
+	for _, source := range e.dataSources(config) {
+		if !sourceEnabled(config, source) {
+			continue
+		}
+		if ac, ok := source.(sources.AvailabilityChecker); ok {
+			if err := ac.Available(); err != nil {
+				plan.Unavailable[source.String()] = err
+			}
+		}
+		if len(source.RequiredKeys()) == 0 {
+			continue
+		}
+		if err := source.VerifyKeys(); err != nil {
+			plan.KeyErrors[source.String()] = err
+		}
+	}
+
+	if config.BruteForcing {
+		expanded, errs := ExpandWordlist(config.Wordlist)
+
+		words := len(expanded)
+		plan.WordlistErrors = errs
+		plan.BruteNames = words * len(plan.Domains)
+
+		if config.BruteForceDepth > 1 {
+			limit := config.MaxBruteCombinations
+			if limit == 0 {
+				limit = defaultBruteCombinations
+			}
+			combos := combinationCount(words, config.BruteForceDepth, limit)
+			plan.BruteNames += combos * len(plan.Domains)
+		}
+	}
+	if config.SRVBruteForcing && !config.Passive {
+		plan.SRVNames = dnssrv.NumOfServiceNames() * len(plan.Domains)
+	}
+
+	names := plan.BruteNames + plan.SRVNames
+	plan.EstimatedQueries = plan.BruteNames*len(dnssrv.ConfiguredQueryTypes(config)) + plan.SRVNames
+	plan.EstimatedDuration = time.Duration(names) * config.Frequency
+	return plan, nil
+}
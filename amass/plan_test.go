@@ -0,0 +1,28 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+func TestPlanResolvers(t *testing.T) {
+	e := NewEnumeration()
+	e.Passive = true
+	e.DisabledSources = amasstest.BuiltinSourceNames()
+	e.Resolvers = []string{"192.0.2.53"}
+	e.AddDomain("example.com")
+
+	if _, err := e.Plan(); err != nil {
+		t.Fatalf("Plan returned an error: %v", err)
+	}
+	for _, r := range dnssrv.CustomResolvers {
+		if r == "192.0.2.53:53" {
+			t.Errorf("The plan changed the resolvers in use")
+		}
+	}
+}
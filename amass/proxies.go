@@ -0,0 +1,75 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The value selecting direct connections for a category of data sources
+const directProxy = "direct"
+
+// The categories of data sources that proxies can be configured for
+var proxyCategories = []string{core.API, core.ARCHIVE, core.CERT, core.SCRAPE, core.WEBSEARCH}
+
+// ParseSourceProxies - Returns the proxies for the values, which have the form
+// [category=]URL. Values without a category apply to the categories not named
+// by other values, and the URL can be "direct" to connect without a proxy
+func ParseSourceProxies(values []string) (map[string]*url.URL, error) {
+	proxies := make(map[string]*url.URL)
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		var category string
+		if i := strings.Index(v, "="); i != -1 {
+			category = strings.ToLower(strings.TrimSpace(v[:i]))
+			v = strings.TrimSpace(v[i+1:])
+
+			if !validProxyCategory(category) {
+				return nil, fmt.Errorf("Proxy error: %s is not one of the data source categories %s",
+					category, strings.Join(proxyCategories, ", "))
+			}
+		}
+		if _, found := proxies[category]; found {
+			return nil, fmt.Errorf("Proxy error: More than one proxy was provided for %s", categoryName(category))
+		}
+
+		if strings.EqualFold(v, directProxy) {
+			proxies[category] = nil
+			continue
+		}
+
+		proxy, err := utils.ParseProxy(v)
+		if err != nil {
+			return nil, fmt.Errorf("Proxy error: %v", err)
+		}
+		proxies[category] = proxy
+	}
+	return proxies, nil
+}
+
+func validProxyCategory(category string) bool {
+	for _, c := range proxyCategories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+func categoryName(category string) string {
+	if category == "" {
+		return "the data sources"
+	}
+	return "the " + category + " data sources"
+}
@@ -0,0 +1,206 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+// ConfigUpdate - The settings that can be changed without restarting a running enumeration,
+// read from the reload file or sent to the /config endpoint. The rates left out keep their
+// current values, and the lists are added to the configuration
+type ConfigUpdate struct {
+	// The timing profile used as the new rates, before the rates below are applied
+	Timing string `json:"timing,omitempty"`
+
+	Frequency     string `json:"frequency,omitempty"`
+	Jitter        string `json:"jitter,omitempty"`
+	SourceDelay   string `json:"source_delay,omitempty"`
+	MaxSourceConc int    `json:"max_source_concurrency,omitempty"`
+	AuthRate      int    `json:"authoritative_rate,omitempty"`
+
+	EnableSources  []string `json:"enable_sources,omitempty"`
+	DisableSources []string `json:"disable_sources,omitempty"`
+
+	// The root domains and netblocks added to the scope of the enumeration
+	Domains []string `json:"domains,omitempty"`
+	CIDRs   []string `json:"cidrs,omitempty"`
+
+	// The subdomains removed from the scope of the enumeration
+	Blacklist []string `json:"blacklist,omitempty"`
+}
+
+// ReadConfigUpdate - Parses the JSON document of settings in the reload file
+func ReadConfigUpdate(path string) (*ConfigUpdate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the reload file: %v", err)
+	}
+
+	u := new(ConfigUpdate)
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, fmt.Errorf("Failed to parse the reload file: %v", err)
+	}
+	return u, nil
+}
+
+// Reload - Applies the settings to the running enumeration. The rates, data source names,
+// domains and netblocks are checked before any of the settings are changed
+func (e *Enumeration) Reload(u *ConfigUpdate) error {
+	e.Lock()
+	config := e.config
+	e.Unlock()
+
+	if config == nil {
+		return errors.New("The configuration can only be reloaded while the enumeration is running")
+	}
+
+	rates, err := u.rates(config.Rates())
+	if err != nil {
+		return err
+	}
+
+	var cidrs []*net.IPNet
+	for _, c := range u.CIDRs {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return fmt.Errorf("The netblock %s is not valid: %v", c, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	var domains []string
+	for _, d := range u.Domains {
+		domain := strings.ToLower(removeLastDot(strings.TrimSpace(d)))
+		if !domainNameRegex.MatchString(domain) {
+			return fmt.Errorf("%s is not a valid domain name", d)
+		}
+		if !config.IsDomainInScope(domain) {
+			domains = append(domains, domain)
+		}
+	}
+
+	if names := append(append([]string(nil), u.DisableSources...), u.EnableSources...); len(names) > 0 {
+		e.Lock()
+		srcs := e.srcs
+		e.Unlock()
+
+		if srcs == nil {
+			return errors.New("The data sources can only be changed while the enumeration is running")
+		}
+		if err := srcs.CheckSourceNames(names...); err != nil {
+			return err
+		}
+	}
+	for _, name := range u.DisableSources {
+		e.DisableSource(name)
+	}
+	for _, name := range u.EnableSources {
+		e.EnableSource(name)
+	}
+
+	config.SetRates(rates)
+	if len(u.Blacklist) > 0 {
+		config.AddBlacklist(u.Blacklist...)
+	}
+	if len(cidrs) > 0 {
+		config.AddCIDRs(cidrs...)
+		if e.sni != nil {
+			e.sni.addCIDRs(cidrs)
+		}
+	}
+	for _, domain := range domains {
+		e.AddScopeDomain(domain)
+	}
+	config.Log.Printf("The configuration was reloaded")
+	return nil
+}
+
+// rates - Returns the current rates with the changes of the update, checked the same way
+// as the rates of a new enumeration
+func (u *ConfigUpdate) rates(cur core.Rates) (core.Rates, error) {
+	if u.Timing != "" {
+		p, err := GetTimingProfile(u.Timing)
+		if err != nil {
+			return cur, err
+		}
+
+		cur.Frequency = p.Frequency
+		cur.Jitter = p.Jitter
+		cur.SourceDelay = p.SourceDelay
+		cur.MaxSourceConcurrency = p.MaxSourceConcurrency
+	}
+
+	durations := []struct {
+		value string
+		dest  *time.Duration
+	}{
+		{u.Frequency, &cur.Frequency},
+		{u.Jitter, &cur.Jitter},
+		{u.SourceDelay, &cur.SourceDelay},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return cur, fmt.Errorf("The duration %s is not valid: %v", d.value, err)
+		}
+		*d.dest = v
+	}
+	if u.MaxSourceConc != 0 {
+		cur.MaxSourceConcurrency = u.MaxSourceConc
+	}
+	if u.AuthRate != 0 {
+		cur.AuthoritativeRate = u.AuthRate
+	}
+
+	if cur.Frequency < DefaultFrequency {
+		return cur, errors.New("The configuration contains a invalid frequency")
+	}
+	if cur.MaxSourceConcurrency < 0 {
+		return cur, errors.New("The configuration contains a negative concurrency")
+	}
+	if cur.Jitter < 0 || cur.SourceDelay < 0 {
+		return cur, errors.New("The configuration contains a negative delay")
+	}
+	if cur.AuthoritativeRate < 0 {
+		return cur, errors.New("The configuration contains a negative authoritative query rate")
+	}
+	return cur, nil
+}
+
+// The largest update accepted by the /config endpoint
+const maxConfigUpdate = 1 << 20
+
+func (e *Enumeration) serveConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "The configuration is changed with a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	u := new(ConfigUpdate)
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxConfigUpdate)).Decode(u); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse the update: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := e.Reload(u); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
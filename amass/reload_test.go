@@ -0,0 +1,114 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestEnumerationReload(t *testing.T) {
+	e := NewEnumeration()
+	if err := e.Reload(&ConfigUpdate{Frequency: "1s"}); err == nil {
+		t.Errorf("The configuration was reloaded before the enumeration started")
+	}
+
+	e.config = &core.AmassConfig{
+		Log:       log.New(ioutil.Discard, "", 0),
+		Frequency: DefaultFrequency,
+	}
+	reloaded := e.config.Reloaded()
+
+	tests := []struct {
+		update *ConfigUpdate
+		valid  bool
+	}{
+		{&ConfigUpdate{Frequency: "1ms"}, false},
+		{&ConfigUpdate{Jitter: "soon"}, false},
+		{&ConfigUpdate{Timing: "glacial"}, false},
+		{&ConfigUpdate{CIDRs: []string{"192.0.2.0"}}, false},
+		{&ConfigUpdate{Timing: "sneaky", SourceDelay: "2s", Blacklist: []string{"dev.example.com"}}, true},
+	}
+	for _, test := range tests {
+		if err := e.Reload(test.update); (err == nil) != test.valid {
+			t.Errorf("The update %+v was accepted %t: %v", test.update, err == nil, err)
+		}
+	}
+
+	select {
+	case <-reloaded:
+	default:
+		t.Errorf("The services were not told of the reload")
+	}
+	rates := e.config.Rates()
+	if rates.Frequency != time.Second || rates.MaxSourceConcurrency != 2 || rates.SourceDelay != 2*time.Second {
+		t.Errorf("The reloaded rates were %+v", rates)
+	}
+	if !e.config.Blacklisted("api.dev.example.com") {
+		t.Errorf("The blacklist additions were not applied")
+	}
+}
+
+func TestReloadSourceNames(t *testing.T) {
+	e := NewEnumeration()
+	e.config = &core.AmassConfig{
+		Log:             log.New(ioutil.Discard, "", 0),
+		Frequency:       DefaultFrequency,
+		DisabledSources: amasstest.BuiltinSourceNames(),
+	}
+	e.srcs = NewSourcesService(e.config, nil)
+	e.srcs.AddSource(amasstest.NewSource("Mock Source", "www.example.com"))
+
+	u := &ConfigUpdate{
+		Frequency:      "1s",
+		DisableSources: []string{"mock source"},
+		EnableSources:  []string{"Unknown Source"},
+	}
+	if err := e.Reload(u); err == nil {
+		t.Fatalf("The update with an unknown data source was accepted")
+	}
+	if e.srcs.sourceDisabled("Mock Source") || e.config.Rates().Frequency == time.Second {
+		t.Errorf("The update was partially applied")
+	}
+}
+
+func TestConfigEndpoint(t *testing.T) {
+	e := NewEnumeration()
+	e.config = &core.AmassConfig{
+		Log:       log.New(ioutil.Discard, "", 0),
+		Frequency: DefaultFrequency,
+	}
+	handler := e.APIHandler()
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "{", http.StatusBadRequest},
+		{http.MethodPost, `{"frequency":"1ms"}`, http.StatusBadRequest},
+		{http.MethodPost, `{"frequency":"250ms","authoritative_rate":5}`, http.StatusNoContent},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, httptest.NewRequest(test.method, "/config", strings.NewReader(test.body)))
+		if w.Code != test.status {
+			t.Errorf("%s %s returned the status %d instead of %d", test.method, test.body, w.Code, test.status)
+		}
+	}
+
+	if rates := e.config.Rates(); rates.Frequency != 250*time.Millisecond || rates.AuthoritativeRate != 5 {
+		t.Errorf("The posted rates were not applied: %+v", rates)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"io"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// RecordInteractions - Saves every HTTP and DNS interaction that follows to the writer,
+// so the run can be replayed later. The recording covers the whole process
+func RecordInteractions(w io.Writer) {
+	utils.StartRecording(w)
+}
+
+// ReplayInteractions - Answers the HTTP and DNS requests that follow from the recording
+// instead of sending them, and no other network connections will be made
+func ReplayInteractions(r io.Reader) error {
+	return utils.StartReplay(r)
+}
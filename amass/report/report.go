@@ -0,0 +1,286 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package report renders the asset inventory of an enumeration as an executive report
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/OWASP/Amass/amass/core"
+)
+
+// The formats that reports are rendered in
+const (
+	HTML     = "html"
+	Markdown = "markdown"
+)
+
+// Matches the country code ending the descriptions of the autonomous systems
+var countryRegex = regexp.MustCompile(`^(.*),\s*([A-Z]{2})$`)
+
+// Report - The asset inventory of a run, and the changes since the previous run
+type Report struct {
+	Generated time.Time
+	Domains   []string
+	Totals    *Totals
+
+	// Only set when the report compares the run to a previous run
+	Compared bool
+	New      []string
+	Removed  []string
+
+	Providers []*Hosting
+	Countries []*Count
+	Takeovers []*Alias
+	Dangling  []*Alias
+
+	// The notable findings of the run, with the most severe first. The findings shown
+	// by the JSON output are replaced by those of the summary file when it is provided
+	Findings []*core.Finding
+}
+
+// Totals - The counts of the assets discovered by the run
+type Totals struct {
+	Names     int
+	Resolved  int
+	Addresses int
+	ASNs      int
+	Private   int
+	CDN       int
+}
+
+// Hosting - The names and addresses hosted within an autonomous system
+type Hosting struct {
+	ASN       int
+	Provider  string
+	Country   string
+	Names     int
+	Addresses int
+}
+
+// Count - The number of names for a value, such as a country
+type Count struct {
+	Value string
+	Names int
+}
+
+// Alias - A name with a CNAME record that did not provide any addresses
+type Alias struct {
+	Name    string
+	Target  string
+	Service string
+}
+
+// New - Returns the report for the results of the run, compared to the results of the
+// previous run when they are provided
+func New(results, previous []*amass.AmassOutput) *Report {
+	r := &Report{
+		Generated: time.Now(),
+		Totals:    new(Totals),
+		Compared:  previous != nil,
+		Findings:  amass.CollectFindings(results),
+	}
+
+	domains := make(map[string]struct{})
+	current := make(map[string]struct{})
+	addrs := make(map[string]struct{})
+	hosting := make(map[int]*Hosting)
+	hostingAddrs := make(map[int]map[string]struct{})
+	countries := make(map[string]int)
+
+	for _, out := range results {
+		if _, found := current[out.Name]; found {
+			continue
+		}
+		current[out.Name] = struct{}{}
+		domains[out.Domain] = struct{}{}
+
+		r.Totals.Names++
+		if out.Resolved() {
+			r.Totals.Resolved++
+		}
+		if out.Private() {
+			r.Totals.Private++
+		}
+		if out.CDN() {
+			r.Totals.CDN++
+		}
+
+		if out.Dangling() {
+			alias := &Alias{
+				Name:    out.Name,
+				Target:  out.CNAMETarget(),
+				Service: out.TakeoverService(),
+			}
+			if alias.Service != "" {
+				r.Takeovers = append(r.Takeovers, alias)
+			} else {
+				r.Dangling = append(r.Dangling, alias)
+			}
+		}
+
+		seen := make(map[int]struct{})
+		seenCountry := make(map[string]struct{})
+		for _, addr := range out.Addresses {
+			ip := addr.Address.String()
+			addrs[ip] = struct{}{}
+			if addr.Reserved != "" {
+				continue
+			}
+
+			h, found := hosting[addr.ASN]
+			if !found {
+				h = &Hosting{ASN: addr.ASN}
+				h.Provider, h.Country = parseDescription(addr.Description)
+				hosting[addr.ASN] = h
+				hostingAddrs[addr.ASN] = make(map[string]struct{})
+			}
+			hostingAddrs[addr.ASN][ip] = struct{}{}
+			if _, found := seen[addr.ASN]; !found {
+				seen[addr.ASN] = struct{}{}
+				h.Names++
+			}
+			if _, found := seenCountry[h.Country]; !found && h.Country != "" {
+				seenCountry[h.Country] = struct{}{}
+				countries[h.Country]++
+			}
+		}
+	}
+
+	r.Totals.Addresses = len(addrs)
+	r.Totals.ASNs = len(hosting)
+	for domain := range domains {
+		if domain != "" {
+			r.Domains = append(r.Domains, domain)
+		}
+	}
+	sort.Strings(r.Domains)
+
+	for asn, h := range hosting {
+		h.Addresses = len(hostingAddrs[asn])
+		r.Providers = append(r.Providers, h)
+	}
+	sort.Slice(r.Providers, func(i, j int) bool {
+		if r.Providers[i].Names != r.Providers[j].Names {
+			return r.Providers[i].Names > r.Providers[j].Names
+		}
+		return r.Providers[i].ASN < r.Providers[j].ASN
+	})
+
+	for country, names := range countries {
+		r.Countries = append(r.Countries, &Count{Value: country, Names: names})
+	}
+	sort.Slice(r.Countries, func(i, j int) bool {
+		if r.Countries[i].Names != r.Countries[j].Names {
+			return r.Countries[i].Names > r.Countries[j].Names
+		}
+		return r.Countries[i].Value < r.Countries[j].Value
+	})
+
+	sort.Slice(r.Takeovers, func(i, j int) bool { return r.Takeovers[i].Name < r.Takeovers[j].Name })
+	sort.Slice(r.Dangling, func(i, j int) bool { return r.Dangling[i].Name < r.Dangling[j].Name })
+
+	if r.Compared {
+		r.New, r.Removed = compare(current, previous)
+	}
+	return r
+}
+
+// compare - Returns the names that were not found by the previous run, and the names that
+// the previous run found which were not found again
+func compare(current map[string]struct{}, previous []*amass.AmassOutput) ([]string, []string) {
+	var added, removed []string
+
+	prev := make(map[string]struct{})
+	for _, out := range previous {
+		if _, found := prev[out.Name]; found {
+			continue
+		}
+		prev[out.Name] = struct{}{}
+
+		if _, found := current[out.Name]; !found {
+			removed = append(removed, out.Name)
+		}
+	}
+	for name := range current {
+		if _, found := prev[name]; !found {
+			added = append(added, name)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// parseDescription - Returns the organization and the country in the description of the
+// autonomous system, such as "AMAZON-02 - Amazon.com, Inc., US"
+func parseDescription(desc string) (string, string) {
+	var country string
+
+	desc = strings.TrimSpace(desc)
+	if m := countryRegex.FindStringSubmatch(desc); m != nil {
+		desc, country = strings.TrimSpace(m[1]), m[2]
+	}
+	if i := strings.Index(desc, " - "); i != -1 {
+		desc = strings.TrimSpace(desc[i+3:])
+	}
+	if desc == "" {
+		desc = "Unknown"
+	}
+	return desc, country
+}
+
+// Write - Renders the report in the format, using the custom template text when it is not empty
+func (r *Report) Write(w io.Writer, format, custom string) error {
+	var text string
+
+	switch format {
+	case HTML:
+		text = htmlTemplate
+	case Markdown:
+		text = markdownTemplate
+	default:
+		return fmt.Errorf("Report error: The format %s is not %s or %s", format, HTML, Markdown)
+	}
+	if custom != "" {
+		text = custom
+	}
+
+	// The HTML template escapes the names, since they were provided by third parties
+	if format == HTML {
+		tmpl, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(text)
+		if err != nil {
+			return fmt.Errorf("Report error: %v", err)
+		}
+		return tmpl.Execute(w, r)
+	}
+
+	tmpl, err := template.New("report").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("Report error: %v", err)
+	}
+	return tmpl.Execute(w, r)
+}
+
+// The functions available to the templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"date":  func(t time.Time) string { return t.Format("2006-01-02 15:04 MST") },
+	"percent": func(part, total int) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%d%%", part*100/total)
+	},
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/amass"
+)
+
+func testResults() []*amass.AmassOutput {
+	amazon := "AMAZON-02 - Amazon.com, Inc., US"
+
+	return []*amass.AmassOutput{
+		{Name: "www.owasp.org", Domain: "owasp.org", Addresses: []amass.AmassAddressInfo{
+			{Address: net.ParseIP("192.0.2.1"), ASN: 16509, Description: amazon},
+		}},
+		{Name: "api.owasp.org", Domain: "owasp.org", Addresses: []amass.AmassAddressInfo{
+			{Address: net.ParseIP("192.0.2.1"), ASN: 16509, Description: amazon},
+			{Address: net.ParseIP("198.51.100.7"), ASN: 24940, Description: "HETZNER-AS, DE"},
+		}},
+		{Name: "docs.owasp.org", Domain: "owasp.org", NoData: true, Records: []amass.AmassRecordInfo{
+			{Type: "CNAME", Data: "owasp.github.io."},
+		}},
+		{Name: "old.owasp.org", Domain: "owasp.org", NoData: true, Records: []amass.AmassRecordInfo{
+			{Type: "CNAME", Data: "gone.example.net"},
+		}},
+	}
+}
+
+func TestReportNew(t *testing.T) {
+	previous := []*amass.AmassOutput{{Name: "www.owasp.org"}, {Name: "vpn.owasp.org"}}
+	r := New(testResults(), previous)
+
+	if r.Totals.Names != 4 || r.Totals.Resolved != 4 || r.Totals.Addresses != 2 || r.Totals.ASNs != 2 {
+		t.Errorf("The totals were not counted correctly: %+v", r.Totals)
+	}
+	if !reflect.DeepEqual(r.New, []string{"api.owasp.org", "docs.owasp.org", "old.owasp.org"}) {
+		t.Errorf("The new names were %v", r.New)
+	}
+	if !reflect.DeepEqual(r.Removed, []string{"vpn.owasp.org"}) {
+		t.Errorf("The removed names were %v", r.Removed)
+	}
+
+	if len(r.Providers) != 2 || r.Providers[0].Provider != "Amazon.com, Inc." || r.Providers[0].Country != "US" ||
+		r.Providers[0].Names != 2 || r.Providers[0].Addresses != 1 {
+		t.Errorf("The hosting breakdown was not built correctly: %+v", r.Providers[0])
+	}
+	if len(r.Countries) != 2 || r.Countries[0].Value != "US" || r.Countries[0].Names != 2 {
+		t.Errorf("The countries were not counted correctly: %+v", r.Countries)
+	}
+
+	if len(r.Takeovers) != 1 || r.Takeovers[0].Name != "docs.owasp.org" || r.Takeovers[0].Service != "GitHub Pages" {
+		t.Errorf("The takeover candidates were %+v", r.Takeovers)
+	}
+	if len(r.Dangling) != 1 || r.Dangling[0].Target != "gone.example.net" {
+		t.Errorf("The dangling records were %+v", r.Dangling)
+	}
+	if len(r.Findings) != 2 || r.Findings[0].Name != "docs.owasp.org" || r.Findings[1].Name != "old.owasp.org" {
+		t.Errorf("The findings were %+v", r.Findings)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	results := append(testResults(), &amass.AmassOutput{Name: "<script>.owasp.org", Domain: "owasp.org"})
+	r := New(results, nil)
+
+	for _, format := range []string{Markdown, HTML} {
+		var buf bytes.Buffer
+
+		if err := r.Write(&buf, format, ""); err != nil {
+			t.Errorf("The %s report returned an error: %v", format, err)
+			continue
+		}
+		out := buf.String()
+		if !strings.Contains(out, "docs.owasp.org") || !strings.Contains(out, "GitHub Pages") {
+			t.Errorf("The %s report did not list the takeover candidate", format)
+		}
+		if !strings.Contains(out, "Findings") || !strings.Contains(out, "HIGH") {
+			t.Errorf("The %s report did not list the findings", format)
+		}
+		if format == HTML && strings.Contains(out, "<script>") {
+			t.Errorf("The HTML report did not escape the names")
+		}
+		if strings.Contains(out, "Previous Run") {
+			t.Errorf("The %s report compared the run without a previous run", format)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, Markdown, "{{.Totals.Names}} names"); err != nil || buf.String() != "5 names" {
+		t.Errorf("The custom template returned %q: %v", buf.String(), err)
+	}
+	if err := r.Write(&buf, "pdf", ""); err == nil {
+		t.Errorf("The unknown format was accepted")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+// The default templates, which users replace by providing their own template files
+const markdownTemplate = `# Attack Surface Report
+
+Generated {{date .Generated}} for {{join .Domains ", "}}
+
+## Asset Inventory
+
+| Asset | Count |
+|---|---|
+| Names | {{.Totals.Names}} |
+| Resolved names | {{.Totals.Resolved}} ({{percent .Totals.Resolved .Totals.Names}}) |
+| Addresses | {{.Totals.Addresses}} |
+| Autonomous systems | {{.Totals.ASNs}} |
+| Names with private addresses | {{.Totals.Private}} |
+| Names hosted by CDNs | {{.Totals.CDN}} |
+
+## Findings
+{{if .Findings}}
+| Severity | Finding | Asset | Description | Evidence |
+|---|---|---|---|---|
+{{range .Findings}}| {{upper .Severity}} | {{.Type}} | {{.Name}} | {{.Description}} | {{join .Evidence ", "}} |
+{{end}}{{else}}
+None were found.
+{{end}}{{if .Compared}}
+## Changes Since the Previous Run
+
+{{len .New}} new names and {{len .Removed}} names that were not found again.
+{{range .New}}
+- New: {{.}}{{end}}{{range .Removed}}
+- Removed: {{.}}{{end}}
+{{end}}
+## Hosting
+
+| ASN | Provider | Country | Names | Addresses |
+|---|---|---|---|---|
+{{range .Providers}}| {{.ASN}} | {{.Provider}} | {{.Country}} | {{.Names}} | {{.Addresses}} |
+{{end}}
+| Country | Names |
+|---|---|
+{{range .Countries}}| {{.Value}} | {{.Names}} |
+{{end}}
+## Takeover Candidates
+{{if .Takeovers}}
+| Name | Target | Service |
+|---|---|---|
+{{range .Takeovers}}| {{.Name}} | {{.Target}} | {{.Service}} |
+{{end}}{{else}}
+None were found.
+{{end}}
+## Dangling Records
+{{if .Dangling}}
+| Name | Target |
+|---|---|
+{{range .Dangling}}| {{.Name}} | {{.Target}} |
+{{end}}{{else}}
+None were found.
+{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Attack Surface Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #eee; }
+.new { color: #070; }
+.removed { color: #a00; }
+.critical, .high { color: #a00; font-weight: bold; }
+.medium { color: #b60; }
+</style>
+</head>
+<body>
+<h1>Attack Surface Report</h1>
+<p>Generated {{date .Generated}} for {{join .Domains ", "}}</p>
+
+<h2>Asset Inventory</h2>
+<table>
+<tr><th>Asset</th><th>Count</th></tr>
+<tr><td>Names</td><td>{{.Totals.Names}}</td></tr>
+<tr><td>Resolved names</td><td>{{.Totals.Resolved}} ({{percent .Totals.Resolved .Totals.Names}})</td></tr>
+<tr><td>Addresses</td><td>{{.Totals.Addresses}}</td></tr>
+<tr><td>Autonomous systems</td><td>{{.Totals.ASNs}}</td></tr>
+<tr><td>Names with private addresses</td><td>{{.Totals.Private}}</td></tr>
+<tr><td>Names hosted by CDNs</td><td>{{.Totals.CDN}}</td></tr>
+</table>
+
+<h2>Findings</h2>
+{{if .Findings}}<table>
+<tr><th>Severity</th><th>Finding</th><th>Asset</th><th>Description</th><th>Evidence</th></tr>
+{{range .Findings}}<tr><td class="{{.Severity}}">{{upper .Severity}}</td><td>{{.Type}}</td><td>{{.Name}}</td><td>{{.Description}}</td><td>{{join .Evidence ", "}}</td></tr>
+{{end}}</table>{{else}}<p>None were found.</p>{{end}}
+{{if .Compared}}
+<h2>Changes Since the Previous Run</h2>
+<p>{{len .New}} new names and {{len .Removed}} names that were not found again.</p>
+<ul>
+{{range .New}}<li class="new">New: {{.}}</li>
+{{end}}{{range .Removed}}<li class="removed">Removed: {{.}}</li>
+{{end}}</ul>
+{{end}}
+<h2>Hosting</h2>
+<table>
+<tr><th>ASN</th><th>Provider</th><th>Country</th><th>Names</th><th>Addresses</th></tr>
+{{range .Providers}}<tr><td>{{.ASN}}</td><td>{{.Provider}}</td><td>{{.Country}}</td><td>{{.Names}}</td><td>{{.Addresses}}</td></tr>
+{{end}}</table>
+<table>
+<tr><th>Country</th><th>Names</th></tr>
+{{range .Countries}}<tr><td>{{.Value}}</td><td>{{.Names}}</td></tr>
+{{end}}</table>
+
+<h2>Takeover Candidates</h2>
+{{if .Takeovers}}<table>
+<tr><th>Name</th><th>Target</th><th>Service</th></tr>
+{{range .Takeovers}}<tr><td>{{.Name}}</td><td>{{.Target}}</td><td>{{.Service}}</td></tr>
+{{end}}</table>{{else}}<p>None were found.</p>{{end}}
+
+<h2>Dangling Records</h2>
+{{if .Dangling}}<table>
+<tr><th>Name</th><th>Target</th></tr>
+{{range .Dangling}}<tr><td>{{.Name}}</td><td>{{.Target}}</td></tr>
+{{end}}</table>{{else}}<p>None were found.</p>{{end}}
+</body>
+</html>
+`
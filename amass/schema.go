@@ -0,0 +1,219 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// OutputSchemaVersion - The version of the JSON output schema written by this release.
+// It is incremented whenever a field is renamed, removed or changes its meaning
+const OutputSchemaVersion = 2
+
+// The field holding the schema version of each JSON output record
+const schemaVersionField = "schema_version"
+
+// schemaMigrations - Each function upgrades a record from the version at its index plus one
+var schemaMigrations = []func(map[string]interface{}){
+	// Version 1 records were written before the schema was versioned
+	func(rec map[string]interface{}) {
+		if addrs, found := rec["addresses"]; !found || addrs == nil {
+			rec["addresses"] = []interface{}{}
+		}
+	},
+}
+
+// SchemaVersion - Returns the schema version of the JSON output record, where records
+// without the field were written using the first version
+func SchemaVersion(rec map[string]interface{}) (int, error) {
+	v, found := rec[schemaVersionField]
+	if !found {
+		return 1, nil
+	}
+
+	var num float64
+	switch n := v.(type) {
+	case float64:
+		num = n
+	case int:
+		num = float64(n)
+	case json.Number:
+		num, _ = n.Float64()
+	}
+	if num < 1 || num != float64(int(num)) {
+		return 0, fmt.Errorf("Schema error: The schema version %v is not valid", v)
+	}
+	return int(num), nil
+}
+
+// UpgradeOutput - Converts the JSON output record to the current schema version
+func UpgradeOutput(rec map[string]interface{}) error {
+	version, err := SchemaVersion(rec)
+	if err != nil {
+		return err
+	}
+	if version > OutputSchemaVersion {
+		return fmt.Errorf("Schema error: Version %d is newer than the supported version %d",
+			version, OutputSchemaVersion)
+	}
+
+	for ; version < OutputSchemaVersion; version++ {
+		schemaMigrations[version-1](rec)
+	}
+	rec[schemaVersionField] = OutputSchemaVersion
+	return nil
+}
+
+// MigrateOutput - Reads the JSON output records written by any release and writes them
+// using the current schema version. It returns the number of records that were upgraded
+func MigrateOutput(r io.Reader, w io.Writer) (int, error) {
+	var upgraded int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	enc := json.NewEncoder(w)
+	for num := 1; scanner.Scan(); num++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		// Keep the integers, such as the SOA serials, from being converted to floats
+		dec.UseNumber()
+		if err := dec.Decode(&rec); err != nil {
+			return upgraded, fmt.Errorf("Schema error: Line %d is not a JSON record: %v", num, err)
+		}
+
+		version, _ := SchemaVersion(rec)
+		if err := UpgradeOutput(rec); err != nil {
+			return upgraded, fmt.Errorf("Line %d: %v", num, err)
+		}
+		if version < OutputSchemaVersion {
+			upgraded++
+		}
+
+		if err := enc.Encode(rec); err != nil {
+			return upgraded, err
+		}
+	}
+	return upgraded, scanner.Err()
+}
+
+// outputRecord - The fields of the JSON output records that are read back into AmassOutput
+type outputRecord struct {
+	Name      string `json:"name"`
+	Domain    string `json:"domain"`
+	Addresses []struct {
+		IP          string `json:"ip"`
+		CIDR        string `json:"cidr"`
+		ASN         int    `json:"asn"`
+		Description string `json:"desc"`
+		PTR         string `json:"ptr"`
+		PTRMatch    bool   `json:"ptr_match"`
+		Reserved    string `json:"reserved"`
+
+		Certificates []struct {
+			Port       int       `json:"port"`
+			ServerName string    `json:"server_name"`
+			Subject    string    `json:"subject"`
+			NotBefore  time.Time `json:"not_before"`
+			NotAfter   time.Time `json:"not_after"`
+			Version    string    `json:"tls_version"`
+			SelfSigned bool      `json:"self_signed"`
+			Legacy     string    `json:"legacy_tls_version"`
+			Mismatched bool      `json:"name_mismatch"`
+		} `json:"certificates"`
+	} `json:"addresses"`
+	Records []struct {
+		Type     string `json:"type"`
+		TTL      int    `json:"ttl"`
+		Priority int    `json:"priority"`
+		Data     string `json:"data"`
+	} `json:"records"`
+	Tag       string `json:"tag"`
+	Source    string `json:"source"`
+	DNSSEC    string `json:"dnssec"`
+	Divergent bool   `json:"divergent"`
+	GeoDNS    bool   `json:"geo_dns"`
+	NoData    bool   `json:"no_data"`
+}
+
+// ReadOutput - Returns the names in the JSON output file written by any release, so the
+// results of earlier runs can be compared and reported
+func ReadOutput(r io.Reader) ([]*AmassOutput, error) {
+	var results []*AmassOutput
+
+	var buf bytes.Buffer
+	if _, err := MigrateOutput(r, &buf); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(&buf)
+	for {
+		var rec outputRecord
+
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Schema error: %v", err)
+		}
+
+		out := &AmassOutput{
+			Name:      rec.Name,
+			Domain:    rec.Domain,
+			Tag:       rec.Tag,
+			Source:    rec.Source,
+			DNSSEC:    rec.DNSSEC,
+			Divergent: rec.Divergent,
+			GeoDNS:    rec.GeoDNS,
+			NoData:    rec.NoData,
+		}
+		for _, addr := range rec.Addresses {
+			_, ipnet, _ := net.ParseCIDR(addr.CIDR)
+
+			info := AmassAddressInfo{
+				Address:     net.ParseIP(addr.IP),
+				Netblock:    ipnet,
+				ASN:         addr.ASN,
+				Description: addr.Description,
+				PTR:         addr.PTR,
+				PTRMatch:    addr.PTRMatch,
+				Reserved:    addr.Reserved,
+			}
+			for _, c := range addr.Certificates {
+				info.Certificates = append(info.Certificates, AmassCertInfo{
+					Port:       c.Port,
+					ServerName: c.ServerName,
+					Subject:    c.Subject,
+					NotBefore:  c.NotBefore,
+					NotAfter:   c.NotAfter,
+					Version:    c.Version,
+					SelfSigned: c.SelfSigned,
+					Legacy:     c.Legacy,
+					Mismatched: c.Mismatched,
+				})
+			}
+			out.Addresses = append(out.Addresses, info)
+		}
+		for _, r := range rec.Records {
+			out.Records = append(out.Records, AmassRecordInfo{
+				Type:     r.Type,
+				TTL:      r.TTL,
+				Priority: r.Priority,
+				Data:     r.Data,
+			})
+		}
+		results = append(results, out)
+	}
+	return results, nil
+}
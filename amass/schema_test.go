@@ -0,0 +1,91 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMigrateOutput(t *testing.T) {
+	input := strings.Join([]string{
+		`{"name":"www.owasp.org","domain":"owasp.org","addresses":[{"ip":"192.0.2.1","cidr":"192.0.2.0/24","asn":64496,"desc":"TEST"}],"tag":"dns","source":"Forward DNS"}`,
+		`{"name":"old.owasp.org","domain":"owasp.org","addresses":null,"tag":"cert","source":"Crtsh"}`,
+		``,
+		`{"schema_version":2,"name":"mail.owasp.org","domain":"owasp.org","addresses":[],"tag":"dns","source":"Forward DNS","domain_info":{"soa_serial":2018101500,"dnskey":false}}`,
+	}, "\n")
+
+	out := new(bytes.Buffer)
+	upgraded, err := MigrateOutput(strings.NewReader(input), out)
+	if err != nil {
+		t.Fatalf("MigrateOutput failed: %v", err)
+	}
+	if upgraded != 2 {
+		t.Errorf("%d records were upgraded instead of two", upgraded)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("%d records were written instead of three", len(lines))
+	}
+	for _, line := range lines {
+		var rec map[string]interface{}
+
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Errorf("The record %q could not be parsed: %v", line, err)
+			continue
+		}
+		if v, _ := SchemaVersion(rec); v != OutputSchemaVersion {
+			t.Errorf("The record %q has schema version %d", line, v)
+		}
+		if _, ok := rec["addresses"].([]interface{}); !ok {
+			t.Errorf("The record %q has no list of addresses", line)
+		}
+	}
+	if !strings.Contains(lines[2], `"soa_serial":2018101500`) {
+		t.Errorf("The SOA serial was not preserved: %s", lines[2])
+	}
+}
+
+func TestMigrateOutputErrors(t *testing.T) {
+	tests := []string{
+		`not a record`,
+		`{"schema_version":99,"name":"www.owasp.org"}`,
+		`{"schema_version":"two","name":"www.owasp.org"}`,
+	}
+
+	for _, input := range tests {
+		if _, err := MigrateOutput(strings.NewReader(input), new(bytes.Buffer)); err == nil {
+			t.Errorf("The input %q was migrated without an error", input)
+		}
+	}
+}
+
+func TestReadOutput(t *testing.T) {
+	input := strings.Join([]string{
+		`{"name":"www.owasp.org","domain":"owasp.org","addresses":[{"ip":"192.0.2.1","cidr":"192.0.2.0/24","asn":64496,"desc":"TEST"}],"tag":"dns","source":"Forward DNS"}`,
+		`{"schema_version":2,"name":"docs.owasp.org","domain":"owasp.org","addresses":[],"records":[{"type":"CNAME","ttl":300,"data":"owasp.github.io"}],"tag":"cert","source":"Crtsh","no_data":true}`,
+	}, "\n")
+
+	results, err := ReadOutput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadOutput returned an error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("ReadOutput returned %d names, expected 2", len(results))
+	}
+
+	if www := results[0]; len(www.Addresses) != 1 || www.Addresses[0].Netblock.String() != "192.0.2.0/24" || www.Addresses[0].ASN != 64496 {
+		t.Errorf("The addresses were not read correctly: %+v", www.Addresses)
+	}
+	if docs := results[1]; !docs.NoData || docs.TakeoverService() != "GitHub Pages" {
+		t.Errorf("The records were not read correctly: %+v", docs)
+	}
+
+	if _, err := ReadOutput(strings.NewReader(`{"schema_version":99}`)); err == nil {
+		t.Errorf("The record from a newer schema version was accepted")
+	}
+}
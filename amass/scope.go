@@ -0,0 +1,109 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// Matches a lowercase domain name with at least two labels
+var domainNameRegex = regexp.MustCompile("^" + utils.SUBRE + "[a-z][a-z0-9-]{0,61}[a-z0-9]$")
+
+// AddScopeDomain - Adds the root domain to the running enumeration. The services start
+// the work for the new domain, such as the data source queries and brute forcing, next
+// to the work already underway for the other domains
+func (e *Enumeration) AddScopeDomain(domain string) error {
+	return e.addScopeDomains([]string{domain})
+}
+
+// addScopeDomains - Adds the root domains to the running enumeration only when all of them
+// are valid and outside the scope, so a rejected request leaves the scope as it was
+func (e *Enumeration) addScopeDomains(names []string) error {
+	var domains []string
+	for _, name := range names {
+		domain := strings.ToLower(removeLastDot(strings.TrimSpace(name)))
+		if !domainNameRegex.MatchString(domain) {
+			return fmt.Errorf("%s is not a valid domain name", domain)
+		}
+		domains = append(domains, domain)
+	}
+
+	// The lock is held from the scope checks until the domains are added,
+	// so two requests cannot both add the same domain
+	e.Lock()
+	config, bus := e.config, e.bus
+	if config == nil {
+		e.Unlock()
+		return errors.New("Domains can only be added to the scope while the enumeration is running")
+	}
+	for i, domain := range domains {
+		if config.IsDomainInScope(domain) || withinDomains(domain, domains[:i]) {
+			e.Unlock()
+			return fmt.Errorf("%s is already within the scope of the enumeration", domain)
+		}
+	}
+	for _, domain := range domains {
+		e.domains = utils.UniqueAppend(e.domains, domain)
+		config.AddDomain(domain)
+	}
+	e.Unlock()
+
+	for _, domain := range domains {
+		bus.Publish(core.NEWDOMAIN, domain)
+		config.Log.Printf("The domain %s was added to the scope", domain)
+	}
+	return nil
+}
+
+// withinDomains - Returns true when the name is one of the domains or beneath one of them
+func withinDomains(name string, domains []string) bool {
+	for _, d := range domains {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeUpdate - The root domains sent to the /domains endpoint
+type scopeUpdate struct {
+	Domains []string `json:"domains"`
+}
+
+// serveDomains - Returns the root domains of the enumeration, or adds the domains sent in a POST request
+func (e *Enumeration) serveDomains(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		e.Lock()
+		domains := append([]string(nil), e.domains...)
+		e.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&scopeUpdate{Domains: domains})
+	case http.MethodPost:
+		u := new(scopeUpdate)
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxConfigUpdate)).Decode(u); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to parse the domains: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if err := e.addScopeDomains(u.Domains); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "The domains are listed with GET and added with POST", http.StatusMethodNotAllowed)
+	}
+}
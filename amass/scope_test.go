@@ -0,0 +1,149 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestAddScopeDomain(t *testing.T) {
+	e := NewEnumeration()
+	e.AddDomain("owasp.org")
+	if err := e.AddScopeDomain("example.org"); err == nil {
+		t.Errorf("A domain was added before the enumeration started")
+	}
+
+	config := &core.AmassConfig{
+		Log:          log.New(ioutil.Discard, "", 0),
+		Frequency:    time.Millisecond,
+		BruteForcing: true,
+		Wordlist:     []string{"www", "mail"},
+	}
+	config.AddDomain("owasp.org")
+	bus := core.NewEventBus(config)
+	e.config, e.bus = config, bus
+
+	var lock sync.Mutex
+	var guesses []string
+	bus.SubscribeAsync(core.DNSQUERY, func(req *core.AmassRequest) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if req.Tag == core.BRUTE {
+			guesses = append(guesses, req.Name)
+		}
+	}, false)
+
+	bfs := NewBruteForceService(config, bus)
+	if err := bfs.Start(); err != nil {
+		t.Fatalf("The brute forcing service was not started: %v", err)
+	}
+	// The root domains provided at the start are not added again
+	for bfs.IsActive() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	lock.Lock()
+	guesses = nil
+	lock.Unlock()
+
+	for _, domain := range []string{"", "example", "www.owasp.org"} {
+		if err := e.AddScopeDomain(domain); err == nil {
+			t.Errorf("The domain %q was added to the scope", domain)
+		}
+	}
+	if err := e.AddScopeDomain("Example.ORG."); err != nil {
+		t.Fatalf("The domain was not added to the scope: %v", err)
+	}
+	if !config.IsDomainInScope("www.example.org") {
+		t.Errorf("The names beneath the new domain are not in scope")
+	}
+
+	// The new domain is brute forced once the event has been delivered
+	for bus.Pending() > 0 || bfs.IsActive() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	bfs.Stop()
+	bus.Stop()
+	bus.WaitAsync()
+
+	lock.Lock()
+	sort.Strings(guesses)
+	if strings.Join(guesses, ",") != "mail.example.org,www.example.org" {
+		t.Errorf("The new domain was brute forced with the guesses %v", guesses)
+	}
+	lock.Unlock()
+
+	w := httptest.NewRecorder()
+	e.APIHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/domains", nil))
+	if body := w.Body.String(); !strings.Contains(body, `"example.org"`) || !strings.Contains(body, `"owasp.org"`) {
+		t.Errorf("The domains endpoint returned %s", body)
+	}
+}
+
+func TestServeDomainsPost(t *testing.T) {
+	e := NewEnumeration()
+	e.AddDomain("owasp.org")
+
+	config := &core.AmassConfig{Log: log.New(ioutil.Discard, "", 0)}
+	config.AddDomain("owasp.org")
+	e.config, e.bus = config, core.NewEventBus(config)
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		e.APIHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/domains", strings.NewReader(body)))
+		return w.Code
+	}
+
+	// A request with any rejected domain leaves the scope as it was
+	for _, body := range []string{
+		`{"domains": ["example.org", "not a domain"]}`,
+		`{"domains": ["example.org", "www.owasp.org"]}`,
+		`{"domains": ["example.org", "dev.example.org"]}`,
+	} {
+		if code := post(body); code != http.StatusBadRequest {
+			t.Errorf("The request %s returned %d", body, code)
+		}
+		if config.IsDomainInScope("example.org") {
+			t.Fatalf("The request %s partly changed the scope", body)
+		}
+	}
+
+	// Only one of the requests adding the same domain at once succeeds
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- post(`{"domains": ["example.org"]}`)
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	var added int
+	for code := range codes {
+		if code == http.StatusNoContent {
+			added++
+		}
+	}
+	if added != 1 {
+		t.Errorf("The domain was added by %d requests", added)
+	}
+	if domains := e.Domains(); len(domains) != 2 {
+		t.Errorf("The enumeration has the root domains %v", domains)
+	}
+	e.bus.Stop()
+	e.bus.WaitAsync()
+}
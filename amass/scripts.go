@@ -0,0 +1,365 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/sources"
+	"github.com/OWASP/Amass/amass/utils"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Scripts are Lua files in the script directory, run by the interpreter embedded in the package.
+// Each script is loaded once for the enumeration and defines any of the following globals:
+//
+//	name = "Example"
+//	function query(domain) return {"www." .. domain} end   -- a data source
+//	function alter(name, domain) return {"dev." .. name} end -- an alteration rule
+//	function keep(result) return result.tag ~= "scrape" end  -- an output filter
+//
+// The result provided to keep holds the name, domain, tag, source and addresses. Only the base,
+// string, table and math libraries are opened, so a script cannot reach the file system or run
+// programs, and the functions below are the only ones added for the scripts:
+//
+//	http_get(url) -> body or nil, message   Requests the page through the proxy of the data sources
+//	find_names(text, domain) -> names      Returns the names beneath the domain found in the text
+//	log(message)                           Writes the message to the enumeration log
+const (
+	// The longest time a script is allowed to run for a single call
+	scriptTimeout = time.Minute
+
+	// The file name extension of the scripts in the script directory
+	scriptExt = ".lua"
+)
+
+// The functions of the base library that reach outside of the interpreter
+var scriptRemovedFuncs = []string{"dofile", "loadfile", "module", "require", "print", "_printregs"}
+
+type script struct {
+	sync.Mutex
+	name   string
+	path   string
+	state  *lua.LState
+	proxy  *url.URL
+	logger *log.Logger
+}
+
+// scriptSet - The scripts found in the script directory, by the functions they defined
+type scriptSet struct {
+	sources     []sources.DataSource
+	alterations []*script
+	filters     []*script
+	scripts     []*script
+}
+
+// scriptSource - A data source implemented by the query function of a script
+type scriptSource struct {
+	sources.BaseDataSource
+	script *script
+}
+
+// loadScripts - Runs the scripts found in the directory, and keeps those defining the known functions
+func loadScripts(dir string, proxy *url.URL, logger *log.Logger) (*scriptSet, error) {
+	set := new(scriptSet)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return set, fmt.Errorf("Failed to read the script directory %s: %v", dir, err)
+	}
+
+	var errs []string
+	for _, f := range files {
+		if !f.Mode().IsRegular() || filepath.Ext(f.Name()) != scriptExt {
+			continue
+		}
+
+		s, err := newScript(filepath.Join(dir, f.Name()), proxy, logger)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		var used bool
+		if s.defines("query") {
+			used = true
+			set.sources = append(set.sources, &scriptSource{
+				BaseDataSource: *sources.NewBaseDataSource(sources.API, s.name),
+				script:         s,
+			})
+		}
+		if s.defines("alter") {
+			used = true
+			set.alterations = append(set.alterations, s)
+		}
+		if s.defines("keep") {
+			used = true
+			set.filters = append(set.filters, s)
+		}
+		if !used {
+			s.state.Close()
+			continue
+		}
+		set.scripts = append(set.scripts, s)
+	}
+
+	if len(errs) > 0 {
+		return set, fmt.Errorf("Failed to load scripts: %v", errs)
+	}
+	return set, nil
+}
+
+// newScript - Runs the script in an interpreter holding only the functions provided to scripts
+func newScript(path string, proxy *url.URL, logger *log.Logger) (*script, error) {
+	s := &script{
+		name:   strings.TrimSuffix(filepath.Base(path), scriptExt),
+		path:   path,
+		state:  lua.NewState(lua.Options{SkipOpenLibs: true}),
+		proxy:  proxy,
+		logger: logger,
+	}
+
+	L := s.state
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.StringLibName, lua.OpenString},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range scriptRemovedFuncs {
+		L.SetGlobal(name, lua.LNil)
+	}
+	L.SetGlobal("http_get", L.NewFunction(s.httpGet))
+	L.SetGlobal("find_names", L.NewFunction(s.findNames))
+	L.SetGlobal("log", L.NewFunction(s.log))
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+
+	L.SetContext(ctx)
+	err := L.DoFile(path)
+	L.RemoveContext()
+	if err != nil {
+		L.Close()
+		return nil, fmt.Errorf("Script %s failed: %v", path, err)
+	}
+
+	if name, ok := L.GetGlobal("name").(lua.LString); ok && name != "" {
+		s.name = string(name)
+	}
+	return s, nil
+}
+
+// Close - Releases the interpreters of the scripts
+func (set *scriptSet) Close() {
+	for _, s := range set.scripts {
+		s.Lock()
+		s.state.Close()
+		s.Unlock()
+	}
+	set.scripts = nil
+}
+
+// loadedScripts - Returns the scripts of the script directory, loaded the first time they are needed
+func (e *Enumeration) loadedScripts() *scriptSet {
+	e.loadLock.Lock()
+	defer e.loadLock.Unlock()
+
+	if e.scripts != nil {
+		return e.scripts
+	}
+
+	e.scripts = new(scriptSet)
+	if e.ScriptDir != "" {
+		proxy := (&core.AmassConfig{SourceProxies: e.SourceProxies}).SourceProxy(sources.API)
+
+		set, err := loadScripts(e.ScriptDir, proxy, e.Log)
+		if err != nil {
+			e.Log.Printf("%v", err)
+		}
+		e.scripts = set
+	}
+	return e.scripts
+}
+
+// closeScripts - Releases the scripts loaded for the enumeration
+func (e *Enumeration) closeScripts() {
+	e.loadLock.Lock()
+	defer e.loadLock.Unlock()
+
+	if e.scripts != nil {
+		e.scripts.Close()
+		e.scripts = nil
+	}
+}
+
+func (s *script) defines(fn string) bool {
+	return s.state.GetGlobal(fn).Type() == lua.LTFunction
+}
+
+// call - Runs the global function of the script and returns the value it returned
+func (s *script) call(fn string, args ...lua.LValue) (lua.LValue, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	L := s.state
+	if L.IsClosed() {
+		return nil, fmt.Errorf("Script %s was closed", s.name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+
+	if err := L.CallByParam(lua.P{
+		Fn:      L.GetGlobal(fn),
+		NRet:    1,
+		Protect: true,
+	}, args...); err != nil {
+		return nil, fmt.Errorf("Script %s failed in %s: %v", s.name, fn, err)
+	}
+
+	ret := L.Get(-1)
+	L.Pop(1)
+	return ret, nil
+}
+
+// names - Returns the strings in the table returned by the script
+func (s *script) names(fn string, args ...lua.LValue) ([]string, error) {
+	ret, err := s.call(fn, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	switch v := ret.(type) {
+	case *lua.LTable:
+		v.ForEach(func(_, value lua.LValue) {
+			if str, ok := value.(lua.LString); ok {
+				names = append(names, strings.ToLower(strings.TrimSpace(string(str))))
+			}
+		})
+	case *lua.LNilType:
+	default:
+		return nil, errors.New("Script " + s.name + " did not return a table of names from " + fn)
+	}
+	return names, nil
+}
+
+// Alter - Returns the names generated by the script from a resolved name
+func (s *script) Alter(name, domain string) ([]string, error) {
+	return s.names("alter", lua.LString(name), lua.LString(domain))
+}
+
+// Keep - Returns true when the script accepts the result for output
+func (s *script) Keep(out *AmassOutput) (bool, error) {
+	result := s.state.NewTable()
+
+	result.RawSetString("name", lua.LString(out.Name))
+	result.RawSetString("domain", lua.LString(out.Domain))
+	result.RawSetString("tag", lua.LString(out.Tag))
+	result.RawSetString("source", lua.LString(out.Source))
+
+	addrs := s.state.NewTable()
+	for _, addr := range out.Addresses {
+		addrs.Append(lua.LString(addr.Address.String()))
+	}
+	result.RawSetString("addresses", addrs)
+
+	ret, err := s.call("keep", result)
+	if err != nil {
+		return false, err
+	}
+	return lua.LVAsBool(ret), nil
+}
+
+func (s *script) httpGet(L *lua.LState) int {
+	page, err := utils.GetWebPageProxy(L.CheckString(1), nil, s.proxy)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LString(page))
+	return 1
+}
+
+func (s *script) findNames(L *lua.LState) int {
+	text, domain := L.CheckString(1), L.CheckString(2)
+
+	names := L.NewTable()
+	for _, name := range utils.UniqueAppend(nil, utils.SubdomainRegex(domain).FindAllString(text, -1)...) {
+		names.Append(lua.LString(strings.ToLower(name)))
+	}
+	L.Push(names)
+	return 1
+}
+
+func (s *script) log(L *lua.LState) int {
+	s.logf("%s: %s", s.name, L.CheckString(1))
+	return 0
+}
+
+func (s *script) logf(format string, v ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, v...)
+	}
+}
+
+// Query - Returns the names beneath the domain that were returned by the script
+func (ss *scriptSource) Query(domain, sub string) []string {
+	var unique []string
+
+	names, err := ss.script.names("query", lua.LString(domain))
+	if err != nil {
+		ss.script.logf("%v", err)
+		return unique
+	}
+
+	re := utils.SubdomainRegex(domain)
+	for _, name := range names {
+		// Do not trust the script to stay within scope
+		if re.FindString(name) != name {
+			continue
+		}
+		if u := utils.NewUniqueElements(unique, name); len(u) > 0 {
+			unique = append(unique, u...)
+		}
+	}
+	return unique
+}
+
+// SetProxy - Sends the requests made by the script through the proxy
+func (ss *scriptSource) SetProxy(proxy *url.URL) {
+	ss.BaseDataSource.SetProxy(proxy)
+
+	ss.script.Lock()
+	ss.script.proxy = proxy
+	ss.script.Unlock()
+}
+
+func (ss *scriptSource) List() string {
+	return "Sends the root domain names to the script " + ss.script.path +
+		", which can contact any third party through http_get"
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The alteration script counts the requests it answered, which only grows while its state is kept
+const testAlterationScript = `
+name = "Counter"
+local n = 0
+
+function alter(name, domain)
+	n = n + 1
+	return {"dev" .. n .. "." .. domain}
+end
+`
+
+const testFilterScript = `
+function keep(result)
+	return not string.find(result.name, "internal", 1, true) and #result.addresses > 0
+end
+`
+
+const testSourceScript = `
+name = "Script Source"
+
+function query(domain)
+	local page, err = http_get(base_url .. "/" .. domain)
+	if page == nil then
+		log(err)
+		return {}
+	end
+	return find_names(page, domain)
+end
+`
+
+// The script is loaded, but it cannot reach the functions that are not provided to scripts
+const testSandboxScript = `
+function keep(result)
+	return io == nil and os == nil and require == nil and dofile == nil and loadfile == nil
+end
+`
+
+func writeTestScripts(t *testing.T, scripts map[string]string) string {
+	dir, err := ioutil.TempDir("", "amass-scripts")
+	if err != nil {
+		t.Fatalf("Failed to create the script directory: %v", err)
+	}
+
+	for name, content := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write the %s script: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadScripts(t *testing.T) {
+	dir := writeTestScripts(t, map[string]string{
+		"alter.lua":  testAlterationScript,
+		"filter.lua": testFilterScript,
+		"notes.txt":  "function keep(result) return false end",
+	})
+	defer os.RemoveAll(dir)
+
+	set, err := loadScripts(dir, nil, nil)
+	if err != nil {
+		t.Fatalf("The scripts were not loaded: %v", err)
+	}
+	defer set.Close()
+
+	if len(set.alterations) != 1 || len(set.filters) != 1 || len(set.sources) != 0 {
+		t.Fatalf("The scripts were loaded as %d alterations, %d filters and %d sources",
+			len(set.alterations), len(set.filters), len(set.sources))
+	}
+	if name := set.alterations[0].name; name != "Counter" {
+		t.Errorf("The alteration script was named %s", name)
+	}
+
+	for i, expected := range []string{"dev1.example.com", "dev2.example.com"} {
+		names, err := set.alterations[0].Alter("www.example.com", "example.com")
+		if err != nil || len(names) != 1 || names[0] != expected {
+			t.Errorf("Request %d to the alteration script returned %v: %v", i+1, names, err)
+		}
+	}
+
+	f := set.filters[0]
+	addrs := []AmassAddressInfo{{Address: []byte{192, 0, 2, 1}}}
+	if keep, err := f.Keep(&AmassOutput{Name: "internal.example.com", Domain: "example.com", Addresses: addrs}); err != nil || keep {
+		t.Errorf("The filter script kept the internal name: %v", err)
+	}
+	if keep, err := f.Keep(&AmassOutput{Name: "www.example.com", Domain: "example.com"}); err != nil || keep {
+		t.Errorf("The filter script kept the name without addresses: %v", err)
+	}
+	if keep, err := f.Keep(&AmassOutput{Name: "www.example.com", Domain: "example.com", Addresses: addrs}); err != nil || !keep {
+		t.Errorf("The filter script dropped the name: %v", err)
+	}
+}
+
+func TestScriptSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "www.%[1]s and mail.%[1]s, but not www.other.org", strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer srv.Close()
+
+	dir := writeTestScripts(t, map[string]string{
+		"source.lua": fmt.Sprintf("base_url = %q\n%s", srv.URL, testSourceScript),
+	})
+	defer os.RemoveAll(dir)
+
+	set, err := loadScripts(dir, nil, nil)
+	if err != nil || len(set.sources) != 1 {
+		t.Fatalf("The source script was not loaded: %v", err)
+	}
+	defer set.Close()
+
+	source := set.sources[0]
+	if source.String() != "Script Source" {
+		t.Errorf("The source script was named %s", source.String())
+	}
+	names := source.Query("example.com", "example.com")
+	if strings.Join(names, ",") != "www.example.com,mail.example.com" {
+		t.Errorf("The source script returned %v", names)
+	}
+}
+
+func TestScriptSandbox(t *testing.T) {
+	dir := writeTestScripts(t, map[string]string{
+		"sandbox.lua": testSandboxScript,
+		"broken.lua":  "function keep(",
+	})
+	defer os.RemoveAll(dir)
+
+	set, err := loadScripts(dir, nil, nil)
+	if err == nil {
+		t.Errorf("The scripts that failed to load were not reported")
+	}
+	defer set.Close()
+
+	if len(set.filters) != 1 {
+		t.Fatalf("%d filter scripts were loaded", len(set.filters))
+	}
+	if keep, err := set.filters[0].Keep(&AmassOutput{Name: "www.example.com"}); err != nil || !keep {
+		t.Errorf("The script was able to reach the functions outside of the interpreter: %v", err)
+	}
+}
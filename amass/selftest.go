@@ -0,0 +1,125 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/sources"
+)
+
+const (
+	// A domain that every working data source has many names for
+	DefaultSelfTestDomain = "owasp.org"
+
+	// The longest time a data source is given to answer during the self-test
+	selfTestTimeout = 2 * time.Minute
+)
+
+// SourceTestResult - Describes how a data source performed during the self-test
+type SourceTestResult struct {
+	Name     string
+	Category string
+	Names    int
+	Invalid  int
+	Errors   []string
+	Duration time.Duration
+	TimedOut bool
+}
+
+// Broken - Returns true when the data source returned nothing usable
+func (r *SourceTestResult) Broken() bool {
+	return r.TimedOut || r.Names == 0 || r.Invalid > 0
+}
+
+// SelfTest - Queries every enabled data source for the domain and reports how many
+// names each returned, so broken scrapers and APIs can be identified
+func (e *Enumeration) SelfTest(domain string) []*SourceTestResult {
+	var wg sync.WaitGroup
+	var results []*SourceTestResult
+
+	config := &core.AmassConfig{
+		Log:             e.Log,
+		DisabledSources: e.DisabledSources,
+	}
+	for _, source := range e.dataSources(config) {
+		if !sourceEnabled(config, source) {
+			continue
+		}
+
+		result := &SourceTestResult{
+			Name:     source.String(),
+			Category: source.Type(),
+		}
+		results = append(results, result)
+
+		wg.Add(1)
+		go testSource(source, domain, result, &wg)
+	}
+	wg.Wait()
+	return results
+}
+
+func testSource(source sources.DataSource, domain string, result *SourceTestResult, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Capture the errors reported by the data source
+	buf := new(syncBuffer)
+	source.SetLogger(log.New(buf, "", 0))
+
+	found := make(chan []string, 1)
+	start := time.Now()
+	go func() {
+		found <- source.Query(domain, domain)
+	}()
+
+	var names []string
+	select {
+	case names = <-found:
+	case <-time.After(selfTestTimeout):
+		result.TimedOut = true
+	}
+	result.Duration = time.Since(start)
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			result.Names++
+		} else {
+			result.Invalid++
+		}
+	}
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result.Errors = append(result.Errors, line)
+		}
+	}
+}
+
+// syncBuffer - A buffer that can be written by data source goroutines while being read
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.Lock()
+	defer sb.Unlock()
+
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.Lock()
+	defer sb.Unlock()
+
+	return sb.buf.String()
+}
@@ -0,0 +1,652 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The states of the jobs run by the enumeration server
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobFinished = "finished"
+	JobFailed   = "failed"
+	JobStopped  = "stopped"
+)
+
+// The most enumerations run at once by the server when no limit is provided
+const defaultMaxJobs = 2
+
+// The most jobs a tenant can have queued or running, and running at once, when its limits are not provided
+const (
+	defaultTenantJobs    = 10
+	defaultTenantRunning = 1
+)
+
+// The file beneath the directory of the server where the jobs are kept across restarts
+const serverJobsFile = "jobs.json"
+
+// The tenant names are used as the directories holding their results
+var tenantNameRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Returned by Submit when the tenant already has as many jobs as it is allowed
+var ErrTooManyJobs = errors.New("Server error: The tenant has reached the limit of queued and running jobs")
+
+// Tenant - A user of the enumeration server, identified by the key presented as a bearer
+// token. The settings of the tenant are applied to each enumeration it submits, and the
+// tenant can only see its own enumerations and results
+type Tenant struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	// Are the enumerations limited to the data sources, without DNS resolution?
+	Passive bool `json:"passive"`
+
+	// The data sources that are never queried, and the names never investigated for the tenant
+	DisabledSources []string `json:"disabled_sources"`
+	Blacklist       []string `json:"blacklist"`
+
+	// The rate budget of each enumeration: the DNS queries per minute and in total, the API
+	// calls made to each data source, and the longest run in minutes (zero means no limit)
+	QueriesPerMinute int64  `json:"queries_per_minute"`
+	MaxDNSQueries    uint64 `json:"max_dns_queries"`
+	MaxSourceCalls   int    `json:"max_source_calls"`
+	MaxRuntime       int    `json:"max_runtime_minutes"`
+
+	// The most jobs of the tenant queued or running, and running at once, so the budgets
+	// of each enumeration cannot be multiplied by submitting more jobs (zero uses the defaults)
+	MaxJobs        int `json:"max_jobs"`
+	MaxRunningJobs int `json:"max_running_jobs"`
+}
+
+func (t *Tenant) maxJobs() int {
+	if t.MaxJobs > 0 {
+		return t.MaxJobs
+	}
+	return defaultTenantJobs
+}
+
+func (t *Tenant) maxRunningJobs() int {
+	if t.MaxRunningJobs > 0 {
+		return t.MaxRunningJobs
+	}
+	return defaultTenantRunning
+}
+
+// ReadTenants - Reads the JSON array of the tenants served by the enumeration server
+func ReadTenants(path string) ([]*Tenant, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenants []*Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("Server error: Failed to parse %s: %v", path, err)
+	}
+	if err := checkTenants(tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func checkTenants(tenants []*Tenant) error {
+	if len(tenants) == 0 {
+		return errors.New("Server error: No tenants were provided")
+	}
+
+	names := make(map[string]struct{})
+	keys := make(map[string]struct{})
+	for _, t := range tenants {
+		if !tenantNameRE.MatchString(t.Name) {
+			return fmt.Errorf("Server error: The tenant name %q can only hold letters, digits, '-' and '_'", t.Name)
+		}
+		if t.Key == "" {
+			return fmt.Errorf("Server error: The tenant %s has no key", t.Name)
+		}
+		if _, found := names[t.Name]; found {
+			return fmt.Errorf("Server error: The tenant %s was provided more than once", t.Name)
+		}
+		if _, found := keys[t.Key]; found {
+			return fmt.Errorf("Server error: The tenant %s shares its key with another tenant", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		keys[t.Key] = struct{}{}
+	}
+	return nil
+}
+
+// JobRequest - The enumeration submitted by a tenant in a POST request to /jobs
+type JobRequest struct {
+	Domains         []string `json:"domains"`
+	Passive         bool     `json:"passive,omitempty"`
+	Active          bool     `json:"active,omitempty"`
+	BruteForce      bool     `json:"brute_force,omitempty"`
+	DisabledSources []string `json:"disabled_sources,omitempty"`
+
+	// The queued jobs of the tenant with a higher priority are run first, and the oldest among
+	// equals. The priority does not move the job ahead of the jobs of the other tenants
+	Priority int `json:"priority,omitempty"`
+}
+
+// Job - An enumeration run by the server for a tenant
+type Job struct {
+	ID        string     `json:"id"`
+	Tenant    string     `json:"tenant"`
+	Request   JobRequest `json:"request"`
+	State     string     `json:"state"`
+	Error     string     `json:"error,omitempty"`
+	Submitted time.Time  `json:"submitted"`
+	Started   time.Time  `json:"started,omitempty"`
+	Ended     time.Time  `json:"ended,omitempty"`
+}
+
+// JobSettings - The settings of a job, where those of the tenant take precedence over
+// those requested. The results are written to the JSON output file and errors to the log
+type JobSettings struct {
+	Domains          []string
+	Passive          bool
+	Active           bool
+	BruteForce       bool
+	DisabledSources  []string
+	Blacklist        []string
+	QueriesPerMinute int64
+	MaxDNSQueries    uint64
+	MaxSourceCalls   int
+	MaxRuntime       time.Duration
+	JSONPath         string
+	LogPath          string
+}
+
+// JobRunner - Runs the enumeration of a job until it completes or the context is canceled
+type JobRunner func(ctx context.Context, settings *JobSettings) error
+
+// EnumServer - Runs the enumerations submitted by the tenants, keeping the results of
+// each tenant in its own directory. The jobs wait in a queue until one of the maxJobs
+// runs is available, the tenants taking turns, and the queue is kept in the directory
+// across restarts
+type EnumServer struct {
+	sync.Mutex
+
+	// Logger for the errors of the jobs completed in the background
+	log *log.Logger
+
+	dir     string
+	tenants []*Tenant
+	maxJobs int
+	run     JobRunner
+	jobs    map[string]*serverJob
+	running int
+
+	// The position in tenants of the next tenant to start a job
+	turn int
+}
+
+type serverJob struct {
+	*Job
+	tenant *Tenant
+	cancel context.CancelFunc
+}
+
+// NewEnumServer - Returns the server running at most maxJobs jobs of the tenants at once
+// with the runner (zero uses the default), and keeping their results beneath the directory.
+// The jobs queued or running when the server last stopped are queued again
+func NewEnumServer(dir string, tenants []*Tenant, maxJobs int, run JobRunner) (*EnumServer, error) {
+	if err := checkTenants(tenants); err != nil {
+		return nil, err
+	}
+	for _, t := range tenants {
+		if err := os.MkdirAll(filepath.Join(dir, t.Name), 0700); err != nil {
+			return nil, fmt.Errorf("Server error: Failed to create the directory of %s: %v", t.Name, err)
+		}
+	}
+	if maxJobs <= 0 {
+		maxJobs = defaultMaxJobs
+	}
+
+	s := &EnumServer{
+		log:     log.New(ioutil.Discard, "", 0),
+		dir:     dir,
+		tenants: tenants,
+		maxJobs: maxJobs,
+		run:     run,
+		jobs:    make(map[string]*serverJob),
+	}
+	if err := s.loadJobs(); err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.schedule(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetLog - Sets the logger for the errors of the jobs completed in the background
+func (s *EnumServer) SetLog(l *log.Logger) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.log = l
+}
+
+// loadJobs - Reads the jobs kept by the server before it was restarted
+func (s *EnumServer) loadJobs() error {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, serverJobsFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Server error: Failed to read the jobs: %v", err)
+	}
+
+	var jobs []*Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("Server error: Failed to parse the jobs: %v", err)
+	}
+
+	for _, job := range jobs {
+		// The jobs of the tenants that were removed are dropped
+		t := s.tenantByName(job.Tenant)
+		if t == nil {
+			continue
+		}
+		// The runs interrupted by the restart are started again
+		if job.State == JobRunning {
+			job.State = JobQueued
+			job.Started = time.Time{}
+		}
+		s.jobs[job.ID] = &serverJob{Job: job, tenant: t}
+	}
+	return nil
+}
+
+// saveJobs - Writes the jobs to the directory, so they are kept across restarts. The
+// caller must hold the lock
+func (s *EnumServer) saveJobs() error {
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.Job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Submitted.Before(jobs[j].Submitted)
+	})
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The file is replaced at once, so a crash does not leave it partly written
+	path := filepath.Join(s.dir, serverJobsFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("Server error: Failed to write the jobs: %v", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (s *EnumServer) tenantByName(name string) *Tenant {
+	for _, t := range s.tenants {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Handler - Returns the endpoints of the server, which require the key of a tenant presented
+// as a bearer token. GET /jobs lists the jobs of the tenant and POST /jobs submits a JobRequest.
+// GET /jobs/ID returns a job, DELETE /jobs/ID stops it and GET /jobs/ID/results returns its
+// results as JSON lines
+func (s *EnumServer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := s.tenant(r)
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "The request did not present the key of a tenant", http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.Trim(r.URL.Path, "/")
+		switch parts := strings.Split(path, "/"); {
+		case path == "jobs":
+			s.serveJobs(w, r, t)
+		case len(parts) == 2 && parts[0] == "jobs":
+			s.serveJob(w, r, t, parts[1])
+		case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "results":
+			s.serveResults(w, r, t, parts[1])
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// tenant - Returns the tenant whose key was presented by the request
+func (s *EnumServer) tenant(r *http.Request) *Tenant {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil
+	}
+
+	key := []byte(strings.TrimPrefix(auth, "Bearer "))
+	for _, t := range s.tenants {
+		if subtle.ConstantTimeCompare(key, []byte(t.Key)) == 1 {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *EnumServer) serveJobs(w http.ResponseWriter, r *http.Request, t *Tenant) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.tenantJobs(t))
+	case http.MethodPost:
+		var req JobRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxConfigUpdate)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to parse the job: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		job, err := s.Submit(t, &req)
+		if errors.Is(err, ErrTooManyJobs) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusCreated, job)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "The method is not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *EnumServer) serveJob(w http.ResponseWriter, r *http.Request, t *Tenant, id string) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		if err := s.stopJob(t, id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "The method is not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job := s.tenantJob(t, id)
+	if job == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *EnumServer) serveResults(w http.ResponseWriter, r *http.Request, t *Tenant, id string) {
+	if s.tenantJob(t, id) == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, s.jobPath(t, id, ".json"))
+}
+
+// Submit - Queues the enumeration requested by the tenant. ErrTooManyJobs is returned
+// when the tenant already has as many jobs queued or running as it is allowed
+func (s *EnumServer) Submit(t *Tenant, req *JobRequest) (*Job, error) {
+	var domains []string
+	for _, d := range req.Domains {
+		d = strings.ToLower(removeLastDot(strings.TrimSpace(d)))
+		if d == "" {
+			continue
+		}
+		// The domains are passed to the enumeration joined by commas
+		if strings.Contains(d, ",") || !domainNameRegex.MatchString(d) {
+			return nil, fmt.Errorf("Server error: %s is not a valid domain name", d)
+		}
+		domains = utils.UniqueAppend(domains, d)
+	}
+	if len(domains) == 0 {
+		return nil, errors.New("Server error: The job provided no root domains")
+	}
+	req.Domains = domains
+
+	job := &serverJob{
+		Job: &Job{
+			ID:        utils.NewUUID(),
+			Tenant:    t.Name,
+			Request:   *req,
+			State:     JobQueued,
+			Submitted: time.Now(),
+		},
+		tenant: t,
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	var pending int
+	for _, j := range s.jobs {
+		if j.tenant == t && (j.State == JobQueued || j.State == JobRunning) {
+			pending++
+		}
+	}
+	if pending >= t.maxJobs() {
+		return nil, ErrTooManyJobs
+	}
+
+	s.jobs[job.ID] = job
+	if err := s.saveJobs(); err != nil {
+		delete(s.jobs, job.ID)
+		return nil, err
+	}
+	c := *job.Job
+	return &c, s.schedule()
+}
+
+// schedule - Starts queued jobs while fewer than maxJobs are running. The tenants take
+// turns, each running at most its MaxRunningJobs at once, and the jobs of a tenant are
+// started by their priority. The caller must hold the lock
+func (s *EnumServer) schedule() error {
+	queues := make(map[*Tenant][]*serverJob)
+	running := make(map[*Tenant]int)
+	for _, job := range s.jobs {
+		switch job.State {
+		case JobQueued:
+			queues[job.tenant] = append(queues[job.tenant], job)
+		case JobRunning:
+			running[job.tenant]++
+		}
+	}
+	for _, queued := range queues {
+		sort.Slice(queued, func(i, j int) bool {
+			if queued[i].Request.Priority != queued[j].Request.Priority {
+				return queued[i].Request.Priority > queued[j].Request.Priority
+			}
+			return queued[i].Submitted.Before(queued[j].Submitted)
+		})
+	}
+
+	for s.running < s.maxJobs {
+		t := s.nextTenant(queues, running)
+		if t == nil {
+			break
+		}
+
+		job := queues[t][0]
+		queues[t] = queues[t][1:]
+		running[t]++
+
+		ctx, cancel := context.WithCancel(context.Background())
+		job.cancel = cancel
+		job.State = JobRunning
+		job.Started = time.Now()
+		s.running++
+		go s.runJob(ctx, job, s.jobSettings(job))
+	}
+	return s.saveJobs()
+}
+
+// nextTenant - Returns the next tenant in turn with a queued job and fewer running jobs
+// than it is allowed. The caller must hold the lock
+func (s *EnumServer) nextTenant(queues map[*Tenant][]*serverJob, running map[*Tenant]int) *Tenant {
+	for i := 0; i < len(s.tenants); i++ {
+		idx := (s.turn + i) % len(s.tenants)
+
+		if t := s.tenants[idx]; len(queues[t]) > 0 && running[t] < t.maxRunningJobs() {
+			s.turn = idx + 1
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *EnumServer) runJob(ctx context.Context, job *serverJob, settings *JobSettings) {
+	err := s.run(ctx, settings)
+
+	s.Lock()
+	defer s.Unlock()
+
+	job.Ended = time.Now()
+	switch {
+	case ctx.Err() != nil:
+		job.State = JobStopped
+	case err != nil:
+		job.State = JobFailed
+		job.Error = err.Error()
+	default:
+		job.State = JobFinished
+	}
+	job.cancel()
+	s.running--
+	if err := s.schedule(); err != nil {
+		s.log.Printf("%v", err)
+	}
+}
+
+// jobSettings - Combines the request of the job with the settings of its tenant, which
+// cannot be loosened by the request
+func (s *EnumServer) jobSettings(job *serverJob) *JobSettings {
+	t := job.tenant
+	req := job.Request
+
+	return &JobSettings{
+		Domains:          req.Domains,
+		Passive:          t.Passive || req.Passive,
+		Active:           req.Active && !t.Passive,
+		BruteForce:       req.BruteForce && !t.Passive,
+		DisabledSources:  append(append([]string(nil), t.DisabledSources...), req.DisabledSources...),
+		Blacklist:        t.Blacklist,
+		QueriesPerMinute: t.QueriesPerMinute,
+		MaxDNSQueries:    t.MaxDNSQueries,
+		MaxSourceCalls:   t.MaxSourceCalls,
+		MaxRuntime:       time.Duration(t.MaxRuntime) * time.Minute,
+		JSONPath:         s.jobPath(t, job.ID, ".json"),
+		LogPath:          s.jobPath(t, job.ID, ".log"),
+	}
+}
+
+func (s *EnumServer) jobPath(t *Tenant, id, ext string) string {
+	return filepath.Join(s.dir, t.Name, id+ext)
+}
+
+// stopJob - Stops the job when it was submitted by the tenant, and returns the error
+// of keeping the queue without the job
+func (s *EnumServer) stopJob(t *Tenant, id string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	job, found := s.jobs[id]
+	if !found || job.tenant != t {
+		return nil
+	}
+
+	switch job.State {
+	case JobQueued:
+		job.State = JobStopped
+		job.Ended = time.Now()
+		return s.saveJobs()
+	case JobRunning:
+		job.cancel()
+	}
+	return nil
+}
+
+// tenantJob - Returns a copy of the job, when it was submitted by the tenant
+func (s *EnumServer) tenantJob(t *Tenant, id string) *Job {
+	s.Lock()
+	defer s.Unlock()
+
+	job, found := s.jobs[id]
+	if !found || job.tenant != t {
+		return nil
+	}
+	c := *job.Job
+	return &c
+}
+
+// tenantJobs - Returns copies of the jobs submitted by the tenant, the newest first
+func (s *EnumServer) tenantJobs(t *Tenant) []*Job {
+	s.Lock()
+	defer s.Unlock()
+
+	jobs := []*Job{}
+	for _, job := range s.jobs {
+		if job.tenant == t {
+			c := *job.Job
+			jobs = append(jobs, &c)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Submitted.After(jobs[j].Submitted)
+	})
+	return jobs
+}
+
+// ServeEnumServer - Serves the endpoints of the enumeration server on the address until an
+// error occurs. An address without a host binds to the loopback interface, and the
+// connections use TLS when tlsConfig is provided
+func ServeEnumServer(addr string, handler http.Handler, tlsConfig *tls.Config) error {
+	srv := &http.Server{
+		Addr:      apiAddress(addr),
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+
+	if tlsConfig != nil {
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
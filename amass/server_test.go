@@ -0,0 +1,334 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serverRequest(t *testing.T, h http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func waitForJob(t *testing.T, s *EnumServer, tenant *Tenant, id string) *Job {
+	for i := 0; i < 100; i++ {
+		if job := s.tenantJob(tenant, id); job != nil && job.State != JobQueued && job.State != JobRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("The job %s did not complete", id)
+	return nil
+}
+
+func TestEnumServerTenants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-server")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tenants := []*Tenant{
+		{Name: "red", Key: "red-key", Passive: true, MaxDNSQueries: 5000, DisabledSources: []string{"Google"}},
+		{Name: "blue", Key: "blue-key", MaxRuntime: 30},
+	}
+	settings := make(chan *JobSettings, 2)
+	s, err := NewEnumServer(dir, tenants, 0, func(ctx context.Context, js *JobSettings) error {
+		settings <- js
+		return ioutil.WriteFile(js.JSONPath, []byte(`{"name":"www.owasp.org"}`+"\n"), 0600)
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+	h := s.Handler()
+
+	if w := serverRequest(t, h, "GET", "/jobs", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("The request without a key returned %d", w.Code)
+	}
+	if w := serverRequest(t, h, "GET", "/jobs", "green-key", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("The request with an unknown key returned %d", w.Code)
+	}
+
+	w := serverRequest(t, h, "POST", "/jobs", "red-key",
+		`{"domains": ["OWASP.org", "owasp.org"], "active": true, "disabled_sources": ["Bing Scrape"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("The job was not submitted: %d %s", w.Code, w.Body.String())
+	}
+	var job Job
+	json.NewDecoder(w.Body).Decode(&job)
+	waitForJob(t, s, tenants[0], job.ID)
+
+	// The settings of the tenant cannot be loosened by the job
+	js := <-settings
+	if len(js.Domains) != 1 || !js.Passive || js.Active || js.MaxDNSQueries != 5000 ||
+		strings.Join(js.DisabledSources, ",") != "Google,Bing Scrape" {
+		t.Errorf("The job was run with the settings %+v", js)
+	}
+
+	if w := serverRequest(t, h, "GET", "/jobs/"+job.ID+"/results", "red-key", ""); w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), "www.owasp.org") {
+		t.Errorf("The results of the job were not returned: %d %s", w.Code, w.Body.String())
+	}
+	// The jobs and results of the other tenants cannot be seen
+	if w := serverRequest(t, h, "GET", "/jobs/"+job.ID+"/results", "blue-key", ""); w.Code != http.StatusNotFound {
+		t.Errorf("The results were returned to another tenant: %d", w.Code)
+	}
+	if w := serverRequest(t, h, "GET", "/jobs/"+job.ID, "blue-key", ""); w.Code != http.StatusNotFound {
+		t.Errorf("The job was returned to another tenant: %d", w.Code)
+	}
+	w = serverRequest(t, h, "GET", "/jobs", "blue-key", "")
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("The jobs of another tenant were listed: %s", body)
+	}
+
+	if w := serverRequest(t, h, "POST", "/jobs", "blue-key", `{"domains": [" "]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("The job without root domains was accepted: %d", w.Code)
+	}
+}
+
+func TestEnumServerStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-server")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tenant := &Tenant{Name: "red", Key: "red-key"}
+	s, err := NewEnumServer(dir, []*Tenant{tenant}, 0, func(ctx context.Context, js *JobSettings) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+
+	job, err := s.Submit(tenant, &JobRequest{Domains: []string{"owasp.org"}})
+	if err != nil {
+		t.Fatalf("The job was not submitted: %v", err)
+	}
+	if w := serverRequest(t, s.Handler(), "DELETE", "/jobs/"+job.ID, "red-key", ""); w.Code != http.StatusOK {
+		t.Errorf("The job could not be stopped: %d", w.Code)
+	}
+	if job := waitForJob(t, s, tenant, job.ID); job.State != JobStopped {
+		t.Errorf("The stopped job was %s", job.State)
+	}
+}
+
+func TestReadTenants(t *testing.T) {
+	tests := []struct {
+		tenants string
+		valid   bool
+	}{
+		{`[{"name": "red", "key": "a"}, {"name": "blue", "key": "b"}]`, true},
+		{`[]`, false},
+		{`[{"name": "../red", "key": "a"}]`, false},
+		{`[{"name": "red"}]`, false},
+		{`[{"name": "red", "key": "a"}, {"name": "blue", "key": "a"}]`, false},
+		{`[{"name": "red", "key": "a"}, {"name": "red", "key": "b"}]`, false},
+	}
+
+	for _, test := range tests {
+		var tenants []*Tenant
+		json.Unmarshal([]byte(test.tenants), &tenants)
+
+		if err := checkTenants(tenants); (err == nil) != test.valid {
+			t.Errorf("The tenants %s were valid %t: %v", test.tenants, err == nil, err)
+		}
+	}
+}
+
+func TestEnumServerQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-server")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tenant := &Tenant{Name: "red", Key: "red-key"}
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	s, err := NewEnumServer(dir, []*Tenant{tenant}, 1, func(ctx context.Context, js *JobSettings) error {
+		started <- js.Domains[0]
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+
+	var jobs []*Job
+	for _, req := range []*JobRequest{
+		{Domains: []string{"first.org"}},
+		{Domains: []string{"low.org"}},
+		{Domains: []string{"high.org"}, Priority: 10},
+	} {
+		job, err := s.Submit(tenant, req)
+		if err != nil {
+			t.Fatalf("The job was not submitted: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if first := <-started; first != "first.org" {
+		t.Errorf("The first job run was %s", first)
+	}
+	if job := s.tenantJob(tenant, jobs[2].ID); job.State != JobQueued {
+		t.Errorf("The job beyond the limit was %s", job.State)
+	}
+
+	// The queue is kept across restarts
+	restarted, err := NewEnumServer(dir, []*Tenant{tenant}, 0, func(ctx context.Context, js *JobSettings) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to restart the server: %v", err)
+	}
+	if n := len(restarted.tenantJobs(tenant)); n != 3 {
+		t.Errorf("The restarted server held %d jobs", n)
+	}
+	for _, job := range restarted.tenantJobs(tenant) {
+		restarted.stopJob(tenant, job.ID)
+	}
+
+	release <- struct{}{}
+	if next := <-started; next != "high.org" {
+		t.Errorf("The job run after the first was %s", next)
+	}
+	close(release)
+	waitForJob(t, s, tenant, jobs[1].ID)
+}
+
+func TestEnumServerFairness(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-server")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	red := &Tenant{Name: "red", Key: "red-key"}
+	blue := &Tenant{Name: "blue", Key: "blue-key"}
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	s, err := NewEnumServer(dir, []*Tenant{red, blue}, 1, func(ctx context.Context, js *JobSettings) error {
+		started <- js.Domains[0]
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+
+	var jobs []*Job
+	for _, sub := range []struct {
+		tenant *Tenant
+		req    *JobRequest
+	}{
+		{red, &JobRequest{Domains: []string{"first.red.org"}, Priority: 100}},
+		{red, &JobRequest{Domains: []string{"second.red.org"}, Priority: 100}},
+		{blue, &JobRequest{Domains: []string{"blue.org"}}},
+	} {
+		job, err := s.Submit(sub.tenant, sub.req)
+		if err != nil {
+			t.Fatalf("The job was not submitted: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if first := <-started; first != "first.red.org" {
+		t.Errorf("The first job run was %s", first)
+	}
+
+	// The priority of the red jobs does not move them ahead of the blue job
+	release <- struct{}{}
+	if next := <-started; next != "blue.org" {
+		t.Errorf("The job run after the first was %s", next)
+	}
+
+	// The failure to keep the queue is returned when the queued job is stopped
+	os.RemoveAll(dir)
+	if w := serverRequest(t, s.Handler(), "DELETE", "/jobs/"+jobs[1].ID, "red-key", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("The job was stopped without keeping the queue: %d", w.Code)
+	}
+	close(release)
+	waitForJob(t, s, blue, jobs[2].ID)
+}
+
+func TestEnumServerSubmit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-server")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tenant := &Tenant{Name: "red", Key: "red-key", MaxJobs: 2, MaxRunningJobs: 1}
+	release := make(chan struct{})
+	started := make(chan string, 2)
+	s, err := NewEnumServer(dir, []*Tenant{tenant}, 2, func(ctx context.Context, js *JobSettings) error {
+		started <- js.Domains[0]
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+
+	for _, domains := range [][]string{
+		{"owasp.org", "not a domain"},
+		{"owasp.org,example.com"},
+		{"-owasp.org"},
+		{"owasp"},
+	} {
+		if _, err := s.Submit(tenant, &JobRequest{Domains: domains}); err == nil {
+			t.Errorf("The job with the domains %v was accepted", domains)
+		}
+	}
+
+	job, err := s.Submit(tenant, &JobRequest{Domains: []string{" OWASP.org. ", "owasp.org"}})
+	if err != nil {
+		t.Fatalf("The job was not submitted: %v", err)
+	}
+	if d := job.Request.Domains; len(d) != 1 || d[0] != "owasp.org" {
+		t.Errorf("The domains of the job were %v", d)
+	}
+	<-started
+
+	// The tenant runs one job at a time, although the server could run another
+	queued, err := s.Submit(tenant, &JobRequest{Domains: []string{"example.com"}})
+	if err != nil {
+		t.Fatalf("The job was not submitted: %v", err)
+	}
+	if job := s.tenantJob(tenant, queued.ID); job.State != JobQueued {
+		t.Errorf("The job beyond the running limit of the tenant was %s", job.State)
+	}
+
+	// The tenant cannot hold more jobs than it is allowed
+	if _, err := s.Submit(tenant, &JobRequest{Domains: []string{"example.org"}}); !errors.Is(err, ErrTooManyJobs) {
+		t.Errorf("The job beyond the limit of the tenant returned %v", err)
+	}
+	w := serverRequest(t, s.Handler(), "POST", "/jobs", "red-key", `{"domains": ["example.org"]}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("The job beyond the limit of the tenant was answered with %d", w.Code)
+	}
+
+	close(release)
+	waitForJob(t, s, tenant, queued.ID)
+}
@@ -0,0 +1,356 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/OWASP/Amass/amass/utils"
+	evbus "github.com/asaskevich/EventBus"
+)
+
+const (
+	// The port connected to while scanning the netblocks
+	sniPort = 443
+
+	// The number of hosts around each discovered address that will be scanned
+	sniSubsetSize = 200
+)
+
+// VirtualHost - A name accepted over TLS SNI by a server within the in-scope netblocks
+type VirtualHost struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// sniHost - A host accepting connections on the TLS port, and the next of the names to present
+type sniHost struct {
+	addr string
+	next int
+}
+
+type SNIService struct {
+	core.BaseAmassService
+
+	bus evbus.Bus
+
+	sync.Mutex
+	// The in-scope names that are presented as SNI values
+	names []string
+
+	// The hosts that accept connections on the TLS port
+	hosts []*sniHost
+
+	// The addresses that have already been checked for the TLS port
+	scanned map[string]struct{}
+
+	// The addresses waiting to be checked for the TLS port
+	pendingHosts []string
+
+	// The target netblocks, and the walks through their hosts waiting to be continued. The
+	// hosts of a large netblock are too many to list, or to record in scanned
+	targets       []*net.IPNet
+	pendingRanges []*utils.HostIterator
+
+	// The hosts with names not yet presented to them. The probes are generated as each
+	// host is reached, rather than for every pair of host and name
+	pendingProbes []*sniHost
+
+	// The names each server was found to accept
+	vhosts []VirtualHost
+
+	// Connects to the hosts and pulls the certificates they present. The tests replace these
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+	pullCert func(addr string, port int, serverName string) (*x509.Certificate, uint16, error)
+}
+
+func NewSNIService(config *core.AmassConfig, bus evbus.Bus) *SNIService {
+	sss := &SNIService{
+		bus:      bus,
+		scanned:  make(map[string]struct{}),
+		dial:     dnssrv.DialContext,
+		pullCert: pullCertificate,
+	}
+
+	sss.BaseAmassService = *core.NewBaseAmassService("SNI Service", config, sss)
+	return sss
+}
+
+func (sss *SNIService) OnStart() error {
+	sss.BaseAmassService.OnStart()
+
+	sss.addCIDRs(sss.Config().CIDRs)
+
+	sss.bus.SubscribeAsync(core.RESOLVED, sss.addName, false)
+	sss.bus.SubscribeAsync(core.DNSSWEEP, sss.addNetblock, false)
+	sss.bus.SubscribeAsync(core.ADDRESS, sss.addAddress, false)
+	go sss.processRequests()
+	return nil
+}
+
+// List - Returns the servers that are presented the discovered names
+func (sss *SNIService) List() string {
+	return "Connects to the addresses in the in-scope netblocks on port " + strconv.Itoa(sniPort) +
+		", presenting the discovered names as TLS SNI values"
+}
+
+func (sss *SNIService) OnStop() error {
+	sss.BaseAmassService.OnStop()
+
+	sss.bus.Unsubscribe(core.RESOLVED, sss.addName)
+	sss.bus.Unsubscribe(core.DNSSWEEP, sss.addNetblock)
+	sss.bus.Unsubscribe(core.ADDRESS, sss.addAddress)
+	return nil
+}
+
+// VirtualHosts - Returns the names each server was found to accept
+func (sss *SNIService) VirtualHosts() []VirtualHost {
+	sss.Lock()
+	defer sss.Unlock()
+
+	vhosts := make([]VirtualHost, len(sss.vhosts))
+	copy(vhosts, sss.vhosts)
+	sort.Slice(vhosts, func(i, j int) bool {
+		if vhosts[i].Name == vhosts[j].Name {
+			return vhosts[i].Address < vhosts[j].Address
+		}
+		return vhosts[i].Name < vhosts[j].Name
+	})
+	return vhosts
+}
+
+// IsActive - Returns true while hosts or names are waiting to be checked
+func (sss *SNIService) IsActive() bool {
+	sss.Lock()
+	pending := len(sss.pendingHosts) > 0 || len(sss.pendingRanges) > 0 || len(sss.pendingProbes) > 0
+	sss.Unlock()
+
+	return pending || sss.BaseAmassService.IsActive()
+}
+
+func (sss *SNIService) addName(req *core.AmassRequest) {
+	if !sss.Config().IsDomainInScope(req.Name) {
+		return
+	}
+
+	sss.Lock()
+	defer sss.Unlock()
+
+	for _, name := range sss.names {
+		if name == req.Name {
+			return
+		}
+	}
+	sss.names = append(sss.names, req.Name)
+
+	// The hosts that were presented all the previous names are waiting again
+	for _, host := range sss.hosts {
+		if host.next == len(sss.names)-1 {
+			sss.pendingProbes = append(sss.pendingProbes, host)
+		}
+	}
+}
+
+func (sss *SNIService) addNetblock(req *core.NetblockRequest) {
+	for _, ip := range utils.CIDRSubset(req.CIDR, req.Address, sniSubsetSize) {
+		sss.addHost(ip.String())
+	}
+}
+
+// addCIDRs - Scans every host within the netblocks provided as targets of the enumeration
+func (sss *SNIService) addCIDRs(cidrs []*net.IPNet) {
+	sss.Lock()
+	defer sss.Unlock()
+
+	for _, cidr := range cidrs {
+		sss.targets = append(sss.targets, cidr)
+		sss.pendingRanges = append(sss.pendingRanges, utils.NewHostIterator(cidr))
+	}
+}
+
+func (sss *SNIService) addAddress(req *core.AddrRequest) {
+	if sss.Config().IsDomainInScope(req.Name) && !cdnExcluded(sss.Config(), req.Address) {
+		sss.addHost(req.Address)
+	}
+}
+
+func (sss *SNIService) addHost(addr string) {
+	sss.Lock()
+	defer sss.Unlock()
+
+	if _, found := sss.scanned[addr]; found {
+		return
+	}
+	// The walk through the target netblock checks the address
+	ip := net.ParseIP(addr)
+	for _, cidr := range sss.targets {
+		if utils.NetblockHost(cidr, ip) {
+			return
+		}
+	}
+	sss.scanned[addr] = struct{}{}
+	sss.pendingHosts = append(sss.pendingHosts, addr)
+}
+
+func (sss *SNIService) processRequests() {
+	t := time.NewTicker(sss.Config().Rates().Frequency)
+	// A reload must not restart the ticker of a paused service
+	var paused bool
+loop:
+	for {
+		select {
+		case <-t.C:
+			sss.nextProbe()
+		case <-sss.PauseChan():
+			t.Stop()
+			paused = true
+		case <-sss.ResumeChan():
+			t = time.NewTicker(sss.Config().Rates().Frequency)
+			paused = false
+		case <-sss.Config().Reloaded():
+			if !paused {
+				t.Stop()
+				t = time.NewTicker(sss.Config().Rates().Frequency)
+			}
+		case <-sss.Quit():
+			break loop
+		}
+	}
+	t.Stop()
+}
+
+// nextProbe - Checks the next host for the TLS port, or presents the next name to a host.
+// The names are presented to the hosts already found before the target netblocks are
+// walked further, so the results of a large netblock do not wait for the end of the walk
+func (sss *SNIService) nextProbe() {
+	if sss.Held() {
+		return
+	}
+	sss.Lock()
+	if len(sss.pendingHosts) > 0 {
+		addr := sss.pendingHosts[0]
+		sss.pendingHosts = sss.pendingHosts[1:]
+		sss.StartWork()
+		sss.Unlock()
+
+		sss.scanHost(addr)
+		sss.FinishWork()
+		return
+	}
+	if len(sss.pendingProbes) > 0 {
+		host := sss.pendingProbes[0]
+		sss.pendingProbes = sss.pendingProbes[1:]
+		name := sss.names[host.next]
+		host.next++
+		// The host takes turns with the others until every name was presented
+		if host.next < len(sss.names) {
+			sss.pendingProbes = append(sss.pendingProbes, host)
+		}
+		sss.StartWork()
+		sss.Unlock()
+
+		sss.presentName(host.addr, name)
+		sss.FinishWork()
+		return
+	}
+	if addr := sss.nextRangeHost(); addr != "" {
+		sss.StartWork()
+		sss.Unlock()
+
+		sss.scanHost(addr)
+		sss.FinishWork()
+		return
+	}
+	sss.Unlock()
+}
+
+// nextRangeHost - Returns the next host of the target netblocks not already checked before
+// the netblock was added. The lock must be held by the caller
+func (sss *SNIService) nextRangeHost() string {
+	for len(sss.pendingRanges) > 0 {
+		ip, ok := sss.pendingRanges[0].Next()
+		if !ok {
+			sss.pendingRanges = sss.pendingRanges[1:]
+			continue
+		}
+
+		if addr := ip.String(); !sss.scannedRange(addr) {
+			return addr
+		}
+	}
+	return ""
+}
+
+// scannedRange - Returns true when the address was checked as a discovered address, or
+// is also a host of a target netblock walked earlier. The lock must be held by the caller
+func (sss *SNIService) scannedRange(addr string) bool {
+	if _, found := sss.scanned[addr]; found {
+		return true
+	}
+
+	ip := net.ParseIP(addr)
+	walked := len(sss.targets) - len(sss.pendingRanges)
+	for _, cidr := range sss.targets[:walked] {
+		if utils.NetblockHost(cidr, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sss *SNIService) scanHost(addr string) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTLSConnectTimeout)
+	defer cancel()
+
+	conn, err := sss.dial(ctx, "tcp", net.JoinHostPort(addr, strconv.Itoa(sniPort)))
+	if err != nil {
+		return
+	}
+	conn.Close()
+
+	sss.Lock()
+	defer sss.Unlock()
+
+	host := &sniHost{addr: addr}
+	sss.hosts = append(sss.hosts, host)
+	if len(sss.names) > 0 {
+		sss.pendingProbes = append(sss.pendingProbes, host)
+	}
+}
+
+// presentName - Records the server as accepting the name when the certificate
+// returned for the SNI value is valid for the name
+func (sss *SNIService) presentName(addr, name string) {
+	cert, version, err := sss.pullCert(addr, sniPort, name)
+	if err != nil {
+		return
+	}
+	// Names in the certificate presented for this name may not have been seen before
+	sss.bus.Publish(core.CERTIFICATE, &core.CertRequest{
+		Address:     addr,
+		Port:        sniPort,
+		ServerName:  name,
+		Certificate: cert,
+		Version:     version,
+		Domain:      SubdomainToDomain(name),
+		Tag:         core.CERT,
+		Source:      "SNI Scan",
+	})
+
+	if err := cert.VerifyHostname(name); err != nil {
+		return
+	}
+
+	sss.Lock()
+	sss.vhosts = append(sss.vhosts, VirtualHost{Name: name, Address: addr})
+	sss.Unlock()
+}
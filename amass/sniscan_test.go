@@ -0,0 +1,142 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+	evbus "github.com/asaskevich/EventBus"
+)
+
+// newTestSNIService - Returns the service with every host accepting connections, and
+// records the hosts connected to and the names presented to them in the order sent
+func newTestSNIService() (*SNIService, func() []string) {
+	config := &core.AmassConfig{}
+	config.AddDomain("example.com")
+
+	var lock sync.Mutex
+	var probes []string
+	sss := NewSNIService(config, evbus.New())
+	sss.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, _ := net.SplitHostPort(address)
+
+		lock.Lock()
+		probes = append(probes, "dial "+host)
+		lock.Unlock()
+
+		c1, c2 := net.Pipe()
+		c2.Close()
+		return c1, nil
+	}
+	sss.pullCert = func(addr string, port int, serverName string) (*x509.Certificate, uint16, error) {
+		lock.Lock()
+		probes = append(probes, "present "+addr+" "+serverName)
+		lock.Unlock()
+
+		return nil, 0, errors.New("no certificate")
+	}
+
+	return sss, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+
+		sent := probes
+		probes = nil
+		return sent
+	}
+}
+
+// runProbes - Sends the probes until nothing is waiting to be checked
+func runProbes(t *testing.T, sss *SNIService) {
+	for i := 0; sss.IsActive(); i++ {
+		if i > 1000 {
+			t.Fatalf("The probes did not finish")
+		}
+		sss.nextProbe()
+	}
+}
+
+func TestSNIServiceProbes(t *testing.T) {
+	sss, probes := newTestSNIService()
+
+	var cidrs []*net.IPNet
+	for _, c := range []string{"192.0.2.0/30", "192.0.2.0/29"} {
+		_, ipnet, _ := net.ParseCIDR(c)
+		cidrs = append(cidrs, ipnet)
+	}
+	sss.addCIDRs(cidrs)
+	sss.addHost("198.51.100.1")
+	sss.addHost("198.51.100.1")
+	// The walk through the netblocks checks this host
+	sss.addHost("192.0.2.5")
+	// The network address is not a host that the walk checks
+	sss.addHost("192.0.2.0")
+	for _, name := range []string{"www.example.com", "mail.example.com", "www.example.com", "www.owasp.org"} {
+		sss.addName(&core.AmassRequest{Name: name})
+	}
+	runProbes(t, sss)
+
+	// The discovered hosts are checked first, and the names are presented to the
+	// hosts found before the netblocks are walked further
+	expected := []string{
+		"dial 198.51.100.1",
+		"dial 192.0.2.0",
+		"present 198.51.100.1 www.example.com",
+		"present 192.0.2.0 www.example.com",
+		"present 198.51.100.1 mail.example.com",
+		"present 192.0.2.0 mail.example.com",
+	}
+	// The broadcast address of the first netblock is a host of the second
+	hosts := []string{"198.51.100.1", "192.0.2.0"}
+	for _, addr := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5", "192.0.2.6"} {
+		hosts = append(hosts, addr)
+		expected = append(expected, "dial "+addr,
+			"present "+addr+" www.example.com", "present "+addr+" mail.example.com")
+	}
+	if got := probes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The probes sent were %v, expected %v", got, expected)
+	}
+
+	// A name discovered later is presented once to each host found
+	sss.addName(&core.AmassRequest{Name: "ftp.example.com"})
+	runProbes(t, sss)
+
+	expected = nil
+	for _, addr := range hosts {
+		expected = append(expected, "present "+addr+" ftp.example.com")
+	}
+	if got := probes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The probes sent for the new name were %v, expected %v", got, expected)
+	}
+}
+
+func TestSNIServiceScannedRange(t *testing.T) {
+	sss, probes := newTestSNIService()
+
+	_, first, _ := net.ParseCIDR("192.0.2.0/29")
+	sss.addCIDRs([]*net.IPNet{first})
+	runProbes(t, sss)
+	probes()
+
+	// The netblock added after the walk only checks the hosts not checked already
+	_, second, _ := net.ParseCIDR("192.0.2.0/28")
+	sss.addCIDRs([]*net.IPNet{second})
+	runProbes(t, sss)
+
+	expected := []string{"dial 192.0.2.7"}
+	for i := 8; i < 15; i++ {
+		expected = append(expected, "dial 192.0.2."+strconv.Itoa(i))
+	}
+	if got := probes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The hosts checked were %v, expected %v", got, expected)
+	}
+}
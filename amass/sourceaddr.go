@@ -0,0 +1,68 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+// BindSourceAddrs - Binds the outbound connections made outside of an enumeration, such as
+// by a resolution worker, to the local addresses. Enumerations use their SourceAddrs
+func BindSourceAddrs(addrs []net.IP) {
+	dnssrv.SetLocalAddresses(addrs)
+}
+
+// ParseSourceAddrs - Returns the local addresses for the values, which can be IP
+// addresses or the names of network interfaces providing every unicast address
+func ParseSourceAddrs(values []string) ([]net.IP, error) {
+	var addrs []net.IP
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(v); ip != nil {
+			addrs = append(addrs, ip)
+			continue
+		}
+
+		ips, err := interfaceAddrs(v)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, ips...)
+	}
+	return addrs, nil
+}
+
+func interfaceAddrs(name string) ([]net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("Source address error: %s is not an IP address or interface: %v", name, err)
+	}
+
+	list, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("Source address error: Failed to obtain the addresses of %s: %v", name, err)
+	}
+
+	var addrs []net.IP
+	for _, a := range list {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.IsLinkLocalUnicast() || ipnet.IP.IsMulticast() {
+			continue
+		}
+		addrs = append(addrs, ipnet.IP)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("Source address error: %s has no usable addresses", name)
+	}
+	return addrs, nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"testing"
+)
+
+func TestParseSourceAddrs(t *testing.T) {
+	addrs, err := ParseSourceAddrs([]string{"192.0.2.1", " 2001:db8::1 ", ""})
+	if err != nil {
+		t.Fatalf("ParseSourceAddrs returned an error: %v", err)
+	}
+	if len(addrs) != 2 || addrs[0].String() != "192.0.2.1" || addrs[1].String() != "2001:db8::1" {
+		t.Errorf("ParseSourceAddrs returned %v", addrs)
+	}
+
+	if _, err := ParseSourceAddrs([]string{"no-such-interface0"}); err == nil {
+		t.Errorf("ParseSourceAddrs accepted an unknown interface")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the optional OTX API key
+const otxKeyVar = "OTX_API_KEY"
+
+// AlienVault - The passive DNS and URL data of the AlienVault Open Threat Exchange
+type AlienVault struct {
+	BaseDataSource
+	baseURL string
+	// The number of URL list pages requested without and with an API key
+	pages    int
+	keyPages int
+}
+
+type otxPassiveDNS struct {
+	PassiveDNS []struct {
+		Hostname string `json:"hostname"`
+	} `json:"passive_dns"`
+}
+
+type otxURLList struct {
+	URLList []struct {
+		Hostname string `json:"hostname"`
+	} `json:"url_list"`
+	HasNext bool `json:"has_next"`
+}
+
+func NewAlienVault() DataSource {
+	a := &AlienVault{
+		baseURL:  "https://otx.alienvault.com/api/v1/indicators/domain/",
+		pages:    5,
+		keyPages: 50,
+	}
+
+	a.BaseDataSource = *NewBaseDataSource(API, "AlienVault OTX")
+	return a
+}
+
+func (a *AlienVault) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	hvals := a.headers()
+
+	u := a.baseURL + url.PathEscape(domain) + "/passive_dns"
+	page, err := a.getWebPage(u, hvals)
+	if err != nil {
+		a.log(fmt.Sprintf("%s: %v", u, err))
+		return unique
+	}
+
+	var pdns otxPassiveDNS
+	if err := json.Unmarshal([]byte(page), &pdns); err != nil {
+		a.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+	for _, rec := range pdns.PassiveDNS {
+		unique = utils.UniqueAppend(unique, ex.Names(rec.Hostname)...)
+	}
+
+	limit := a.pages
+	if hvals != nil {
+		limit = a.keyPages
+	}
+	for num := 1; num <= limit; num++ {
+		u := a.urlListURL(domain, num)
+		page, err := a.getWebPage(u, hvals)
+		if err != nil {
+			a.log(fmt.Sprintf("%s: %v", u, err))
+			break
+		}
+
+		var list otxURLList
+		if err := json.Unmarshal([]byte(page), &list); err != nil {
+			a.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			break
+		}
+		for _, rec := range list.URLList {
+			unique = utils.UniqueAppend(unique, ex.Names(rec.Hostname)...)
+		}
+		if !list.HasNext {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return unique
+}
+
+// headers - Returns the header providing the API key, or nil when the key was not set
+func (a *AlienVault) headers() map[string]string {
+	if key := os.Getenv(otxKeyVar); key != "" {
+		return map[string]string{"X-OTX-API-KEY": key}
+	}
+	return nil
+}
+
+func (a *AlienVault) urlListURL(domain string, page int) string {
+	u, _ := url.Parse(a.baseURL + url.PathEscape(domain) + "/url_list")
+
+	u.RawQuery = url.Values{
+		"limit": {"100"},
+		"page":  {strconv.Itoa(page)},
+	}.Encode()
+	return u.String()
+}
+
+func (a *AlienVault) List() string {
+	return disclosure(sendsRootDomains, hostname(a.baseURL))
+}
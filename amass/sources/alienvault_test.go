@@ -0,0 +1,56 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAlienVaultPagination(t *testing.T) {
+	var requests, keyed int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-OTX-API-KEY") == "secret" {
+			keyed++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/passive_dns") {
+			fmt.Fprint(w, `{"passive_dns": [{"hostname": "WWW.owasp.org"}, {"hostname": "owasp.org"}]}`)
+			return
+		}
+
+		page := r.URL.Query().Get("page")
+		fmt.Fprintf(w, `{"url_list": [{"hostname": "page%s.owasp.org"}, {"hostname": "www.other.org"}], "has_next": %t}`,
+			page, page != "3")
+	}))
+	defer srv.Close()
+
+	a := NewAlienVault().(*AlienVault)
+	a.baseURL = srv.URL + "/"
+	a.pages = 2
+
+	os.Unsetenv(otxKeyVar)
+	names := a.Query("owasp.org", "owasp.org")
+	sort.Strings(names)
+	if got := strings.Join(names, " "); got != "page1.owasp.org page2.owasp.org www.owasp.org" {
+		t.Errorf("Without an API key, the names returned were %s", got)
+	}
+
+	os.Setenv(otxKeyVar, "secret")
+	defer os.Unsetenv(otxKeyVar)
+	requests, keyed = 0, 0
+	names = a.Query("owasp.org", "owasp.org")
+	if len(names) != 4 || requests != 4 || keyed != 4 {
+		t.Errorf("With an API key, %d names were returned from %d requests, and %d sent the key",
+			len(names), requests, keyed)
+	}
+}
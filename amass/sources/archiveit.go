@@ -32,3 +32,7 @@ func (a *ArchiveIt) Query(domain, sub string) []string {
 func (a *ArchiveIt) Subdomains() bool {
 	return true
 }
+
+func (a *ArchiveIt) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(a.baseURL))
+}
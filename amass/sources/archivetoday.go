@@ -32,3 +32,7 @@ func (a *ArchiveToday) Query(domain, sub string) []string {
 func (a *ArchiveToday) Subdomains() bool {
 	return true
 }
+
+func (a *ArchiveToday) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(a.baseURL))
+}
@@ -0,0 +1,25 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+// The listing of every capture within the domain complements the crawl performed by Archive Today
+func NewArchiveTodaySearch() DataSource {
+	return newSearchEngine(WEBSEARCH, "Archive Today Search", &searchEngine{
+		host: "archive.is",
+		// The listing shows 20 captures per page
+		pageURL: func(query string, page int) string {
+			return fmt.Sprintf("http://archive.is/offset=%d/%s", page*20, query)
+		},
+		dork:    "*.%s",
+		pages:   10,
+		limiter: webSearchLimiter,
+		rule:    extract.Rule{Mode: extract.Strict},
+	})
+}
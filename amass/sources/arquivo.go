@@ -32,3 +32,7 @@ func (a *Arquivo) Query(domain, sub string) []string {
 func (a *Arquivo) Subdomains() bool {
 	return true
 }
+
+func (a *Arquivo) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(a.baseURL))
+}
@@ -4,62 +4,23 @@
 package sources
 
 import (
-	"fmt"
 	"net/url"
-	"strconv"
-	"time"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Ask struct {
-	BaseDataSource
-	quantity int
-	limit    int
-}
-
 func NewAsk() DataSource {
-	a := &Ask{
-		quantity: 10, // ask.com appears to be hardcoded at 10 results per page
-		limit:    100,
-	}
-
-	a.BaseDataSource = *NewBaseDataSource(SCRAPE, "Ask Scrape")
-	return a
-}
-
-func (a *Ask) Query(domain, sub string) []string {
-	var unique []string
-
-	if domain != sub {
-		return unique
-	}
-
-	re := utils.SubdomainRegex(domain)
-	num := a.limit / a.quantity
-	for i := 0; i < num; i++ {
-		u := a.urlByPageNum(domain, i)
-		page, err := utils.GetWebPage(u, nil)
-		if err != nil {
-			a.log(fmt.Sprintf("%s: %v", u, err))
-			break
-		}
-
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
-		time.Sleep(1 * time.Second)
-	}
-	return unique
-}
-
-func (a *Ask) urlByPageNum(domain string, page int) string {
-	p := strconv.Itoa(page)
-	u, _ := url.Parse("https://www.ask.com/web")
-
-	u.RawQuery = url.Values{"q": {"site:" + domain},
-		"o": {"0"}, "l": {"dir"}, "qo": {"pagination"}, "page": {p}}.Encode()
-	return u.String()
+	// Ask was queried by default before the web search category, so it keeps the scrape tag
+	return newSearchEngine(SCRAPE, "Ask Scrape", &searchEngine{
+		host: "www.ask.com",
+		// ask.com appears to be hardcoded at 10 results per page
+		pageURL: func(query string, page int) string {
+			return searchURL("https://www.ask.com/web", url.Values{"q": {query},
+				"o": {"0"}, "l": {"dir"}, "qo": {"pagination"}}, "page", page, 0, 1)
+		},
+		dork:    "site:%s",
+		pages:   10,
+		limiter: webSearchLimiter,
+		rule:    extract.Rule{Mode: extract.Loose},
+	})
 }
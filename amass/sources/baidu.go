@@ -4,61 +4,23 @@
 package sources
 
 import (
-	"fmt"
 	"net/url"
-	"strconv"
 	"time"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Baidu struct {
-	BaseDataSource
-	quantity int
-	limit    int
-}
-
 func NewBaidu() DataSource {
-	b := &Baidu{
-		quantity: 20,
-		limit:    100,
-	}
-
-	b.BaseDataSource = *NewBaseDataSource(SCRAPE, "Baidu")
-	return b
-}
-
-func (b *Baidu) Query(domain, sub string) []string {
-	var unique []string
-
-	if domain != sub {
-		return unique
-	}
-
-	re := utils.SubdomainRegex(domain)
-	num := b.limit / b.quantity
-	for i := 0; i < num; i++ {
-		u := b.urlByPageNum(domain, i)
-		page, err := utils.GetWebPage(u, nil)
-		if err != nil {
-			b.log(fmt.Sprintf("%s: %v", u, err))
-			break
-		}
-
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
-		time.Sleep(1 * time.Second)
-	}
-	return unique
-}
-
-func (b *Baidu) urlByPageNum(domain string, page int) string {
-	pn := strconv.Itoa(page)
-	u, _ := url.Parse("https://www.baidu.com/s")
-
-	u.RawQuery = url.Values{"pn": {pn}, "wd": {domain}, "oq": {domain}}.Encode()
-	return u.String()
+	return newSearchEngine(SCRAPE, "Baidu", &searchEngine{
+		host: "www.baidu.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://www.baidu.com/s",
+				url.Values{"wd": {query}, "oq": {query}, "rn": {"20"}}, "pn", page, 20, 0)
+		},
+		dork:  "site:%s",
+		pages: 5,
+		delay: time.Second,
+		// The result links are redirects, so the names come from the displayed addresses
+		rule: extract.Rule{Mode: extract.Loose},
+	})
 }
@@ -4,63 +4,22 @@
 package sources
 
 import (
-	"fmt"
 	"net/url"
-	"strconv"
 	"time"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Bing struct {
-	BaseDataSource
-	quantity int
-	limit    int
-}
-
 func NewBing() DataSource {
-	b := &Bing{
-		quantity: 20,
-		limit:    200,
-	}
-
-	b.BaseDataSource = *NewBaseDataSource(SCRAPE, "Bing Scrape")
-	return b
-}
-
-func (b *Bing) Query(domain, sub string) []string {
-	var unique []string
-
-	if domain != sub {
-		return unique
-	}
-
-	re := utils.SubdomainRegex(domain)
-	num := b.limit / b.quantity
-	for i := 0; i < num; i++ {
-		u := b.urlByPageNum(domain, i)
-		page, err := utils.GetWebPage(u, nil)
-		if err != nil {
-			b.log(fmt.Sprintf("%s: %v", u, err))
-			break
-		}
-
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
-		time.Sleep(1 * time.Second)
-	}
-	return unique
-}
-
-func (b *Bing) urlByPageNum(domain string, page int) string {
-	count := strconv.Itoa(b.quantity)
-	first := strconv.Itoa((page * b.quantity) + 1)
-	u, _ := url.Parse("http://www.bing.com/search")
-
-	u.RawQuery = url.Values{"q": {"domain:" + domain},
-		"count": {count}, "first": {first}, "FORM": {"PORE"}}.Encode()
-	return u.String()
+	return newSearchEngine(SCRAPE, "Bing Scrape", &searchEngine{
+		host: "www.bing.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("http://www.bing.com/search", url.Values{"q": {query},
+				"count": {"20"}, "FORM": {"PORE"}}, "first", page, 20, 1)
+		},
+		dork:  "domain:%s",
+		pages: 10,
+		delay: time.Second,
+		rule:  extract.Rule{Mode: extract.Loose},
+	})
 }
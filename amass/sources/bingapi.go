@@ -0,0 +1,31 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+func NewBingAPI() DataSource {
+	return newSearchEngine(API, "Bing API", &searchEngine{
+		host: "api.bing.microsoft.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://api.bing.microsoft.com/v7.0/search",
+				url.Values{"q": {query}, "count": {"50"}, "responseFilter": {"Webpages"}}, "offset", page, 50, 0)
+		},
+		dork:      "site:%s",
+		pages:     20,
+		delay:     500 * time.Millisecond,
+		keyVar:    "BING_API_KEY",
+		keyHeader: "Ocp-Apim-Subscription-Key",
+		rule: extract.Rule{
+			Scope: regexp.MustCompile(`"url":\s*"([^"]+)"`),
+			Mode:  extract.Strict,
+		},
+	})
+}
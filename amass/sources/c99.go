@@ -0,0 +1,102 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the c99.nl API key
+const c99KeyVar = "C99_API_KEY"
+
+// C99 - The subdomain finder of the c99.nl API
+type C99 struct {
+	BaseDataSource
+	baseURL string
+}
+
+type c99Response struct {
+	Success    bool   `json:"success"`
+	Error      string `json:"error"`
+	Subdomains []struct {
+		Subdomain string `json:"subdomain"`
+	} `json:"subdomains"`
+}
+
+func NewC99() DataSource {
+	c := &C99{baseURL: "https://api.c99.nl/"}
+
+	c.BaseDataSource = *NewBaseDataSource(API, "C99")
+	return c
+}
+
+func (c *C99) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	resp, err := c.request("subdomainfinder", domain)
+	if err != nil {
+		c.log(err.Error())
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for _, s := range resp.Subdomains {
+		unique = utils.UniqueAppend(unique, ex.Names(s.Subdomain)...)
+	}
+	return unique
+}
+
+// request - Calls the API and returns the error messages, such as an exhausted quota, as errors
+func (c *C99) request(api, domain string) (*c99Response, error) {
+	u, _ := url.Parse(c.baseURL + api)
+	u.RawQuery = url.Values{
+		"key":    {os.Getenv(c99KeyVar)},
+		"domain": {domain},
+		"json":   {""},
+	}.Encode()
+
+	// The address is not logged, since it contains the API key
+	page, err := c.getWebPage(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %v", c.baseURL, api, err)
+	}
+
+	var resp c99Response
+	if err := json.Unmarshal([]byte(page), &resp); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal JSON: %v", err)
+	}
+	if !resp.Success {
+		if resp.Error == "" {
+			resp.Error = "The request was not successful"
+		}
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+func (c *C99) RequiredKeys() []string {
+	return []string{c99KeyVar}
+}
+
+func (c *C99) VerifyKeys() error {
+	if _, err := c.request("subdomainfinder", "example.com"); err != nil {
+		return fmt.Errorf("The %s key was not accepted: %v", c99KeyVar, err)
+	}
+	return nil
+}
+
+func (c *C99) List() string {
+	return disclosure(sendsRootDomains, hostname(c.baseURL))
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -30,7 +31,7 @@ func (c *CertDB) Query(domain, sub string) []string {
 	}
 
 	u := c.getURL(domain)
-	page, err := utils.GetWebPage(u, nil)
+	page, err := c.getWebPage(u, nil)
 	if err != nil {
 		c.log(fmt.Sprintf("%s: %v", u, err))
 		return unique
@@ -42,11 +43,9 @@ func (c *CertDB) Query(domain, sub string) []string {
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
+	ex := extract.New(domain, extract.Strict)
 	for _, name := range names {
-		if n := re.FindString(name); n != "" {
-			unique = utils.UniqueAppend(unique, n)
-		}
+		unique = utils.UniqueAppend(unique, ex.Names(name)...)
 	}
 	return unique
 }
@@ -60,3 +59,7 @@ func (c *CertDB) getURL(domain string) string {
 	}.Encode()
 	return u.String()
 }
+
+func (c *CertDB) List() string {
+	return disclosure(sendsRootDomains, "certdb.com")
+}
@@ -6,6 +6,7 @@ package sources
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -28,18 +29,14 @@ func (c *CertSpotter) Query(domain, sub string) []string {
 	}
 
 	url := c.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := c.getWebPage(url, nil)
 	if err != nil {
 		c.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -48,3 +45,7 @@ func (c *CertSpotter) getURL(domain string) string {
 
 	return fmt.Sprintf(format, domain)
 }
+
+func (c *CertSpotter) List() string {
+	return disclosure(sendsRootDomains, "certspotter.com")
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -45,20 +46,16 @@ func (cc *CommonCrawl) Query(domain, sub string) []string {
 		return []string{}
 	}
 
-	re := utils.SubdomainRegex(domain)
+	ex := extract.New(domain, extract.Strict)
 	for _, index := range CommonCrawlIndexes {
 		u := cc.getURL(index, domain)
-		page, err := utils.GetWebPage(u, nil)
+		page, err := cc.getWebPage(u, nil)
 		if err != nil {
 			cc.log(fmt.Sprintf("%s: %v", u, err))
 			continue
 		}
 
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
+		unique = utils.UniqueAppend(unique, ex.Names(page)...)
 		time.Sleep(1 * time.Second)
 	}
 	return unique
@@ -73,3 +70,7 @@ func (cc *CommonCrawl) getURL(index, domain string) string {
 	}.Encode()
 	return u.String()
 }
+
+func (cc *CommonCrawl) List() string {
+	return disclosure(sendsRootDomains, hostname(cc.baseURL))
+}
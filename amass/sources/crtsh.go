@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -32,7 +33,7 @@ func (c *Crtsh) Query(domain, sub string) []string {
 
 	// Pull the page that lists all certs for this domain
 	url := "https://crt.sh/?q=%25." + domain
-	page, err := utils.GetWebPage(url, nil)
+	page, err := c.getWebPage(url, nil)
 	if err != nil {
 		c.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
@@ -45,7 +46,7 @@ func (c *Crtsh) Query(domain, sub string) []string {
 		time.Sleep(50 * time.Millisecond)
 		// Pull the certificate web page
 		url = "https://crt.sh/" + rel
-		cert, err := utils.GetWebPage(url, nil)
+		cert, err := c.getWebPage(url, nil)
 		if err != nil {
 			c.log(fmt.Sprintf("%s: %v", url, err))
 			continue
@@ -57,13 +58,7 @@ func (c *Crtsh) Query(domain, sub string) []string {
 }
 
 func (c *Crtsh) getMatches(content, domain string) []string {
-	var results []string
-
-	re := utils.SubdomainRegex(domain)
-	for _, s := range re.FindAllString(content, -1) {
-		results = append(results, s)
-	}
-	return results
+	return extract.Names(domain, content)
 }
 
 func (c *Crtsh) getSubmatches(content string) []string {
@@ -75,3 +70,7 @@ func (c *Crtsh) getSubmatches(content string) []string {
 	}
 	return results
 }
+
+func (c *Crtsh) List() string {
+	return disclosure(sendsRootDomains, "crt.sh")
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -46,34 +47,26 @@ func (d *DNSDB) Query(domain, sub string) []string {
 	d.filter[name] = unique
 
 	url := d.getURL(domain, sub)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := d.getWebPage(url, nil)
 	if err != nil {
 		d.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 
 	for _, rel := range d.getSubmatches(page) {
 		// Do not go too fast
 		time.Sleep(50 * time.Millisecond)
 		// Pull the certificate web page
-		another, err := utils.GetWebPage(url+rel, nil)
+		another, err := d.getWebPage(url+rel, nil)
 		if err != nil {
 			d.log(fmt.Sprintf("%s: %v", url+rel, err))
 			continue
 		}
 
-		for _, sd := range re.FindAllString(another, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
+		unique = utils.UniqueAppend(unique, ex.Names(another)...)
 	}
 	d.filter[name] = unique
 	return unique
@@ -109,3 +102,7 @@ func (d *DNSDB) getSubmatches(content string) []string {
 	}
 	return results
 }
+
+func (d *DNSDB) List() string {
+	return disclosure(sendsSubdomains, "www.dnsdb.org")
+}
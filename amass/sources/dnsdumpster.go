@@ -5,13 +5,13 @@ package sources
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -34,7 +34,7 @@ func (d *DNSDumpster) Query(domain, sub string) []string {
 	}
 
 	u := "https://dnsdumpster.com/"
-	page, err := utils.GetWebPage(u, nil)
+	page, err := d.getWebPage(u, nil)
 	if err != nil {
 		d.log(fmt.Sprintf("%s: %v", u, err))
 		return unique
@@ -52,12 +52,8 @@ func (d *DNSDumpster) Query(domain, sub string) []string {
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -72,10 +68,11 @@ func (d *DNSDumpster) getCSRFToken(page string) string {
 
 func (d *DNSDumpster) postForm(token, domain string) (string, error) {
 	client := &http.Client{
-		Transport: &http.Transport{
+		Transport: utils.WrapTransport(&http.Transport{
+			Proxy:               http.ProxyURL(d.proxy),
 			DialContext:         utils.DialContext,
 			TLSHandshakeTimeout: 10 * time.Second,
-		},
+		}),
 	}
 	params := url.Values{
 		"csrfmiddlewaretoken": {token},
@@ -108,7 +105,14 @@ func (d *DNSDumpster) postForm(token, domain string) (string, error) {
 		return "", err
 	}
 	// Now, grab the entire page
-	in, err := ioutil.ReadAll(resp.Body)
+	in, err := utils.ReadResponseBody(resp)
 	resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
 	return string(in), nil
 }
+
+func (d *DNSDumpster) List() string {
+	return disclosure(sendsRootDomains, "dnsdumpster.com")
+}
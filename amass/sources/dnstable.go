@@ -6,6 +6,7 @@ package sources
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -28,18 +29,14 @@ func (d *DNSTable) Query(domain, sub string) []string {
 	}
 
 	url := d.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := d.getWebPage(url, nil)
 	if err != nil {
 		d.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -48,3 +45,7 @@ func (d *DNSTable) getURL(domain string) string {
 
 	return fmt.Sprintf(format, domain)
 }
+
+func (d *DNSTable) List() string {
+	return disclosure(sendsRootDomains, "dnstable.com")
+}
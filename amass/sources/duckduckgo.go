@@ -0,0 +1,30 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+func NewDuckDuckGo() DataSource {
+	return newSearchEngine(SCRAPE, "DuckDuckGo", &searchEngine{
+		host:    "html.duckduckgo.com",
+		pageURL: duckDuckGoURL,
+		dork:    "site:%s",
+		pages:   5,
+		delay:   2 * time.Second,
+		rule: extract.Rule{
+			Scope: regexp.MustCompile(`class="result__(?:url|a)"[^>]*href="([^"]+)"`),
+			Mode:  extract.Loose,
+		},
+	})
+}
+
+func duckDuckGoURL(query string, page int) string {
+	return searchURL("https://html.duckduckgo.com/html/", url.Values{"q": {query}}, "s", page, 30, 0)
+}
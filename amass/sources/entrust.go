@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -31,26 +32,18 @@ func (e *Entrust) Query(domain, sub string) []string {
 	}
 
 	u := e.getURL(domain)
-	page, err := utils.GetWebPage(u, nil)
+	page, err := e.getWebPage(u, nil)
 	if err != nil {
 		e.log(fmt.Sprintf("%s: %v", u, err))
 		return unique
 	}
 	content := strings.Replace(page, "u003d", " ", -1)
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(content, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(content)...)
 
 	for _, name := range e.extractReversedSubmatches(page) {
-		if match := re.FindString(name); match != "" {
-			if u := utils.NewUniqueElements(unique, match); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
+		unique = utils.UniqueAppend(unique, ex.Names(name)...)
 	}
 	return unique
 }
@@ -93,3 +86,7 @@ func (e *Entrust) reverseSubdomain(name string) string {
 	}
 	return strings.Join(result, "")
 }
+
+func (e *Entrust) List() string {
+	return disclosure(sendsRootDomains, "ctsearch.entrust.com")
+}
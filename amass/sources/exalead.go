@@ -4,48 +4,22 @@
 package sources
 
 import (
-	"fmt"
+	"net/url"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Exalead struct {
-	BaseDataSource
-}
-
 func NewExalead() DataSource {
-	e := new(Exalead)
-
-	e.BaseDataSource = *NewBaseDataSource(SCRAPE, "Exalead")
-	return e
-}
-
-func (e *Exalead) Query(domain, sub string) []string {
-	var unique []string
-
-	if domain != sub {
-		return unique
-	}
-
-	url := e.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
-	if err != nil {
-		e.log(fmt.Sprintf("%s: %v", url, err))
-		return unique
-	}
-
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
-	return unique
-}
-
-func (e *Exalead) getURL(domain string) string {
-	base := "http://www.exalead.com/search/web/results/"
-	format := base + "?q=site:%s+-www?elements_per_page=50"
-
-	return fmt.Sprintf(format, domain)
+	// Exalead was queried by default before the web search category, so it keeps the scrape tag
+	return newSearchEngine(SCRAPE, "Exalead", &searchEngine{
+		host: "www.exalead.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://www.exalead.com/search/web/results/",
+				url.Values{"q": {query}, "elements_per_page": {"50"}}, "start_index", page, 50, 0)
+		},
+		dork:    "site:%s -www",
+		pages:   4,
+		limiter: webSearchLimiter,
+		rule:    extract.Rule{Mode: extract.Loose},
+	})
 }
@@ -0,0 +1,145 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+// The record in each line of the JSON formatted datasets
+type fdnsRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FDNSFile - Searches a local forward DNS dataset, such as the Rapid7 Project Sonar
+// files, for names within the domain. The file can be in the JSON lines or CSV format,
+// and is decompressed while it is read when gzipped. The entire file is read for each
+// root domain, so no requests leave the host
+type FDNSFile struct {
+	BaseDataSource
+	path string
+}
+
+func NewFDNSFile(path string) DataSource {
+	f := &FDNSFile{path: path}
+
+	f.BaseDataSource = *NewBaseDataSource(DNS, "Forward DNS Dataset")
+	return f
+}
+
+func (f *FDNSFile) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		f.log(err.Error())
+		return unique
+	}
+	defer file.Close()
+
+	names, err := SearchFDNS(file, domain)
+	if err != nil {
+		f.log(err.Error())
+	}
+	return names
+}
+
+// SearchFDNS - Returns the names within the domain found in the names and values of the dataset
+func SearchFDNS(r io.Reader, domain string) ([]string, error) {
+	var unique []string
+	filter := make(map[string]struct{})
+
+	in, err := fdnsReader(r)
+	if err != nil {
+		return unique, err
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.ToLower(scanner.Text())
+		// Avoid parsing the vast majority of lines that cannot match
+		if !strings.Contains(line, domain) {
+			continue
+		}
+
+		for _, name := range fdnsNames(line) {
+			if !ex.Match(name) {
+				continue
+			}
+			if _, found := filter[name]; !found {
+				filter[name] = struct{}{}
+				unique = append(unique, name)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return unique, fmt.Errorf("Failed to read the dataset: %v", err)
+	}
+	return unique, nil
+}
+
+// fdnsReader - Decompresses the dataset when the gzip header is present
+func fdnsReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decompress the dataset: %v", err)
+		}
+		return gz, nil
+	}
+	return br, nil
+}
+
+// fdnsNames - Returns the name and the value of the record, since the values of
+// CNAME, NS, MX and PTR records are names as well
+func fdnsNames(line string) []string {
+	var fields []string
+
+	if strings.HasPrefix(line, "{") {
+		var rec fdnsRecord
+
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil
+		}
+		fields = []string{rec.Name, rec.Value}
+	} else {
+		// The CSV datasets provide the timestamp, name, type and value
+		fields = strings.Split(line, ",")
+	}
+
+	var names []string
+	for _, f := range fields {
+		// MX values are preceded by the preference
+		if parts := strings.Fields(f); len(parts) > 0 {
+			names = append(names, strings.TrimSuffix(parts[len(parts)-1], "."))
+		}
+	}
+	return names
+}
+
+func (f *FDNSFile) RevealsDomain() bool {
+	return false
+}
+
+func (f *FDNSFile) List() string {
+	return "Sends nothing, since the local dataset " + f.path + " is searched"
+}
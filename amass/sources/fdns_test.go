@@ -0,0 +1,50 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testFDNS = `{"timestamp":"1535241600","name":"www.owasp.org","type":"a","value":"192.0.2.10"}
+{"timestamp":"1535241600","name":"docs.owasp.org","type":"cname","value":"www.owasp.org"}
+{"timestamp":"1535241600","name":"owasp.org","type":"mx","value":"10 mail.owasp.org."}
+{"timestamp":"1535241600","name":"www.notowasp.org","type":"a","value":"192.0.2.20"}
+1535241600,dev.owasp.org,a,192.0.2.30
+1535241600,www.example.com,cname,cdn.owasp.org.evil.com
+`
+
+func TestFDNSSearch(t *testing.T) {
+	expected := []string{"dev.owasp.org", "docs.owasp.org", "mail.owasp.org", "www.owasp.org"}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testFDNS))
+	w.Close()
+
+	for _, input := range []*bytes.Reader{
+		bytes.NewReader([]byte(testFDNS)),
+		bytes.NewReader(gz.Bytes()),
+	} {
+		names, err := SearchFDNS(input, "owasp.org")
+		if err != nil {
+			t.Errorf("SearchFDNS returned an error: %v", err)
+			continue
+		}
+
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("SearchFDNS returned %v instead of %v", names, expected)
+		}
+	}
+
+	if names, _ := SearchFDNS(strings.NewReader(testFDNS), "example.org"); len(names) != 0 {
+		t.Errorf("SearchFDNS returned names outside of the domain: %v", names)
+	}
+}
@@ -6,6 +6,7 @@ package sources
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -28,18 +29,14 @@ func (f *FindSubdomains) Query(domain, sub string) []string {
 	}
 
 	url := f.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := f.getWebPage(url, nil)
 	if err != nil {
 		f.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -48,3 +45,7 @@ func (f *FindSubdomains) getURL(domain string) string {
 
 	return fmt.Sprintf(format, domain)
 }
+
+func (f *FindSubdomains) List() string {
+	return disclosure(sendsRootDomains, "findsubdomains.com")
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the FullHunt API key
+const fullHuntKeyVar = "FULLHUNT_API_KEY"
+
+// FullHunt - The subdomains known by the FullHunt attack surface database
+type FullHunt struct {
+	BaseDataSource
+	baseURL string
+}
+
+type fullHuntResponse struct {
+	Hosts   []string `json:"hosts"`
+	Message string   `json:"message"`
+}
+
+func NewFullHunt() DataSource {
+	f := &FullHunt{baseURL: "https://fullhunt.io/api/v1/"}
+
+	f.BaseDataSource = *NewBaseDataSource(API, "FullHunt")
+	return f
+}
+
+func (f *FullHunt) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	u := f.baseURL + "domain/" + url.PathEscape(domain) + "/subdomains"
+	page, err := f.getWebPage(u, f.headers())
+	if err != nil {
+		f.log(fmt.Sprintf("%s: %v", u, err))
+		return unique
+	}
+
+	var resp fullHuntResponse
+	if err := json.Unmarshal([]byte(page), &resp); err != nil {
+		f.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for _, host := range resp.Hosts {
+		unique = utils.UniqueAppend(unique, ex.Names(host)...)
+	}
+	return unique
+}
+
+func (f *FullHunt) headers() map[string]string {
+	return map[string]string{"X-API-KEY": os.Getenv(fullHuntKeyVar)}
+}
+
+func (f *FullHunt) RequiredKeys() []string {
+	return []string{fullHuntKeyVar}
+}
+
+func (f *FullHunt) VerifyKeys() error {
+	if _, err := f.getWebPage(f.baseURL+"auth/status", f.headers()); err != nil {
+		return fmt.Errorf("The %s key was not accepted: %v", fullHuntKeyVar, err)
+	}
+	return nil
+}
+
+func (f *FullHunt) List() string {
+	return disclosure(sendsRootDomains, hostname(f.baseURL))
+}
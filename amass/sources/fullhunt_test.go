@@ -0,0 +1,35 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFullHuntQuery(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"hosts": ["www.owasp.org", "Mail.OWASP.org", "www.example.com"], "message": ""}`)
+	}))
+	defer srv.Close()
+
+	f := NewFullHunt().(*FullHunt)
+	f.baseURL = srv.URL + "/"
+
+	if names := f.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org mail.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+	if path != "/domain/owasp.org/subdomains" {
+		t.Errorf("The subdomains were requested from %s", path)
+	}
+	if names := f.Query("owasp.org", "www.owasp.org"); len(names) != 0 {
+		t.Errorf("The subdomain was queried and returned %v", names)
+	}
+}
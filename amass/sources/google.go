@@ -4,75 +4,27 @@
 package sources
 
 import (
-	"fmt"
 	"net/url"
-	"strconv"
-	"sync"
 	"time"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Google struct {
-	sync.Mutex
-	BaseDataSource
-	quantity int
-	limit    int
-}
+// The requests sent to Google are spaced out across the names searched at the same time
+var googleLimiter = &searchLimiter{interval: time.Second}
 
 func NewGoogle() DataSource {
-	g := &Google{
-		quantity: 10,
-		limit:    100,
-	}
-
-	g.BaseDataSource = *NewBaseDataSource(SCRAPE, "Google")
-	return g
-}
-
-func (g *Google) Query(domain, sub string) []string {
-	g.Lock()
-	defer g.Unlock()
-
-	var unique []string
-
-	re := utils.SubdomainRegex(sub)
-	num := g.limit / g.quantity
-	for i := 0; i < num; i++ {
-		u := g.urlByPageNum(sub, i)
-		page, err := utils.GetWebPage(u, nil)
-		if err != nil {
-			g.log(fmt.Sprintf("%s: %v", u, err))
-			break
-		}
-
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
-		time.Sleep(1 * time.Second)
-	}
-	return unique
-}
-
-func (g *Google) urlByPageNum(domain string, page int) string {
-	start := strconv.Itoa(g.quantity * page)
-	u, _ := url.Parse("https://www.google.com/search")
-
-	u.RawQuery = url.Values{
-		"q":      {"site:" + domain},
-		"btnG":   {"Search"},
-		"hl":     {"en"},
-		"biw":    {""},
-		"bih":    {""},
-		"gbv":    {"1"},
-		"start":  {start},
-		"filter": {"0"},
-	}.Encode()
-	return u.String()
-}
-
-func (g *Google) Subdomains() bool {
-	return true
+	return newSearchEngine(SCRAPE, "Google", &searchEngine{
+		host: "www.google.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://www.google.com/search", url.Values{"q": {query},
+				"btnG": {"Search"}, "hl": {"en"}, "biw": {""}, "bih": {""}, "gbv": {"1"},
+				"filter": {"0"}}, "start", page, 10, 0)
+		},
+		dork:       "site:%s",
+		subdomains: true,
+		pages:      10,
+		limiter:    googleLimiter,
+		rule:       extract.Rule{Mode: extract.Loose},
+	})
 }
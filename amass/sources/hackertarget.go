@@ -4,17 +4,37 @@
 package sources
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
+// The environment variable providing the optional HackerTarget membership API key
+const hackerTargetKeyVar = "HACKERTARGET_API_KEY"
+
+// The messages returned with a successful status when the free quota has been used up
+var hackerTargetErrors = []string{
+	"api count exceeded",
+	"error check your search parameter",
+	"error invalid api key",
+}
+
+// Matches the site names returned by the analytics lookup
+var hackerTargetNameRegex = regexp.MustCompile("^" + utils.SUBRE + "[a-z][a-z0-9-]{0,61}[a-z0-9]$")
+
 type HackerTarget struct {
 	BaseDataSource
+	baseURL string
 }
 
 func NewHackerTarget() DataSource {
-	h := new(HackerTarget)
+	h := &HackerTarget{baseURL: "http://api.hackertarget.com/"}
 
 	h.BaseDataSource = *NewBaseDataSource(API, "HackerTarget")
 	return h
@@ -27,24 +47,77 @@ func (h *HackerTarget) Query(domain, sub string) []string {
 		return unique
 	}
 
-	url := h.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := h.getWebPage(h.getURL("hostsearch/", domain), nil)
+	if err == nil {
+		err = hackerTargetError(page)
+	}
 	if err != nil {
-		h.log(fmt.Sprintf("%s: %v", url, err))
+		// The address is not logged, since it can contain the API key
+		h.log(fmt.Sprintf("%shostsearch/: %v", h.baseURL, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
+	return unique
+}
+
+// hackerTargetError - Returns the error reported in the text of the page
+func hackerTargetError(page string) error {
+	first := strings.TrimSpace(page)
+	if i := strings.Index(first, "\n"); i != -1 {
+		first = first[:i]
+	}
+
+	lower := strings.ToLower(first)
+	for _, msg := range hackerTargetErrors {
+		if strings.HasPrefix(lower, msg) {
+			return errors.New(first)
 		}
 	}
-	return unique
+	return nil
 }
 
-func (h *HackerTarget) getURL(domain string) string {
-	format := "http://api.hackertarget.com/hostsearch/?q=%s"
+// AnalyticsDomains - Returns the names of the sites that HackerTarget found embedding the
+// Google Analytics or AdSense ID
+func (h *HackerTarget) AnalyticsDomains(id string) []string {
+	var names []string
+
+	// The lookup only knows the Universal Analytics and AdSense publisher IDs
+	if !strings.HasPrefix(id, "UA-") && !strings.HasPrefix(id, "ca-pub-") {
+		return names
+	}
+
+	page, err := h.getWebPage(h.getURL("analyticslookup/", strings.TrimPrefix(id, "ca-")), nil)
+	if err == nil {
+		err = hackerTargetError(page)
+	}
+	if err != nil {
+		h.log(fmt.Sprintf("%sanalyticslookup/: %v", h.baseURL, err))
+		return names
+	}
+
+	for _, line := range strings.Split(page, "\n") {
+		for _, field := range strings.Split(line, ",") {
+			if name := strings.ToLower(strings.TrimSpace(field)); hackerTargetNameRegex.MatchString(name) {
+				names = utils.UniqueAppend(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func (h *HackerTarget) getURL(path, query string) string {
+	u, _ := url.Parse(h.baseURL + path)
+
+	vals := url.Values{"q": {query}}
+	if key := os.Getenv(hackerTargetKeyVar); key != "" {
+		vals.Set("apikey", key)
+	}
+	u.RawQuery = vals.Encode()
+	return u.String()
+}
 
-	return fmt.Sprintf(format, domain)
+func (h *HackerTarget) List() string {
+	return disclosure(sendsRootDomains, hostname(h.baseURL))
 }
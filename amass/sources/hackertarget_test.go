@@ -0,0 +1,62 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHackerTargetQuota(t *testing.T) {
+	tests := []struct {
+		page  string
+		names int
+	}{
+		{"www.owasp.org,192.0.2.1\nmail.owasp.org,192.0.2.2\n", 2},
+		{"API count exceeded - Increase Quota with Membership", 0},
+		{"error check your search parameter", 0},
+	}
+
+	for _, test := range tests {
+		page := test.page
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, page)
+		}))
+
+		h := NewHackerTarget().(*HackerTarget)
+		h.baseURL = srv.URL + "/"
+		if names := h.Query("owasp.org", "owasp.org"); len(names) != test.names {
+			t.Errorf("The page %q returned the names %v", test.page, names)
+		}
+		srv.Close()
+	}
+
+	if err := hackerTargetError("API count exceeded - Increase Quota with Membership"); err == nil {
+		t.Errorf("The exhausted quota was not reported as an error")
+	}
+}
+
+func TestHackerTargetAnalytics(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		fmt.Fprint(w, "UA-1234567,owasp.org\nUA-1234567,www.Example.com\nUA-1234567,\n")
+	}))
+	defer srv.Close()
+
+	h := NewHackerTarget().(*HackerTarget)
+	h.baseURL = srv.URL + "/"
+	if names := h.AnalyticsDomains("UA-1234567-2"); len(names) != 2 || names[1] != "www.example.com" {
+		t.Errorf("The analytics lookup returned the names %v", names)
+	}
+	if names := h.AnalyticsDomains("GTM-K9X2PQ"); len(names) != 0 || len(queries) != 1 {
+		t.Errorf("The unsupported ID was looked up")
+	}
+	h.AnalyticsDomains("ca-pub-1234567890123456")
+	if len(queries) != 2 || queries[1] != "/analyticslookup/?q=pub-1234567890123456" {
+		t.Errorf("The AdSense ID was looked up with %v", queries)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -31,7 +32,7 @@ func (i *IPv4Info) Query(domain, sub string) []string {
 	}
 
 	url := i.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := i.getWebPage(url, nil)
 	if err != nil {
 		i.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
@@ -39,7 +40,7 @@ func (i *IPv4Info) Query(domain, sub string) []string {
 	time.Sleep(1 * time.Second)
 
 	url = i.ipSubmatch(page, domain)
-	page, err = utils.GetWebPage(url, nil)
+	page, err = i.getWebPage(url, nil)
 	if err != nil {
 		i.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
@@ -47,7 +48,7 @@ func (i *IPv4Info) Query(domain, sub string) []string {
 	time.Sleep(1 * time.Second)
 
 	url = i.domainSubmatch(page, domain)
-	page, err = utils.GetWebPage(url, nil)
+	page, err = i.getWebPage(url, nil)
 	if err != nil {
 		i.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
@@ -55,18 +56,14 @@ func (i *IPv4Info) Query(domain, sub string) []string {
 	time.Sleep(1 * time.Second)
 
 	url = i.subdomainSubmatch(page, domain)
-	page, err = utils.GetWebPage(url, nil)
+	page, err = i.getWebPage(url, nil)
 	if err != nil {
 		i.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -102,3 +99,7 @@ func (i *IPv4Info) subdomainSubmatch(content, domain string) string {
 	}
 	return i.baseURL + subs[0]
 }
+
+func (i *IPv4Info) List() string {
+	return disclosure(sendsRootDomains, hostname(i.baseURL))
+}
@@ -0,0 +1,19 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import "github.com/OWASP/Amass/amass/extract"
+
+// The content types used by web servers for JavaScript files
+var scriptContentTypes = []string{
+	"application/javascript",
+	"application/x-javascript",
+	"text/javascript",
+}
+
+// namesFromScript - Returns the names within the domain found in the JavaScript source,
+// including names built by concatenating string literals
+func namesFromScript(domain, script string) []string {
+	return extract.New(domain, extract.Strict).ScriptNames(script)
+}
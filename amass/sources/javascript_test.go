@@ -0,0 +1,30 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import "testing"
+
+func TestJavaScriptNames(t *testing.T) {
+	script := `var a={api:"https:\/\/API.example.com\/v2"};` +
+		`var b="https://" + "staging" + ".example.com/graphql";` +
+		`fetch('//cdn.other.com/lib.js');` +
+		"const c = `ws.` + `example.com`;"
+
+	expected := map[string]struct{}{
+		"api.example.com":     struct{}{},
+		"staging.example.com": struct{}{},
+		"ws.example.com":      struct{}{},
+	}
+
+	names := namesFromScript("example.com", script)
+	for _, name := range names {
+		if _, found := expected[name]; !found {
+			t.Errorf("namesFromScript returned the unexpected name %s", name)
+		}
+		delete(expected, name)
+	}
+	for name := range expected {
+		t.Errorf("namesFromScript did not return %s", name)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// LocalDir - Searches a directory of outputs from previous recon tools for names within the
+// domain. The text outputs of subfinder and assetfinder, the JSON lines of subfinder, and the
+// XML, JSON and CSV reports of dnsrecon are understood, and gzipped files are decompressed
+// while they are read. The entire directory is read for each root domain
+type LocalDir struct {
+	BaseDataSource
+	path string
+}
+
+func NewLocalDir(path string) DataSource {
+	l := &LocalDir{path: path}
+
+	l.BaseDataSource = *NewBaseDataSource(LOCAL, "Local Outputs")
+	return l
+}
+
+func (l *LocalDir) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	err := filepath.Walk(l.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			l.log(err.Error())
+			return nil
+		}
+		defer file.Close()
+
+		names, err := SearchLocalOutput(file, path, domain)
+		if err != nil {
+			l.log(fmt.Sprintf("%s: %v", path, err))
+		}
+		unique = utils.UniqueAppend(unique, names...)
+		return nil
+	})
+	if err != nil {
+		l.log(err.Error())
+	}
+	return unique
+}
+
+// SearchLocalOutput - Returns the names within the domain found in the tool output, where the
+// format is selected by the extension of the file name
+func SearchLocalOutput(r io.Reader, filename, domain string) ([]string, error) {
+	in, err := fdnsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	switch ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, ".gz"))); ext {
+	case ".xml":
+		values, err = xmlValues(in)
+	case ".json", ".jsonl":
+		values, err = jsonValues(in)
+	case ".csv":
+		values, err = csvValues(in)
+	default:
+		values, err = textValues(in)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the output: %v", err)
+	}
+
+	var unique []string
+	ex := extract.New(domain, extract.Strict)
+	for _, v := range values {
+		name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(v), "."))
+
+		if ex.Match(name) {
+			unique = utils.UniqueAppend(unique, name)
+		}
+	}
+	return unique, nil
+}
+
+// xmlValues - Returns the attribute values of the elements, such as the name
+// and target of the records in the dnsrecon reports
+func xmlValues(r io.Reader) ([]string, error) {
+	var values []string
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return values, err
+		}
+
+		if elem, ok := tok.(xml.StartElement); ok {
+			for _, attr := range elem.Attr {
+				values = append(values, attr.Value)
+			}
+		}
+	}
+}
+
+// jsonValues - Returns the strings within the sequence of JSON documents, which covers
+// the JSON lines of subfinder and the array written by dnsrecon
+func jsonValues(r io.Reader) ([]string, error) {
+	var values []string
+
+	dec := json.NewDecoder(r)
+	for {
+		var doc interface{}
+
+		if err := dec.Decode(&doc); err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return values, err
+		}
+		values = append(values, jsonStrings(doc)...)
+	}
+}
+
+func jsonStrings(v interface{}) []string {
+	var values []string
+
+	switch t := v.(type) {
+	case string:
+		values = append(values, t)
+	case []interface{}:
+		for _, e := range t {
+			values = append(values, jsonStrings(e)...)
+		}
+	case map[string]interface{}:
+		for _, e := range t {
+			values = append(values, jsonStrings(e)...)
+		}
+	}
+	return values
+}
+
+func csvValues(r io.Reader) ([]string, error) {
+	var values []string
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return values, err
+		}
+		values = append(values, record...)
+	}
+}
+
+// textValues - Returns the fields of each line, since the tools write one name per
+// line, sometimes followed by the addresses or the source
+func textValues(r io.Reader) ([]string, error) {
+	var values []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		values = append(values, strings.FieldsFunc(scanner.Text(), func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t'
+		})...)
+	}
+	return values, scanner.Err()
+}
+
+func (l *LocalDir) RevealsDomain() bool {
+	return false
+}
+
+func (l *LocalDir) List() string {
+	return "Sends nothing, since the local directory " + l.path + " is searched"
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSearchLocalOutput(t *testing.T) {
+	tests := []struct {
+		filename string
+		output   string
+		expected []string
+	}{
+		{"subfinder.txt", "www.owasp.org\napi.owasp.org\nwww.example.com\n", []string{"www.owasp.org", "api.owasp.org"}},
+		{"assetfinder.out", "mail.owasp.org\nowasp.org.evil.com\n", []string{"mail.owasp.org"}},
+		{"subfinder.json", `{"host":"dev.owasp.org","source":"crtsh"}` + "\n" + `{"host":"vpn.owasp.org","source":"alienvault"}`,
+			[]string{"dev.owasp.org", "vpn.owasp.org"}},
+		{"dnsrecon.xml", `<?xml version="1.0"?><records><record address="192.0.2.1" name="ns1.owasp.org" type="A"/>` +
+			`<record name="owasp.org" target="MX1.owasp.org." type="MX"/></records>`, []string{"ns1.owasp.org", "mx1.owasp.org"}},
+		{"dnsrecon.json", `[{"type":"A","name":"ftp.owasp.org","address":"192.0.2.2"}]`, []string{"ftp.owasp.org"}},
+		{"dnsrecon.csv", "Type,Name,Address,Target\nCNAME,docs.owasp.org,,www.owasp.org\n", []string{"docs.owasp.org", "www.owasp.org"}},
+	}
+
+	for _, test := range tests {
+		names, err := SearchLocalOutput(strings.NewReader(test.output), test.filename, "owasp.org")
+		if err != nil {
+			t.Errorf("%s returned an error: %v", test.filename, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("%s returned %v, expected %v", test.filename, names, test.expected)
+		}
+	}
+
+	if _, err := SearchLocalOutput(strings.NewReader("<records><record"), "bad.xml", "owasp.org"); err == nil {
+		t.Errorf("The malformed XML output was accepted")
+	}
+}
+
+func TestLocalDirQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-local")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"host":"dev.owasp.org"}`))
+	w.Close()
+
+	os.MkdirAll(filepath.Join(dir, "old"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "subs.txt"), []byte("www.owasp.org\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "old", "subfinder.json.gz"), gz.Bytes(), 0644)
+
+	l := NewLocalDir(dir)
+	names := l.Query("owasp.org", "owasp.org")
+	sort.Strings(names)
+	if expected := []string{"dev.owasp.org", "www.owasp.org"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("The directory returned %v, expected %v", names, expected)
+	}
+	if l.Type() != LOCAL || l.RevealsDomain() {
+		t.Errorf("The directory was not a local source")
+	}
+}
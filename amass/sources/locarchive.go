@@ -32,3 +32,7 @@ func (la *LoCArchive) Query(domain, sub string) []string {
 func (la *LoCArchive) Subdomains() bool {
 	return true
 }
+
+func (la *LoCArchive) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(la.baseURL))
+}
@@ -0,0 +1,109 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the Netlas API key
+const netlasKeyVar = "NETLAS_API_KEY"
+
+// Netlas - The domain search of the Netlas Internet scanning data
+type Netlas struct {
+	BaseDataSource
+	baseURL string
+	// The results on each page are fixed by the API
+	perPage int
+	pages   int
+}
+
+type netlasResponse struct {
+	Items []struct {
+		Data struct {
+			Domain string `json:"domain"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+func NewNetlas() DataSource {
+	n := &Netlas{
+		baseURL: "https://app.netlas.io/api/",
+		perPage: 20,
+		pages:   25,
+	}
+
+	n.BaseDataSource = *NewBaseDataSource(API, "Netlas")
+	return n
+}
+
+func (n *Netlas) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for num := 0; num < n.pages; num++ {
+		u := n.getURL(domain, num*n.perPage)
+		page, err := n.getWebPage(u, n.headers())
+		if err != nil {
+			n.log(fmt.Sprintf("%s: %v", u, err))
+			break
+		}
+
+		var resp netlasResponse
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			n.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			break
+		}
+		for _, item := range resp.Items {
+			unique = utils.UniqueAppend(unique, ex.Names(item.Data.Domain)...)
+		}
+		if len(resp.Items) < n.perPage {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	return unique
+}
+
+func (n *Netlas) headers() map[string]string {
+	return map[string]string{"X-API-Key": os.Getenv(netlasKeyVar)}
+}
+
+func (n *Netlas) getURL(domain string, start int) string {
+	u, _ := url.Parse(n.baseURL + "domains/")
+
+	u.RawQuery = url.Values{
+		"q":      {"domain:*." + domain},
+		"start":  {strconv.Itoa(start)},
+		"fields": {"domain"},
+	}.Encode()
+	return u.String()
+}
+
+func (n *Netlas) RequiredKeys() []string {
+	return []string{netlasKeyVar}
+}
+
+func (n *Netlas) VerifyKeys() error {
+	if _, err := n.getWebPage(n.baseURL+"users/current/", n.headers()); err != nil {
+		return fmt.Errorf("The %s key was not accepted: %v", netlasKeyVar, err)
+	}
+	return nil
+}
+
+func (n *Netlas) List() string {
+	return disclosure(sendsRootDomains, hostname(n.baseURL))
+}
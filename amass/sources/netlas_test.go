@@ -0,0 +1,33 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNetlasQuery(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q")+" "+r.URL.Query().Get("start"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"items": [{"data": {"domain": "www.owasp.org"}}, {"data": {"domain": "vpn.owasp.org"}}]}`)
+	}))
+	defer srv.Close()
+
+	n := NewNetlas().(*Netlas)
+	n.baseURL = srv.URL + "/"
+
+	if names := n.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org vpn.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+	// The short page is the last one
+	if len(queries) != 1 || queries[0] != "domain:*.owasp.org 0" {
+		t.Errorf("The domains were searched with %v", queries)
+	}
+}
@@ -32,3 +32,7 @@ func (o *OpenUKArchive) Query(domain, sub string) []string {
 func (o *OpenUKArchive) Subdomains() bool {
 	return true
 }
+
+func (o *OpenUKArchive) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(o.baseURL))
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The paste sites searched through DuckDuckGo, since most of them do not provide a search API
+var pasteSites = []string{
+	"pastebin.com",
+	"gist.github.com",
+	"paste.ee",
+	"ghostbin.com",
+	"justpaste.it",
+	"controlc.com",
+	"hastebin.com",
+	"paste.ubuntu.com",
+}
+
+// Pastes - The hostnames found in paste sites, since pastes of credentials and configuration
+// files often contain internal names
+type Pastes struct {
+	BaseDataSource
+	// The address of the psbdmp API, which indexes the Pastebin pastes
+	baseURL string
+	// The most pastes downloaded for each domain
+	limit  int
+	engine *searchEngine
+}
+
+type psbdmpResult struct {
+	ID string `json:"id"`
+}
+
+type psbdmpDump struct {
+	Content string `json:"content"`
+}
+
+func NewPastes() DataSource {
+	var sites []string
+	for _, site := range pasteSites {
+		sites = append(sites, "site:"+site)
+	}
+
+	p := &Pastes{
+		baseURL: "https://psbdmp.ws/api/v3/",
+		limit:   20,
+		engine: &searchEngine{
+			host:    "html.duckduckgo.com",
+			pageURL: duckDuckGoURL,
+			dork:    `"%s" (` + strings.Join(sites, " OR ") + ")",
+			pages:   3,
+			delay:   2 * time.Second,
+			// The names are found in the snippets of the pastes
+			rule: extract.Rule{Mode: extract.Loose},
+		},
+	}
+
+	p.BaseDataSource = *NewBaseDataSource(SCRAPE, "Pastes")
+	return p
+}
+
+func (p *Pastes) Query(domain, sub string) []string {
+	if domain != sub {
+		return []string{}
+	}
+
+	unique := p.dumps(domain)
+	return utils.UniqueAppend(unique, p.search(p.engine, domain)...)
+}
+
+// dumps - Returns the names found in the Pastebin pastes that mention the domain
+func (p *Pastes) dumps(domain string) []string {
+	var unique []string
+
+	u := p.baseURL + "search/" + url.PathEscape(domain)
+	page, err := p.getWebPage(u, nil)
+	if err != nil {
+		p.log(fmt.Sprintf("%s: %v", u, err))
+		return unique
+	}
+
+	var results []psbdmpResult
+	if err := json.Unmarshal([]byte(page), &results); err != nil {
+		p.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for i, r := range results {
+		if i >= p.limit {
+			break
+		}
+
+		u := p.baseURL + "dump/" + url.PathEscape(r.ID)
+		page, err := p.getWebPage(u, nil)
+		if err != nil {
+			p.log(fmt.Sprintf("%s: %v", u, err))
+			continue
+		}
+
+		var dump psbdmpDump
+		if err := json.Unmarshal([]byte(page), &dump); err != nil {
+			p.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			continue
+		}
+		unique = utils.UniqueAppend(unique, ex.Names(dump.Content)...)
+		time.Sleep(500 * time.Millisecond)
+	}
+	return unique
+}
+
+func (p *Pastes) List() string {
+	return disclosure(sendsRootDomains, hostname(p.baseURL), p.engine.host)
+}
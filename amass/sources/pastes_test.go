@@ -0,0 +1,51 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPastesQuery(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/owasp.org":
+			fmt.Fprint(w, `[{"id":"a1"},{"id":"b2"},{"id":"c3"}]`)
+		case "/dump/a1":
+			fmt.Fprint(w, `{"content":"DB_HOST=db.internal.owasp.org\nDB_USER=admin"}`)
+		case "/dump/b2":
+			fmt.Fprint(w, `{"content":"https://jenkins.owasp.org/login"}`)
+		case "/dump/c3":
+			fmt.Fprint(w, `{"content":"https://never.owasp.org/"}`)
+		case "/html/":
+			query = r.URL.Query().Get("q")
+			fmt.Fprint(w, `<a class="result__snippet">ssh deploy@vpn.owasp.org</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewPastes().(*Pastes)
+	p.baseURL = srv.URL + "/"
+	p.limit = 2
+	p.engine.delay = 0
+	p.engine.pageURL = func(query string, num int) string {
+		return searchURL(srv.URL+"/html/", url.Values{"q": {query}}, "s", num, 30, 0)
+	}
+
+	names := p.Query("owasp.org", "owasp.org")
+	if expected := []string{"db.internal.owasp.org", "jenkins.owasp.org", "vpn.owasp.org"}; !equalNames(names, expected) {
+		t.Errorf("The pastes returned %v, expected %v", names, expected)
+	}
+	if !strings.HasPrefix(query, `"owasp.org" (site:pastebin.com OR site:gist.github.com`) {
+		t.Errorf("The paste sites were searched with %q", query)
+	}
+}
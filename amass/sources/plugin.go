@@ -0,0 +1,204 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+const (
+	// The longest time a plugin process is allowed to run
+	pluginTimeout = 2 * time.Minute
+)
+
+// The request written to the standard input of a plugin process
+type pluginRequest struct {
+	Method    string `json:"method"`
+	Domain    string `json:"domain,omitempty"`
+	Subdomain string `json:"subdomain,omitempty"`
+}
+
+// The response read from the standard output of a plugin process
+type pluginResponse struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Subdomains bool     `json:"subdomains"`
+	Reveals    *bool    `json:"reveals_domain"`
+	Names      []string `json:"names"`
+	Error      string   `json:"error"`
+}
+
+// Plugin - A data source implemented by an external executable. Every request is
+// performed by running the executable with a JSON object written to its standard
+// input, and a single JSON object is expected on its standard output:
+//
+//	{"method": "describe"} -> {"name": "Example", "type": "api", "subdomains": false,
+//	    "reveals_domain": true}
+//	{"method": "query", "domain": "example.com", "subdomain": "example.com"}
+//	    -> {"names": ["www.example.com"]} or {"error": "message"}
+//
+// Plugins that only search local data describe themselves with "reveals_domain": false,
+// so they are still used while the exposure to third parties is minimized
+type Plugin struct {
+	BaseDataSource
+	path       string
+	subdomains bool
+	reveals    bool
+}
+
+// NewPlugin - Describes the plugin executable found at the path
+func NewPlugin(path string) (DataSource, error) {
+	p := &Plugin{path: path}
+
+	resp, err := p.run(&pluginRequest{Method: "describe"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Name == "" {
+		return nil, fmt.Errorf("Plugin %s did not provide a name", path)
+	}
+
+	stype := resp.Type
+	if stype != ARCHIVE && stype != API && stype != CERT && stype != SCRAPE {
+		stype = API
+	}
+	p.subdomains = resp.Subdomains
+	p.reveals = resp.Reveals == nil || *resp.Reveals
+	p.BaseDataSource = *NewBaseDataSource(stype, resp.Name)
+	return p, nil
+}
+
+// LoadPlugins - Returns the plugins for all the executables in the directory
+func LoadPlugins(dir string) ([]DataSource, error) {
+	var plugins []DataSource
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the plugin directory %s: %v", dir, err)
+	}
+
+	var errs []string
+	for _, f := range files {
+		// Only regular files with an execute permission bit are plugins
+		if !f.Mode().IsRegular() || f.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		p, err := NewPlugin(filepath.Join(dir, f.Name()))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+
+	if len(errs) > 0 {
+		return plugins, fmt.Errorf("Failed to load plugins: %v", errs)
+	}
+	return plugins, nil
+}
+
+func (p *Plugin) Query(domain, sub string) []string {
+	var unique []string
+
+	resp, err := p.run(&pluginRequest{
+		Method:    "query",
+		Domain:    domain,
+		Subdomain: sub,
+	})
+	if err != nil {
+		p.log(err.Error())
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for _, name := range resp.Names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		// Do not trust the plugin to stay within scope
+		if !ex.Match(name) {
+			continue
+		}
+		if u := utils.NewUniqueElements(unique, name); len(u) > 0 {
+			unique = append(unique, u...)
+		}
+	}
+	return unique
+}
+
+func (p *Plugin) Subdomains() bool {
+	return p.subdomains
+}
+
+func (p *Plugin) run(req *pluginRequest) (*pluginResponse, error) {
+	var env []string
+	// The plugin is asked to send its requests through the proxy of the data source
+	if proxy := p.Proxy(); proxy != nil {
+		for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY"} {
+			env = append(env, name+"="+proxy.String())
+		}
+	}
+
+	out, err := execPlugin(p.path, req, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("Plugin %s returned a malformed response: %v", p.path, err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// execPlugin - Runs the plugin with the variables added to its environment
+func execPlugin(path string, req interface{}, env []string) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("Plugin %s failed: %v: %s", path, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+func (p *Plugin) RevealsDomain() bool {
+	return p.reveals
+}
+
+func (p *Plugin) List() string {
+	data := sendsRootDomains
+	if p.subdomains {
+		data = sendsSubdomains
+	}
+	return disclosure(data, "the plugin "+p.path+", which can contact any third party")
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// AvailabilityChecker - Implemented by the data sources run as free community services,
+// which often go offline, so they are checked before the first query
+type AvailabilityChecker interface {
+	// Returns an error when the service cannot be used
+	Available() error
+}
+
+// PublicDB - A free subdomain database returning a JSON array of the names for a domain
+type PublicDB struct {
+	BaseDataSource
+	baseURL   string
+	statusURL string
+}
+
+func NewAnubis() DataSource {
+	p := &PublicDB{
+		baseURL:   "https://jldc.me/anubis/subdomains/",
+		statusURL: "https://jldc.me/anubis/subdomains/example.com",
+	}
+
+	p.BaseDataSource = *NewBaseDataSource(API, "Anubis-DB")
+	return p
+}
+
+func NewOmnisint() DataSource {
+	p := &PublicDB{
+		baseURL:   "https://sonar.omnisint.io/subdomains/",
+		statusURL: "https://sonar.omnisint.io/",
+	}
+
+	p.BaseDataSource = *NewBaseDataSource(API, "Omnisint")
+	return p
+}
+
+func (p *PublicDB) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	u := p.baseURL + url.PathEscape(domain)
+	page, err := p.getWebPage(u, nil)
+	if err != nil {
+		p.log(fmt.Sprintf("%s: %v", u, err))
+		return unique
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(page), &names); err != nil {
+		p.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for _, name := range names {
+		unique = utils.UniqueAppend(unique, ex.Names(name)...)
+	}
+	return unique
+}
+
+func (p *PublicDB) Available() error {
+	if _, err := p.getWebPage(p.statusURL, nil); err != nil {
+		return fmt.Errorf("%s is not available: %v", hostname(p.statusURL), err)
+	}
+	return nil
+}
+
+func (p *PublicDB) List() string {
+	return disclosure(sendsRootDomains, hostname(p.baseURL))
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublicDBQuery(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `["www.owasp.org", "*.vpn.owasp.org", "www.example.com"]`)
+	}))
+	defer srv.Close()
+
+	p := NewOmnisint().(*PublicDB)
+	p.baseURL = srv.URL + "/subdomains/"
+	p.statusURL = srv.URL + "/"
+
+	if err := p.Available(); err != nil {
+		t.Errorf("The database was not available: %v", err)
+	}
+	if names := p.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org vpn.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+	if path != "/subdomains/owasp.org" {
+		t.Errorf("The subdomains were requested from %s", path)
+	}
+
+	srv.Close()
+	if err := p.Available(); err == nil {
+		t.Errorf("The database was available after it went offline")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -39,7 +40,7 @@ func (r *Robtex) Query(domain, sub string) []string {
 	}
 
 	url := "https://freeapi.robtex.com/pdns/forward/" + domain
-	page, err := utils.GetWebPage(url, nil)
+	page, err := r.getWebPage(url, nil)
 	if err != nil {
 		r.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
@@ -56,7 +57,7 @@ func (r *Robtex) Query(domain, sub string) []string {
 		time.Sleep(500 * time.Millisecond)
 
 		url = "https://freeapi.robtex.com/pdns/reverse/" + ip
-		pdns, err := utils.GetWebPage(url, nil)
+		pdns, err := r.getWebPage(url, nil)
 		if err != nil {
 			r.log(fmt.Sprintf("%s: %v", url, err))
 			continue
@@ -67,12 +68,8 @@ func (r *Robtex) Query(domain, sub string) []string {
 		}
 	}
 
-	re := utils.SubdomainRegex(domain)
-	for _, sd := range re.FindAllString(list, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	ex := extract.New(domain, extract.Strict)
+	unique = utils.UniqueAppend(unique, ex.Names(list)...)
 	return unique
 }
 
@@ -98,3 +95,7 @@ func (r *Robtex) parseJSON(page string) []robtexJSON {
 	}
 	return lines
 }
+
+func (r *Robtex) List() string {
+	return disclosure(sendsRootDomains+" and the addresses found for them", "freeapi.robtex.com")
+}
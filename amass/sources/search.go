@@ -0,0 +1,180 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// searchEngine - Declares how a search engine is queried and where the names are found
+// within its result pages
+type searchEngine struct {
+	// The host receiving the searches
+	host string
+
+	// Returns the address of the zero based page of results for the query
+	pageURL func(query string, page int) string
+
+	// The format of the query sent for the root domain, such as site:%s
+	dork string
+
+	// Are the subdomains searched along with the root domains?
+	subdomains bool
+
+	// The most pages requested, and the time waited between them. The long-tail
+	// scrapers wait for the limiter they share instead
+	pages   int
+	delay   time.Duration
+	limiter *searchLimiter
+
+	// The environment variable and the header providing the API key, when one is required
+	keyVar    string
+	keyHeader string
+
+	// Selects the parts of the result pages where the names are found
+	rule extract.Rule
+}
+
+// The interval between the requests sent by all the long-tail scrapers together, which
+// include those of the web search category along with Ask and Exalead
+const webSearchInterval = 2 * time.Second
+
+var webSearchLimiter = &searchLimiter{interval: webSearchInterval}
+
+// searchLimiter - Spaces out the requests sent by a group of search engines
+type searchLimiter struct {
+	sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait - Blocks until the caller can send its request
+func (l *searchLimiter) wait() {
+	l.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.Unlock()
+
+	time.Sleep(delay)
+}
+
+// SearchEngine - A data source sending site: searches to a search engine
+type SearchEngine struct {
+	BaseDataSource
+	engine *searchEngine
+}
+
+func newSearchEngine(stype, name string, engine *searchEngine) *SearchEngine {
+	s := &SearchEngine{engine: engine}
+
+	s.BaseDataSource = *NewBaseDataSource(stype, name)
+	return s
+}
+
+func (s *SearchEngine) Query(domain, sub string) []string {
+	if domain != sub && !s.engine.subdomains {
+		return []string{}
+	}
+	return s.search(s.engine, sub)
+}
+
+func (s *SearchEngine) Subdomains() bool {
+	return s.engine.subdomains
+}
+
+// search - Sends the query of the search engine for the domain, and pages through the results
+// until a page does not provide any new names
+func (bds *BaseDataSource) search(engine *searchEngine, domain string) []string {
+	var unique []string
+
+	query := fmt.Sprintf(engine.dork, domain)
+	for num := 0; num < engine.pages; num++ {
+		if engine.limiter != nil {
+			engine.limiter.wait()
+		} else if num > 0 {
+			time.Sleep(engine.delay)
+		}
+
+		page, err := bds.getWebPage(engine.pageURL(query, num), engine.headers())
+		if err != nil {
+			// The address is not logged, since it can contain the API key
+			bds.log(fmt.Sprintf("%s page %d: %v", engine.host, num+1, err))
+			break
+		}
+
+		names := utils.NewUniqueElements(unique, engine.rule.Names(domain, page)...)
+		// Search engines repeat the last page of results when asked for more
+		if len(names) == 0 {
+			break
+		}
+		unique = append(unique, names...)
+	}
+	return unique
+}
+
+func (e *searchEngine) headers() map[string]string {
+	if e.keyHeader == "" {
+		return nil
+	}
+	return map[string]string{e.keyHeader: os.Getenv(e.keyVar)}
+}
+
+func (s *SearchEngine) RequiredKeys() []string {
+	if s.engine.keyVar == "" {
+		return nil
+	}
+	return []string{s.engine.keyVar}
+}
+
+func (s *SearchEngine) VerifyKeys() error {
+	if s.engine.keyVar == "" {
+		return nil
+	}
+
+	u := s.engine.pageURL(fmt.Sprintf(s.engine.dork, "example.com"), 0)
+	if _, err := s.getWebPage(u, s.engine.headers()); err != nil {
+		return fmt.Errorf("The %s key was not accepted: %v", s.engine.keyVar, err)
+	}
+	return nil
+}
+
+func (s *SearchEngine) SetRule(rule extract.Rule) {
+	s.engine.rule = rule
+}
+
+func (s *SearchEngine) List() string {
+	sends := sendsRootDomains
+	if s.engine.subdomains {
+		sends = sendsSubdomains
+	}
+	return disclosure(sends+" in site: searches", s.engine.host)
+}
+
+// searchURL - Returns the address with the query parameters, where the page parameter
+// holds the offset of the first result, or the page number when perPage is zero
+func searchURL(base string, params url.Values, pageParam string, page, perPage, first int) string {
+	u, _ := url.Parse(base)
+
+	if pageParam != "" {
+		offset := page
+		if perPage > 0 {
+			offset = page * perPage
+		}
+		params.Set(pageParam, strconv.Itoa(offset+first))
+	}
+	u.RawQuery = params.Encode()
+	return u.String()
+}
@@ -0,0 +1,130 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+func TestSearchEnginePagination(t *testing.T) {
+	results := []string{
+		`<a class="result" href="https://www.owasp.org/">www.owasp.org</a> ad.owasp.org`,
+		`<a class="result" href="https://wiki.owasp.org/">wiki.owasp.org</a>`,
+		// The engine repeats the last page when the results run out
+		`<a class="result" href="https://wiki.owasp.org/">wiki.owasp.org</a>`,
+		`<a class="result" href="https://never.owasp.org/">never.owasp.org</a>`,
+	}
+
+	var requests int
+	var query, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		key = r.Header.Get("X-Key")
+
+		var num int
+		fmt.Sscanf(r.URL.Query().Get("first"), "%d", &num)
+		requests++
+		fmt.Fprint(w, results[(num-1)/10])
+	}))
+	defer srv.Close()
+
+	s := newSearchEngine(SCRAPE, "Test", &searchEngine{
+		host: hostname(srv.URL),
+		pageURL: func(query string, page int) string {
+			return searchURL(srv.URL+"/search", url.Values{"q": {query}}, "first", page, 10, 1)
+		},
+		dork:      "site:%s",
+		pages:     len(results),
+		keyVar:    "AMASS_TEST_SEARCH_KEY",
+		keyHeader: "X-Key",
+		rule: extract.Rule{
+			Scope: regexp.MustCompile(`class="result" href="([^"]+)"`),
+		},
+	})
+
+	names := s.Query("owasp.org", "owasp.org")
+	if expected := []string{"www.owasp.org", "wiki.owasp.org"}; !equalNames(names, expected) {
+		t.Errorf("The search returned %v, expected %v", names, expected)
+	}
+	if requests != 3 {
+		t.Errorf("The search requested %d pages, expected 3", requests)
+	}
+	if query != "site:owasp.org" {
+		t.Errorf("The search sent the query %q", query)
+	}
+	if keys := s.RequiredKeys(); len(keys) != 1 || keys[0] != "AMASS_TEST_SEARCH_KEY" {
+		t.Errorf("The search returned the required keys %v", keys)
+	}
+	if key != "" {
+		t.Errorf("The search sent the unset key %q", key)
+	}
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchLimiter(t *testing.T) {
+	l := &searchLimiter{interval: 20 * time.Millisecond}
+
+	start := time.Now()
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			l.wait()
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+
+	if e := time.Since(start); e < 60*time.Millisecond {
+		t.Errorf("Four requests were allowed within %v", e)
+	}
+}
+
+func TestSearchEngineURLs(t *testing.T) {
+	tests := []struct {
+		source   DataSource
+		page     int
+		expected string
+	}{
+		{NewGoogle(), 2, "https://www.google.com/search?bih=&biw=&btnG=Search&filter=0&gbv=1&hl=en&q=site%3Aowasp.org&start=20"},
+		{NewBing(), 1, "http://www.bing.com/search?FORM=PORE&count=20&first=21&q=domain%3Aowasp.org"},
+		{NewYahoo(), 0, "https://search.yahoo.com/search?b=1&bct=0&p=site%3Aowasp.org&pz=10&xargs=0"},
+		{NewDogpile(), 3, "http://www.dogpile.com/search/web?q=owasp.org&qsi=45"},
+		{NewBingAPI(), 1, "https://api.bing.microsoft.com/v7.0/search?count=50&offset=50&q=site%3Aowasp.org&responseFilter=Webpages"},
+	}
+
+	for _, test := range tests {
+		engine := test.source.(*SearchEngine).engine
+		if u := engine.pageURL(fmt.Sprintf(engine.dork, "owasp.org"), test.page); u != test.expected {
+			t.Errorf("%s requested %s, expected %s", test.source.String(), u, test.expected)
+		}
+	}
+
+	if !NewGoogle().Subdomains() || NewBing().Subdomains() {
+		t.Errorf("Only Google searches the subdomains")
+	}
+	if keys := NewBingAPI().RequiredKeys(); len(keys) != 1 || keys[0] != "BING_API_KEY" {
+		t.Errorf("The Bing API returned the required keys %v", keys)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the SecurityTrails API key
+const securityTrailsKeyVar = "SECURITYTRAILS_API_KEY"
+
+// The record types requested from the SecurityTrails DNS history
+var securityTrailsHistoryTypes = []string{"a", "aaaa", "ns", "mx"}
+
+// SecurityTrails - The subdomains and DNS history of the SecurityTrails API
+type SecurityTrails struct {
+	BaseDataSource
+	baseURL string
+}
+
+type securityTrailsHistory struct {
+	Records []struct {
+		Values []struct {
+			IP         string `json:"ip"`
+			IPv6       string `json:"ipv6"`
+			Nameserver string `json:"nameserver"`
+			Host       string `json:"host"`
+		} `json:"values"`
+		FirstSeen string `json:"first_seen"`
+		LastSeen  string `json:"last_seen"`
+	} `json:"records"`
+}
+
+func NewSecurityTrails() DataSource {
+	s := &SecurityTrails{baseURL: "https://api.securitytrails.com/v1/"}
+
+	s.BaseDataSource = *NewBaseDataSource(API, "SecurityTrails")
+	return s
+}
+
+func (s *SecurityTrails) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	u := s.baseURL + "domain/" + url.PathEscape(domain) + "/subdomains"
+	page, err := s.getWebPage(u, s.headers())
+	if err != nil {
+		s.log(fmt.Sprintf("%s: %v", u, err))
+		return unique
+	}
+
+	// The subdomains are returned as the labels in front of the root domain
+	var resp struct {
+		Subdomains []string `json:"subdomains"`
+	}
+	if err := json.Unmarshal([]byte(page), &resp); err != nil {
+		s.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for _, label := range resp.Subdomains {
+		unique = utils.UniqueAppend(unique, ex.Names(label+"."+domain)...)
+	}
+	return unique
+}
+
+func (s *SecurityTrails) History(domain string) []*core.HistoryRequest {
+	var history []*core.HistoryRequest
+
+	for _, rrtype := range securityTrailsHistoryTypes {
+		u := s.baseURL + "history/" + url.PathEscape(domain) + "/dns/" + rrtype
+		page, err := s.getWebPage(u, s.headers())
+		if err != nil {
+			s.log(fmt.Sprintf("%s: %v", u, err))
+			continue
+		}
+
+		var resp securityTrailsHistory
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			s.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			continue
+		}
+
+		for _, rec := range resp.Records {
+			first, err1 := time.Parse("2006-01-02", rec.FirstSeen)
+			last, err2 := time.Parse("2006-01-02", rec.LastSeen)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+
+			for _, v := range rec.Values {
+				value := v.IP + v.IPv6 + v.Nameserver + v.Host
+				if value == "" {
+					continue
+				}
+
+				history = append(history, &core.HistoryRequest{
+					Name:      domain,
+					Domain:    domain,
+					Type:      rrtype,
+					Value:     value,
+					FirstSeen: first,
+					LastSeen:  last,
+					Tag:       s.Type(),
+					Source:    s.String(),
+				})
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	return history
+}
+
+func (s *SecurityTrails) headers() map[string]string {
+	return map[string]string{"APIKEY": os.Getenv(securityTrailsKeyVar)}
+}
+
+func (s *SecurityTrails) RequiredKeys() []string {
+	return []string{securityTrailsKeyVar}
+}
+
+func (s *SecurityTrails) VerifyKeys() error {
+	if _, err := s.getWebPage(s.baseURL+"ping", s.headers()); err != nil {
+		return fmt.Errorf("The %s key was not accepted: %v", securityTrailsKeyVar, err)
+	}
+	return nil
+}
+
+func (s *SecurityTrails) List() string {
+	return disclosure(sendsRootDomains, hostname(s.baseURL))
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecurityTrailsHistory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/dns/a"):
+			fmt.Fprint(w, `{"records": [{"values": [{"ip": "198.51.100.7"}], "first_seen": "2015-01-10", "last_seen": "2016-06-20"},
+				{"values": [{"ip": "192.0.2.1"}], "first_seen": "not a date", "last_seen": "2016-06-20"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/dns/ns"):
+			fmt.Fprint(w, `{"records": [{"values": [{"nameserver": "ns1.oldhost.net"}], "first_seen": "2014-02-01", "last_seen": "2017-03-04"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/subdomains"):
+			fmt.Fprint(w, `{"subdomains": ["www", "mail"]}`)
+		default:
+			fmt.Fprint(w, `{"records": []}`)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSecurityTrails().(*SecurityTrails)
+	s.baseURL = srv.URL + "/"
+
+	history := s.History("owasp.org")
+	if len(history) != 2 {
+		t.Fatalf("History returned %d records instead of 2", len(history))
+	}
+	if h := history[0]; h.Type != "a" || h.Value != "198.51.100.7" || h.FirstSeen.Year() != 2015 || h.LastSeen.Year() != 2016 {
+		t.Errorf("The first historical record was %+v", h)
+	}
+	if h := history[1]; h.Type != "ns" || h.Value != "ns1.oldhost.net" {
+		t.Errorf("The second historical record was %+v", h)
+	}
+
+	if names := s.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org mail.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+}
@@ -4,24 +4,33 @@
 package sources
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 	"github.com/PuerkitoBio/fetchbot"
 	"github.com/PuerkitoBio/goquery"
 )
 
+// The tags for the data source types are shared with the rest of the enumeration
 const (
-	ARCHIVE = "archive"
-	API     = "api"
-	CERT    = "cert"
-	SCRAPE  = "scrape"
+	ARCHIVE   = core.ARCHIVE
+	API       = core.API
+	CERT      = core.CERT
+	DNS       = core.DNS
+	LOCAL     = core.LOCAL
+	SCRAPE    = core.SCRAPE
+	WEBSEARCH = core.WEBSEARCH
 )
 
 // All data sources are handled through this interface in amass
@@ -32,6 +41,9 @@ type DataSource interface {
 	// Sets the logger to be used by this data source
 	SetLogger(l *log.Logger)
 
+	// Sets the proxy that the web requests of the data source are sent through (nil connects directly)
+	SetProxy(proxy *url.URL)
+
 	// Returns the data source's associated organization
 	String() string
 
@@ -40,6 +52,39 @@ type DataSource interface {
 
 	// Returns one of the types defined above in the constants
 	Type() string
+
+	// Returns the environment variables that must provide API keys for the data source
+	RequiredKeys() []string
+
+	// Performs a lightweight request to check that the API keys are accepted
+	VerifyKeys() error
+
+	// Returns the OPSEC disclosure of the third parties contacted and the data sent to them
+	List() string
+
+	// Returns true if the root domain names are submitted to a third party
+	RevealsDomain() bool
+}
+
+// HistorySource - Implemented by the data sources that provide the values that the DNS
+// records of the root domains held in the past
+type HistorySource interface {
+	// Returns the previous record values along with when each was observed
+	History(domain string) []*core.HistoryRequest
+}
+
+// AnalyticsSource - Implemented by the data sources that index the analytics and advertising
+// IDs embedded in web pages
+type AnalyticsSource interface {
+	// Returns the names of the sites found embedding the ID
+	AnalyticsDomains(id string) []string
+}
+
+// RuleSource - Implemented by the data sources that extract the names from their responses
+// with a rule, which the scraper rules data file can replace
+type RuleSource interface {
+	// Replaces the rule selecting the parts of the responses where the names are found
+	SetRule(rule extract.Rule)
 }
 
 // The common functionalities and default behaviors for all data sources
@@ -48,6 +93,7 @@ type BaseDataSource struct {
 	SourceType   string
 	Organization string
 	logger       *log.Logger
+	proxy        *url.URL
 }
 
 func NewBaseDataSource(stype, org string) *BaseDataSource {
@@ -72,10 +118,44 @@ func (bds *BaseDataSource) Subdomains() bool {
 	return false
 }
 
+// Data sources that do not require API keys use this default
+func (bds *BaseDataSource) RequiredKeys() []string {
+	return nil
+}
+
+// Data sources that require API keys implement this check
+func (bds *BaseDataSource) VerifyKeys() error {
+	return nil
+}
+
+// Data sources that do not describe the third parties they contact use this default
+func (bds *BaseDataSource) List() string {
+	return "N/A"
+}
+
+// Data sources submit the root domain names to a third party unless they say otherwise
+func (bds *BaseDataSource) RevealsDomain() bool {
+	return true
+}
+
 func (bds *BaseDataSource) SetLogger(l *log.Logger) {
 	bds.logger = l
 }
 
+func (bds *BaseDataSource) SetProxy(proxy *url.URL) {
+	bds.proxy = proxy
+}
+
+// Proxy - Returns the proxy that the web requests are sent through, or nil
+func (bds *BaseDataSource) Proxy() *url.URL {
+	return bds.proxy
+}
+
+// All data sources request web pages through this method, so the proxy is used
+func (bds *BaseDataSource) getWebPage(u string, hvals map[string]string) (string, error) {
+	return utils.GetWebPageProxy(u, hvals, bds.proxy)
+}
+
 func (bds *BaseDataSource) String() string {
 	return bds.Organization
 }
@@ -88,6 +168,30 @@ func (bds *BaseDataSource) log(msg string) {
 	bds.logger.Printf("%s: %s", bds.Organization, msg)
 }
 
+//-------------------------------------------------------------------------------------------------
+// OPSEC disclosure implementation
+//-------------------------------------------------------------------------------------------------
+
+// The descriptions of the data sent by the data sources
+const (
+	sendsRootDomains  = "the root domain names"
+	sendsSubdomains   = "the root domain names and the subdomain names discovered"
+	sendsArchiveCrawl = sendsSubdomains + " in the requests for archived pages"
+)
+
+// disclosure - Describes the data that is sent to the hosts
+func disclosure(data string, hosts ...string) string {
+	return "Sends " + data + " to " + strings.Join(hosts, ", ")
+}
+
+// hostname - Returns the host in the URL of a data source
+func hostname(u string) string {
+	if parsed, err := url.Parse(u); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return u
+}
+
 //-------------------------------------------------------------------------------------------------
 // Web archive crawler implementation
 //-------------------------------------------------------------------------------------------------
@@ -121,10 +225,26 @@ func (bds *BaseDataSource) crawl(base, domain, sub string) ([]string, error) {
 			bds.linksAndNames(domain, ctx, res, links, names)
 		}))
 
+	for _, ct := range scriptContentTypes {
+		mux.Response().Method("GET").ContentType(ct).Handler(fetchbot.HandlerFunc(
+			func(ctx *fetchbot.Context, res *http.Response, err error) {
+				filterMutex.Lock()
+				defer filterMutex.Unlock()
+
+				u := res.Request.URL.String()
+				if _, found := filter[u]; found {
+					return
+				}
+				filter[u] = struct{}{}
+
+				bds.scriptNames(domain, ctx, res, names)
+			}))
+	}
+
 	f := fetchbot.New(fetchbot.HandlerFunc(func(ctx *fetchbot.Context, res *http.Response, err error) {
 		mux.Handle(ctx, res, err)
 	}))
-	setFetcherConfig(f)
+	setFetcherConfig(f, bds.proxy)
 
 	q := f.Start()
 	u := fmt.Sprintf("%s/%s/%s", base, year, sub)
@@ -163,13 +283,21 @@ loop:
 
 func (bds *BaseDataSource) linksAndNames(domain string, ctx *fetchbot.Context, res *http.Response, links, names chan string) {
 	// Process the body to find the links
-	doc, err := goquery.NewDocumentFromResponse(res)
+	defer res.Body.Close()
+
+	body, err := utils.ReadResponseBody(res)
+	if err != nil {
+		bds.log(fmt.Sprintf("Crawler error: %s %s - %s\n", ctx.Cmd.Method(), ctx.Cmd.URL(), err))
+		return
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		bds.log(fmt.Sprintf("Crawler error: %s %s - %s\n", ctx.Cmd.Method(), ctx.Cmd.URL(), err))
 		return
 	}
 
-	re := utils.SubdomainRegex(domain)
+	ex := extract.New(domain, extract.Strict)
 	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
 		val, _ := s.Attr("href")
 		// Resolve address
@@ -179,23 +307,53 @@ func (bds *BaseDataSource) linksAndNames(domain string, ctx *fetchbot.Context, r
 			return
 		}
 
-		if sub := re.FindString(u.String()); sub != "" {
-			names <- sub
+		if found := ex.Names(u.String()); len(found) > 0 {
+			names <- found[0]
+			links <- u.String()
+		}
+	})
+	// Bundles for single-page applications reference many names not found in the HTML
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+		val, _ := s.Attr("src")
+
+		u, err := ctx.Cmd.URL().Parse(val)
+		if err != nil {
+			bds.log(fmt.Sprintf("Crawler failed to parse: %s - %v\n", val, err))
+			return
+		}
+
+		if strings.HasSuffix(strings.ToLower(u.Path), ".js") {
 			links <- u.String()
 		}
 	})
 }
 
-func setFetcherConfig(f *fetchbot.Fetcher) {
+// scriptNames - Sends the names found in the referenced JavaScript file
+func (bds *BaseDataSource) scriptNames(domain string, ctx *fetchbot.Context, res *http.Response, names chan string) {
+	defer res.Body.Close()
+
+	body, err := utils.ReadResponseBody(res)
+	if err != nil {
+		bds.log(fmt.Sprintf("Crawler error: %s %s - %s\n", ctx.Cmd.Method(), ctx.Cmd.URL(), err))
+		return
+	}
+
+	for _, name := range namesFromScript(domain, string(body)) {
+		names <- name
+	}
+}
+
+func setFetcherConfig(f *fetchbot.Fetcher, proxy *url.URL) {
 	f.HttpClient = &http.Client{
 		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
+		Transport: utils.WrapTransport(&http.Transport{
+			Proxy:                 http.ProxyURL(proxy),
 			DialContext:           utils.DialContext,
 			MaxIdleConns:          200,
 			IdleConnTimeout:       5 * time.Second,
 			TLSHandshakeTimeout:   5 * time.Second,
 			ExpectContinueTimeout: 5 * time.Second,
-		},
+		}),
 	}
 	f.CrawlDelay = 1 * time.Second
 	f.DisablePoliteness = true
@@ -206,11 +364,16 @@ func setFetcherConfig(f *fetchbot.Fetcher) {
 
 func GetAllSources() []DataSource {
 	return []DataSource{
+		NewAlienVault(),
+		NewAnubis(),
 		NewArchiveIt(),
 		NewArchiveToday(),
+		NewArchiveTodaySearch(),
 		NewArquivo(),
 		NewAsk(),
 		NewBaidu(),
+		NewBingAPI(),
+		NewC99(),
 		NewCensys(),
 		NewCertDB(),
 		NewCertSpotter(),
@@ -220,25 +383,47 @@ func GetAllSources() []DataSource {
 		NewDNSDumpster(),
 		NewDNSTable(),
 		NewDogpile(),
+		NewDuckDuckGo(),
 		NewEntrust(),
 		NewExalead(),
 		NewFindSubdomains(),
+		NewFullHunt(),
 		NewGoogle(),
 		NewHackerTarget(),
 		NewIPv4Info(),
 		NewLoCArchive(),
 		NewNetcraft(),
+		NewNetlas(),
+		NewOmnisint(),
 		NewOpenUKArchive(),
+		NewPastes(),
 		NewPTRArchive(),
 		NewRiddler(),
 		NewRobtex(),
+		NewSecurityTrails(),
 		NewSiteDossier(),
+		NewSpyse(),
 		NewThreatCrowd(),
 		NewUKGovArchive(),
+		NewURLScan(),
 		NewVirusTotal(),
 		NewWaybackMachine(),
+		NewWhoisXML(),
 		NewYahoo(),
+		NewYandex(),
+	}
+}
+
+// MissingKeys - Returns the required API key environment variables that have not been set
+func MissingKeys(source DataSource) []string {
+	var missing []string
+
+	for _, key := range source.RequiredKeys() {
+		if os.Getenv(key) == "" {
+			missing = append(missing, key)
+		}
 	}
+	return missing
 }
 
 func removeAsteriskLabel(s string) string {
@@ -0,0 +1,109 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the Spyse API token
+const spyseKeyVar = "SPYSE_API_TOKEN"
+
+// Spyse - The subdomain search of the Spyse API, which also serves the Seekr data
+type Spyse struct {
+	BaseDataSource
+	baseURL string
+	limit   int
+	pages   int
+}
+
+type spyseResponse struct {
+	Data struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+		TotalCount int `json:"total_count"`
+	} `json:"data"`
+}
+
+func NewSpyse() DataSource {
+	s := &Spyse{
+		baseURL: "https://api.spyse.com/v3/data/",
+		limit:   100,
+		pages:   10,
+	}
+
+	s.BaseDataSource = *NewBaseDataSource(API, "Spyse")
+	return s
+}
+
+func (s *Spyse) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	for num := 0; num < s.pages; num++ {
+		u := s.getURL(domain, num*s.limit)
+		page, err := s.getWebPage(u, s.headers())
+		if err != nil {
+			s.log(fmt.Sprintf("%s: %v", u, err))
+			break
+		}
+
+		var resp spyseResponse
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			s.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			break
+		}
+		for _, item := range resp.Data.Items {
+			unique = utils.UniqueAppend(unique, ex.Names(item.Name)...)
+		}
+		if len(resp.Data.Items) < s.limit || (num+1)*s.limit >= resp.Data.TotalCount {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return unique
+}
+
+func (s *Spyse) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + os.Getenv(spyseKeyVar)}
+}
+
+func (s *Spyse) getURL(domain string, offset int) string {
+	u, _ := url.Parse(s.baseURL + "domain/subdomain")
+
+	u.RawQuery = url.Values{
+		"domain": {domain},
+		"limit":  {strconv.Itoa(s.limit)},
+		"offset": {strconv.Itoa(offset)},
+	}.Encode()
+	return u.String()
+}
+
+func (s *Spyse) RequiredKeys() []string {
+	return []string{spyseKeyVar}
+}
+
+func (s *Spyse) VerifyKeys() error {
+	if _, err := s.getWebPage(s.baseURL+"account/quota", s.headers()); err != nil {
+		return fmt.Errorf("The %s token was not accepted: %v", spyseKeyVar, err)
+	}
+	return nil
+}
+
+func (s *Spyse) List() string {
+	return disclosure(sendsRootDomains, hostname(s.baseURL))
+}
@@ -0,0 +1,55 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpyseQuery(t *testing.T) {
+	saved, set := os.LookupEnv(spyseKeyVar)
+	os.Setenv(spyseKeyVar, "token")
+	defer func() {
+		if set {
+			os.Setenv(spyseKeyVar, saved)
+		} else {
+			os.Unsetenv(spyseKeyVar)
+		}
+	}()
+
+	var offsets []string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+
+		name := "www.owasp.org"
+		if r.URL.Query().Get("offset") != "0" {
+			name = "vpn.owasp.org"
+		}
+		fmt.Fprintf(w, `{"data": {"items": [{"name": %q}, {"name": "www.example.com"}], "total_count": 4}}`, name)
+	}))
+	defer srv.Close()
+
+	s := NewSpyse().(*Spyse)
+	s.baseURL = srv.URL + "/"
+	s.limit = 2
+
+	if names := s.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org vpn.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+	// The pages stop at the total count
+	if strings.Join(offsets, " ") != "0 2" {
+		t.Errorf("The subdomains were requested at the offsets %v", offsets)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("The token was sent as %q", auth)
+	}
+}
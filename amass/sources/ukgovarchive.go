@@ -32,3 +32,7 @@ func (u *UKGovArchive) Query(domain, sub string) []string {
 func (u *UKGovArchive) Subdomains() bool {
 	return true
 }
+
+func (u *UKGovArchive) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(u.baseURL))
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The environment variable providing the optional urlscan.io API key
+const urlscanKeyVar = "URLSCAN_API_KEY"
+
+// URLScan - The scans of web pages submitted to urlscan.io
+type URLScan struct {
+	BaseDataSource
+	baseURL string
+	// The number of scan results fetched in full without and with an API key,
+	// while ten times as many are searched
+	results    int
+	keyResults int
+}
+
+type urlscanSearch struct {
+	Results []struct {
+		ID   string `json:"_id"`
+		Page struct {
+			Domain string `json:"domain"`
+			URL    string `json:"url"`
+		} `json:"page"`
+		Task struct {
+			Domain string `json:"domain"`
+			URL    string `json:"url"`
+		} `json:"task"`
+	} `json:"results"`
+}
+
+func NewURLScan() DataSource {
+	u := &URLScan{
+		baseURL:    "https://urlscan.io/api/v1/",
+		results:    10,
+		keyResults: 50,
+	}
+
+	u.BaseDataSource = *NewBaseDataSource(API, "URLScan")
+	return u
+}
+
+func (u *URLScan) Query(domain, sub string) []string {
+	var unique []string
+
+	if domain != sub {
+		return unique
+	}
+
+	hvals := u.headers()
+	limit := u.results
+	if hvals != nil {
+		limit = u.keyResults
+	}
+
+	ex := extract.New(domain, extract.Strict)
+	surl := u.searchURL(domain, limit)
+	page, err := u.getWebPage(surl, hvals)
+	if err != nil {
+		u.log(fmt.Sprintf("%s: %v", surl, err))
+		return unique
+	}
+
+	var search urlscanSearch
+	if err := json.Unmarshal([]byte(page), &search); err != nil {
+		u.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+		return unique
+	}
+
+	for i, r := range search.Results {
+		for _, s := range []string{r.Page.Domain, r.Page.URL, r.Task.Domain, r.Task.URL} {
+			unique = utils.UniqueAppend(unique, ex.Names(s)...)
+		}
+		if r.ID == "" || i >= limit {
+			continue
+		}
+
+		// The result of each scan lists the hosts that the page sent requests to
+		rurl := u.baseURL + "result/" + url.PathEscape(r.ID) + "/"
+		result, err := u.getWebPage(rurl, hvals)
+		if err != nil {
+			u.log(fmt.Sprintf("%s: %v", rurl, err))
+			continue
+		}
+		unique = utils.UniqueAppend(unique, ex.Names(result)...)
+		time.Sleep(time.Second)
+	}
+	return unique
+}
+
+// headers - Returns the header providing the API key, or nil when the key was not set
+func (u *URLScan) headers() map[string]string {
+	if key := os.Getenv(urlscanKeyVar); key != "" {
+		return map[string]string{"API-Key": key}
+	}
+	return nil
+}
+
+func (u *URLScan) searchURL(domain string, size int) string {
+	su, _ := url.Parse(u.baseURL + "search/")
+
+	su.RawQuery = url.Values{
+		"q":    {"domain:" + domain},
+		"size": {strconv.Itoa(size * 10)},
+	}.Encode()
+	return su.String()
+}
+
+func (u *URLScan) List() string {
+	return disclosure(sendsRootDomains, hostname(u.baseURL))
+}
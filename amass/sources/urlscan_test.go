@@ -0,0 +1,53 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestURLScanQuery(t *testing.T) {
+	var keys, sizes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("API-Key"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search/"):
+			sizes = append(sizes, r.URL.Query().Get("size"))
+			fmt.Fprint(w, `{"results": [{"_id": "scan-1", "page": {"domain": "www.owasp.org", "url": "https://www.owasp.org/"},
+				"task": {"domain": "owasp.org", "url": "https://owasp.org/login"}}]}`)
+		case r.URL.Path == "/result/scan-1/":
+			fmt.Fprint(w, `{"lists": {"domains": ["cdn.owasp.org", "fonts.example.com"]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	u := NewURLScan().(*URLScan)
+	u.baseURL = srv.URL + "/"
+
+	os.Unsetenv(urlscanKeyVar)
+	if names := u.Query("owasp.org", "owasp.org"); strings.Join(names, " ") != "www.owasp.org cdn.owasp.org" {
+		t.Errorf("Query returned %v", names)
+	}
+
+	os.Setenv(urlscanKeyVar, "secret")
+	defer os.Unsetenv(urlscanKeyVar)
+	u.Query("owasp.org", "owasp.org")
+
+	// The API key raises the number of scans searched
+	if len(sizes) != 2 || sizes[0] != "100" || sizes[1] != "500" {
+		t.Errorf("The searches asked for %v results", sizes)
+	}
+	if len(keys) != 4 || keys[0] != "" || keys[2] != "secret" || keys[3] != "secret" {
+		t.Errorf("The requests provided the API keys %q", keys)
+	}
+}
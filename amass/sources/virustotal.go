@@ -6,6 +6,7 @@ package sources
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/amass/extract"
 	"github.com/OWASP/Amass/amass/utils"
 )
 
@@ -27,19 +28,15 @@ func (v *VirusTotal) Query(domain, sub string) []string {
 		return unique
 	}
 
-	re := utils.SubdomainRegex(domain)
+	ex := extract.New(domain, extract.Strict)
 	url := v.getURL(domain)
-	page, err := utils.GetWebPage(url, nil)
+	page, err := v.getWebPage(url, nil)
 	if err != nil {
 		v.log(fmt.Sprintf("%s: %v", url, err))
 		return unique
 	}
 
-	for _, sd := range re.FindAllString(page, -1) {
-		if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-			unique = append(unique, u...)
-		}
-	}
+	unique = utils.UniqueAppend(unique, ex.Names(page)...)
 	return unique
 }
 
@@ -48,3 +45,7 @@ func (v *VirusTotal) getURL(domain string) string {
 
 	return fmt.Sprintf(format, domain)
 }
+
+func (v *VirusTotal) List() string {
+	return disclosure(sendsRootDomains, "www.virustotal.com")
+}
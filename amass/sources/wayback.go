@@ -32,3 +32,7 @@ func (w *WaybackMachine) Query(domain, sub string) []string {
 func (w *WaybackMachine) Subdomains() bool {
 	return true
 }
+
+func (w *WaybackMachine) List() string {
+	return disclosure(sendsArchiveCrawl, hostname(w.baseURL))
+}
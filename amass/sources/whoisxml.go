@@ -0,0 +1,98 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+// The environment variable providing the WhoisXML API key
+const whoisXMLKeyVar = "WHOISXML_API_KEY"
+
+// The record types requested from the WhoisXML DNS history
+var whoisXMLHistoryTypes = []string{"a", "aaaa", "ns", "mx"}
+
+// WhoisXML - The DNS history of the WhoisXML API, which does not return new names
+type WhoisXML struct {
+	BaseDataSource
+	baseURL string
+}
+
+type whoisXMLHistory struct {
+	Result []struct {
+		Value     string `json:"value"`
+		FirstSeen int64  `json:"firstSeen"`
+		LastSeen  int64  `json:"lastSeen"`
+	} `json:"result"`
+}
+
+func NewWhoisXML() DataSource {
+	w := &WhoisXML{baseURL: "https://dns-history.whoisxmlapi.com/api/v1"}
+
+	w.BaseDataSource = *NewBaseDataSource(API, "WhoisXML")
+	return w
+}
+
+func (w *WhoisXML) History(domain string) []*core.HistoryRequest {
+	var history []*core.HistoryRequest
+
+	for _, rrtype := range whoisXMLHistoryTypes {
+		page, err := w.getWebPage(w.getURL(domain, rrtype), nil)
+		if err != nil {
+			// The address is not logged, since it contains the API key
+			w.log(fmt.Sprintf("%s: %v", w.baseURL, err))
+			continue
+		}
+
+		var resp whoisXMLHistory
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			w.log(fmt.Sprintf("Failed to unmarshal JSON: %v", err))
+			continue
+		}
+
+		for _, rec := range resp.Result {
+			if rec.Value == "" || rec.FirstSeen == 0 {
+				continue
+			}
+
+			history = append(history, &core.HistoryRequest{
+				Name:      domain,
+				Domain:    domain,
+				Type:      rrtype,
+				Value:     rec.Value,
+				FirstSeen: time.Unix(rec.FirstSeen, 0),
+				LastSeen:  time.Unix(rec.LastSeen, 0),
+				Tag:       w.Type(),
+				Source:    w.String(),
+			})
+		}
+	}
+	return history
+}
+
+func (w *WhoisXML) getURL(domain, rrtype string) string {
+	u, _ := url.Parse(w.baseURL)
+
+	u.RawQuery = url.Values{
+		"apiKey":     {os.Getenv(whoisXMLKeyVar)},
+		"searchType": {"forward"},
+		"recordType": {rrtype},
+		"domainName": {domain},
+	}.Encode()
+	return u.String()
+}
+
+func (w *WhoisXML) RequiredKeys() []string {
+	return []string{whoisXMLKeyVar}
+}
+
+func (w *WhoisXML) List() string {
+	return disclosure(sendsRootDomains, hostname(w.baseURL))
+}
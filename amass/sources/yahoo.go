@@ -4,63 +4,22 @@
 package sources
 
 import (
-	"fmt"
 	"net/url"
-	"strconv"
 	"time"
 
-	"github.com/OWASP/Amass/amass/utils"
+	"github.com/OWASP/Amass/amass/extract"
 )
 
-type Yahoo struct {
-	BaseDataSource
-	quantity int
-	limit    int
-}
-
 func NewYahoo() DataSource {
-	y := &Yahoo{
-		quantity: 10,
-		limit:    100,
-	}
-
-	y.BaseDataSource = *NewBaseDataSource(SCRAPE, "Yahoo")
-	return y
-}
-
-func (y *Yahoo) Query(domain, sub string) []string {
-	var unique []string
-
-	if domain != sub {
-		return unique
-	}
-
-	re := utils.SubdomainRegex(domain)
-	num := y.limit / y.quantity
-	for i := 0; i < num; i++ {
-		u := y.urlByPageNum(domain, i)
-		page, err := utils.GetWebPage(u, nil)
-		if err != nil {
-			y.log(fmt.Sprintf("%s: %v", u, err))
-			break
-		}
-
-		for _, sd := range re.FindAllString(page, -1) {
-			if u := utils.NewUniqueElements(unique, sd); len(u) > 0 {
-				unique = append(unique, u...)
-			}
-		}
-		time.Sleep(1 * time.Second)
-	}
-	return unique
-}
-
-func (y *Yahoo) urlByPageNum(domain string, page int) string {
-	b := strconv.Itoa(y.quantity*page + 1)
-	pz := strconv.Itoa(y.quantity)
-
-	u, _ := url.Parse("https://search.yahoo.com/search")
-	u.RawQuery = url.Values{"p": {"site:" + domain},
-		"b": {b}, "pz": {pz}, "bct": {"0"}, "xargs": {"0"}}.Encode()
-	return u.String()
+	return newSearchEngine(SCRAPE, "Yahoo", &searchEngine{
+		host: "search.yahoo.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://search.yahoo.com/search", url.Values{"p": {query},
+				"pz": {"10"}, "bct": {"0"}, "xargs": {"0"}}, "b", page, 10, 1)
+		},
+		dork:  "site:%s",
+		pages: 10,
+		delay: time.Second,
+		rule:  extract.Rule{Mode: extract.Loose},
+	})
 }
@@ -0,0 +1,26 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sources
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/OWASP/Amass/amass/extract"
+)
+
+func NewYandex() DataSource {
+	return newSearchEngine(SCRAPE, "Yandex", &searchEngine{
+		host: "yandex.com",
+		pageURL: func(query string, page int) string {
+			return searchURL("https://yandex.com/search/",
+				url.Values{"text": {query}, "lr": {"84"}}, "p", page, 0, 0)
+		},
+		dork:  "site:%s",
+		pages: 5,
+		// Yandex answers fast paging with a CAPTCHA
+		delay: 3 * time.Second,
+		rule:  extract.Rule{Mode: extract.Loose},
+	})
+}
@@ -4,7 +4,11 @@
 package amass
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,6 +23,56 @@ type entry struct {
 	Sub    string
 }
 
+// SourceInfo - Describes a data source and whether the enumeration will use it
+type SourceInfo struct {
+	Name       string
+	Category   string
+	Subdomains bool
+	Enabled    bool
+	// Does the data source submit the root domain names to a third party?
+	RevealsDomain bool
+	RequiredKeys  []string
+	MissingKeys   []string
+}
+
+// SourceStats - Describes the contribution of a data source to the enumeration
+type SourceStats struct {
+	Name string
+
+	// The number of queries sent to the data source
+	Queries int
+
+	// The number of distinct names returned by the data source
+	Names int
+
+	// The names that no other data source returned
+	Unique int
+
+	// The number of error messages logged by the data source
+	Errors int
+
+	// The total time spent waiting on the data source
+	Duration time.Duration
+
+	// Was the data source disabled while the enumeration was running?
+	Disabled bool
+}
+
+// sourceLogWriter - Counts the error messages logged by a data source
+type sourceLogWriter struct {
+	ss     *SourcesService
+	source string
+	w      io.Writer
+}
+
+func (slw *sourceLogWriter) Write(p []byte) (int, error) {
+	slw.ss.Lock()
+	slw.ss.stats[slw.source].Errors++
+	slw.ss.Unlock()
+
+	return slw.w.Write(p)
+}
+
 type SourcesService struct {
 	core.BaseAmassService
 
@@ -30,43 +84,98 @@ type SourcesService struct {
 	inFilter      map[string]struct{}
 	outFilter     map[string]struct{}
 	domainFilter  map[string]struct{}
+
+	// The analytics IDs that the data sources have already been searched for
+	analyticsFilter map[string]struct{}
+
+	// The contribution of each data source, and the sources that returned each name
+	stats       map[string]*SourceStats
+	nameSources map[string]map[string]struct{}
+
+	// The data sources that will not be queried for the remainder of the enumeration
+	disabled map[string]struct{}
+
+	// The community run data sources that have already been checked for availability
+	checked map[string]*availabilityCheck
 }
 
 func NewSourcesService(config *core.AmassConfig, bus evbus.Bus) *SourcesService {
 	ss := &SourcesService{
-		bus:          bus,
-		responses:    make(chan *core.AmassRequest, 50),
-		inFilter:     make(map[string]struct{}),
-		outFilter:    make(map[string]struct{}),
-		domainFilter: make(map[string]struct{}),
-	}
-
-	for _, source := range sources.GetAllSources() {
-		if source.Type() == core.ARCHIVE {
-			//if false {
-			ss.throttles = append(ss.throttles, source)
-			//}
-		} else {
-			ss.directs = append(ss.directs, source)
-		}
-		source.SetLogger(config.Log)
+		bus:             bus,
+		responses:       make(chan *core.AmassRequest, 50),
+		inFilter:        make(map[string]struct{}),
+		outFilter:       make(map[string]struct{}),
+		domainFilter:    make(map[string]struct{}),
+		analyticsFilter: make(map[string]struct{}),
+		stats:           make(map[string]*SourceStats),
+		nameSources:     make(map[string]map[string]struct{}),
+		disabled:        make(map[string]struct{}),
+		checked:         make(map[string]*availabilityCheck),
 	}
 
 	ss.BaseAmassService = *core.NewBaseAmassService("Sources Service", config, ss)
+	for _, source := range allSources(config) {
+		ss.AddSource(source)
+	}
 	return ss
 }
 
+// AddSource - Includes the data source in the enumeration if the configuration has it enabled
+func (ss *SourcesService) AddSource(source sources.DataSource) {
+	config := ss.Config()
+	if !sourceEnabled(config, source) {
+		return
+	}
+
+	ss.Lock()
+	defer ss.Unlock()
+
+	if source.Type() == core.ARCHIVE {
+		//if false {
+		ss.throttles = append(ss.throttles, source)
+		//}
+	} else {
+		ss.directs = append(ss.directs, source)
+	}
+	source.SetProxy(config.SourceProxy(source.Type()))
+	ss.stats[source.String()] = &SourceStats{Name: source.String()}
+	source.SetLogger(log.New(&sourceLogWriter{
+		ss:     ss,
+		source: source.String(),
+		w:      config.Log.Writer(),
+	}, config.Log.Prefix(), config.Log.Flags()))
+}
+
 func (ss *SourcesService) OnStart() error {
 	ss.BaseAmassService.OnStart()
 
 	ss.bus.SubscribeAsync(core.RESOLVED, ss.SendRequest, false)
+	ss.bus.SubscribeAsync(core.NEWDOMAIN, ss.addDomain, false)
+	if ss.Config().AnalyticsPivot {
+		ss.bus.SubscribeAsync(core.ANALYTICS, ss.pivotAnalytics, false)
+	}
 	go ss.processRequests()
 	go ss.processOutput()
 	go ss.processThrottleQueue()
+	ss.StartWork()
 	go ss.queryAllSources()
 	return nil
 }
 
+// List - Returns the data sources that will be queried and the data sent to each of them
+func (ss *SourcesService) List() string {
+	var list []string
+
+	for _, source := range append(ss.directs, ss.throttles...) {
+		line := source.String() + " (" + source.Type() + "): " + source.List()
+		if proxy := ss.Config().SourceProxy(source.Type()); proxy != nil {
+			line += " through the proxy " + proxy.Host
+		}
+		list = append(list, line)
+	}
+	return strings.Join(list, "\n")
+}
+
 func (ss *SourcesService) OnPause() error {
 	return nil
 }
@@ -79,6 +188,10 @@ func (ss *SourcesService) OnStop() error {
 	ss.BaseAmassService.OnStop()
 
 	ss.bus.Unsubscribe(core.RESOLVED, ss.SendRequest)
+	ss.bus.Unsubscribe(core.NEWDOMAIN, ss.addDomain)
+	if ss.Config().AnalyticsPivot {
+		ss.bus.Unsubscribe(core.ANALYTICS, ss.pivotAnalytics)
+	}
 	return nil
 }
 
@@ -88,9 +201,7 @@ loop:
 	for {
 		select {
 		case <-t.C:
-			if req := ss.NextRequest(); req != nil {
-				go ss.handleRequest(req)
-			}
+			ss.nextRequest()
 		case <-ss.PauseChan():
 			t.Stop()
 		case <-ss.ResumeChan():
@@ -102,12 +213,20 @@ loop:
 	t.Stop()
 }
 
-func (ss *SourcesService) handleRequest(req *core.AmassRequest) {
-	if ss.inDup(req.Name) || !ss.Config().IsDomainInScope(req.Name) {
-		return
+// nextRequest - Skips past the requests already handled and starts on the next new name
+func (ss *SourcesService) nextRequest() {
+	req := ss.NextRequest()
+	for req != nil && (ss.inDup(req.Name) || !ss.Config().IsDomainInScope(req.Name)) {
+		ss.FinishWork()
+		req = ss.NextRequest()
+	}
+	if req != nil {
+		go ss.handleRequest(req)
 	}
+}
 
-	ss.SetActive()
+func (ss *SourcesService) handleRequest(req *core.AmassRequest) {
+	defer ss.FinishWork()
 
 	var subsrch bool
 	if req.Name != req.Domain {
@@ -118,11 +237,13 @@ func (ss *SourcesService) handleRequest(req *core.AmassRequest) {
 		if subsrch && !source.Subdomains() {
 			continue
 		}
+		ss.StartWork()
 		go ss.queryOneSource(source, req.Domain, req.Name)
 	}
 
-	// Do not queue requests that were not resolved
-	if len(req.Records) == 0 {
+	// Do not queue requests that were not resolved, except for the root
+	// domains when names will not be resolved during the enumeration
+	if len(req.Records) == 0 && !(ss.Config().Passive && req.Name == req.Domain) {
 		return
 	}
 
@@ -146,6 +267,8 @@ func (ss *SourcesService) processOutput() {
 }
 
 func (ss *SourcesService) handleOutput(req *core.AmassRequest) {
+	defer ss.FinishWork()
+
 	re := regexp.MustCompile("^((20)|(25)|(2f)|(3d)|(40))+")
 
 	// Clean up the names scraped from the web
@@ -158,11 +281,15 @@ func (ss *SourcesService) handleOutput(req *core.AmassRequest) {
 		req.Name = req.Name[1:]
 	}
 
+	span := ss.Config().StartSpan(core.SpanEmit, req)
+	defer span.End()
+
+	ss.recordName(req.Source, req.Name)
 	if ss.outDup(req.Name) {
+		span.SetAttribute("duplicate", "true")
 		return
 	}
 
-	ss.SetActive()
 	if ss.Config().Passive {
 		ss.bus.Publish(core.OUTPUT, &AmassOutput{
 			Name:   req.Name,
@@ -176,6 +303,139 @@ func (ss *SourcesService) handleOutput(req *core.AmassRequest) {
 	ss.SendRequest(req)
 }
 
+func (ss *SourcesService) recordName(source, name string) {
+	ss.Lock()
+	defer ss.Unlock()
+
+	if _, found := ss.nameSources[name]; !found {
+		ss.nameSources[name] = make(map[string]struct{})
+	}
+	ss.nameSources[name][source] = struct{}{}
+}
+
+// Report - Returns the contribution of each data source so far
+func (ss *SourcesService) Report() []*SourceStats {
+	ss.Lock()
+	defer ss.Unlock()
+
+	var report []*SourceStats
+	for _, s := range ss.stats {
+		stats := *s
+		_, stats.Disabled = ss.disabled[s.Name]
+
+		for _, srcs := range ss.nameSources {
+			if _, found := srcs[s.Name]; !found {
+				continue
+			}
+
+			stats.Names++
+			if len(srcs) == 1 {
+				stats.Unique++
+			}
+		}
+		report = append(report, &stats)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Unique == report[j].Unique {
+			return report[i].Name < report[j].Name
+		}
+		return report[i].Unique > report[j].Unique
+	})
+	return report
+}
+
+// SetSourceEnabled - Stops or resumes the queries sent to the data source while the
+// enumeration is running, and returns the name of the data source that was matched
+func (ss *SourcesService) SetSourceEnabled(name string, enable bool) (string, error) {
+	ss.Lock()
+	defer ss.Unlock()
+
+	source, err := ss.sourceName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if enable {
+		delete(ss.disabled, source)
+	} else {
+		ss.disabled[source] = struct{}{}
+	}
+	return source, nil
+}
+
+// CheckSourceNames - Returns an error for the first name that is not a data source used by the enumeration
+func (ss *SourcesService) CheckSourceNames(names ...string) error {
+	ss.Lock()
+	defer ss.Unlock()
+
+	for _, name := range names {
+		if _, err := ss.sourceName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceName - Returns the name of the data source matching the name regardless of case.
+// The caller must hold the lock
+func (ss *SourcesService) sourceName(name string) (string, error) {
+	for source := range ss.stats {
+		if strings.EqualFold(source, strings.TrimSpace(name)) {
+			return source, nil
+		}
+	}
+	return "", fmt.Errorf("Source error: %s is not used by the enumeration", name)
+}
+
+func (ss *SourcesService) sourceDisabled(name string) bool {
+	ss.Lock()
+	defer ss.Unlock()
+
+	_, found := ss.disabled[name]
+	return found
+}
+
+// availabilityCheck - The availability check of a data source, which is done once the
+// channel has been closed
+type availabilityCheck struct {
+	done      chan struct{}
+	available bool
+}
+
+// sourceAvailable - Checks the data sources that come and go before they are first queried,
+// and disables those that cannot be reached for the rest of the enumeration. The queries
+// arriving during the check wait for its result
+func (ss *SourcesService) sourceAvailable(source sources.DataSource) bool {
+	ac, ok := source.(sources.AvailabilityChecker)
+	if !ok {
+		return true
+	}
+
+	name := source.String()
+	ss.Lock()
+	if check, found := ss.checked[name]; found {
+		ss.Unlock()
+		<-check.done
+		return check.available
+	}
+	check := &availabilityCheck{done: make(chan struct{})}
+	ss.checked[name] = check
+	ss.Unlock()
+
+	err := ac.Available()
+	if err != nil {
+		ss.Config().Log.Printf("%s: %v", name, err)
+
+		ss.Lock()
+		ss.disabled[name] = struct{}{}
+		ss.Unlock()
+	}
+	check.available = err == nil
+	close(check.done)
+	return check.available
+}
+
 func (ss *SourcesService) inDup(sub string) bool {
 	ss.Lock()
 	defer ss.Unlock()
@@ -199,22 +459,56 @@ func (ss *SourcesService) outDup(sub string) bool {
 }
 
 func (ss *SourcesService) queryAllSources() {
-	ss.SetActive()
+	defer ss.FinishWork()
 
 	for _, domain := range ss.Config().Domains() {
 		if _, found := ss.domainFilter[domain]; found {
 			continue
 		}
 
-		ss.SendRequest(&core.AmassRequest{
-			Name:   domain,
-			Domain: domain,
-		})
+		ss.addDomain(domain)
 	}
 }
 
+// addDomain - Queries the data sources for the root domain
+func (ss *SourcesService) addDomain(domain string) {
+	ss.SendRequest(&core.AmassRequest{
+		Name:   domain,
+		Domain: domain,
+		Tag:    core.DNS,
+		Source: "Forward DNS",
+	})
+}
+
+// queryOneSource - Sends the names returned by the data source. The caller
+// must have called StartWork for the query
 func (ss *SourcesService) queryOneSource(source sources.DataSource, domain, sub string) {
-	for _, name := range source.Query(domain, sub) {
+	defer ss.FinishWork()
+
+	if ss.sourceDisabled(source.String()) || !ss.sourceAvailable(source) ||
+		!ss.Config().AllowSourceCall(source.String()) {
+		return
+	}
+
+	start := time.Now()
+	names := source.Query(domain, sub)
+
+	ss.Lock()
+	if stats, found := ss.stats[source.String()]; found {
+		stats.Queries++
+		stats.Duration += time.Since(start)
+	}
+	ss.Unlock()
+
+	// The previous record values are only requested for the root domains
+	if hs, ok := source.(sources.HistorySource); ok && domain == sub {
+		for _, rec := range hs.History(domain) {
+			ss.bus.Publish(core.HISTORY, rec)
+		}
+	}
+
+	for _, name := range names {
+		ss.StartWork()
 		ss.responses <- &core.AmassRequest{
 			Name:   name,
 			Domain: domain,
@@ -224,7 +518,27 @@ func (ss *SourcesService) queryOneSource(source sources.DataSource, domain, sub
 	}
 }
 
+// InjectNames - Handles names obtained outside of the data sources as new discoveries
+func (ss *SourcesService) InjectNames(reqs []*core.AmassRequest) {
+	for range reqs {
+		ss.StartWork()
+	}
+
+	go func() {
+		for _, req := range reqs {
+			select {
+			case ss.responses <- req:
+			case <-ss.Quit():
+				return
+			}
+		}
+	}()
+}
+
 func (ss *SourcesService) throttleAdd(source sources.DataSource, domain, sub string) {
+	// The work is finished once the source has been queried
+	ss.StartWork()
+
 	ss.Lock()
 	defer ss.Unlock()
 
@@ -254,35 +568,178 @@ func (ss *SourcesService) throttleNext() *entry {
 
 const MAX_THROTTLED int = 20
 
+// The default time waited between starting the throttled data source queries
+const defaultSourceDelay = 100 * time.Millisecond
+
+// throttleRates - Returns the most throttled queries running at once and the time waited between them
+func (ss *SourcesService) throttleRates() (int, time.Duration) {
+	rates := ss.Config().Rates()
+
+	max := MAX_THROTTLED
+	if n := rates.MaxSourceConcurrency; n > 0 {
+		max = n
+	}
+	delay := defaultSourceDelay
+	if d := rates.SourceDelay; d > 0 {
+		delay = d
+	}
+	return max, delay
+}
+
+// resizeDone - Returns a channel with room for the new maximum of queries running at once.
+// The queries still running signal the old channel, so their completions are forwarded
+func resizeDone(old chan struct{}, max, running int) chan struct{} {
+	done := make(chan struct{}, max)
+
+	go func() {
+		for i := 0; i < running; i++ {
+			<-old
+			done <- struct{}{}
+		}
+	}()
+	return done
+}
+
 func (ss *SourcesService) processThrottleQueue() {
+	max, delay := ss.throttleRates()
+
 	var running int
-	done := make(chan struct{}, MAX_THROTTLED)
+	var paused bool
+	done := make(chan struct{}, max)
 
-	t := time.NewTicker(100 * time.Millisecond)
+	t := time.NewTicker(delay)
 loop:
 	for {
 		select {
 		case <-t.C:
-			if running >= MAX_THROTTLED {
+			if running >= max {
 				continue
 			}
 
 			if th := ss.throttleNext(); th != nil {
 				running++
-				go func() {
+				go func(done chan struct{}) {
 					ss.queryOneSource(th.Source, th.Domain, th.Sub)
 					done <- struct{}{}
-				}()
+				}(done)
 			}
 		case <-done:
 			running--
 		case <-ss.PauseChan():
 			t.Stop()
+			paused = true
 		case <-ss.ResumeChan():
-			t = time.NewTicker(100 * time.Millisecond)
+			t = time.NewTicker(delay)
+			paused = false
+		case <-ss.Config().Reloaded():
+			var d time.Duration
+			if max, d = ss.throttleRates(); max != cap(done) {
+				done = resizeDone(done, max, running)
+			}
+			if !paused && d != delay {
+				t.Stop()
+				t = time.NewTicker(d)
+			}
+			delay = d
 		case <-ss.Quit():
 			break loop
 		}
 	}
 	t.Stop()
 }
+
+// Sources - Returns the status of every registered data source for the current configuration
+func (e *Enumeration) Sources() []*SourceInfo {
+	var infos []*SourceInfo
+
+	config := e.sourcesConfig()
+	for _, source := range e.dataSources(config) {
+		infos = append(infos, &SourceInfo{
+			Name:          source.String(),
+			Category:      source.Type(),
+			Subdomains:    source.Subdomains(),
+			Enabled:       sourceEnabled(config, source),
+			RevealsDomain: source.RevealsDomain(),
+			RequiredKeys:  source.RequiredKeys(),
+			MissingKeys:   sources.MissingKeys(source),
+		})
+	}
+	return infos
+}
+
+// sourcesConfig - Returns the configuration deciding the data sources used, for the
+// data sources consulted without starting the enumeration
+func (e *Enumeration) sourcesConfig() *core.AmassConfig {
+	return &core.AmassConfig{
+		Log:              e.Log,
+		DisabledSources:  e.DisabledSources,
+		MinimizeExposure: e.MinimizeExposure,
+		WebSearch:        e.WebSearch,
+		FDNSFile:         e.FDNSFile,
+		LocalDir:         e.LocalDir,
+	}
+}
+
+// dataSources - Returns the data sources of the configuration, along with those provided
+// by the plugins, the scripts and through AddSource
+func (e *Enumeration) dataSources(config *core.AmassConfig) []sources.DataSource {
+	return append(allSources(config), e.addedSources()...)
+}
+
+// addedSources - Returns the data sources provided by the plugins, the scripts and through AddSource
+func (e *Enumeration) addedSources() []sources.DataSource {
+	all := append(e.loadedPlugins(), e.loadedScripts().sources...)
+
+	return append(all, e.custom...)
+}
+
+// loadedPlugins - Returns the plugins of the plugin directory, described the first time they
+// are needed, so listing the data sources does not run the plugins again
+func (e *Enumeration) loadedPlugins() []sources.DataSource {
+	e.loadLock.Lock()
+	defer e.loadLock.Unlock()
+
+	if e.plugins == nil {
+		e.plugins = []sources.DataSource{}
+
+		if e.PluginDir != "" {
+			plugins, err := sources.LoadPlugins(e.PluginDir)
+			if err != nil {
+				e.Log.Printf("%v", err)
+			}
+			e.plugins = append(e.plugins, plugins...)
+		}
+	}
+	return e.plugins
+}
+
+// allSources - Returns the built-in data sources and those reading local files
+func allSources(config *core.AmassConfig) []sources.DataSource {
+	all := sources.GetAllSources()
+
+	if config.FDNSFile != "" {
+		all = append(all, sources.NewFDNSFile(config.FDNSFile))
+	}
+	if config.LocalDir != "" {
+		all = append(all, sources.NewLocalDir(config.LocalDir))
+	}
+
+	applyScraperRules(all)
+	return all
+}
+
+func sourceEnabled(config *core.AmassConfig, source sources.DataSource) bool {
+	if !config.StageEnabled(core.StagePassive) {
+		return false
+	}
+	if config.SourceDisabled(source.String()) {
+		return false
+	}
+	if config.MinimizeExposure && source.RevealsDomain() {
+		return false
+	}
+	if source.Type() == sources.WEBSEARCH && !config.WebSearch {
+		return false
+	}
+	return len(sources.MissingKeys(source)) == 0
+}
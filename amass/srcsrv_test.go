@@ -0,0 +1,304 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/amass/amasstest"
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestSourcesServiceSetSourceEnabled(t *testing.T) {
+	config := &core.AmassConfig{
+		Log:             log.New(ioutil.Discard, "", 0),
+		DisabledSources: amasstest.BuiltinSourceNames(),
+	}
+	src := amasstest.NewSource("Mock Source", "www.example.com")
+
+	ss := NewSourcesService(config, nil)
+	ss.AddSource(src)
+
+	name, err := ss.SetSourceEnabled("mock source", false)
+	if err != nil || name != "Mock Source" {
+		t.Fatalf("The source was not disabled: %q %v", name, err)
+	}
+	ss.StartWork()
+	ss.queryOneSource(src, "example.com", "example.com")
+	if n := src.NumOfQueries(); n != 0 {
+		t.Errorf("The disabled source was queried %d times", n)
+	}
+	if report := ss.Report(); len(report) != 1 || !report[0].Disabled {
+		t.Errorf("The report did not show the source as disabled")
+	}
+
+	if _, err := ss.SetSourceEnabled("Mock Source", true); err != nil {
+		t.Fatalf("The source was not enabled: %v", err)
+	}
+	ss.StartWork()
+	ss.queryOneSource(src, "example.com", "example.com")
+	if n := src.NumOfQueries(); n != 1 {
+		t.Errorf("The enabled source was queried %d times", n)
+	}
+
+	if _, err := ss.SetSourceEnabled("Unknown Source", false); err == nil {
+		t.Errorf("A source that is not used by the enumeration was accepted")
+	}
+}
+
+func TestMinimizeExposure(t *testing.T) {
+	e := NewEnumeration()
+	e.MinimizeExposure = true
+	e.AddSource(amasstest.NewSource("Mock Source", "www.example.com"))
+
+	for _, src := range e.Sources() {
+		if src.Enabled == src.RevealsDomain {
+			t.Errorf("%s was enabled %t while revealing the domain %t", src.Name, src.Enabled, src.RevealsDomain)
+		}
+	}
+
+	e.AddDomain("example.com")
+	e.Whois = true
+	if _, err := e.generateAmassConfig(); err == nil {
+		t.Errorf("Reverse whois was accepted while minimizing exposure")
+	}
+}
+
+type offlineSource struct {
+	*amasstest.Source
+	checks int
+}
+
+func (o *offlineSource) Available() error {
+	o.checks++
+	return errors.New("The service is offline")
+}
+
+func TestSourcesServiceAvailability(t *testing.T) {
+	config := &core.AmassConfig{
+		Log:             log.New(ioutil.Discard, "", 0),
+		DisabledSources: amasstest.BuiltinSourceNames(),
+	}
+	src := &offlineSource{Source: amasstest.NewSource("Offline Source", "www.example.com")}
+
+	ss := NewSourcesService(config, nil)
+	ss.AddSource(src)
+	for i := 0; i < 2; i++ {
+		ss.StartWork()
+		ss.queryOneSource(src, "example.com", "example.com")
+	}
+
+	if n := src.NumOfQueries(); n != 0 || src.checks != 1 {
+		t.Errorf("The unavailable source was queried %d times after %d checks", n, src.checks)
+	}
+	if report := ss.Report(); len(report) != 1 || !report[0].Disabled {
+		t.Errorf("The report did not show the unavailable source as disabled")
+	}
+}
+
+func TestSourcesServiceAvailabilityWait(t *testing.T) {
+	config := &core.AmassConfig{
+		Log:             log.New(ioutil.Discard, "", 0),
+		DisabledSources: amasstest.BuiltinSourceNames(),
+	}
+	src := &slowOfflineSource{offlineSource{Source: amasstest.NewSource("Offline Source", "www.example.com")}}
+
+	ss := NewSourcesService(config, nil)
+	ss.AddSource(src)
+
+	var wg sync.WaitGroup
+	results := make([]bool, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = ss.sourceAvailable(src)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, available := range results {
+		if available {
+			t.Errorf("Query %d did not wait for the availability check", i)
+		}
+	}
+	if src.checks != 1 {
+		t.Errorf("The availability was checked %d times", src.checks)
+	}
+}
+
+type slowOfflineSource struct {
+	offlineSource
+}
+
+func (s *slowOfflineSource) Available() error {
+	time.Sleep(50 * time.Millisecond)
+	return s.offlineSource.Available()
+}
+
+func TestWebSearchCategory(t *testing.T) {
+	e := NewEnumeration()
+
+	for _, enabled := range []bool{false, true} {
+		e.WebSearch = enabled
+
+		var found bool
+		for _, src := range e.Sources() {
+			if src.Category != core.WEBSEARCH || len(src.MissingKeys) > 0 {
+				continue
+			}
+			found = true
+			if src.Enabled != enabled {
+				t.Errorf("%s was enabled %t while the web search category was enabled %t", src.Name, src.Enabled, enabled)
+			}
+		}
+		if !found {
+			t.Errorf("No data sources belong to the web search category")
+		}
+	}
+
+	// The scrapers queried by default before the category was added are still queried
+	for _, src := range NewEnumeration().Sources() {
+		if (src.Name == "Ask Scrape" || src.Name == "Exalead") && (src.Category != core.SCRAPE || !src.Enabled) {
+			t.Errorf("%s was moved to the %s category, enabled %t", src.Name, src.Category, src.Enabled)
+		}
+	}
+}
+
+type analyticsSource struct {
+	*amasstest.Source
+	ids []string
+}
+
+func (a *analyticsSource) AnalyticsDomains(id string) []string {
+	a.ids = append(a.ids, id)
+	return []string{"www.example.com", "shop.example.net", "example-store.org", "cdn.example-store.org"}
+}
+
+func TestAnalyticsPivot(t *testing.T) {
+	config := &core.AmassConfig{
+		Log:             log.New(ioutil.Discard, "", 0),
+		DisabledSources: amasstest.BuiltinSourceNames(),
+		AnalyticsPivot:  true,
+	}
+	config.AddDomain("example.com")
+	bus := core.NewEventBus(config)
+	src := &analyticsSource{Source: amasstest.NewSource("Analytics Source", "www.example.com")}
+
+	// The root domains are known, so they are not looked up in the DNS
+	domainLock.Lock()
+	domainCache["example.net"] = struct{}{}
+	domainCache["example-store.org"] = struct{}{}
+	domainLock.Unlock()
+
+	var lock sync.Mutex
+	var findings []*core.Finding
+	bus.SubscribeAsync(core.FINDING, func(f *core.Finding) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		findings = append(findings, f)
+	}, false)
+
+	ss := NewSourcesService(config, bus)
+	ss.AddSource(src)
+	for _, id := range []string{"UA-1234567-1", "UA-1234567-1"} {
+		ss.pivotAnalytics(&core.AnalyticsRequest{
+			ID:     id,
+			URL:    "https://www.example.com/",
+			Name:   "www.example.com",
+			Domain: "example.com",
+		})
+	}
+	for ss.IsActive() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	bus.WaitAsync()
+
+	if len(src.ids) != 1 {
+		t.Errorf("The data source was searched for the IDs %v", src.ids)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	var names []string
+	for _, f := range findings {
+		if f.Type != core.FindingRelated || f.Domain != "example.com" {
+			t.Errorf("The finding %+v was published", f)
+		}
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "example-store.org,example.net" {
+		t.Errorf("The related domains were %v", names)
+	}
+}
+
+func TestResizeDone(t *testing.T) {
+	old := make(chan struct{}, 4)
+	old <- struct{}{}
+
+	// One query finished before the resize and two are still running
+	done := resizeDone(old, 1, 3)
+	if cap(done) != 1 {
+		t.Errorf("The channel was resized to %d", cap(done))
+	}
+	old <- struct{}{}
+	old <- struct{}{}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Only %d of the completions were forwarded", i)
+		}
+	}
+}
+
+func TestPluginsLoadedOnce(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("The test plugin requires a shell")
+	}
+
+	dir, err := ioutil.TempDir("", "amass-plugins")
+	if err != nil {
+		t.Fatalf("Failed to create the plugin directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	runs := filepath.Join(dir, "runs")
+	plugin := "#!/bin/sh\necho run >> " + runs + "\necho '{\"kind\": \"source\", \"name\": \"Counted\"}'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "counted"), []byte(plugin), 0755); err != nil {
+		t.Fatalf("Failed to write the plugin: %v", err)
+	}
+
+	e := NewEnumeration()
+	e.PluginDir = dir
+	for i := 0; i < 3; i++ {
+		var found bool
+		for _, info := range e.Sources() {
+			if info.Name == "Counted" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("The plugin was not listed")
+		}
+	}
+
+	if data, _ := ioutil.ReadFile(runs); strings.Count(string(data), "run") != 1 {
+		t.Errorf("The plugin was run %d times", strings.Count(string(data), "run"))
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/expr"
+)
+
+// Organizations operating content delivery networks, as found in ASN descriptions
+var cdnOrganizations = []string{
+	"akamai",
+	"cloudflare",
+	"cloudfront",
+	"fastly",
+	"incapsula",
+	"edgecast",
+	"limelight",
+	"stackpath",
+	"cdnetworks",
+	"cachefly",
+}
+
+// The address ranges published by the content delivery networks, including their anycast
+// ranges, which are replaced by those of the cloud ranges data file once it is downloaded
+var cdnRanges = []string{
+	// https://www.cloudflare.com/ips/
+	"173.245.48.0/20 cloudflare",
+	"103.21.244.0/22 cloudflare",
+	"103.22.200.0/22 cloudflare",
+	"103.31.4.0/22 cloudflare",
+	"141.101.64.0/18 cloudflare",
+	"108.162.192.0/18 cloudflare",
+	"190.93.240.0/20 cloudflare",
+	"188.114.96.0/20 cloudflare",
+	"197.234.240.0/22 cloudflare",
+	"198.41.128.0/17 cloudflare",
+	"162.158.0.0/15 cloudflare",
+	"104.16.0.0/13 cloudflare",
+	"104.24.0.0/14 cloudflare",
+	"172.64.0.0/13 cloudflare",
+	"131.0.72.0/22 cloudflare",
+	"2400:cb00::/32 cloudflare",
+	"2606:4700::/32 cloudflare",
+	"2803:f800::/32 cloudflare",
+	"2405:b500::/32 cloudflare",
+	"2405:8100::/32 cloudflare",
+	"2a06:98c0::/29 cloudflare",
+	"2c0f:f248::/32 cloudflare",
+	// https://api.fastly.com/public-ip-list
+	"23.235.32.0/20 fastly",
+	"43.249.72.0/22 fastly",
+	"103.244.50.0/24 fastly",
+	"103.245.222.0/23 fastly",
+	"103.245.224.0/24 fastly",
+	"104.156.80.0/20 fastly",
+	"140.248.64.0/18 fastly",
+	"140.248.128.0/17 fastly",
+	"146.75.0.0/17 fastly",
+	"151.101.0.0/16 fastly",
+	"157.52.64.0/18 fastly",
+	"167.82.0.0/17 fastly",
+	"167.82.128.0/20 fastly",
+	"167.82.160.0/20 fastly",
+	"167.82.224.0/20 fastly",
+	"172.111.64.0/18 fastly",
+	"185.31.16.0/22 fastly",
+	"199.27.72.0/21 fastly",
+	"199.232.0.0/16 fastly",
+	"2a04:4e40::/32 fastly",
+	"2a04:4e42::/32 fastly",
+}
+
+// OutputFilter - Selects the results delivered to a subscription
+type OutputFilter struct {
+	// Only deliver names that were resolved
+	ResolvedOnly bool
+
+	// Only deliver names with at least this confidence score
+	MinConfidence int
+
+	// Only deliver names not previously delivered or listed in Known
+	OnlyNew bool
+
+	// Names already known to the subscriber, such as those from a previous run
+	Known []string
+
+	// Only deliver results matching the expression, such as "resolved && !cdn"
+	Expression *expr.Expression
+}
+
+type subscription struct {
+	filter *OutputFilter
+	seen   map[string]struct{}
+	ch     chan *AmassOutput
+}
+
+// Subscribe - Returns a channel receiving the results that pass the filter. The channel
+// is closed once the enumeration has completed, and must be drained by the subscriber
+func (e *Enumeration) Subscribe(filter *OutputFilter) <-chan *AmassOutput {
+	if filter == nil {
+		filter = new(OutputFilter)
+	}
+
+	sub := &subscription{
+		filter: filter,
+		seen:   make(map[string]struct{}),
+		ch:     make(chan *AmassOutput, 100),
+	}
+	for _, name := range filter.Known {
+		sub.seen[strings.ToLower(name)] = struct{}{}
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	select {
+	case <-e.done:
+		close(sub.ch)
+	default:
+		e.subscriptions = append(e.subscriptions, sub)
+	}
+	return sub.ch
+}
+
+func (e *Enumeration) publishToSubscribers(out *AmassOutput) {
+	e.Lock()
+	subs := e.subscriptions
+	e.Unlock()
+
+	for _, sub := range subs {
+		if sub.accepts(out) {
+			sub.ch <- out
+		}
+	}
+}
+
+func (e *Enumeration) closeSubscriptions() {
+	e.Lock()
+	defer e.Unlock()
+
+	for _, sub := range e.subscriptions {
+		close(sub.ch)
+	}
+	e.subscriptions = nil
+}
+
+func (s *subscription) accepts(out *AmassOutput) bool {
+	if s.filter.ResolvedOnly && !out.Resolved() {
+		return false
+	}
+	if out.Confidence() < s.filter.MinConfidence {
+		return false
+	}
+	if s.filter.Expression != nil {
+		if ok, err := s.filter.Expression.Eval(out.Fields()); err != nil || !ok {
+			return false
+		}
+	}
+	if s.filter.OnlyNew {
+		name := strings.ToLower(out.Name)
+
+		if _, found := s.seen[name]; found {
+			return false
+		}
+		s.seen[name] = struct{}{}
+	}
+	return true
+}
+
+// Resolved - Returns true when DNS records were obtained for the name
+func (o *AmassOutput) Resolved() bool {
+	return len(o.Addresses) > 0 || len(o.Records) > 0
+}
+
+// Confidence - Scores the likelihood that the name exists as reported, from 0 to 100
+func (o *AmassOutput) Confidence() int {
+	// The resolvers confirmed the name exists, but without any records
+	if !o.Resolved() && o.NoData {
+		return 50
+	}
+	// Only reported by a data source
+	if !o.Resolved() {
+		return 25
+	}
+
+	score := 75
+	if o.DNSSEC == core.DNSSECSecure {
+		score += 15
+	}
+	for _, addr := range o.Addresses {
+		if addr.PTRMatch {
+			score += 10
+			break
+		}
+	}
+	if o.DNSSEC == core.DNSSECBogus {
+		score -= 25
+	}
+	if o.Divergent {
+		score -= 25
+	}
+
+	if score > 100 {
+		score = 100
+	} else if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// CDN - Returns true when an address for the name belongs to a content delivery network
+func (o *AmassOutput) CDN() bool {
+	for _, addr := range o.Addresses {
+		if cloudRangeProvider(addr.Address.String()) != "" || cdnDescription(addr.Description) {
+			return true
+		}
+	}
+	return false
+}
+
+// cdnDescription - Returns true when the ASN description names a content delivery network
+func cdnDescription(desc string) bool {
+	desc = strings.ToLower(desc)
+
+	for _, org := range cdnOrgs() {
+		if strings.Contains(desc, org) {
+			return true
+		}
+	}
+	return false
+}
+
+// cdnExcluded - Returns true when the address belongs to a content delivery network, and the
+// active techniques are not allowed to probe them. Probing the shared address space of these
+// networks reveals nothing about the target and adds noise. The address is matched against the
+// published ranges first, and the ASN description is only consulted for the networks that do
+// not publish their ranges
+func cdnExcluded(config *core.AmassConfig, addr string) bool {
+	if config.ProbeCDN {
+		return false
+	}
+	if cloudRangeProvider(addr) != "" {
+		return true
+	}
+
+	_, _, desc, err := IPRequest(addr)
+	return err == nil && cdnDescription(desc)
+}
+
+// Private - Returns true when an address for the name cannot be reached from the Internet,
+// which indicates internal infrastructure disclosed through public DNS
+func (o *AmassOutput) Private() bool {
+	for _, addr := range o.Addresses {
+		if addr.Reserved != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOutputFilter - Compiles the filter expression, which can only refer to the fields of the results
+func ParseOutputFilter(text string) (*expr.Expression, error) {
+	var fields []string
+
+	for name := range new(AmassOutput).Fields() {
+		fields = append(fields, name)
+	}
+	return expr.Parse(text, fields)
+}
+
+// Fields - Returns the values that filter expressions can refer to
+func (o *AmassOutput) Fields() map[string]interface{} {
+	var ptrMatch bool
+	for _, addr := range o.Addresses {
+		if addr.PTRMatch {
+			ptrMatch = true
+			break
+		}
+	}
+
+	return map[string]interface{}{
+		"name":       o.Name,
+		"domain":     o.Domain,
+		"tag":        o.Tag,
+		"source":     o.Source,
+		"resolved":   o.Resolved(),
+		"confidence": o.Confidence(),
+		"cdn":        o.CDN(),
+		"private":    o.Private(),
+		"addresses":  len(o.Addresses),
+		"records":    len(o.Records),
+		"dnssec":     o.DNSSEC,
+		"divergent":  o.Divergent,
+		"geodns":     o.GeoDNS,
+		"nodata":     o.NoData,
+		"ptr_match":  ptrMatch,
+		"root":       o.DomainInfo != nil,
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"net"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+func TestCDNExcluded(t *testing.T) {
+	netDataLock.Lock()
+	netDataCache[64510] = &ASRecord{
+		ASN:         64510,
+		Description: "EXAMPLE-NET - Example Hosting",
+		Netblocks:   []string{"203.0.113.0/24"},
+	}
+	netDataCache[64511] = &ASRecord{
+		ASN:         64511,
+		Description: "AKAMAI-AS - Akamai Technologies, Inc.",
+		Netblocks:   []string{"198.51.100.0/24"},
+	}
+	netDataLock.Unlock()
+	defer func() {
+		netDataLock.Lock()
+		delete(netDataCache, 64510)
+		delete(netDataCache, 64511)
+		netDataLock.Unlock()
+	}()
+
+	tests := []struct {
+		addr   string
+		probe  bool
+		result bool
+	}{
+		// The anycast range is published by Cloudflare, so no ASN lookup is needed
+		{"104.16.132.229", false, true},
+		{"2606:4700::6810:84e5", false, true},
+		{"104.16.132.229", true, false},
+		// Akamai does not publish its ranges, so the ASN description is matched
+		{"198.51.100.7", false, true},
+		{"198.51.100.7", true, false},
+		{"203.0.113.10", false, false},
+	}
+
+	for _, test := range tests {
+		config := &core.AmassConfig{ProbeCDN: test.probe}
+
+		if r := cdnExcluded(config, test.addr); r != test.result {
+			t.Errorf("The address %s was excluded %t when probing CDNs was %t", test.addr, r, test.probe)
+		}
+	}
+
+	out := &AmassOutput{Addresses: []AmassAddressInfo{{Description: "FASTLY - Fastly"}}}
+	if !out.CDN() {
+		t.Errorf("The name served by Fastly was not marked as a CDN")
+	}
+	out = &AmassOutput{Addresses: []AmassAddressInfo{{Address: net.ParseIP("151.101.1.69"), Description: "Example Transit"}}}
+	if !out.CDN() {
+		t.Errorf("The name within the ranges published by Fastly was not marked as a CDN")
+	}
+}
+
+func TestParseOutputFilter(t *testing.T) {
+	if _, err := ParseOutputFilter("resolved && !cdn && confidence > 50"); err != nil {
+		t.Errorf("The filter was not parsed: %v", err)
+	}
+	if _, err := ParseOutputFilter("resolved && !cnd"); err == nil {
+		t.Errorf("The filter with a misspelled field was accepted")
+	}
+}
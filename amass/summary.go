@@ -0,0 +1,169 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/OWASP/Amass/amass/core"
+)
+
+// EnumerationSummary - Totals for the names reported by a run, so the attack
+// surface can be tracked across runs by dashboards
+type EnumerationSummary struct {
+	ID       string                    `json:"id"`
+	Start    time.Time                 `json:"start"`
+	End      time.Time                 `json:"end"`
+	Duration string                    `json:"duration"`
+	Totals   *SummaryCounts            `json:"totals"`
+	Domains  map[string]*SummaryCounts `json:"domains"`
+	Sources  map[string]int            `json:"sources"`
+	Tags     map[string]int            `json:"tags"`
+	ASNs     []int                     `json:"asns"`
+	Findings []*core.Finding           `json:"findings"`
+}
+
+// SummaryCounts - The counts rolled up for all the names, or the names within a root domain
+type SummaryCounts struct {
+	Names      int    `json:"names"`
+	Addresses  int    `json:"addresses"`
+	Netblocks  int    `json:"netblocks"`
+	ASNs       int    `json:"asns"`
+	Private    int    `json:"private_names"`
+	CDN        int    `json:"cdn_names"`
+	DNSQueries uint64 `json:"dns_queries,omitempty"`
+}
+
+// summaryTally - Collects the unique addresses, netblocks and ASNs as the output is sent
+type summaryTally struct {
+	counts    SummaryCounts
+	addresses map[string]struct{}
+	netblocks map[string]struct{}
+	asns      map[int]struct{}
+}
+
+type summaryData struct {
+	total   *summaryTally
+	domains map[string]*summaryTally
+	sources map[string]int
+	tags    map[string]int
+}
+
+func newSummaryTally() *summaryTally {
+	return &summaryTally{
+		addresses: make(map[string]struct{}),
+		netblocks: make(map[string]struct{}),
+		asns:      make(map[int]struct{}),
+	}
+}
+
+func (st *summaryTally) add(out *AmassOutput) {
+	st.counts.Names++
+	if out.Private() {
+		st.counts.Private++
+	}
+	if out.CDN() {
+		st.counts.CDN++
+	}
+
+	for _, addr := range out.Addresses {
+		st.addresses[addr.Address.String()] = struct{}{}
+		if addr.Netblock != nil {
+			st.netblocks[addr.Netblock.String()] = struct{}{}
+		}
+		if addr.Reserved == "" {
+			st.asns[addr.ASN] = struct{}{}
+		}
+	}
+}
+
+func (st *summaryTally) summary() *SummaryCounts {
+	c := st.counts
+
+	c.Addresses = len(st.addresses)
+	c.Netblocks = len(st.netblocks)
+	c.ASNs = len(st.asns)
+	return &c
+}
+
+// tallyOutput - Adds the name and its findings to the summary of the run. The caller must hold the lock
+func (e *Enumeration) tallyOutput(out *AmassOutput) {
+	if e.summary == nil {
+		e.summary = &summaryData{
+			total:   newSummaryTally(),
+			domains: make(map[string]*summaryTally),
+			sources: make(map[string]int),
+			tags:    make(map[string]int),
+		}
+	}
+
+	e.summary.total.add(out)
+	if _, found := e.summary.domains[out.Domain]; !found {
+		e.summary.domains[out.Domain] = newSummaryTally()
+	}
+	e.summary.domains[out.Domain].add(out)
+	e.summary.sources[out.Source]++
+	e.summary.tags[out.Tag]++
+
+	if e.findings == nil {
+		e.findings = newFindingSet()
+	}
+	for _, f := range outputFindings(out) {
+		e.findings.add(f)
+	}
+}
+
+// Summary - Returns the counts by domain, data source and tag for the names reported
+func (e *Enumeration) Summary() *EnumerationSummary {
+	queries := e.Stats().DNSQueries
+
+	e.Lock()
+	defer e.Unlock()
+
+	s := &EnumerationSummary{
+		ID:      e.ID,
+		Start:   e.started,
+		End:     e.ended,
+		Totals:  new(SummaryCounts),
+		Domains: make(map[string]*SummaryCounts),
+		Sources: make(map[string]int),
+		Tags:    make(map[string]int),
+	}
+	if !e.ended.IsZero() {
+		s.Duration = e.ended.Sub(e.started).String()
+	}
+
+	if e.summary != nil {
+		s.Totals = e.summary.total.summary()
+		for domain, st := range e.summary.domains {
+			s.Domains[domain] = st.summary()
+		}
+		for k, v := range e.summary.sources {
+			s.Sources[k] = v
+		}
+		for k, v := range e.summary.tags {
+			s.Tags[k] = v
+		}
+		for asn := range e.summary.total.asns {
+			s.ASNs = append(s.ASNs, asn)
+		}
+		sort.Ints(s.ASNs)
+	}
+	if e.findings != nil {
+		s.Findings = e.findings.sorted()
+	}
+	s.Totals.DNSQueries = queries
+	return s
+}
+
+// WriteSummary - Encodes the summary of the enumeration as JSON
+func (e *Enumeration) WriteSummary(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	enc.SetIndent("", "  ")
+	return enc.Encode(e.Summary())
+}
@@ -0,0 +1,58 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestEnumerationSummary(t *testing.T) {
+	_, public, _ := net.ParseCIDR("192.0.2.0/24")
+	_, private, _ := net.ParseCIDR("10.0.0.0/8")
+
+	addr := func(ip string, asn int, cidr *net.IPNet, reserved string) AmassAddressInfo {
+		return AmassAddressInfo{
+			Address:  net.ParseIP(ip),
+			Netblock: cidr,
+			ASN:      asn,
+			Reserved: reserved,
+		}
+	}
+
+	e := NewEnumeration()
+	for _, out := range []*AmassOutput{
+		{Name: "www.owasp.org", Domain: "owasp.org", Tag: "cert", Source: "Censys",
+			Addresses: []AmassAddressInfo{addr("192.0.2.1", 64496, public, "")}},
+		{Name: "dev.owasp.org", Domain: "owasp.org", Tag: "cert", Source: "Censys",
+			Addresses: []AmassAddressInfo{addr("192.0.2.1", 64496, public, "")}},
+		{Name: "vpn.owasp.org", Domain: "owasp.org", Tag: "scrape", Source: "Google",
+			Addresses: []AmassAddressInfo{addr("10.1.1.1", 0, private, "private")}},
+		{Name: "www.example.com", Domain: "example.com", Tag: "brute", Source: "Brute Forcing",
+			Addresses: []AmassAddressInfo{addr("192.0.2.9", 64496, public, "")}},
+	} {
+		e.tallyOutput(out)
+	}
+
+	s := e.Summary()
+	// The DNS query count comes from the resolvers shared by the process
+	totals := &SummaryCounts{Names: 4, Addresses: 3, Netblocks: 2, ASNs: 1, Private: 1,
+		DNSQueries: s.Totals.DNSQueries}
+	if !reflect.DeepEqual(s.Totals, totals) {
+		t.Errorf("The totals were %+v instead of %+v", s.Totals, totals)
+	}
+
+	domain := &SummaryCounts{Names: 3, Addresses: 2, Netblocks: 2, ASNs: 1, Private: 1}
+	if d := s.Domains["owasp.org"]; !reflect.DeepEqual(d, domain) {
+		t.Errorf("The owasp.org counts were %+v instead of %+v", d, domain)
+	}
+
+	if s.Sources["Censys"] != 2 || s.Tags["cert"] != 2 || s.Tags["brute"] != 1 {
+		t.Errorf("The source and tag counts were %v and %v", s.Sources, s.Tags)
+	}
+	if !reflect.DeepEqual(s.ASNs, []int{64496}) {
+		t.Errorf("The ASNs were %v instead of [64496]", s.ASNs)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import "strings"
+
+// takeoverServices - The suffixes of the names used by hosted services that let anyone claim
+// a name that is no longer in use, along with the name of the service
+var takeoverServices = []struct {
+	suffix  string
+	service string
+}{
+	{".github.io", "GitHub Pages"},
+	{".herokuapp.com", "Heroku"},
+	{".herokudns.com", "Heroku"},
+	{".s3.amazonaws.com", "Amazon S3"},
+	{".elasticbeanstalk.com", "AWS Elastic Beanstalk"},
+	{".cloudfront.net", "Amazon CloudFront"},
+	{".azurewebsites.net", "Azure App Service"},
+	{".cloudapp.net", "Azure Cloud Services"},
+	{".cloudapp.azure.com", "Azure Cloud Services"},
+	{".trafficmanager.net", "Azure Traffic Manager"},
+	{".blob.core.windows.net", "Azure Blob Storage"},
+	{".azureedge.net", "Azure CDN"},
+	{".myshopify.com", "Shopify"},
+	{".surge.sh", "Surge"},
+	{".bitbucket.io", "Bitbucket"},
+	{".ghost.io", "Ghost"},
+	{".pantheonsite.io", "Pantheon"},
+	{".zendesk.com", "Zendesk"},
+	{".readme.io", "ReadMe"},
+	{".helpscoutdocs.com", "Help Scout"},
+	{".wordpress.com", "WordPress.com"},
+	{".netlify.app", "Netlify"},
+	{".netlify.com", "Netlify"},
+	{".fly.dev", "Fly.io"},
+	{".unbouncepages.com", "Unbounce"},
+}
+
+// CNAMETarget - Returns the target of the CNAME record for the name, or an empty string
+func (o *AmassOutput) CNAMETarget() string {
+	for _, rec := range o.Records {
+		if strings.EqualFold(rec.Type, "CNAME") {
+			return strings.ToLower(strings.TrimSuffix(rec.Data, "."))
+		}
+	}
+	return ""
+}
+
+// Dangling - Returns true when the name is an alias, but no addresses were obtained for it
+func (o *AmassOutput) Dangling() bool {
+	return len(o.Addresses) == 0 && o.CNAMETarget() != ""
+}
+
+// TakeoverService - Returns the hosted service that could let a third party take over the name,
+// since the name remains an alias of the service after the resource there was removed
+func (o *AmassOutput) TakeoverService() string {
+	if !o.Dangling() {
+		return ""
+	}
+
+	target := o.CNAMETarget()
+	for _, s := range takeoverServices {
+		if strings.HasSuffix(target, s.suffix) {
+			return s.service
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimingProfile - Preset query rates and concurrency, similar to the nmap -T levels
+type TimingProfile struct {
+	Name string
+
+	// The time between the requests handled by the services
+	Frequency time.Duration
+
+	// The most DNS queries in flight at once (zero uses the open file limit)
+	MaxDNSConcurrency int
+
+	// The most random delay inserted before each DNS query
+	Jitter time.Duration
+
+	// The time waited between starting the rate limited data source queries
+	SourceDelay time.Duration
+
+	// The most rate limited data source queries running at once
+	MaxSourceConcurrency int
+}
+
+// TimingProfiles - The presets that can be selected by name, from slowest to fastest
+var TimingProfiles = []*TimingProfile{
+	{
+		Name:                 "paranoid",
+		Frequency:            5 * time.Second,
+		MaxDNSConcurrency:    1,
+		Jitter:               5 * time.Second,
+		SourceDelay:          30 * time.Second,
+		MaxSourceConcurrency: 1,
+	},
+	{
+		Name:                 "sneaky",
+		Frequency:            time.Second,
+		MaxDNSConcurrency:    2,
+		Jitter:               time.Second,
+		SourceDelay:          10 * time.Second,
+		MaxSourceConcurrency: 2,
+	},
+	{
+		Name:                 "polite",
+		Frequency:            100 * time.Millisecond,
+		MaxDNSConcurrency:    10,
+		Jitter:               100 * time.Millisecond,
+		SourceDelay:          time.Second,
+		MaxSourceConcurrency: 5,
+	},
+	{
+		Name:                 "normal",
+		Frequency:            DefaultFrequency,
+		SourceDelay:          100 * time.Millisecond,
+		MaxSourceConcurrency: 20,
+	},
+	{
+		Name:                 "aggressive",
+		Frequency:            DefaultFrequency,
+		SourceDelay:          25 * time.Millisecond,
+		MaxSourceConcurrency: 50,
+	},
+}
+
+// GetTimingProfile - Returns the preset with the name, or the level from 0 (paranoid) to 4 (aggressive)
+func GetTimingProfile(name string) (*TimingProfile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for i, p := range TimingProfiles {
+		if name == p.Name || name == fmt.Sprintf("%d", i) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("Timing profile error: %s is not one of %s",
+		name, strings.Join(TimingProfileNames(), ", "))
+}
+
+// TimingProfileNames - Returns the names of the presets from slowest to fastest
+func TimingProfileNames() []string {
+	var names []string
+
+	for _, p := range TimingProfiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ApplyTimingProfile - Sets the rates and concurrency of the enumeration to the named preset
+func (e *Enumeration) ApplyTimingProfile(name string) error {
+	p, err := GetTimingProfile(name)
+	if err != nil {
+		return err
+	}
+
+	e.Frequency = p.Frequency
+	e.MaxDNSConcurrency = p.MaxDNSConcurrency
+	e.Jitter = p.Jitter
+	e.SourceDelay = p.SourceDelay
+	e.MaxSourceConcurrency = p.MaxSourceConcurrency
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTimingProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"paranoid", "paranoid", false},
+		{" Polite ", "polite", false},
+		{"0", "paranoid", false},
+		{"4", "aggressive", false},
+		{"5", "", true},
+		{"insane", "", true},
+	}
+
+	for _, test := range tests {
+		p, err := GetTimingProfile(test.name)
+		if test.err {
+			if err == nil {
+				t.Errorf("GetTimingProfile(%q) did not return an error", test.name)
+			}
+			continue
+		}
+		if err != nil || p.Name != test.expected {
+			t.Errorf("GetTimingProfile(%q) returned %v, %v, expected %s", test.name, p, err, test.expected)
+		}
+	}
+}
+
+func TestTimingProfilesOrder(t *testing.T) {
+	for i := 1; i < len(TimingProfiles); i++ {
+		prev, cur := TimingProfiles[i-1], TimingProfiles[i]
+
+		if cur.Frequency > prev.Frequency || cur.SourceDelay > prev.SourceDelay {
+			t.Errorf("The %s profile is slower than the %s profile", cur.Name, prev.Name)
+		}
+		if cur.Frequency < DefaultFrequency {
+			t.Errorf("The %s profile has a frequency below the minimum", cur.Name)
+		}
+	}
+}
+
+func TestApplyTimingProfile(t *testing.T) {
+	e := NewEnumeration()
+
+	if err := e.ApplyTimingProfile("sneaky"); err != nil {
+		t.Fatalf("ApplyTimingProfile returned an error: %v", err)
+	}
+	if e.Frequency != time.Second || e.MaxDNSConcurrency != 2 || e.MaxSourceConcurrency != 2 {
+		t.Errorf("ApplyTimingProfile did not set the sneaky rates: %v %d %d",
+			e.Frequency, e.MaxDNSConcurrency, e.MaxSourceConcurrency)
+	}
+	e.AddDomain("example.com")
+	if _, err := e.generateAmassConfig(); err != nil {
+		t.Errorf("The sneaky profile produced an invalid configuration: %v", err)
+	}
+
+	if err := e.ApplyTimingProfile("bogus"); err == nil {
+		t.Errorf("ApplyTimingProfile accepted an unknown profile")
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The server-side encryption settings of the uploaded artifacts
+const (
+	// EncryptAES256 - The keys managed by the storage service
+	EncryptAES256 = "aes256"
+	// EncryptKMS - The key held by the key management service of the cloud provider
+	EncryptKMS = "kms"
+)
+
+// UploadConfig - Describes the bucket receiving the output directory of the run. The
+// credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// for S3, and from the HMAC keys in GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET for GCS
+type UploadConfig struct {
+	// The destination, as in s3://bucket/prefix or gs://bucket/prefix
+	Destination string
+
+	// The server-side encryption (EncryptAES256 or EncryptKMS), or the bucket default when empty
+	Encryption string
+
+	// The KMS key used by EncryptKMS, which is required for GCS
+	KMSKey string
+
+	// The region of the S3 bucket (default: AWS_REGION or us-east-1)
+	Region string
+
+	// Replaces the address of the storage service, such as for MinIO (optional)
+	Endpoint string
+}
+
+// objectStore - Sends objects to S3 or to the interoperable XML API of GCS, signing the
+// requests with the AWS Signature Version 4
+type objectStore struct {
+	client    *http.Client
+	base      *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	// The encryption headers sent with each object
+	headers map[string]string
+}
+
+// Check - Returns an error when the destination, encryption or credentials cannot be used,
+// so the mistakes are found before the enumeration instead of at completion
+func (c *UploadConfig) Check() error {
+	_, _, err := newObjectStore(c)
+	return err
+}
+
+// UploadArtifacts - Uploads every file within the directory to the destination, keeping the
+// relative paths of the files below the prefix
+func UploadArtifacts(dir string, config *UploadConfig) error {
+	store, prefix, err := newObjectStore(config)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("Upload error: %v", err)
+		}
+
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		if err := store.put(key, data, time.Now()); err != nil {
+			return fmt.Errorf("Upload error: %s: %v", key, err)
+		}
+		return nil
+	})
+}
+
+func newObjectStore(config *UploadConfig) (*objectStore, string, error) {
+	dest, err := url.Parse(config.Destination)
+	if err != nil || dest.Host == "" {
+		return nil, "", fmt.Errorf("Upload error: The destination %s is not a bucket URL", config.Destination)
+	}
+	prefix := strings.Trim(dest.Path, "/")
+
+	store := &objectStore{
+		client:  &http.Client{Timeout: 5 * time.Minute},
+		headers: make(map[string]string),
+	}
+
+	var endpoint string
+	switch dest.Scheme {
+	case "s3":
+		store.region = config.Region
+		if store.region == "" {
+			store.region = os.Getenv("AWS_REGION")
+		}
+		if store.region == "" {
+			store.region = "us-east-1"
+		}
+		store.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		store.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		store.token = os.Getenv("AWS_SESSION_TOKEN")
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", dest.Host, store.region)
+
+		switch config.Encryption {
+		case EncryptAES256:
+			store.headers["x-amz-server-side-encryption"] = "AES256"
+		case EncryptKMS:
+			store.headers["x-amz-server-side-encryption"] = "aws:kms"
+			if config.KMSKey != "" {
+				store.headers["x-amz-server-side-encryption-aws-kms-key-id"] = config.KMSKey
+			}
+		}
+	case "gs":
+		store.region = "auto"
+		store.accessKey = os.Getenv("GCS_HMAC_ACCESS_ID")
+		store.secretKey = os.Getenv("GCS_HMAC_SECRET")
+		endpoint = "https://storage.googleapis.com/" + dest.Host
+
+		// GCS always encrypts the objects with the keys it manages
+		if config.Encryption == EncryptKMS {
+			if config.KMSKey == "" {
+				return nil, "", fmt.Errorf("Upload error: GCS requires the name of the KMS key")
+			}
+			store.headers["x-goog-encryption-kms-key-name"] = config.KMSKey
+		}
+	default:
+		return nil, "", fmt.Errorf("Upload error: The scheme of %s is not s3 or gs", config.Destination)
+	}
+
+	if e := config.Encryption; e != "" && e != EncryptAES256 && e != EncryptKMS {
+		return nil, "", fmt.Errorf("Upload error: The encryption %s is not %s or %s", e, EncryptAES256, EncryptKMS)
+	}
+	if store.accessKey == "" || store.secretKey == "" {
+		return nil, "", fmt.Errorf("Upload error: The credentials for %s were not provided", dest.Scheme)
+	}
+
+	// A custom endpoint receives the requests with the bucket in the path
+	if config.Endpoint != "" {
+		endpoint = strings.TrimSuffix(config.Endpoint, "/") + "/" + dest.Host
+	}
+	if store.base, err = url.Parse(endpoint); err != nil {
+		return nil, "", fmt.Errorf("Upload error: The endpoint %s is not valid", endpoint)
+	}
+	return store, prefix, nil
+}
+
+func (s *objectStore) put(key string, data []byte, now time.Time) error {
+	u := *s.base
+	u.Path = path.Join(u.Path, key)
+
+	req, err := http.NewRequest("PUT", u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	ctype := mime.TypeByExtension(path.Ext(key))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", ctype)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+	s.sign(req, data, now)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("The storage service returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// sign - Adds the AWS Signature Version 4 of the request, covering the host, the payload
+// and every header set on the request
+func (s *objectStore) sign(req *http.Request, payload []byte, now time.Time) {
+	amzdate := now.UTC().Format("20060102T150405Z")
+	date := amzdate[:8]
+	hash := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(hash[:])
+
+	req.Header.Set("X-Amz-Date", amzdate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonHeaders string
+	for _, k := range names {
+		canonHeaders += k + ":" + headers[k] + "\n"
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsEscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonHeaders,
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	chash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzdate + "\n" + scope + "\n" + hex.EncodeToString(chash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscapePath - Escapes every byte of the path other than the unreserved characters and slashes
+func awsEscapePath(p string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	if buf.Len() == 0 {
+		return "/"
+	}
+	return buf.String()
+}
@@ -4,6 +4,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -69,3 +71,13 @@ func CopyString(src string) string {
 	copy(str, src)
 	return string(str)
 }
+
+// NewUUID - Returns a random (version 4) UUID string
+func NewUUID() string {
+	b := make([]byte, 16)
+
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -5,10 +5,16 @@ package utils
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"math/big"
+	"mime"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -19,6 +25,38 @@ const (
 	ACCEPT_LANG = "en-US,en;q=0.8"
 )
 
+// The kinds of reserved networks that addresses can belong to
+const (
+	ReservedPrivate   = "private"
+	ReservedLoopback  = "loopback"
+	ReservedLinkLocal = "link-local"
+	ReservedShared    = "shared"
+)
+
+// The address ranges that cannot be reached from the Internet
+var reservedNetworks = map[string][]string{
+	ReservedPrivate:   {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+	ReservedLoopback:  {"127.0.0.0/8", "::1/128"},
+	ReservedLinkLocal: {"169.254.0.0/16", "fe80::/10"},
+	// The shared address space used behind carrier-grade NAT (RFC 6598)
+	ReservedShared: {"100.64.0.0/10"},
+}
+
+// MaxResponseSize - The largest response body, in bytes, that is read from a web server
+const MaxResponseSize = 32 << 20
+
+// ErrResponseTooLarge - Returned when the response body exceeds MaxResponseSize
+var ErrResponseTooLarge = fmt.Errorf("The response body exceeded %d bytes", MaxResponseSize)
+
+// The media types of the response bodies that names can be extracted from
+var textContentTypes = []string{
+	"application/javascript",
+	"application/json",
+	"application/x-javascript",
+	"application/xhtml+xml",
+	"application/xml",
+}
+
 type DialCtx func(ctx context.Context, network, addr string) (net.Conn, error)
 
 var (
@@ -35,19 +73,25 @@ func SetDialContext(dc DialCtx) {
 	DialContext = dc
 }
 
-func GetWebPage(url string, hvals map[string]string) (string, error) {
+func GetWebPage(u string, hvals map[string]string) (string, error) {
+	return GetWebPageProxy(u, hvals, nil)
+}
+
+// GetWebPageProxy - Performs the request through the proxy, or directly when it is nil
+func GetWebPageProxy(u string, hvals map[string]string, proxy *url.URL) (string, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
+		Transport: WrapTransport(&http.Transport{
+			Proxy:                 http.ProxyURL(proxy),
 			DialContext:           DialContext,
 			MaxIdleConns:          200,
 			IdleConnTimeout:       90 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 5 * time.Second,
-		},
+		}),
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
 		return "", err
 	}
@@ -68,11 +112,136 @@ func GetWebPage(url string, hvals map[string]string) (string, error) {
 		return "", errors.New(resp.Status)
 	}
 
-	in, err := ioutil.ReadAll(resp.Body)
+	in, err := ReadResponseBody(resp)
 	resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
 	return string(in), nil
 }
 
+// ReadResponseBody - Reads the body of the response after checking that it holds text,
+// and returns an error instead of reading more than MaxResponseSize bytes
+func ReadResponseBody(resp *http.Response) ([]byte, error) {
+	if ct := resp.Header.Get("Content-Type"); !TextContentType(ct) {
+		return nil, fmt.Errorf("The response has the unexpected content type %s", ct)
+	}
+	if resp.ContentLength > MaxResponseSize {
+		return nil, ErrResponseTooLarge
+	}
+
+	in, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(in) > MaxResponseSize {
+		return nil, ErrResponseTooLarge
+	}
+	return in, nil
+}
+
+// TextContentType - Returns true when the Content-Type header value is missing or
+// names a textual media type, such as HTML, JSON or JavaScript
+func TextContentType(ct string) bool {
+	if strings.TrimSpace(ct) == "" {
+		return true
+	}
+
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mt, "text/") || strings.HasSuffix(mt, "+json") || strings.HasSuffix(mt, "+xml") {
+		return true
+	}
+	for _, t := range textContentTypes {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProxy - Parses the URL of a proxy using the http, https or socks5 scheme,
+// such as socks5://127.0.0.1:9050 for a local Tor client
+func ParseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("The proxy %s is not a valid URL: %v", s, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("The proxy %s must use the http, https or socks5 scheme", s)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("The proxy %s does not provide a host", s)
+	}
+	return u, nil
+}
+
+// RedactProxy - Returns the URL of the proxy without the credentials it contains
+func RedactProxy(proxy *url.URL) string {
+	if proxy == nil {
+		return "direct"
+	}
+
+	u := *proxy
+	u.User = nil
+	return u.String()
+}
+
+// GetWebHeaders - Requests the URL without following redirects and returns the response headers
+func GetWebHeaders(url string) (http.Header, error) {
+	headers, _, err := getWebResponse(url, false)
+	return headers, err
+}
+
+// GetWebResponse - Requests the URL without following redirects and returns the response
+// headers along with the page, which is empty when the response does not hold text
+func GetWebResponse(url string) (http.Header, string, error) {
+	return getWebResponse(url, true)
+}
+
+func getWebResponse(url string, body bool) (http.Header, string, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: WrapTransport(&http.Transport{
+			DialContext:         DialContext,
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			TLSHandshakeTimeout: 5 * time.Second,
+		}),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req.Header.Add("User-Agent", USER_AGENT)
+	req.Header.Add("Accept", ACCEPT)
+	req.Header.Add("Accept-Language", ACCEPT_LANG)
+	// Ask for the CORS headers that are only returned for cross-origin requests
+	req.Header.Add("Origin", "https://"+req.URL.Hostname())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var page string
+	if body {
+		if in, err := ReadResponseBody(resp); err == nil {
+			page = string(in)
+		}
+	}
+	return resp.Header, page, nil
+}
+
 // Obtained/modified the next two functions from the following:
 // https://gist.github.com/kotakanbe/d3059af990252ba89a82
 func NetHosts(cidr *net.IPNet) []net.IP {
@@ -100,6 +269,97 @@ func RangeHosts(start, end net.IP) []net.IP {
 	return ips
 }
 
+// HostIterator - Steps through the hosts within a netblock, so the hosts of a large
+// netblock do not need to be held in memory together
+type HostIterator struct {
+	next, last *big.Int
+	size       int
+}
+
+// NewHostIterator - Returns an iterator over the hosts within the netblock, which excludes
+// the network and broadcast addresses unless the netblock holds no more than two addresses
+func NewHostIterator(cidr *net.IPNet) *HostIterator {
+	first, last, size := hostBounds(cidr)
+
+	return &HostIterator{next: first, last: last, size: size}
+}
+
+// Next - Returns the next host, or false once every host has been returned
+func (it *HostIterator) Next() (net.IP, bool) {
+	if it.next.Cmp(it.last) > 0 {
+		return nil, false
+	}
+
+	ip := intToIP(it.next, it.size)
+	it.next = new(big.Int).Add(it.next, big.NewInt(1))
+	return ip, true
+}
+
+// NetblockHost - Returns true when the address is one of the hosts that the HostIterator
+// steps through for the netblock, so its network and broadcast addresses are not included
+func NetblockHost(cidr *net.IPNet, ip net.IP) bool {
+	if ip == nil || !cidr.Contains(ip) {
+		return false
+	}
+
+	first, last, size := hostBounds(cidr)
+	addr := ip.To4()
+	if size == net.IPv6len {
+		addr = ip.To16()
+	}
+	n := new(big.Int).SetBytes(addr)
+	return n.Cmp(first) >= 0 && n.Cmp(last) <= 0
+}
+
+// NetHostsSample - Returns at most max of the hosts within the netblock, spread evenly
+// across it, without listing every host of the netblock
+func NetHostsSample(cidr *net.IPNet, max int) []net.IP {
+	first, last, size := hostBounds(cidr)
+	if max <= 0 || first.Cmp(last) > 0 {
+		return nil
+	}
+
+	count := new(big.Int).Sub(last, first)
+	count.Add(count, big.NewInt(1))
+	step := big.NewInt(1)
+	if count.Cmp(big.NewInt(int64(max))) > 0 {
+		step.Div(count, big.NewInt(int64(max)))
+	}
+
+	var hosts []net.IP
+	for n := new(big.Int).Set(first); n.Cmp(last) <= 0 && len(hosts) < max; n.Add(n, step) {
+		hosts = append(hosts, intToIP(n, size))
+	}
+	return hosts
+}
+
+// hostBounds - Returns the first and last hosts of the netblock, and the length of its addresses
+func hostBounds(cidr *net.IPNet) (*big.Int, *big.Int, int) {
+	ip := cidr.IP.To4()
+	if ip == nil {
+		ip = cidr.IP.To16()
+	}
+	ones, bits := cidr.Mask.Size()
+
+	first := new(big.Int).SetBytes(ip.Mask(cidr.Mask))
+	last := new(big.Int).Add(first, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+	last.Sub(last, big.NewInt(1))
+	// The network and broadcast addresses are only hosts of the smallest netblocks
+	if bits-ones > 1 {
+		first.Add(first, big.NewInt(1))
+		last.Sub(last, big.NewInt(1))
+	}
+	return first, last, len(ip)
+}
+
+func intToIP(n *big.Int, size int) net.IP {
+	b := n.Bytes()
+
+	ip := make(net.IP, size)
+	copy(ip[size-len(b):], b)
+	return net.ParseIP(ip.String())
+}
+
 func addrInc(ip net.IP) {
 	for j := len(ip) - 1; j >= 0; j-- {
 		ip[j]++
@@ -155,6 +415,24 @@ func CIDRSubset(cidr *net.IPNet, addr string, num int) []net.IP {
 	return RangeHosts(first, last)
 }
 
+// ReservedAddress - Returns the kind and range of the reserved network containing the
+// address, or an empty kind when the address can be reached from the Internet
+func ReservedAddress(addr string) (string, *net.IPNet) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return "", nil
+	}
+
+	for kind, cidrs := range reservedNetworks {
+		for _, cidr := range cidrs {
+			if _, ipnet, err := net.ParseCIDR(cidr); err == nil && ipnet.Contains(ip) {
+				return kind, ipnet
+			}
+		}
+	}
+	return "", nil
+}
+
 func ReverseIP(ip string) string {
 	var reversed []string
 
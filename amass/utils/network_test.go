@@ -4,8 +4,12 @@
 package utils
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -33,6 +37,68 @@ func TestAmassNetHosts(t *testing.T) {
 	}
 }
 
+func TestAmassHostIterator(t *testing.T) {
+	_, ipnet, _ := net.ParseCIDR("72.237.4.0/24")
+
+	var num int
+	var last net.IP
+	for it := NewHostIterator(ipnet); ; num++ {
+		ip, ok := it.Next()
+		if !ok {
+			break
+		}
+		last = ip
+	}
+	if num != 254 || last.String() != "72.237.4.254" {
+		t.Errorf("The iterator returned %d hosts ending with %s instead of %d", num, last, 254)
+	}
+
+	_, ipnet, _ = net.ParseCIDR("2001:db8::/127")
+	it := NewHostIterator(ipnet)
+	if ip, _ := it.Next(); ip.String() != "2001:db8::" {
+		t.Errorf("The first host of the IPv6 netblock was %s", ip)
+	}
+}
+
+func TestAmassNetblockHost(t *testing.T) {
+	tests := []struct {
+		cidr string
+		addr string
+		want bool
+	}{
+		{"192.0.2.0/30", "192.0.2.1", true},
+		{"192.0.2.0/30", "192.0.2.0", false},
+		{"192.0.2.0/30", "192.0.2.3", false},
+		{"192.0.2.0/30", "192.0.2.4", false},
+		{"192.0.2.0/31", "192.0.2.0", true},
+		{"2001:db8::/126", "2001:db8::3", false},
+		{"2001:db8::/126", "2001:db8::2", true},
+		{"2001:db8::/126", "192.0.2.1", false},
+	}
+
+	for _, test := range tests {
+		_, ipnet, _ := net.ParseCIDR(test.cidr)
+
+		if got := NetblockHost(ipnet, net.ParseIP(test.addr)); got != test.want {
+			t.Errorf("NetblockHost(%s, %s) returned %t, expected %t", test.cidr, test.addr, got, test.want)
+		}
+	}
+}
+
+func TestAmassNetHostsSample(t *testing.T) {
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/8")
+
+	hosts := NetHostsSample(ipnet, 8)
+	if len(hosts) != 8 || hosts[0].String() != "10.0.0.1" || hosts[1].String() != "10.32.0.0" {
+		t.Errorf("NetHostsSample returned %v", hosts)
+	}
+
+	_, ipnet, _ = net.ParseCIDR("192.0.2.0/30")
+	if hosts := NetHostsSample(ipnet, 8); len(hosts) != 2 {
+		t.Errorf("NetHostsSample returned %v for the small netblock", hosts)
+	}
+}
+
 func TestAmassCIDRSubset(t *testing.T) {
 	_, ipnet, err := net.ParseCIDR(testCIDR)
 	if err != nil {
@@ -62,3 +128,82 @@ func TestAmassCIDRSubset(t *testing.T) {
 		t.Error("CIDRSubset returned an incorrect number of elements")
 	}
 }
+
+func TestAmassReservedAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		kind string
+	}{
+		{"10.1.2.3", ReservedPrivate},
+		{"172.20.0.1", ReservedPrivate},
+		{"172.32.0.1", ""},
+		{"192.168.1.55", ReservedPrivate},
+		{"127.0.0.1", ReservedLoopback},
+		{"169.254.169.254", ReservedLinkLocal},
+		{"fd12:3456::1", ReservedPrivate},
+		{"fe80::1", ReservedLinkLocal},
+		{"::1", ReservedLoopback},
+		{"100.64.0.1", ReservedShared},
+		{"100.127.255.254", ReservedShared},
+		{"100.128.0.1", ""},
+		{"8.8.8.8", ""},
+		{"2001:4860:4860::8888", ""},
+		{"not an address", ""},
+	}
+
+	for _, test := range tests {
+		if kind, _ := ReservedAddress(test.addr); kind != test.kind {
+			t.Errorf("ReservedAddress returned %q for %s instead of %q", kind, test.addr, test.kind)
+		}
+	}
+}
+
+func TestAmassReadResponseBody(t *testing.T) {
+	tests := []struct {
+		ct     string
+		size   int
+		length int64
+		ok     bool
+	}{
+		{"text/html; charset=utf-8", 1024, -1, true},
+		{"application/json", 1024, -1, true},
+		{"application/vnd.api+json", 1024, -1, true},
+		{"", 1024, -1, true},
+		{"image/png", 1024, -1, false},
+		{"application/octet-stream", 1024, -1, false},
+		{"text/plain", MaxResponseSize, -1, true},
+		{"text/plain", MaxResponseSize + 1, -1, false},
+		{"text/plain", 1024, MaxResponseSize + 1, false},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{
+			Header:        http.Header{},
+			Body:          ioutil.NopCloser(bytes.NewReader(bytes.Repeat([]byte("a"), test.size))),
+			ContentLength: test.length,
+		}
+		if test.ct != "" {
+			resp.Header.Set("Content-Type", test.ct)
+		}
+
+		body, err := ReadResponseBody(resp)
+		if test.ok && (err != nil || len(body) != test.size) {
+			t.Errorf("The %d byte %q response was not read: %v", test.size, test.ct, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("The %d byte %q response was accepted", test.size, test.ct)
+		}
+	}
+}
+
+func TestAmassTextContentType(t *testing.T) {
+	for _, ct := range []string{"text/javascript", "application/xhtml+xml", "application/x-javascript"} {
+		if !TextContentType(ct) {
+			t.Errorf("%s was not accepted as a textual content type", ct)
+		}
+	}
+	for _, ct := range []string{"video/mp4", "application/zip", strings.Repeat(";", 3)} {
+		if TextContentType(ct) {
+			t.Errorf("%s was accepted as a textual content type", ct)
+		}
+	}
+}
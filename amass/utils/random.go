@@ -0,0 +1,40 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// The source of pseudo-random numbers shared by the enumeration
+var (
+	rngLock sync.Mutex
+	rng     = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// SetRandomSeed - Makes the pseudo-random numbers reproducible between runs
+func SetRandomSeed(seed int64) {
+	rngLock.Lock()
+	defer rngLock.Unlock()
+
+	rng = rand.New(rand.NewSource(seed))
+}
+
+// RandomInt - Returns a non-negative pseudo-random int
+func RandomInt() int {
+	rngLock.Lock()
+	defer rngLock.Unlock()
+
+	return rng.Int()
+}
+
+// RandomShuffle - Pseudo-randomizes the order of n elements using the swap function
+func RandomShuffle(n int, swap func(i, j int)) {
+	rngLock.Lock()
+	defer rngLock.Unlock()
+
+	rng.Shuffle(n, swap)
+}
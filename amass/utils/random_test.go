@@ -0,0 +1,31 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import "testing"
+
+func TestRandomSeed(t *testing.T) {
+	sequence := func() []int {
+		var nums []int
+
+		SetRandomSeed(42)
+		for i := 0; i < 5; i++ {
+			nums = append(nums, RandomInt())
+		}
+
+		order := []int{0, 1, 2, 3, 4, 5, 6, 7}
+		RandomShuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+		return append(nums, order...)
+	}
+
+	first := sequence()
+	second := sequence()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("The same seed produced %v and %v", first, second)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const (
+	InteractionHTTP = "http"
+	InteractionDNS  = "dns"
+)
+
+// ErrNotRecorded - Returned when a replayed run makes a request that was not recorded
+var ErrNotRecorded = errors.New("The interaction was not recorded")
+
+// Interaction - An HTTP or DNS exchange saved while recording a run
+type Interaction struct {
+	Kind   string      `json:"kind"`
+	Key    string      `json:"key"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body"`
+}
+
+var (
+	recordLock sync.Mutex
+	recordTo   io.Writer
+	replayFrom map[string][]*Interaction
+)
+
+// StartRecording - Writes every HTTP and DNS interaction that follows as JSON lines
+func StartRecording(w io.Writer) {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	recordTo = w
+}
+
+// StartReplay - Answers the HTTP and DNS requests that follow from the interactions
+// read, instead of sending them
+func StartReplay(r io.Reader) error {
+	replay := make(map[string][]*Interaction)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var i Interaction
+		if err := json.Unmarshal(line, &i); err != nil {
+			return fmt.Errorf("Replay error: Failed to decode an interaction: %v", err)
+		}
+		replay[i.Kind+" "+i.Key] = append(replay[i.Kind+" "+i.Key], &i)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Replay error: Failed to read the recording: %v", err)
+	}
+
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	replayFrom = replay
+	return nil
+}
+
+// Recording - Returns true when interactions are being saved
+func Recording() bool {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	return recordTo != nil
+}
+
+// Replaying - Returns true when requests are answered from a recording
+func Replaying() bool {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	return replayFrom != nil
+}
+
+// RecordInteraction - Saves the interaction when a run is being recorded
+func RecordInteraction(i *Interaction) {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	if recordTo == nil {
+		return
+	}
+
+	if data, err := json.Marshal(i); err == nil {
+		recordTo.Write(append(data, '\n'))
+	}
+}
+
+// ReplayInteraction - Returns the recorded interactions with the key in the order they
+// were saved, and repeats the last one after the others have been used
+func ReplayInteraction(kind, key string) (*Interaction, bool) {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	list := replayFrom[kind+" "+key]
+	if len(list) == 0 {
+		return nil, false
+	}
+
+	i := list[0]
+	if len(list) > 1 {
+		replayFrom[kind+" "+key] = list[1:]
+	}
+	return i, true
+}
+
+// recordingTransport - Saves or replays the HTTP requests sent through it
+type recordingTransport struct {
+	base http.RoundTripper
+}
+
+// WrapTransport - Returns a transport that records or replays the HTTP requests when
+// the run is being recorded or replayed
+func WrapTransport(base http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{base: base}
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+
+	if Replaying() {
+		i, found := ReplayInteraction(InteractionHTTP, key)
+		if !found {
+			return nil, ErrNotRecorded
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode:    i.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Header,
+			Body:          ioutil.NopCloser(bytes.NewReader(i.Body)),
+			ContentLength: int64(len(i.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil || !Recording() {
+		return resp, err
+	}
+
+	// One byte beyond the limit lets ReadResponseBody reject the oversized response
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	RecordInteraction(&Interaction{
+		Kind:   InteractionHTTP,
+		Key:    key,
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   body,
+	})
+	return resp, nil
+}
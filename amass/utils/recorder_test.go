@@ -0,0 +1,57 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecordReplayHTTP(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("www.example.com"))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	StartRecording(&buf)
+	client := &http.Client{Transport: WrapTransport(http.DefaultTransport)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("The recorded request failed: %v", err)
+	}
+	resp.Body.Close()
+	StartRecording(nil)
+
+	if err := StartReplay(&buf); err != nil {
+		t.Fatalf("StartReplay returned an error: %v", err)
+	}
+	defer func() {
+		recordLock.Lock()
+		replayFrom = nil
+		recordLock.Unlock()
+	}()
+
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("The replayed request failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "www.example.com" {
+		t.Errorf("The replayed body was %q", string(body))
+	}
+	if hits != 1 {
+		t.Errorf("The server received %d requests, expected only the recorded one", hits)
+	}
+	if _, err := client.Get(srv.URL + "/other"); err == nil {
+		t.Errorf("A request missing from the recording did not fail")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// The reasons that names are rejected by SanitizeName
+const (
+	RejectEmpty   = "empty"
+	RejectAddress = "IP address instead of a name"
+	RejectSyntax  = "invalid label syntax"
+	RejectLength  = "name too long"
+	RejectSingle  = "single label name"
+)
+
+// A label may hold an underscore for service names, as SUBRE does
+var labelRE = regexp.MustCompile("^([_a-z0-9]|[_a-z0-9][_a-z0-9-]{0,61}[a-z0-9])$")
+
+// SanitizeName - Normalizes a name taken from an external list, stripping URL schemes,
+// paths, ports, wildcard labels and trailing dots. An empty name and the reason are
+// returned when the result is not a valid DNS name
+func SanitizeName(raw string) (string, string) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+
+	if i := strings.Index(name, "://"); i != -1 {
+		name = name[i+3:]
+	}
+	if i := strings.IndexAny(name, "/?#"); i != -1 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "@"); i != -1 {
+		name = name[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(name); err == nil {
+		name = host
+	}
+	name = strings.Trim(name, "[]")
+	if name == "" {
+		return "", RejectEmpty
+	}
+	if net.ParseIP(name) != nil {
+		return "", RejectAddress
+	}
+
+	name = strings.TrimSuffix(name, ".")
+	for strings.HasPrefix(name, "*.") {
+		name = name[2:]
+	}
+	name = strings.TrimPrefix(name, ".")
+	if len(name) > 253 {
+		return "", RejectLength
+	}
+
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return "", RejectSingle
+	}
+	for _, label := range labels {
+		if !labelRE.MatchString(label) {
+			return "", RejectSyntax
+		}
+	}
+	return name, ""
+}
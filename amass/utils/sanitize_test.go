@@ -0,0 +1,37 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package utils
+
+import "testing"
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		raw    string
+		name   string
+		reason string
+	}{
+		{"www.owasp.org", "www.owasp.org", ""},
+		{"  WWW.OWASP.ORG.  ", "www.owasp.org", ""},
+		{"https://user@www.owasp.org:8443/login?next=/", "www.owasp.org", ""},
+		{"www.owasp.org:443", "www.owasp.org", ""},
+		{"*.dev.owasp.org", "dev.owasp.org", ""},
+		{"_sip._tcp.owasp.org", "_sip._tcp.owasp.org", ""},
+		{"", "", RejectEmpty},
+		{"http://", "", RejectEmpty},
+		{"192.0.2.10", "", RejectAddress},
+		{"[2001:db8::1]:443", "", RejectAddress},
+		{"localhost", "", RejectSingle},
+		{"-bad.owasp.org", "", RejectSyntax},
+		{"bad name.owasp.org", "", RejectSyntax},
+		{"www..owasp.org", "", RejectSyntax},
+	}
+
+	for _, test := range tests {
+		name, reason := SanitizeName(test.raw)
+		if name != test.name || reason != test.reason {
+			t.Errorf("SanitizeName(%q) returned %q, %q instead of %q, %q",
+				test.raw, name, reason, test.name, test.reason)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OWASP/Amass/amass/extract"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The response headers that routinely reference other hosts of the organization
+var disclosingHeaders = []string{
+	"Content-Security-Policy",
+	"Content-Security-Policy-Report-Only",
+	"Access-Control-Allow-Origin",
+	"Location",
+	"Link",
+	"Report-To",
+}
+
+// ProbeWebHeaders - Requests the name on each web port and returns the names within
+// the root domains that are referenced by the response headers, along with the URLs that
+// responded and the analytics IDs embedded in the pages
+func ProbeWebHeaders(name string, ports []int, domains []string) ([]string, []string, map[string][]string) {
+	var names, urls []string
+	ids := make(map[string][]string)
+
+	for _, port := range ports {
+		scheme := "http"
+		if port == 443 || port == 8443 {
+			scheme = "https"
+		}
+
+		url := scheme + "://" + name
+		if (scheme == "http" && port != 80) || (scheme == "https" && port != 443) {
+			url += ":" + strconv.Itoa(port)
+		}
+
+		headers, page, err := utils.GetWebResponse(url + "/")
+		if err != nil {
+			continue
+		}
+		urls = append(urls, url+"/")
+		names = utils.UniqueAppend(names, namesFromHeaders(headers, domains)...)
+		if found := AnalyticsIDs(page); len(found) > 0 {
+			ids[url+"/"] = found
+		}
+	}
+	return names, urls, ids
+}
+
+func namesFromHeaders(headers http.Header, domains []string) []string {
+	var names []string
+
+	for _, key := range disclosingHeaders {
+		for _, value := range headers[http.CanonicalHeaderKey(key)] {
+			for _, domain := range domains {
+				names = utils.UniqueAppend(names, extract.Names(domain, value)...)
+			}
+		}
+	}
+	return names
+}
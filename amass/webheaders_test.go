@@ -0,0 +1,34 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWebHeadersNames(t *testing.T) {
+	headers := http.Header{}
+	headers.Add("Content-Security-Policy", "default-src 'self'; script-src https://static.example.com *.cdn.example.com https://other.net")
+	headers.Add("Access-Control-Allow-Origin", "https://App.Example.com")
+	headers.Add("Location", "https://login.example.com/?next=/")
+	headers.Add("Server", "internal.example.com")
+
+	names := namesFromHeaders(headers, []string{"example.com"})
+
+	expected := map[string]struct{}{
+		"static.example.com": struct{}{},
+		"cdn.example.com":    struct{}{},
+		"app.example.com":    struct{}{},
+		"login.example.com":  struct{}{},
+	}
+	if len(names) != len(expected) {
+		t.Fatalf("namesFromHeaders returned %v, expected %d names", names, len(expected))
+	}
+	for _, name := range names {
+		if _, found := expected[name]; !found {
+			t.Errorf("namesFromHeaders returned the unexpected name %s", name)
+		}
+	}
+}
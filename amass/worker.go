@@ -0,0 +1,27 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"crypto/tls"
+	"log"
+
+	"github.com/OWASP/Amass/amass/dnssrv"
+)
+
+// ServeWorker - Resolves names sent by coordinating enumerations until an error occurs.
+// The coordinators must present the token when one is provided, and connect with TLS
+// when tlsConfig is provided. Without the token or mutual TLS only the loopback interface is served
+func ServeWorker(addr string, resolvers []string, token string, tlsConfig *tls.Config, l *log.Logger) error {
+	if len(resolvers) > 0 {
+		dnssrv.SetCustomResolvers(resolvers)
+	}
+	return dnssrv.ServeWorker(addr, token, tlsConfig, l)
+}
+
+// LoadWorkerTLS - Loads the certificate, key and CA files used to secure the connections
+// between the coordinators and the workers. Providing the CA file enables mutual TLS
+func LoadWorkerTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	return dnssrv.LoadWorkerTLS(certFile, keyFile, caFile)
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/dnssrv"
+	"github.com/miekg/dns"
+)
+
+// ZoneFileSource - The data source recorded for the names imported from zone files
+const ZoneFileSource = "Zone File"
+
+// ParseZoneFile - Reads the records from a BIND zone file and returns the answers for
+// each name. The origin is used for relative names when the file does not set $ORIGIN
+func ParseZoneFile(r io.Reader, origin string) (map[string][]core.DNSAnswer, error) {
+	results := make(map[string][]core.DNSAnswer)
+
+	if origin != "" {
+		origin = dns.Fqdn(origin)
+	}
+	for t := range dns.ParseZone(r, origin, "") {
+		if t.Error != nil {
+			return results, fmt.Errorf("Zone file error: %v", t.Error)
+		}
+		if t.RR != nil {
+			addRecordAnswers(results, t.RR)
+		}
+	}
+	return results, nil
+}
+
+// ImportZoneFile - Reads a zone file obtained from the owner of the domain. The names are
+// inserted into the enumeration when it starts as authoritative data, and are not resolved
+// again. Names that are not within the root domains are ignored
+func (e *Enumeration) ImportZoneFile(r io.Reader, origin string) error {
+	results, err := ParseZoneFile(r, origin)
+
+	e.Lock()
+	defer e.Unlock()
+
+	for name, answers := range results {
+		domain := e.rootDomain(name)
+		if domain == "" {
+			continue
+		}
+
+		e.imports = append(e.imports, &core.AmassRequest{
+			Name:    name,
+			Domain:  domain,
+			Records: answers,
+			Tag:     core.AXFR,
+			Source:  ZoneFileSource,
+		})
+		e.authoritative = append(e.authoritative, name)
+	}
+	return err
+}
+
+// addRecordAnswers - Converts the record into the answers used by amass,
+// ignoring the record types that are not used
+func addRecordAnswers(results map[string][]core.DNSAnswer, rr dns.RR) {
+	msg := new(dns.Msg)
+
+	msg.Answer = append(msg.Answer, rr)
+	for _, a := range dnssrv.ExtractAnswers(msg, rr.Header().Rrtype) {
+		key := strings.ToLower(a.Name)
+
+		a.Name = key
+		a.Data = strings.TrimSpace(a.Data)
+		results[key] = append(results[key], a)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package amass
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/miekg/dns"
+)
+
+const testZoneFile = `$TTL 3600
+@	IN	SOA	ns1.example.com. hostmaster.example.com. 1 7200 900 1209600 86400
+@	IN	NS	ns1
+@	IN	MX	10 mail
+www	IN	CNAME	web
+web	IN	A	192.0.2.10
+mail	300	IN	A	192.0.2.25
+vpn.example.org.	IN	A	192.0.2.30
+`
+
+func TestZoneFileImport(t *testing.T) {
+	results, err := ParseZoneFile(strings.NewReader(testZoneFile), "example.com")
+	if err != nil {
+		t.Fatalf("ParseZoneFile returned an error: %v", err)
+	}
+
+	if a := results["www.example.com"]; len(a) != 1 || a[0].Type != int(dns.TypeCNAME) {
+		t.Errorf("The CNAME record was not parsed: %v", a)
+	}
+	if a := results["mail.example.com"]; len(a) != 1 || a[0].TTL != 300 || a[0].Data != "192.0.2.25" {
+		t.Errorf("The A record with a TTL was not parsed: %v", a)
+	}
+	if a := results["example.com"]; len(a) != 3 {
+		t.Errorf("The SOA, NS and MX records for the origin were not parsed: %v", a)
+	}
+
+	e := NewEnumeration()
+	e.AddDomain("example.com")
+	if err := e.ImportZoneFile(strings.NewReader(testZoneFile), "example.com"); err != nil {
+		t.Fatalf("ImportZoneFile returned an error: %v", err)
+	}
+	for _, req := range e.imports {
+		if req.Domain != "example.com" || req.Tag != core.AXFR || req.Source != ZoneFileSource {
+			t.Errorf("The name %s was imported as %+v", req.Name, req)
+		}
+	}
+	if len(e.authoritative) != len(e.imports) || len(e.imports) != 4 {
+		t.Errorf("Imported %d names with %d authoritative instead of 4", len(e.imports), len(e.authoritative))
+	}
+
+	if _, err := ParseZoneFile(strings.NewReader("www IN A not-an-address\n"), "example.com"); err == nil {
+		t.Errorf("ParseZoneFile did not return an error for a malformed record")
+	}
+}
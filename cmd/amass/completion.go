@@ -0,0 +1,268 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OWASP/Amass/amass"
+)
+
+var (
+	completionCommand = flag.NewFlagSet("completion", flag.ExitOnError)
+
+	completionHelp = completionCommand.Bool("h", false, "Show the program usage message")
+)
+
+// The shells that completion scripts can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// The flags that are provided data source names
+var sourceNameFlags = map[string]struct{}{
+	"exclude": {},
+}
+
+// The kinds of values that are completed for the flags
+const (
+	valueNone = iota
+	valueAny
+	valueFile
+	valueDir
+	valueSource
+)
+
+// completionFlag - A flag of a subcommand as it is described to the shells
+type completionFlag struct {
+	Name  string
+	Usage string
+	Kind  int
+}
+
+// runCompletionCommand - Prints the completion script for the shell requested
+func runCompletionCommand(args []string) {
+	completionCommand.Parse(args)
+
+	if *completionHelp || completionCommand.NArg() != 1 {
+		fmt.Printf("Usage: %s completion <%s>\n", filepath.Base(os.Args[0]), strings.Join(completionShells, "|"))
+		completionCommand.PrintDefaults()
+		return
+	}
+
+	prog := filepath.Base(os.Args[0])
+	switch shell := completionCommand.Arg(0); shell {
+	case "bash":
+		WriteBashCompletion(os.Stdout, prog)
+	case "zsh":
+		WriteZshCompletion(os.Stdout, prog)
+	case "fish":
+		WriteFishCompletion(os.Stdout, prog)
+	default:
+		r.Printf("Completion scripts are not available for the %s shell\n", shell)
+	}
+}
+
+// completionFlags - Returns the flags defined for the subcommand, sorted by name
+func completionFlags(cmd *command) []completionFlag {
+	var flags []completionFlag
+
+	cmd.flags.VisitAll(func(f *flag.Flag) {
+		cf := completionFlag{Name: f.Name, Usage: f.Usage, Kind: valueAny}
+
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+			cf.Kind = valueNone
+		} else if _, found := sourceNameFlags[f.Name]; found {
+			cf.Kind = valueSource
+		} else if strings.HasPrefix(f.Usage, "Path to a directory") {
+			cf.Kind = valueDir
+		} else if strings.HasPrefix(f.Usage, "Path to") || strings.Contains(f.Usage, " file") {
+			cf.Kind = valueFile
+		}
+		flags = append(flags, cf)
+	})
+	return flags
+}
+
+// completionSources - Returns the names of the registered data sources
+func completionSources() []string {
+	var names []string
+
+	for _, src := range amass.NewEnumeration().Sources() {
+		names = append(names, src.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func flagsOfKind(flags []completionFlag, kind int) []string {
+	var names []string
+
+	for _, f := range flags {
+		if f.Kind == kind {
+			names = append(names, "-"+f.Name)
+		}
+	}
+	return names
+}
+
+// singleQuote - Quotes the string for the POSIX shells
+func singleQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// WriteBashCompletion - Writes the bash completion script for the program
+func WriteBashCompletion(w io.Writer, prog string) {
+	fn := "_" + strings.Replace(prog, ".", "_", -1)
+
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s, generated by '%s completion bash'\n\n", prog, prog)
+	fmt.Fprintf(w, "%s_sources=%s\n\n", fn, singleQuote(strings.Join(completionSources(), "\n")))
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(w, "\tlocal prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "\tCOMPREPLY=()\n\n")
+	fmt.Fprintf(w, "\tif [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", singleQuote(strings.Join(names, " ")))
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n\n")
+	fmt.Fprintf(w, "\tcase \"${COMP_WORDS[1]}\" in\n")
+
+	for _, cmd := range commands {
+		flags := completionFlags(cmd)
+
+		fmt.Fprintf(w, "\t%s)\n", cmd.name)
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, "\t\tif [[ \"$cur\" != -* ]]; then\n")
+			fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", singleQuote(strings.Join(completionShells, " ")))
+			fmt.Fprintf(w, "\t\t\treturn\n\t\tfi\n")
+		}
+
+		fmt.Fprintf(w, "\t\tcase \"$prev\" in\n")
+		if f := flagsOfKind(flags, valueFile); len(f) > 0 {
+			fmt.Fprintf(w, "\t\t%s)\n\t\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\t\treturn\n\t\t\t;;\n", strings.Join(f, "|"))
+		}
+		if f := flagsOfKind(flags, valueDir); len(f) > 0 {
+			fmt.Fprintf(w, "\t\t%s)\n\t\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n\t\t\treturn\n\t\t\t;;\n", strings.Join(f, "|"))
+		}
+		if f := flagsOfKind(flags, valueSource); len(f) > 0 {
+			fmt.Fprintf(w, "\t\t%s)\n\t\t\tlocal IFS=$'\\n'\n", strings.Join(f, "|"))
+			fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W \"$%s_sources\" -- \"$cur\"))\n", fn)
+			fmt.Fprintf(w, "\t\t\tCOMPREPLY=(\"${COMPREPLY[@]// /\\\\ }\")\n\t\t\treturn\n\t\t\t;;\n")
+		}
+		if f := flagsOfKind(flags, valueAny); len(f) > 0 {
+			fmt.Fprintf(w, "\t\t%s)\n\t\t\treturn\n\t\t\t;;\n", strings.Join(f, "|"))
+		}
+		fmt.Fprintf(w, "\t\tesac\n")
+
+		var all []string
+		for _, f := range flags {
+			all = append(all, "-"+f.Name)
+		}
+		fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n\t\t;;\n", singleQuote(strings.Join(all, " ")))
+	}
+	fmt.Fprintf(w, "\tesac\n}\n\ncomplete -F %s %s\n", fn, prog)
+}
+
+// zshDescription - Escapes the usage message for an option description of _arguments
+func zshDescription(s string) string {
+	replacer := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`)
+	return replacer.Replace(s)
+}
+
+// WriteZshCompletion - Writes the zsh completion script for the program
+func WriteZshCompletion(w io.Writer, prog string) {
+	fn := "_" + strings.Replace(prog, ".", "_", -1)
+
+	var sources []string
+	for _, name := range completionSources() {
+		sources = append(sources, strings.Replace(name, " ", `\ `, -1))
+	}
+
+	fmt.Fprintf(w, "#compdef %s\n\n# zsh completion for %s, generated by '%s completion zsh'\n\n", prog, prog, prog)
+	fmt.Fprintf(w, "%s() {\n\tlocal -a commands\n\tcommands=(\n", fn)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t\t%s\n", singleQuote(cmd.name+":"+cmd.description))
+	}
+	fmt.Fprintf(w, "\t)\n\n")
+	fmt.Fprintf(w, "\tif (( CURRENT == 2 )); then\n\t\t_describe 'subcommand' commands\n\t\treturn\n\tfi\n\n")
+	fmt.Fprintf(w, "\tlocal cmd=$words[2]\n\tshift words\n\t(( CURRENT-- ))\n\n\tcase $cmd in\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments", cmd.name)
+
+		for _, f := range completionFlags(cmd) {
+			spec := "-" + f.Name + "[" + zshDescription(f.Usage) + "]"
+
+			switch f.Kind {
+			case valueAny:
+				spec += ":value: "
+			case valueFile:
+				spec += ":file:_files"
+			case valueDir:
+				spec += ":directory:_files -/"
+			case valueSource:
+				spec += ":source:(" + strings.Replace(strings.Join(sources, " "), "'", `'\''`, -1) + ")"
+			}
+			fmt.Fprintf(w, " \\\n\t\t\t'%s'", spec)
+		}
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, " \\\n\t\t\t'1:shell:(%s)'", strings.Join(completionShells, " "))
+		}
+		fmt.Fprintf(w, "\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n}\n\n%s \"$@\"\n", fn)
+}
+
+// WriteFishCompletion - Writes the fish completion script for the program
+func WriteFishCompletion(w io.Writer, prog string) {
+	var sources []string
+	for _, name := range completionSources() {
+		sources = append(sources, singleQuote(name))
+	}
+
+	fmt.Fprintf(w, "# fish completion for %s, generated by '%s completion fish'\n\n", prog, prog)
+	fmt.Fprintf(w, "complete -c %s -f\n", prog)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n",
+			prog, cmd.name, fishQuote(cmd.description))
+	}
+
+	for _, cmd := range commands {
+		cond := fishQuote("__fish_seen_subcommand_from " + cmd.name)
+
+		fmt.Fprintln(w)
+		for _, f := range completionFlags(cmd) {
+			var args string
+
+			switch f.Kind {
+			case valueAny:
+				args = " -x"
+			case valueFile:
+				args = " -r -F"
+			case valueDir:
+				args = " -x -a '(__fish_complete_directories)'"
+			case valueSource:
+				args = " -x -a " + fishQuote("(printf '%s\\n' "+strings.Join(sources, " ")+")")
+			}
+			fmt.Fprintf(w, "complete -c %s -n %s -o %s%s -d %s\n", prog, cond, f.Name, args, fishQuote(f.Usage))
+		}
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, "complete -c %s -n %s -a %s\n", prog, cond, fishQuote(strings.Join(completionShells, " ")))
+		}
+	}
+}
+
+// fishQuote - Quotes the string for fish, where only the backslash and quote are escaped
+func fishQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	return "'" + replacer.Replace(s) + "'"
+}
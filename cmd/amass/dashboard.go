@@ -0,0 +1,280 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/fatih/color"
+)
+
+const (
+	// The number of discoveries kept on the screen
+	dashboardRecent = 15
+
+	// The number of data sources listed on the screen
+	dashboardSources = 20
+
+	// Terminal control sequences for the alternate screen and clearing it
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Dashboard - Redraws a full screen view of the enumeration on stderr and reads the
+// commands typed on stdin for pausing it and toggling the data sources
+type Dashboard struct {
+	sync.Mutex
+	enum       *amass.Enumeration
+	out        io.Writer
+	discovered int
+	recent     []string
+	paused     bool
+	message    string
+	stopped    bool
+	quit       chan struct{}
+}
+
+// NewDashboard - Returns nil when stdin or stderr is not a terminal
+func NewDashboard(enum *amass.Enumeration) *Dashboard {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		return nil
+	}
+	return &Dashboard{
+		enum: enum,
+		out:  color.Error,
+		quit: make(chan struct{}),
+	}
+}
+
+// Run - Periodically redraws the screen and handles the commands until the dashboard is stopped
+func (d *Dashboard) Run() {
+	if d == nil {
+		return
+	}
+
+	d.Lock()
+	if d.stopped {
+		d.Unlock()
+		return
+	}
+	fmt.Fprint(d.out, enterAltScreen)
+	d.Unlock()
+
+	go d.readCommands(os.Stdin)
+
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	d.draw()
+	for {
+		select {
+		case <-d.quit:
+			return
+		case <-t.C:
+			d.draw()
+		}
+	}
+}
+
+// Stop - Restores the terminal screen and prevents the dashboard from being drawn again
+func (d *Dashboard) Stop() {
+	if d == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.stopped {
+		d.stopped = true
+		close(d.quit)
+		fmt.Fprint(d.out, leaveAltScreen)
+	}
+}
+
+// Discovered - Adds the line describing a new name to the discoveries shown on the screen
+func (d *Dashboard) Discovered(line string) {
+	if d == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	d.discovered++
+	d.recent = append(d.recent, line)
+	if len(d.recent) > dashboardRecent {
+		d.recent = d.recent[len(d.recent)-dashboardRecent:]
+	}
+}
+
+func (d *Dashboard) readCommands(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+
+	for scanner.Scan() {
+		select {
+		case <-d.quit:
+			return
+		default:
+		}
+
+		d.handleCommand(scanner.Text())
+		d.draw()
+	}
+}
+
+// handleCommand - Performs the command typed by the user and sets the message shown
+func (d *Dashboard) handleCommand(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	arg := strings.Join(fields[1:], " ")
+
+	var msg string
+	switch strings.ToLower(fields[0]) {
+	case "p", "pause":
+		msg = d.setPaused(true)
+	case "r", "resume":
+		msg = d.setPaused(false)
+	case "d", "disable":
+		msg = d.toggleSource(arg, false)
+	case "e", "enable":
+		msg = d.toggleSource(arg, true)
+	case "a", "add":
+		msg = d.addDomain(arg)
+	case "q", "quit":
+		msg = "Stopping the enumeration"
+		d.enum.Stop()
+	default:
+		msg = fmt.Sprintf("Unknown command: %s", fields[0])
+	}
+
+	d.Lock()
+	d.message = msg
+	d.Unlock()
+}
+
+func (d *Dashboard) setPaused(pause bool) string {
+	d.Lock()
+	if d.paused == pause {
+		d.Unlock()
+		if pause {
+			return "The enumeration is already paused"
+		}
+		return "The enumeration is not paused"
+	}
+	d.paused = pause
+	d.Unlock()
+
+	if pause {
+		d.enum.Pause()
+		return "The enumeration was paused"
+	}
+	d.enum.Resume()
+	return "The enumeration was resumed"
+}
+
+func (d *Dashboard) toggleSource(name string, enable bool) string {
+	if name == "" {
+		return "The name of the data source must be provided"
+	}
+
+	var err error
+	if enable {
+		name, err = d.enum.EnableSource(name)
+	} else {
+		name, err = d.enum.DisableSource(name)
+	}
+	if err != nil {
+		return err.Error()
+	}
+
+	if enable {
+		return fmt.Sprintf("%s was enabled", name)
+	}
+	return fmt.Sprintf("%s was disabled", name)
+}
+
+func (d *Dashboard) addDomain(domain string) string {
+	if domain == "" {
+		return "The root domain name must be provided"
+	}
+
+	if err := d.enum.AddScopeDomain(domain); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s was added to the scope", strings.ToLower(strings.TrimSpace(domain)))
+}
+
+func (d *Dashboard) draw() {
+	stats := d.enum.Stats()
+	report := d.enum.SourceReport()
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(clearScreen)
+
+	state := green("running")
+	if d.paused {
+		state = yellow("paused")
+	}
+	fmt.Fprintf(buf, "%s %s | Discovered: %d | DNS Queries: %d | Elapsed: %s\n\n", blue("OWASP Amass"),
+		state, d.discovered, stats.DNSQueries, roundDuration(stats.Elapsed))
+
+	var queues []string
+	for name := range stats.Queued {
+		queues = append(queues, name)
+	}
+	sort.Strings(queues)
+
+	buf.WriteString(blue("Queues") + "\n")
+	for _, name := range queues {
+		fmt.Fprintf(buf, "  %-28s %d\n", name, stats.Queued[name])
+	}
+
+	fmt.Fprintf(buf, "\n%s\n  %-24s %8s %8s %8s %8s\n", blue("Data Sources"),
+		"Name", "Queries", "Names", "Unique", "Errors")
+	for i, s := range report {
+		if i == dashboardSources {
+			fmt.Fprintf(buf, "  ... %d more\n", len(report)-i)
+			break
+		}
+
+		name := fmt.Sprintf("%-24s", s.Name)
+		if s.Disabled {
+			name = red(name)
+		}
+		fmt.Fprintf(buf, "  %s %8d %8d %8d %8d\n", name, s.Queries, s.Names, s.Unique, s.Errors)
+	}
+
+	buf.WriteString("\n" + blue("Recent Discoveries") + "\n")
+	for _, line := range d.recent {
+		buf.WriteString("  " + line + "\n")
+	}
+
+	buf.WriteString("\n" + yellow("Commands: [p]ause, [r]esume, [d]isable <source>, [e]nable <source>, [a]dd <domain>, [q]uit") + "\n")
+	if d.message != "" {
+		buf.WriteString(d.message + "\n")
+	}
+	buf.WriteString("> ")
+
+	d.out.Write(buf.Bytes())
+}
@@ -0,0 +1,265 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/OWASP/Amass/amass/handlers"
+)
+
+// The name of the local graph file kept in the output directory of the user
+const defaultStoreName = "amass_graph.json"
+
+var (
+	dbCommand = flag.NewFlagSet("db", flag.ExitOnError)
+
+	dbHelp  = dbCommand.Bool("h", false, "Show the program usage message")
+	dbInput = dbCommand.String("i", "", "The Amass data operations JSON file")
+	dbNeo4j = dbCommand.String("neo4j", "", "URL to the Neo4j database")
+	dbStore = dbCommand.String("store", "", "Path to the local graph file used instead of a database server (default: in the output directory)")
+	dbQuery = dbCommand.String("query", "", "Path query run against the local graph (e.g. \"owasp.org in:root out:a_to\")")
+	dbRuns  = dbCommand.Bool("runs", false, "List the runs recorded in the local graph")
+	dbSince = dbCommand.String("since", "", "Show the assets first seen since the date (YYYY-MM-DD)")
+	dbDiff  = dbCommand.String("diff", "", "Show the assets present in run A but not run B, as A,B using the numbers listed by -runs (or last)")
+	dbASN   = dbCommand.Int("asn", 0, "Show the netblocks, addresses and names within the autonomous system")
+	dbJSON  = dbCommand.Bool("json", false, "Print the assets as JSON lines instead of a table")
+
+	dbExport = dbCommand.String("export", "", "Path to the graph archive written with the entire local graph")
+	dbImport = dbCommand.String("import", "", "Path to a graph archive added to the local graph")
+	dbOrigin = dbCommand.String("origin", "", "Name of the machine or tester recorded in the exported archive (default: the hostname)")
+)
+
+// runDBCommand - Populates the graph databases with the data operations and runs the path queries
+func runDBCommand(args []string) {
+	dbCommand.Parse(args)
+
+	if *dbHelp {
+		fmt.Printf("Usage: %s db -i infile [--neo4j URL | --store path [--query path]]\n", filepath.Base(os.Args[0]))
+		fmt.Printf("       %s db [--store path] [-runs] [-since date] [-diff A,B] [-asn number] [-json]\n", filepath.Base(os.Args[0]))
+		fmt.Printf("       %s db [--store path] [-import archive] [-export archive [-origin name]]\n", filepath.Base(os.Args[0]))
+		dbCommand.PrintDefaults()
+		return
+	}
+
+	queries := *dbQuery != "" || *dbRuns || *dbSince != "" || *dbDiff != "" || *dbASN != 0 ||
+		*dbExport != "" || *dbImport != ""
+	// The local graph in the output directory is used when no other database was selected
+	store := *dbStore
+	if store == "" && (*dbNeo4j == "" || queries) {
+		path, err := defaultStore()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		store = path
+	}
+
+	var opts []handlers.JSONFileFormat
+	if *dbInput != "" {
+		f, err := os.Open(*dbInput)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		opts, err = handlers.ParseDataOpts(f)
+		f.Close()
+		if err != nil {
+			fmt.Println("Failed to parse the provided data operations")
+			return
+		}
+	} else if !queries {
+		fmt.Println("The data operations JSON file must be provided using the '-i' flag")
+		return
+	}
+
+	if *dbNeo4j != "" {
+		db, err := handlers.NewNeo4j(*dbNeo4j)
+		if err != nil {
+			fmt.Println("Failed to connect with the database")
+			return
+		}
+
+		err = handlers.DataOptsDriver(opts, db)
+		if err != nil {
+			fmt.Printf("Failed to populate the database: %v\n", err)
+		}
+	}
+
+	if store == "" {
+		return
+	}
+
+	qs, err := handlers.NewQuadStore(store)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer qs.Close()
+
+	if len(opts) > 0 {
+		if err := handlers.DataOptsDriver(opts, qs); err != nil {
+			fmt.Printf("Failed to populate the local graph: %v\n", err)
+			return
+		}
+	}
+
+	if *dbImport != "" {
+		if err := importArchive(qs, *dbImport); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	if *dbExport != "" {
+		if err := exportArchive(qs, *dbExport, *dbOrigin); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+
+	if *dbQuery != "" {
+		nodes, err := qs.Query(*dbQuery)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		for _, n := range nodes {
+			fmt.Println(n)
+		}
+	}
+
+	runs := qs.Runs()
+	if *dbRuns {
+		for i, run := range runs {
+			fmt.Printf("%-4d %s\n", i+1, run.Format(storeTimeFormat))
+		}
+	}
+
+	var filters []handlers.AssetFilter
+	if *dbSince != "" {
+		since, err := time.Parse(dateLayout, *dbSince)
+		if err != nil {
+			fmt.Printf("The '-since' date must be provided as YYYY-MM-DD: %v\n", err)
+			return
+		}
+		filters = append(filters, handlers.FirstSeenAfter(since))
+	}
+	if *dbDiff != "" {
+		pair := strings.Split(*dbDiff, ",")
+		if len(pair) != 2 {
+			fmt.Println("Error: The runs must be provided as A,B")
+			return
+		}
+
+		a, err := selectRun(runs, pair[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		b, err := selectRun(runs, pair[1])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		filters = append(filters, handlers.RunDiff(a, b))
+	}
+	if *dbASN != 0 {
+		filters = append(filters, qs.WithinASN(*dbASN))
+	}
+
+	if len(filters) > 0 {
+		printAssets(qs.Inventory(filters...), *dbJSON)
+	}
+}
+
+// The format of the times shown for the runs and the assets, which are stored in UTC
+const storeTimeFormat = "2006-01-02 15:04:05"
+
+// selectRun - Returns the time of the run with the number listed by -runs, or the last run
+func selectRun(runs []time.Time, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "last" && len(runs) > 0 {
+		return runs[len(runs)-1], nil
+	}
+
+	num, err := strconv.Atoi(value)
+	if err != nil || num < 1 || num > len(runs) {
+		return time.Time{}, fmt.Errorf("The run %q is not one of the %d runs listed by -runs", value, len(runs))
+	}
+	return runs[num-1], nil
+}
+
+func printAssets(assets []*handlers.StoredAsset, asJSON bool) {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, a := range assets {
+			enc.Encode(a)
+		}
+		return
+	}
+
+	fmt.Printf("%-10s %-40s %-20s %-20s\n", "TYPE", "ASSET", "FIRST SEEN", "LAST SEEN")
+	for _, a := range assets {
+		fmt.Printf("%-10s %-40s %-20s %-20s\n", a.Type, a.Node,
+			a.FirstSeen.Format(storeTimeFormat), a.LastSeen.Format(storeTimeFormat))
+	}
+}
+
+func importArchive(qs *handlers.QuadStore, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open the graph archive: %v", err)
+	}
+	defer f.Close()
+
+	added, err := qs.ImportArchive(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d quads from %s\n", added, path)
+	return nil
+}
+
+func exportArchive(qs *handlers.QuadStore, path, origin string) error {
+	if origin == "" {
+		origin, _ = os.Hostname()
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create the graph archive: %v", err)
+	}
+
+	if err := qs.ExportArchive(f, origin); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Failed to write the graph archive: %v", err)
+	}
+	fmt.Printf("Exported %d quads to %s\n", qs.Len(), path)
+	return nil
+}
+
+// defaultStore - Returns the path of the local graph file in the output directory of the user
+func defaultStore() (string, error) {
+	dir, err := amass.OutputDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("Failed to create the output directory: %v", err)
+	}
+	return filepath.Join(dir, defaultStoreName), nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/OWASP/Amass/amass/utils"
+)
+
+// The most hosts that certificates are pulled from at once
+const maxCertPulls = 100
+
+var (
+	intelCommand = flag.NewFlagSet("intel", flag.ExitOnError)
+
+	intelHelp    = intelCommand.Bool("h", false, "Show the program usage message")
+	intelWhois   = intelCommand.Bool("whois", false, "Add the domains discovered with reverse whois")
+	intelPivot   = intelCommand.Bool("pivot", false, "Add the domains of the sites sharing the analytics IDs of the domains provided")
+	intelDomains = intelCommand.String("df", "", "Path to a file providing root domain names")
+	intelOutput  = intelCommand.String("o", "", "Path to the text output file")
+	intelScore   = intelCommand.Float64("min-score", 0, fmt.Sprintf("Lowest ownership score for a discovered "+
+		"domain or netblock to be added, verified with WHOIS, TLS and HTTP (e.g. %.1f; 0 adds them all)", amass.DefaultOwnershipScore))
+)
+
+// The flags of the intel subcommand that can be provided several times or as lists
+var (
+	intelDomainList parseStrings
+	intelAddrs      parseIPs
+	intelCIDRs      parseCIDRs
+	intelASNs       parseInts
+	intelPorts      parseInts
+	intelOrgs       parseStrings
+)
+
+func init() {
+	intelCommand.Var(&intelDomainList, "d", "Domain names separated by commas (can be used multiple times)")
+	intelCommand.Var(&intelAddrs, "addr", "IPs and ranges (192.168.1.1-254) separated by commas")
+	intelCommand.Var(&intelCIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
+	intelCommand.Var(&intelASNs, "asn", "ASNs separated by commas (can be used multiple times)")
+	intelCommand.Var(&intelPorts, "p", "Ports separated by commas (default: 443)")
+	intelCommand.Var(&intelOrgs, "org", "Names of the target organization separated by commas (can be used multiple times)")
+}
+
+// runIntelCommand - Lists the root domains associated with the domains, addresses and
+// ASNs provided, using reverse whois and the certificates served by the hosts
+func runIntelCommand(args []string) {
+	intelCommand.Parse(args)
+
+	if *intelHelp {
+		g.Printf("Usage: %s intel [--whois] [--pivot] [-d domain] [--addr IP] [--cidr CIDR] [--asn number] [-p number] [--org name]\n", filepath.Base(os.Args[0]))
+		intelCommand.PrintDefaults()
+		return
+	}
+
+	domains := intelDomainList
+	if *intelDomains != "" {
+		domains = utils.UniqueAppend(domains, GetNamesFromFile(*intelDomains)...)
+	}
+	ports := intelPorts
+	if len(ports) == 0 {
+		ports = []int{443}
+	}
+
+	enum := amass.NewEnumeration()
+	enum.Log = log.New(os.Stderr, "", 0)
+	enum.Whois = *intelWhois
+	enum.Organizations = intelOrgs
+	enum.OwnershipScore = *intelScore
+	for _, domain := range domains {
+		enum.AddDomain(domain)
+	}
+	enum.ObtainAdditionalDomains()
+	if *intelPivot {
+		enum.ObtainAnalyticsDomains()
+	}
+	enum.AddRelatedDomains(CertificateDomains(IPsInScope(enum, intelAddrs, intelCIDRs, intelASNs), ports))
+
+	if len(enum.Domains()) == 0 {
+		r.Println("The parameters identified no domains")
+		return
+	}
+	ListDomains(enum, *intelOutput)
+}
+
+// IPsInScope - Returns the addresses provided and those within the CIDRs and ASNs. The
+// netblocks of the ASNs are only included when they are scored as belonging to the target
+func IPsInScope(enum *amass.Enumeration, addrs parseIPs, cidrs parseCIDRs, asns parseInts) []net.IP {
+	var ips []net.IP
+
+	ips = append(ips, addrs...)
+	for _, cidr := range cidrs {
+		ips = append(ips, utils.NetHosts(cidr)...)
+	}
+
+	for _, asn := range asns {
+		record, err := amass.ASNRequest(asn)
+		if err != nil {
+			continue
+		}
+
+		for _, cidr := range record.Netblocks {
+			if _, ipnet, err := net.ParseCIDR(cidr); err == nil && enum.RelatedNetblock(ipnet) {
+				ips = append(ips, utils.NetHosts(ipnet)...)
+			}
+		}
+	}
+	return ips
+}
+
+// CertificateDomains - Returns the root domains of the names in the certificates served by the hosts
+func CertificateDomains(ips []net.IP, ports []int) []string {
+	var lock sync.Mutex
+	var domains []string
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxCertPulls)
+	for _, ip := range ips {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(addr string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			for _, req := range amass.PullCertificateNames(addr, ports) {
+				lock.Lock()
+				domains = utils.UniqueAppend(domains, req.Domain)
+				lock.Unlock()
+			}
+		}(ip.String())
+	}
+	wg.Wait()
+	return domains
+}
@@ -5,21 +5,15 @@ package main
 
 import (
 	"bufio"
-	"bytes"
 	"flag"
 	"fmt"
-	"log"
-	"math/rand"
+	"net/http"
 	"os"
-	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
-	//"runtime"
-	//"runtime/pprof"
-
 	"github.com/OWASP/Amass/amass"
-	"github.com/OWASP/Amass/amass/utils"
 	"github.com/fatih/color"
 )
 
@@ -32,180 +26,78 @@ var (
 	yellow = color.New(color.FgHiYellow).SprintFunc()
 	green  = color.New(color.FgHiGreen).SprintFunc()
 	blue   = color.New(color.FgHiBlue).SprintFunc()
-	// Command-line switches and provided parameters
-	help          = flag.Bool("h", false, "Show the program usage message")
-	version       = flag.Bool("version", false, "Print the version number of this amass binary")
-	ips           = flag.Bool("ip", false, "Show the IP addresses for discovered names")
-	brute         = flag.Bool("brute", false, "Execute brute forcing after searches")
-	active        = flag.Bool("active", false, "Attempt zone transfers and certificate name grabs")
-	norecursive   = flag.Bool("norecursive", false, "Turn off recursive brute forcing")
-	minrecursive  = flag.Int("min-for-recursive", 0, "Number of subdomain discoveries before recursive brute forcing")
-	passive       = flag.Bool("passive", false, "Disable DNS resolution of names and dependent features")
-	noalts        = flag.Bool("noalts", false, "Disable generation of altered names")
-	verbose       = flag.Bool("v", false, "Print the data source and summary information")
-	whois         = flag.Bool("whois", false, "Include domains discoverd with reverse whois")
-	list          = flag.Bool("l", false, "List all domains to be used in an enumeration")
-	freq          = flag.Int64("freq", 0, "Sets the number of max DNS queries per minute")
-	wordlist      = flag.String("w", "", "Path to a different wordlist file")
-	allpath       = flag.String("oA", "", "Path prefix used for naming all output files")
-	logpath       = flag.String("log", "", "Path to the log file where errors will be written")
-	outpath       = flag.String("o", "", "Path to the text output file")
-	jsonpath      = flag.String("json", "", "Path to the JSON output file")
-	datapath      = flag.String("do", "", "Path to data operations output file")
-	domainspath   = flag.String("df", "", "Path to a file providing root domain names")
-	resolvepath   = flag.String("rf", "", "Path to a file providing preferred DNS resolvers")
-	blacklistpath = flag.String("blf", "", "Path to a file providing blacklisted subdomains")
-	neo4j         = flag.String("neo4j", "", "URL in the format of user:password@address:port")
+	red    = color.New(color.FgHiRed).SprintFunc()
 )
 
+// command - A subcommand of the amass binary with its own flags
+type command struct {
+	name        string
+	description string
+	flags       *flag.FlagSet
+	run         func(args []string)
+}
+
+// The subcommands in the order they are listed in the usage message
+var commands []*command
+
+// The list is built here, since the completion subcommand reads it
+func init() {
+	commands = []*command{
+		{"enum", "Discover the subdomains of the root domains", enumCommand, runEnumCommand},
+		{"intel", "Discover the root domains associated with an organization", intelCommand, runIntelCommand},
+		{"viz", "Visualize the data operations of an enumeration as network graphs", vizCommand, runVizCommand},
+		{"db", "Populate and query the graph databases with the data operations", dbCommand, runDBCommand},
+		{"track", "Report the names that disappeared or changed between enumerations", trackCommand, runTrackCommand},
+		{"merge", "Combine the graphs of several machines or testers into one graph", mergeCommand, runMergeCommand},
+		{"migrate", "Upgrade JSON output files to the current schema version", migrateCommand, runMigrateCommand},
+		{"report", "Render the asset inventory report of a run from its JSON output", reportCommand, runReportCommand},
+		{"serve", "Run the enumerations submitted by the tenants of a shared server", serveCommand, runServeCommand},
+		{"update", "Download the latest data files from the release endpoint", updateCommand, runUpdateCommand},
+		{"completion", "Print the completion script for bash, zsh or fish", completionCommand, runCompletionCommand},
+	}
+}
+
 func main() {
-	var ports parseInts
-	var domains, resolvers, blacklist parseStrings
-
-	defaultBuf := new(bytes.Buffer)
-	flag.CommandLine.SetOutput(defaultBuf)
-
-	flag.Var(&ports, "p", "Ports separated by commas (default: 80,443)")
-	flag.Var(&domains, "d", "Domain names separated by commas (can be used multiple times)")
-	flag.Var(&resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
-	flag.Var(&blacklist, "bl", "Blacklist of subdomain names that will not be investigated")
-	flag.Parse()
-
-	// Some input validation
-	if *help {
-		PrintBanner()
-		g.Printf("Usage: %s [options] <-d domain>\n", path.Base(os.Args[0]))
-		flag.PrintDefaults()
-		g.Println(defaultBuf.String())
+	if len(os.Args) < 2 {
+		PrintUsage()
 		return
 	}
-	if *version {
+
+	name := os.Args[1]
+	switch name {
+	case "-h", "-help", "--help", "help":
+		PrintUsage()
+		return
+	case "-version", "--version", "version":
 		fmt.Printf("version %s\n", amass.Version)
 		return
 	}
-	if *passive && *ips {
-		r.Println("IP addresses cannot be provided without DNS resolution")
+	// The flags of an enumeration can still be provided without the subcommand
+	if strings.HasPrefix(name, "-") {
+		runEnumCommand(os.Args[1:])
 		return
 	}
 
-	var words []string
-	// Obtain parameters from provided files
-	if *wordlist != "" {
-		words = GetLinesFromFile(*wordlist)
-	}
-	if *domainspath != "" {
-		domains = utils.UniqueAppend(domains, GetLinesFromFile(*domainspath)...)
-	}
-	if *resolvepath != "" {
-		resolvers = utils.UniqueAppend(resolvers, GetLinesFromFile(*resolvepath)...)
-	}
-	if *blacklistpath != "" {
-		blacklist = utils.UniqueAppend(blacklist, GetLinesFromFile(*blacklistpath)...)
-	}
-
-	// Prepare output files
-	logfile := *logpath
-	txt := *outpath
-	jsonfile := *jsonpath
-	datafile := *datapath
-	if *allpath != "" {
-		logfile = *allpath + ".log"
-		txt = *allpath + ".txt"
-		jsonfile = *allpath + ".json"
-		datafile = *allpath + "_data.json"
-	}
-
-	// Seed the default pseudo-random number generator
-	rand.Seed(time.Now().UTC().UnixNano())
-
-	done := make(chan struct{})
-	results := make(chan *amass.AmassOutput, 100)
-	// Setup the amass configuration
-	alts := true
-	recursive := true
-	if *noalts {
-		alts = false
-	}
-	if *norecursive {
-		recursive = false
-	}
-	enum := amass.NewEnumeration()
-	enum.Whois = *whois
-	enum.Wordlist = words
-	enum.BruteForcing = *brute
-	enum.Recursive = recursive
-	enum.MinForRecursive = *minrecursive
-	enum.Active = *active
-	enum.Alterations = alts
-	enum.Passive = *passive
-	enum.Frequency = FreqToDuration(*freq)
-	enum.Resolvers = resolvers
-	enum.Blacklist = blacklist
-	enum.Output = results
-
-	for _, domain := range domains {
-		enum.AddDomain(domain)
-	}
-	// Setup the log file for saving error messages
-	if logfile != "" {
-		fileptr, err := os.OpenFile(logfile, os.O_WRONLY|os.O_CREATE, 0644)
-		if err != nil {
-			r.Printf("Failed to open the log file: %v", err)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(os.Args[2:])
 			return
 		}
-		defer func() {
-			fileptr.Sync()
-			fileptr.Close()
-		}()
-		enum.Log = log.New(fileptr, "", log.Lmicroseconds)
-	}
-	// Setup the data operations output file
-	if datafile != "" {
-		fileptr, err := os.OpenFile(datafile, os.O_WRONLY|os.O_CREATE, 0644)
-		if err != nil {
-			r.Printf("Failed to open the data operations output file: %v", err)
-			return
-		}
-		defer func() {
-			fileptr.Sync()
-			fileptr.Close()
-		}()
-		enum.DataOptsWriter = fileptr
-	}
-	enum.ObtainAdditionalDomains()
-	if *list {
-		ListDomains(enum, txt)
-		return
-	}
-	// Can an enumeration be performed with the provided parameters?
-	if len(enum.Domains()) == 0 {
-		r.Println("No root domain names were provided or discovered")
-		return
 	}
+	r.Printf("Unknown subcommand: %s\n\n", name)
+	PrintUsage()
+}
 
-	go ManageOutput(&OutputParams{
-		Enum:     enum,
-		Verbose:  *verbose,
-		PrintIPs: *ips,
-		FileOut:  txt,
-		JSONOut:  jsonfile,
-		Done:     done,
-	})
-
-	// Execute the signal handler
-	go SignalHandler(enum, results, done)
+// PrintUsage - Prints the banner and the subcommands of the binary
+func PrintUsage() {
+	PrintBanner()
+	g.Printf("Usage: %s <subcommand> [options]\n\n", filepath.Base(os.Args[0]))
 
-	err := enum.Start()
-	if err != nil {
-		r.Println(err)
-		return
+	g.Println("Subcommands:")
+	for _, cmd := range commands {
+		g.Printf("  %-12s%s\n", cmd.name, cmd.description)
 	}
-	//profFile, _ := os.Create("amass_mem.prof")
-	//defer profFile.Close()
-	//runtime.GC()
-	//pprof.WriteHeapProfile(profFile)
-	// Wait for output manager to finish
-	<-done
+	g.Printf("\nUse '%s <subcommand> -h' for the options of the subcommand\n", filepath.Base(os.Args[0]))
 }
 
 func GetLinesFromFile(path string) []string {
@@ -230,6 +122,34 @@ func GetLinesFromFile(path string) []string {
 	return lines
 }
 
+// GetNamesFromFile - Returns the normalized names in the file, reporting the lines that were rejected
+func GetNamesFromFile(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Error opening the file %s: %v\n", path, err)
+		return nil
+	}
+	defer file.Close()
+
+	names, report, err := amass.ParseNameList(file)
+	if err != nil {
+		fmt.Printf("Error reading the file %s: %v\n", path, err)
+	}
+	printImportReport(path, report)
+	return names
+}
+
+func printImportReport(path string, report *amass.ImportReport) {
+	if len(report.Rejected) == 0 {
+		return
+	}
+
+	y.Fprintf(color.Error, "%s: %s\n", path, report)
+	for _, rej := range report.Rejected {
+		fmt.Fprintf(color.Error, "  line %d: %q %s\n", rej.Line, rej.Input, rej.Reason)
+	}
+}
+
 func FreqToDuration(freq int64) time.Duration {
 	if freq > 0 {
 		d := time.Duration(freq)
@@ -248,3 +168,15 @@ func FreqToDuration(freq int64) time.Duration {
 	// Use the default rate
 	return amass.DefaultFrequency
 }
+
+func serveHealth(addr string, handler http.Handler) {
+	if err := amass.ServeHealth(addr, handler); err != nil {
+		r.Printf("The health endpoints failed: %v\n", err)
+	}
+}
+
+func serveAPI(addr string, handler http.Handler) {
+	if err := amass.ServeAPI(addr, handler); err != nil {
+		r.Printf("The API endpoints failed: %v\n", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OWASP/Amass/amass/handlers"
+)
+
+var (
+	mergeCommand = flag.NewFlagSet("merge", flag.ExitOnError)
+
+	mergeHelp   = mergeCommand.Bool("h", false, "Show the program usage message")
+	mergeOutput = mergeCommand.String("o", "", "Path to the graph archive written with the merged graph")
+	mergeStore  = mergeCommand.String("store", "", "Path to the local graph file that the inputs are merged into")
+	mergeOrigin = mergeCommand.String("origin", "", "Name recorded in the written archive for the statements without an origin (default: the hostname)")
+)
+
+// runMergeCommand - Combines the graph archives and local graph files of several testers into one graph
+func runMergeCommand(args []string) {
+	mergeCommand.Parse(args)
+
+	inputs := mergeCommand.Args()
+	if *mergeHelp || len(inputs) == 0 || (*mergeOutput == "" && *mergeStore == "") {
+		fmt.Printf("Usage: %s merge [-o archive] [-store path] [-origin name] input...\n", filepath.Base(os.Args[0]))
+		fmt.Println("The inputs are graph archives written by 'db -export', or local graph files")
+		mergeCommand.PrintDefaults()
+		return
+	}
+
+	store := *mergeStore
+	if store == "" {
+		// The merged graph is only kept in the archive
+		dir, err := ioutil.TempDir("", "amass-merge")
+		if err != nil {
+			r.Printf("Failed to create the temporary directory: %v\n", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+		store = filepath.Join(dir, defaultStoreName)
+	}
+
+	qs, err := handlers.NewQuadStore(store)
+	if err != nil {
+		r.Println(err)
+		os.Exit(1)
+	}
+	defer qs.Close()
+
+	for _, path := range inputs {
+		added, err := mergeInput(qs, path)
+		if err != nil {
+			r.Printf("%s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Merged %d quads from %s\n", added, path)
+	}
+
+	if *mergeOutput != "" {
+		if err := exportArchive(qs, *mergeOutput, *mergeOrigin); err != nil {
+			r.Println(err)
+			os.Exit(1)
+		}
+	}
+}
+
+// mergeInput - Adds the graph archive or local graph file to the store, where the quads of
+// a local graph file are recorded with the name of the file as their origin
+func mergeInput(qs *handlers.QuadStore, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to open the input: %v", err)
+	}
+	defer f.Close()
+
+	// The graph archives are gzip compressed
+	br := bufio.NewReader(f)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return qs.ImportArchive(br)
+	}
+	f.Close()
+
+	other, err := handlers.NewQuadStore(path)
+	if err != nil {
+		return 0, err
+	}
+	defer other.Close()
+
+	origin := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return qs.MergeStore(other, origin)
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/OWASP/Amass/amass"
+)
+
+var (
+	migrateCommand = flag.NewFlagSet("migrate", flag.ExitOnError)
+
+	migrateHelp   = migrateCommand.Bool("h", false, "Show the program usage message")
+	migrateInput  = migrateCommand.String("i", "", "Path to the JSON output file written by an earlier release")
+	migrateOutput = migrateCommand.String("o", "", "Path to the upgraded JSON output file (default: replaces the input file)")
+)
+
+// runMigrateCommand - Upgrades the JSON output file to the current schema version
+func runMigrateCommand(args []string) {
+	migrateCommand.Parse(args)
+
+	if *migrateHelp || *migrateInput == "" {
+		fmt.Printf("Usage: %s migrate -i infile [-o outfile]\n", filepath.Base(os.Args[0]))
+		migrateCommand.PrintDefaults()
+		return
+	}
+
+	in, err := os.Open(*migrateInput)
+	if err != nil {
+		r.Printf("Failed to open the input file: %v\n", err)
+		os.Exit(1)
+	}
+	defer in.Close()
+
+	// The records are written to a temporary file, so the input is only replaced on success
+	outpath := *migrateOutput
+	if outpath == "" {
+		outpath = *migrateInput
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(outpath), "."+filepath.Base(outpath)+"-")
+	if err != nil {
+		r.Printf("Failed to create the output file: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmp.Chmod(0644)
+
+	upgraded, err := migrate(in, tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), outpath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		r.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d records were upgraded to schema version %d in %s\n",
+		upgraded, amass.OutputSchemaVersion, outpath)
+}
+
+func migrate(in io.Reader, out io.Writer) (int, error) {
+	bufwr := bufio.NewWriter(out)
+
+	upgraded, err := amass.MigrateOutput(in, bufwr)
+	if err != nil {
+		return upgraded, err
+	}
+	return upgraded, bufwr.Flush()
+}
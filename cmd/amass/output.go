@@ -8,9 +8,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/OWASP/Amass/amass"
+	"github.com/OWASP/Amass/amass/core"
+	"github.com/OWASP/Amass/amass/expr"
 	"github.com/fatih/color"
 )
 
@@ -20,7 +25,12 @@ type OutputParams struct {
 	PrintIPs bool
 	FileOut  string
 	JSONOut  string
-	Done     chan struct{}
+	MassDNS  string
+	Filter   *expr.Expression
+	Progress *Progress
+	// The names are shown on the dashboard instead of being printed to the terminal
+	Dashboard *Dashboard
+	Done      chan struct{}
 }
 
 type ASNData struct {
@@ -33,33 +43,136 @@ type JsonAddr struct {
 	CIDR        string `json:"cidr"`
 	ASN         int    `json:"asn"`
 	Description string `json:"desc"`
+	PTR         string `json:"ptr,omitempty"`
+	PTRMatch    bool   `json:"ptr_match,omitempty"`
+	Reserved    string `json:"reserved,omitempty"`
+
+	Certificates []JsonCert `json:"certificates,omitempty"`
+}
+
+type JsonCert struct {
+	Port       int       `json:"port"`
+	ServerName string    `json:"server_name,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	Version    string    `json:"tls_version,omitempty"`
+	SelfSigned bool      `json:"self_signed,omitempty"`
+	Legacy     string    `json:"legacy_tls_version,omitempty"`
+	Mismatched bool      `json:"name_mismatch,omitempty"`
+}
+
+type JsonRecord struct {
+	Type     string `json:"type"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority,omitempty"`
+	Data     string `json:"data"`
+}
+
+type JsonDomainInfo struct {
+	SOANameServer string           `json:"soa_ns,omitempty"`
+	SOAContact    string           `json:"soa_contact,omitempty"`
+	SOASerial     uint32           `json:"soa_serial,omitempty"`
+	CAA           []string         `json:"caa,omitempty"`
+	DNSKEY        bool             `json:"dnskey"`
+	NameServers   []JsonNameServer `json:"name_servers,omitempty"`
+}
+
+type JsonNameServer struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Software string `json:"software,omitempty"`
+	Version  string `json:"version,omitempty"`
 }
 
 type JsonSave struct {
-	Name      string     `json:"name"`
-	Domain    string     `json:"domain"`
-	Addresses []JsonAddr `json:"addresses"`
-	Tag       string     `json:"tag"`
-	Source    string     `json:"source"`
+	SchemaVersion int `json:"schema_version"`
+
+	Name      string       `json:"name"`
+	Domain    string       `json:"domain"`
+	Addresses []JsonAddr   `json:"addresses"`
+	Records   []JsonRecord `json:"records,omitempty"`
+	Tag       string       `json:"tag"`
+	Source    string       `json:"source"`
+	DNSSEC    string       `json:"dnssec,omitempty"`
+	Divergent bool         `json:"divergent,omitempty"`
+	GeoDNS    bool         `json:"geo_dns,omitempty"`
+	NoData    bool         `json:"no_data,omitempty"`
+	Private   bool         `json:"private,omitempty"`
+
+	DomainInfo *JsonDomainInfo `json:"domain_info,omitempty"`
 }
 
 func WriteJSONData(f *os.File, result *amass.AmassOutput) {
 	save := &JsonSave{
-		Name:   result.Name,
-		Domain: result.Domain,
-		Tag:    result.Tag,
-		Source: result.Source,
+		SchemaVersion: amass.OutputSchemaVersion,
+
+		Name:      result.Name,
+		Domain:    result.Domain,
+		Tag:       result.Tag,
+		Source:    result.Source,
+		DNSSEC:    result.DNSSEC,
+		Divergent: result.Divergent,
+		GeoDNS:    result.GeoDNS,
+		NoData:    result.NoData,
+		Private:   result.Private(),
 	}
 
 	for _, addr := range result.Addresses {
-		save.Addresses = append(save.Addresses, JsonAddr{
+		a := JsonAddr{
 			IP:          addr.Address.String(),
 			CIDR:        addr.Netblock.String(),
 			ASN:         addr.ASN,
 			Description: addr.Description,
+			PTR:         addr.PTR,
+			PTRMatch:    addr.PTRMatch,
+			Reserved:    addr.Reserved,
+		}
+
+		for _, c := range addr.Certificates {
+			a.Certificates = append(a.Certificates, JsonCert{
+				Port:       c.Port,
+				ServerName: c.ServerName,
+				Subject:    c.Subject,
+				NotBefore:  c.NotBefore,
+				NotAfter:   c.NotAfter,
+				Version:    c.Version,
+				SelfSigned: c.SelfSigned,
+				Legacy:     c.Legacy,
+				Mismatched: c.Mismatched,
+			})
+		}
+		save.Addresses = append(save.Addresses, a)
+	}
+
+	for _, rec := range result.Records {
+		save.Records = append(save.Records, JsonRecord{
+			Type:     rec.Type,
+			TTL:      rec.TTL,
+			Priority: rec.Priority,
+			Data:     rec.Data,
 		})
 	}
 
+	if info := result.DomainInfo; info != nil {
+		save.DomainInfo = &JsonDomainInfo{
+			SOANameServer: info.SOANameServer,
+			SOAContact:    info.SOAContact,
+			SOASerial:     info.SOASerial,
+			CAA:           info.CAA,
+			DNSKEY:        info.DNSKEY,
+		}
+
+		for _, ns := range info.NameServers {
+			save.DomainInfo.NameServers = append(save.DomainInfo.NameServers, JsonNameServer{
+				Name:     ns.Name,
+				Address:  ns.Address,
+				Software: ns.Software,
+				Version:  ns.Version,
+			})
+		}
+	}
+
 	enc := json.NewEncoder(f)
 	enc.Encode(save)
 }
@@ -90,6 +203,218 @@ func ListDomains(enum *amass.Enumeration, outfile string) {
 	}
 }
 
+func ListSources(enum *amass.Enumeration) {
+	for _, src := range enum.Sources() {
+		status := green(fmt.Sprintf("%-10s", "enabled"))
+		if !src.Enabled {
+			status = red(fmt.Sprintf("%-10s", "disabled"))
+		}
+
+		subs := "no"
+		if src.Subdomains {
+			subs = "yes"
+		}
+
+		keys := "keys: N/A"
+		if len(src.MissingKeys) > 0 {
+			keys = red("keys missing: " + strings.Join(src.MissingKeys, ","))
+		} else if len(src.RequiredKeys) > 0 {
+			keys = green("keys: present")
+		}
+		fmt.Fprintf(color.Output, "%s%s%-17s%s%s\n", blue(fmt.Sprintf("%-20s", src.Name)),
+			yellow(fmt.Sprintf("%-10s", src.Category)), "subdomains: "+subs, status, keys)
+	}
+}
+
+func PrintSelfTest(domain string, results []*amass.SourceTestResult) {
+	var broken int
+
+	fmt.Fprintf(color.Output, "%s %s\n\n", blue("Data source self-test for"), green(domain))
+	for _, res := range results {
+		status := green(fmt.Sprintf("%-8s", "ok"))
+		if res.Broken() {
+			status = red(fmt.Sprintf("%-8s", "broken"))
+			broken++
+		}
+
+		note := ""
+		if res.TimedOut {
+			note = red("timed out")
+		} else if res.Invalid > 0 {
+			note = red(fmt.Sprintf("%d names outside the domain", res.Invalid))
+		}
+		if len(res.Errors) > 0 {
+			note = strings.TrimSpace(note + " " + red(fmt.Sprintf("%d errors, last: %s", len(res.Errors), res.Errors[len(res.Errors)-1])))
+		}
+		fmt.Fprintf(color.Output, "%s%s%s%-12s%-10s%s\n", blue(fmt.Sprintf("%-20s", res.Name)),
+			yellow(fmt.Sprintf("%-10s", res.Category)), status, "names: "+strconv.Itoa(res.Names),
+			res.Duration.Round(time.Millisecond).String(), note)
+	}
+	fmt.Fprintf(color.Output, "\n%s %s\n", yellow(strconv.Itoa(broken)), blue("data sources appear to be broken"))
+}
+
+func PrintSourceReport(report []*amass.SourceStats) {
+	if len(report) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Output, "\n%s%s%s%s%s%s\n", blue(fmt.Sprintf("%-20s", "Source")),
+		blue(fmt.Sprintf("%-10s", "Queries")), blue(fmt.Sprintf("%-10s", "Names")),
+		blue(fmt.Sprintf("%-10s", "Unique")), blue(fmt.Sprintf("%-10s", "Errors")), blue("Time"))
+	for _, s := range report {
+		if s.Queries == 0 {
+			continue
+		}
+
+		errs := green(fmt.Sprintf("%-10d", s.Errors))
+		if s.Errors > 0 {
+			errs = red(fmt.Sprintf("%-10d", s.Errors))
+		}
+		fmt.Fprintf(color.Output, "%s%-10d%s%s%s%s\n", blue(fmt.Sprintf("%-20s", s.Name)), s.Queries,
+			yellow(fmt.Sprintf("%-10d", s.Names)), green(fmt.Sprintf("%-10d", s.Unique)), errs,
+			s.Duration.Round(time.Millisecond).String())
+	}
+}
+
+func PrintSkipped(skipped map[string]int) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	var keys []string
+	for k := range skipped {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r.Println("\nThe enumeration budget was exhausted, and the following work was skipped:")
+	for _, k := range keys {
+		fmt.Fprintf(color.Output, "%s: %s\n", green(k), yellow(strconv.Itoa(skipped[k])))
+	}
+}
+
+func PrintVirtualHosts(vhosts []amass.VirtualHost) {
+	if len(vhosts) == 0 {
+		return
+	}
+
+	r.Println("\nThe following names were accepted over TLS SNI by these servers:")
+	for _, vh := range vhosts {
+		fmt.Fprintf(color.Output, "%s -> %s\n", green(vh.Name), yellow(vh.Address))
+	}
+}
+
+func PrintFindings(findings []*core.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	r.Println("\nNotable findings:")
+	for _, f := range findings {
+		fmt.Fprintf(color.Output, "[%s] %s: %s\n", red(strings.ToUpper(f.Severity)), green(f.Name), yellow(f.Description))
+	}
+}
+
+func WriteMassDNSFile(path string, names []string) {
+	fileptr, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		r.Printf("Failed to open the massdns output file: %v\n", err)
+		return
+	}
+	defer func() {
+		fileptr.Sync()
+		fileptr.Close()
+	}()
+
+	if err := amass.WriteMassDNSNames(fileptr, names); err != nil {
+		r.Printf("Failed to write the massdns output file: %v\n", err)
+	}
+}
+
+func WriteManifest(enum *amass.Enumeration, path string) {
+	fileptr, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		r.Printf("Failed to open the manifest file: %v\n", err)
+		return
+	}
+	defer func() {
+		fileptr.Sync()
+		fileptr.Close()
+	}()
+
+	if err := enum.WriteManifest(fileptr); err != nil {
+		r.Printf("Failed to write the manifest file: %v\n", err)
+	}
+}
+
+func WriteSummary(enum *amass.Enumeration, path string) {
+	fileptr, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		r.Printf("Failed to open the summary file: %v\n", err)
+		return
+	}
+	defer func() {
+		fileptr.Sync()
+		fileptr.Close()
+	}()
+
+	if err := enum.WriteSummary(fileptr); err != nil {
+		r.Printf("Failed to write the summary file: %v\n", err)
+	}
+}
+
+func PrintPlan(plan *amass.EnumerationPlan) {
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Domains:"), green(strings.Join(plan.Domains, ", ")))
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Services:"), green(strings.Join(plan.Services, ", ")))
+
+	if len(plan.Resolvers) > 0 || len(plan.UnreachableResolvers) > 0 {
+		fmt.Fprintf(color.Output, "%s %s\n", blue("Resolvers:"), green(strings.Join(plan.Resolvers, ", ")))
+	}
+	if len(plan.UnreachableResolvers) > 0 {
+		fmt.Fprintf(color.Output, "%s %s\n", blue("Unreachable resolvers:"),
+			red(strings.Join(plan.UnreachableResolvers, ", ")))
+	}
+
+	var enabled int
+	for _, src := range plan.Sources {
+		if src.Enabled {
+			enabled++
+		}
+	}
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Data sources enabled:"),
+		yellow(fmt.Sprintf("%d of %d", enabled, len(plan.Sources))))
+	for name, err := range plan.KeyErrors {
+		fmt.Fprintf(color.Output, "%s %s\n", red(name+" API key check failed:"), red(err.Error()))
+	}
+	for name, err := range plan.Unavailable {
+		fmt.Fprintf(color.Output, "%s %s\n", red(name+" is unavailable:"), red(err.Error()))
+	}
+
+	for _, err := range plan.WordlistErrors {
+		fmt.Fprintf(color.Output, "%s\n", red(err.Error()))
+	}
+	if plan.BruteNames > 0 {
+		fmt.Fprintf(color.Output, "%s %s\n", blue("Brute forced names:"), yellow(strconv.Itoa(plan.BruteNames)))
+	}
+	if plan.SRVNames > 0 {
+		fmt.Fprintf(color.Output, "%s %s\n", blue("SRV names:"), yellow(strconv.Itoa(plan.SRVNames)))
+	}
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Estimated DNS queries:"), yellow(strconv.Itoa(plan.EstimatedQueries)))
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Estimated duration:"), yellow(plan.EstimatedDuration.String()))
+}
+
+// PrintOPSEC - Prints the aggregate disclosure of the third parties contacted by the enumeration
+func PrintOPSEC(disclosures []*amass.OPSECDisclosure) {
+	for _, d := range disclosures {
+		fmt.Fprintf(color.Output, "%s\n", blue(d.Service+":"))
+
+		for _, line := range d.Details {
+			fmt.Fprintf(color.Output, "  %s\n", yellow(line))
+		}
+	}
+	fmt.Fprintln(color.Output)
+}
+
 func PrintBanner() {
 	rightmost := 76
 	desc := "In-Depth DNS Enumeration"
@@ -132,6 +457,32 @@ func ResultToLine(result *amass.AmassOutput, params *OutputParams) (string, stri
 	return source, result.Name, comma, ips
 }
 
+// ResultNotes - Returns the remarks printed after a result that deserves attention
+func ResultNotes(result *amass.AmassOutput) string {
+	var notes []string
+
+	if result.DNSSEC == core.DNSSECBogus {
+		notes = append(notes, "DNSSEC validation failed")
+	}
+	if result.Divergent {
+		notes = append(notes, "divergent answers")
+	}
+	if result.GeoDNS {
+		notes = append(notes, "geo-DNS answers")
+	}
+	if result.Private() {
+		notes = append(notes, "internal address")
+	}
+	if result.NoData {
+		notes = append(notes, "exists without records")
+	}
+
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
 func ManageOutput(params *OutputParams) {
 	var total int
 	var err error
@@ -157,16 +508,29 @@ func ManageOutput(params *OutputParams) {
 		}
 	}
 
+	var names []string
 	tags := make(map[string]int)
 	asns := make(map[int]*ASNData)
 	// Collect all the names returned by the enumeration
 	for result := range params.Enum.Output {
+		if params.Filter != nil {
+			if ok, err := params.Filter.Eval(result.Fields()); err != nil || !ok {
+				continue
+			}
+		}
+
 		total++
 		UpdateData(result, tags, asns)
+		params.Progress.Clear()
+		params.Progress.Discovered()
 
 		source, name, comma, ips := ResultToLine(result, params)
-		fmt.Fprintf(color.Output, "%s%s%s%s\n",
-			blue(source), green(name), green(comma), yellow(ips))
+		line := fmt.Sprintf("%s%s%s%s%s", blue(source), green(name),
+			green(comma), yellow(ips), red(ResultNotes(result)))
+		params.Dashboard.Discovered(line)
+		if params.Dashboard == nil || !isTerminal(os.Stdout) {
+			fmt.Fprintln(color.Output, line)
+		}
 		// Handle writing the line to a specified output file
 		if outptr != nil {
 			WriteTextData(outptr, source, name, comma, ips)
@@ -175,7 +539,15 @@ func ManageOutput(params *OutputParams) {
 		if jsonptr != nil {
 			WriteJSONData(jsonptr, result)
 		}
+		if params.MassDNS != "" {
+			names = append(names, result.Name)
+		}
+	}
+	if params.MassDNS != "" {
+		WriteMassDNSFile(params.MassDNS, names)
 	}
+	params.Progress.Stop()
+	params.Dashboard.Stop()
 	// Check to print the summary information
 	if params.Verbose {
 		PrintSummary(total, tags, asns)
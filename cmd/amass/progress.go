@@ -0,0 +1,161 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/fatih/color"
+)
+
+// Progress - Renders a single status line on stderr while the enumeration runs
+type Progress struct {
+	sync.Mutex
+	enum       *amass.Enumeration
+	discovered int
+	queries    uint64
+	last       time.Time
+	width      int
+	stopped    bool
+	quit       chan struct{}
+}
+
+// NewProgress - Returns nil when stderr is not a terminal
+func NewProgress(enum *amass.Enumeration) *Progress {
+	if !isTerminal(os.Stderr) {
+		return nil
+	}
+	return &Progress{
+		enum: enum,
+		last: time.Now(),
+		quit: make(chan struct{}),
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Run - Periodically redraws the status line until the display is stopped
+func (p *Progress) Run() {
+	if p == nil {
+		return
+	}
+
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-t.C:
+			p.draw()
+		}
+	}
+}
+
+// Stop - Erases the status line and prevents it from being drawn again
+func (p *Progress) Stop() {
+	if p == nil {
+		return
+	}
+
+	p.Clear()
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.stopped {
+		p.stopped = true
+		close(p.quit)
+	}
+}
+
+// Discovered - Increments the number of names shown in the status line
+func (p *Progress) Discovered() {
+	if p == nil {
+		return
+	}
+
+	p.Lock()
+	p.discovered++
+	p.Unlock()
+}
+
+// Clear - Erases the status line so other output can be printed
+func (p *Progress) Clear() {
+	if p == nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.width > 0 {
+		fmt.Fprint(color.Error, "\r"+strings.Repeat(" ", p.width)+"\r")
+		p.width = 0
+	}
+}
+
+func (p *Progress) draw() {
+	stats := p.enum.Stats()
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	now := time.Now()
+	var rate float64
+	if secs := now.Sub(p.last).Seconds(); secs > 0 && stats.DNSQueries >= p.queries {
+		rate = float64(stats.DNSQueries-p.queries) / secs
+	}
+	p.queries = stats.DNSQueries
+	p.last = now
+
+	var total int
+	var queues []string
+	for name, num := range stats.Queued {
+		if num > 0 {
+			total += num
+			queues = append(queues, fmt.Sprintf("%s: %d", name, num))
+		}
+	}
+	sort.Strings(queues)
+
+	eta := "N/A"
+	if total > 0 && rate > 0 {
+		eta = roundDuration(time.Duration(float64(total)/rate) * time.Second).String()
+	}
+
+	line := fmt.Sprintf("Discovered: %d | Queued: %d", p.discovered, total)
+	if len(queues) > 0 {
+		line += " (" + strings.Join(queues, ", ") + ")"
+	}
+	line += fmt.Sprintf(" | Queries/sec: %.1f | Elapsed: %s | ETA: %s",
+		rate, roundDuration(stats.Elapsed), eta)
+
+	pad := p.width - len(line)
+	if pad < 0 {
+		pad = 0
+	}
+	fmt.Fprint(color.Error, "\r"+line+strings.Repeat(" ", pad))
+	p.width = len(line)
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d - (d % time.Second)
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OWASP/Amass/amass"
+	"github.com/OWASP/Amass/amass/report"
+)
+
+var (
+	reportCommand = flag.NewFlagSet("report", flag.ExitOnError)
+
+	reportHelp     = reportCommand.Bool("h", false, "Show the program usage message")
+	reportInput    = reportCommand.String("i", "", "Path to the JSON output file of the run")
+	reportPrevious = reportCommand.String("prev", "", "Path to the JSON output file of the previous run, for the changes since that run")
+	reportSummary  = reportCommand.String("summary", "", "Path to the summary file of the run, for the findings published by the services")
+	reportOutput   = reportCommand.String("o", "", "Path to the report file (default: stdout)")
+	reportFormat   = reportCommand.String("format", "", "Format of the report, html or markdown (default: from the output file extension, or markdown)")
+	reportTemplate = reportCommand.String("template", "", "Path to a Go template file replacing the default template of the format")
+)
+
+// runReportCommand - Renders the asset inventory report of a run from its JSON output
+func runReportCommand(args []string) {
+	reportCommand.Parse(args)
+
+	if *reportHelp || *reportInput == "" {
+		fmt.Printf("Usage: %s report -i infile [-prev infile] [-summary infile] [-o outfile] [-format html|markdown] [-template path]\n", filepath.Base(os.Args[0]))
+		reportCommand.PrintDefaults()
+		return
+	}
+
+	results, err := readOutputFile(*reportInput)
+	if err != nil {
+		r.Println(err)
+		os.Exit(1)
+	}
+	var previous []*amass.AmassOutput
+	if *reportPrevious != "" {
+		if previous, err = readOutputFile(*reportPrevious); err != nil {
+			r.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	var custom string
+	if *reportTemplate != "" {
+		data, err := ioutil.ReadFile(*reportTemplate)
+		if err != nil {
+			r.Printf("Failed to read the template file: %v\n", err)
+			os.Exit(1)
+		}
+		custom = string(data)
+	}
+
+	format := *reportFormat
+	if format == "" {
+		format = report.Markdown
+		if ext := strings.ToLower(filepath.Ext(*reportOutput)); ext == ".html" || ext == ".htm" {
+			format = report.HTML
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *reportOutput != "" {
+		f, err := os.Create(*reportOutput)
+		if err != nil {
+			r.Printf("Failed to create the report file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	rep := report.New(results, previous)
+	if *reportSummary != "" {
+		summary, err := readSummaryFile(*reportSummary)
+		if err != nil {
+			r.Println(err)
+			os.Exit(1)
+		}
+		rep.Findings = summary.Findings
+	}
+
+	if err := rep.Write(out, format, custom); err != nil {
+		r.Println(err)
+		os.Exit(1)
+	}
+}
+
+func readOutputFile(path string) ([]*amass.AmassOutput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the JSON output file: %v", err)
+	}
+	defer f.Close()
+
+	return amass.ReadOutput(f)
+}
+
+func readSummaryFile(path string) (*amass.EnumerationSummary, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the summary file: %v", err)
+	}
+
+	var summary amass.EnumerationSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("Failed to parse the summary file: %v", err)
+	}
+	return &summary, nil
+}
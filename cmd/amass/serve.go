@@ -0,0 +1,132 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/amass"
+)
+
+var (
+	serveCommand = flag.NewFlagSet("serve", flag.ExitOnError)
+
+	serveHelp    = serveCommand.Bool("h", false, "Show the program usage message")
+	serveAddr    = serveCommand.String("addr", ":8080", "Address where the job endpoints are served (loopback when no host is given)")
+	serveTenants = serveCommand.String("tenants", "", "Path to the JSON file providing the names, keys and settings of the tenants")
+	serveDir     = serveCommand.String("dir", "", "Path to the directory where the jobs and the results of each tenant are kept")
+	serveMaxJobs = serveCommand.Int("max-jobs", 2, "Maximum number of enumerations run at once, while the others wait in the queue")
+	serveCert    = serveCommand.String("cert", "", "Path to the TLS certificate presented to the clients")
+	serveKey     = serveCommand.String("key", "", "Path to the private key for the TLS certificate")
+	serveCA      = serveCommand.String("ca", "", "Path to the CA certificate that the clients must be signed by (mutual TLS)")
+)
+
+// runServeCommand - Runs the enumerations submitted by the tenants, each in its own process
+// so the configurations and rate budgets of the tenants do not affect one another
+func runServeCommand(args []string) {
+	serveCommand.Parse(args)
+
+	if *serveHelp {
+		fmt.Printf("Usage: %s serve --tenants path --dir path [--addr address] [--cert path --key path]\n",
+			filepath.Base(os.Args[0]))
+		serveCommand.PrintDefaults()
+		return
+	}
+	if *serveTenants == "" || *serveDir == "" {
+		r.Println("The '-tenants' and '-dir' flags must be provided")
+		return
+	}
+
+	tenants, err := amass.ReadTenants(*serveTenants)
+	if err != nil {
+		r.Println(err)
+		return
+	}
+
+	var tlsConfig *tls.Config
+	if *serveCert != "" || *serveKey != "" || *serveCA != "" {
+		c, err := amass.LoadWorkerTLS(*serveCert, *serveKey, *serveCA)
+		if err != nil {
+			r.Println(err)
+			return
+		}
+		if len(c.Certificates) == 0 {
+			r.Println("The '-cert' and '-key' flags must be provided for the server to accept TLS connections")
+			return
+		}
+		tlsConfig = c
+	}
+
+	srv, err := amass.NewEnumServer(*serveDir, tenants, *serveMaxJobs, runServerJob)
+	if err != nil {
+		r.Println(err)
+		return
+	}
+	srv.SetLog(log.New(os.Stderr, "", log.Lmicroseconds))
+
+	g.Printf("Running the enumerations of %d tenants on %s\n", len(tenants), *serveAddr)
+	if err := amass.ServeEnumServer(*serveAddr, srv.Handler(), tlsConfig); err != nil {
+		r.Println(err)
+	}
+}
+
+// runServerJob - Runs the enumeration of the job with the enum subcommand of this binary
+func runServerJob(ctx context.Context, settings *amass.JobSettings) error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path, enumJobArgs(settings)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func enumJobArgs(settings *amass.JobSettings) []string {
+	args := []string{"enum", "-noprogress",
+		"-d", strings.Join(settings.Domains, ","),
+		"-json", settings.JSONPath,
+		"-log", settings.LogPath,
+	}
+
+	if settings.Passive {
+		args = append(args, "-passive")
+	}
+	if settings.Active {
+		args = append(args, "-active")
+	}
+	if settings.BruteForce {
+		args = append(args, "-brute")
+	}
+	if len(settings.DisabledSources) > 0 {
+		args = append(args, "-exclude", strings.Join(settings.DisabledSources, ","))
+	}
+	for _, name := range settings.Blacklist {
+		args = append(args, "-bl", name)
+	}
+	if settings.QueriesPerMinute > 0 {
+		args = append(args, "-freq", strconv.FormatInt(settings.QueriesPerMinute, 10))
+	}
+	if settings.MaxDNSQueries > 0 {
+		args = append(args, "-max-queries", strconv.FormatUint(settings.MaxDNSQueries, 10))
+	}
+	if settings.MaxSourceCalls > 0 {
+		args = append(args, "-max-calls", strconv.Itoa(settings.MaxSourceCalls))
+	}
+	if settings.MaxRuntime > 0 {
+		args = append(args, "-max-runtime", settings.MaxRuntime.String())
+	}
+	return args
+}
@@ -13,15 +13,18 @@ import (
 	"github.com/OWASP/Amass/amass"
 )
 
-// If the user interrupts the program, print the summary information
-func SignalHandler(e *amass.Enumeration, output chan *amass.AmassOutput, done chan struct{}) {
+// If the user interrupts the program, print the summary information, and reload the
+// configuration on SIGHUP
+func SignalHandler(e *amass.Enumeration, done chan struct{}) {
 	quit := make(chan os.Signal, 1)
 	pause := make(chan os.Signal, 1)
 	resume := make(chan os.Signal, 1)
+	reload := make(chan os.Signal, 1)
 
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	signal.Notify(pause, syscall.SIGTSTP)
 	signal.Notify(resume, syscall.SIGCONT)
+	signal.Notify(reload, syscall.SIGHUP)
 loop:
 	for {
 		select {
@@ -29,9 +32,11 @@ loop:
 			e.Pause()
 		case <-resume:
 			e.Resume()
+		case <-reload:
+			reloadConfig(e)
 		case <-quit:
 			// Start final output operations
-			close(output)
+			e.Stop()
 			// Wait for the broadcast indicating completion
 			<-done
 			break loop
@@ -14,14 +14,14 @@ import (
 )
 
 // If the user interrupts the program, print the summary information
-func SignalHandler(e *amass.Enumeration, output chan *amass.AmassOutput, done chan struct{}) {
+func SignalHandler(e *amass.Enumeration, done chan struct{}) {
 	quit := make(chan os.Signal, 1)
 
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	<-quit
 	// Start final output operations
-	close(output)
+	e.Stop()
 	// Wait for the broadcast indicating completion
 	<-done
 	os.Exit(1)
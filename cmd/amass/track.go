@@ -0,0 +1,98 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/amass/handlers"
+)
+
+var (
+	trackCommand = flag.NewFlagSet("track", flag.ExitOnError)
+
+	trackHelp  = trackCommand.Bool("h", false, "Show the program usage message")
+	trackStore = trackCommand.String("store", "", "Path to the local graph file populated by the enumerations (default: in the output directory)")
+	gone       = trackCommand.Bool("disappeared", false, "List only the names that were no longer observed")
+	moved      = trackCommand.Bool("changed", false, "List only the names that gained or lost addresses")
+	since      = trackCommand.String("since", "", "Start of the date range (YYYY-MM-DD)")
+	until      = trackCommand.String("until", "", "End of the date range (YYYY-MM-DD)")
+)
+
+const dateLayout = "2006-01-02"
+
+// runTrackCommand - Reports the names in the local graph that disappeared or changed addresses
+func runTrackCommand(args []string) {
+	trackCommand.Parse(args)
+
+	if *trackHelp {
+		fmt.Printf("Usage: %s track [--store path] [--since date] [--until date]\n", filepath.Base(os.Args[0]))
+		trackCommand.PrintDefaults()
+		return
+	}
+
+	store := *trackStore
+	if store == "" {
+		path, err := defaultStore()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		store = path
+	}
+
+	var from, to time.Time
+	if *since != "" {
+		t, err := time.Parse(dateLayout, *since)
+		if err != nil {
+			fmt.Printf("The '-since' date must be provided as YYYY-MM-DD: %v\n", err)
+			return
+		}
+		from = t
+	}
+	if *until != "" {
+		t, err := time.Parse(dateLayout, *until)
+		if err != nil {
+			fmt.Printf("The '-until' date must be provided as YYYY-MM-DD: %v\n", err)
+			return
+		}
+		// The range includes the entire last day
+		to = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	qs, err := handlers.NewQuadStore(store)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer qs.Close()
+
+	// Both reports are provided when neither was selected
+	all := !*gone && !*moved
+	if *gone || all {
+		for _, a := range qs.Disappeared(from, to) {
+			fmt.Printf("%s (first seen %s, last seen %s)\n", a.Name,
+				a.FirstSeen.Format(dateLayout), a.LastSeen.Format(dateLayout))
+		}
+	}
+
+	if *moved || all {
+		for _, c := range qs.AddressChanges(from, to) {
+			var changes []string
+
+			for _, addr := range c.Added {
+				changes = append(changes, "+"+addr)
+			}
+			for _, addr := range c.Removed {
+				changes = append(changes, "-"+addr)
+			}
+			fmt.Printf("%s %s\n", c.Name, strings.Join(changes, " "))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/OWASP/Amass/amass"
+)
+
+var (
+	updateCommand = flag.NewFlagSet("update", flag.ExitOnError)
+
+	updateHelp = updateCommand.Bool("h", false, "Show the program usage message")
+	updateURL  = updateCommand.String("url", amass.DefaultDataURL, "URL of the release endpoint providing the data files")
+	updateDir  = updateCommand.String("dir", "", "Path to a directory where the data files are kept (default: in the output directory)")
+)
+
+// runUpdateCommand - Downloads the data files that changed since the last update
+func runUpdateCommand(args []string) {
+	updateCommand.Parse(args)
+
+	if *updateHelp {
+		fmt.Printf("Usage: %s update [--url URL] [--dir path]\n", filepath.Base(os.Args[0]))
+		updateCommand.PrintDefaults()
+		return
+	}
+
+	dir := *updateDir
+	if dir == "" {
+		path, err := amass.DataDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		dir = path
+	}
+
+	updates, err := amass.UpdateDataFiles(*updateURL, dir)
+	if err != nil {
+		r.Println(err)
+		os.Exit(1)
+	}
+
+	var failed bool
+	for _, u := range updates {
+		switch {
+		case u.Err != nil:
+			failed = true
+			r.Printf("%s: %v\n", u.Name, u.Err)
+		case u.Updated:
+			g.Printf("%s: updated\n", u.Name)
+		default:
+			fmt.Printf("%s: up to date\n", u.Name)
+		}
+	}
+	fmt.Printf("The data files are kept in %s\n", dir)
+	if *updateDir != "" {
+		fmt.Printf("Provide -data-dir %s to the enum subcommand to use them\n", dir)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
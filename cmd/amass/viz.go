@@ -7,36 +7,39 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/OWASP/Amass/amass/handlers"
 	"github.com/OWASP/Amass/amass/utils/viz"
 )
 
 var (
-	help           = flag.Bool("h", false, "Show the program usage message")
-	input          = flag.String("i", "", "The Amass data operations JSON file")
-	visjspath      = flag.String("visjs", "", "Path to the Visjs output HTML file")
-	graphistrypath = flag.String("graphistry", "", "Path to the Graphistry JSON file")
-	gexfpath       = flag.String("gexf", "", "Path to the Gephi Graph Exchange XML Format (GEXF) file")
-	d3path         = flag.String("d3", "", "Path to the D3 v4 force simulation HTML file")
+	vizCommand = flag.NewFlagSet("viz", flag.ExitOnError)
+
+	vizHelp        = vizCommand.Bool("h", false, "Show the program usage message")
+	vizInput       = vizCommand.String("i", "", "The Amass data operations JSON file")
+	visjspath      = vizCommand.String("visjs", "", "Path to the Visjs output HTML file")
+	graphistrypath = vizCommand.String("graphistry", "", "Path to the Graphistry JSON file")
+	gexfpath       = vizCommand.String("gexf", "", "Path to the Gephi Graph Exchange XML Format (GEXF) file")
+	d3path         = vizCommand.String("d3", "", "Path to the D3 v4 force simulation HTML file")
 )
 
-func main() {
-	flag.Parse()
+// runVizCommand - Writes the network graph built from the data operations in the formats requested
+func runVizCommand(args []string) {
+	vizCommand.Parse(args)
 
-	if *help {
-		fmt.Printf("Usage: %s -i infile --visjs of1 --gexf of2 --d3 of3 --graphistry of4\n", path.Base(os.Args[0]))
-		flag.PrintDefaults()
+	if *vizHelp {
+		fmt.Printf("Usage: %s viz -i infile --visjs of1 --gexf of2 --d3 of3 --graphistry of4\n", filepath.Base(os.Args[0]))
+		vizCommand.PrintDefaults()
 		return
 	}
 
-	if *input == "" {
+	if *vizInput == "" {
 		fmt.Println("The data operations JSON file must be provided using the '-i' flag")
 		return
 	}
 
-	f, err := os.Open(*input)
+	f, err := os.Open(*vizInput)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return